@@ -0,0 +1,153 @@
+package rig
+
+import (
+	"net"
+	"time"
+)
+
+// AccessEvent describes a handled request for audit and security (SIEM)
+// pipelines. Unlike ResponseEvent, it holds only plain values, so it can be
+// sent to other goroutines and retained after the request.
+type AccessEvent struct {
+	// Time is when the request started.
+	Time time.Time `json:"time"`
+
+	// Method and Path are the request method and URL path.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Route is the matched route pattern (e.g., "GET /users/{id}").
+	Route string `json:"route"`
+
+	// Status is the response status. If the handler returned an error, it
+	// is the status the error handler answers with (500 unless the error
+	// carries a status).
+	Status int `json:"status"`
+
+	// Bytes is the number of response body bytes written by the handler.
+	Bytes int64 `json:"bytes"`
+
+	// Latency is the time the handler took.
+	Latency time.Duration `json:"latency"`
+
+	// IP is the client address. Register RealIP first behind a proxy.
+	IP string `json:"ip"`
+
+	// Identity is the authenticated identity stored under IdentityKey, if
+	// any.
+	Identity string `json:"identity,omitempty"`
+
+	// RequestID is the request ID stored under RequestIDKey, if any.
+	RequestID string `json:"request_id,omitempty"`
+
+	// UserAgent is the User-Agent request header.
+	UserAgent string `json:"user_agent,omitempty"`
+
+	// Error is the message of the error returned by the handler, if any.
+	Error string `json:"error,omitempty"`
+}
+
+// AccessEventConfig defines the configuration for AccessEvents middleware.
+// At least one of Handler and Channel is required.
+type AccessEventConfig struct {
+	// Handler is called with every event, synchronously on the request
+	// goroutine. Hand slow work off to a queue.
+	Handler func(AccessEvent)
+
+	// Channel receives every event. Sends never block: when the channel is
+	// full, the event is passed to OnDrop instead, so a stalled consumer
+	// cannot slow down requests.
+	Channel chan<- AccessEvent
+
+	// OnDrop is called with events that did not fit in Channel.
+	// Default: nil (dropped events are discarded).
+	OnDrop func(AccessEvent)
+
+	// Skipper selects requests that emit no event, such as health checks.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// AccessEvents creates middleware that emits a typed AccessEvent (route,
+// identity, status, latency, bytes, client IP, ...) for every request it
+// handles, to a callback or a channel. It is separate from text logging,
+// so audit and SIEM pipelines receive structured data they can rely on.
+// Register it after authentication and request ID middleware, so events
+// carry the identity and request ID. It panics if neither config.Handler
+// nor config.Channel is set.
+//
+// Example:
+//
+//	events := make(chan rig.AccessEvent, 1024)
+//	go func() {
+//	    for e := range events {
+//	        siem.Send(e)
+//	    }
+//	}()
+//
+//	api.Use(auth.Bearer(bearerConfig))
+//	api.Use(rig.AccessEvents(rig.AccessEventConfig{
+//	    Channel: events,
+//	    OnDrop:  func(rig.AccessEvent) { droppedEvents.Inc() },
+//	}))
+func AccessEvents(config AccessEventConfig) MiddlewareFunc {
+	if config.Handler == nil && config.Channel == nil {
+		panic("rig: AccessEvents requires a Handler or a Channel")
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+
+			event := AccessEvent{
+				Time:      start,
+				Method:    c.Method(),
+				Path:      c.Path(),
+				Route:     c.Request().Pattern,
+				Status:    c.StatusCode(),
+				Bytes:     c.BytesWritten(),
+				Latency:   time.Since(start),
+				IP:        clientIP(c),
+				UserAgent: c.GetHeader("User-Agent"),
+			}
+			event.Identity, _ = GetType[string](c, IdentityKey)
+			event.RequestID, _ = GetType[string](c, RequestIDKey)
+			if err != nil {
+				event.Error = err.Error()
+				// The router's error handler writes the response after this
+				// middleware, so infer the status from the error
+				if !c.Written() {
+					event.Status = ErrorStatus(err)
+				}
+			}
+
+			if config.Handler != nil {
+				config.Handler(event)
+			}
+			if config.Channel != nil {
+				select {
+				case config.Channel <- event:
+				default:
+					if config.OnDrop != nil {
+						config.OnDrop(event)
+					}
+				}
+			}
+			return err
+		}
+	}
+}
+
+// clientIP returns the host of the request's RemoteAddr.
+func clientIP(c *Context) string {
+	addr := c.Request().RemoteAddr
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
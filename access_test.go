@@ -0,0 +1,88 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAccessEvents(t *testing.T) {
+	var events []AccessEvent
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(IdentityKey, "alice")
+			c.Set(RequestIDKey, "01HREQ")
+			return next(c)
+		}
+	})
+	r.Use(AccessEvents(AccessEventConfig{
+		Handler: func(e AccessEvent) { events = append(events, e) },
+		Skipper: PathPrefix("/health"),
+	}))
+	r.GET("/users/{id}", func(c *Context) error {
+		switch c.Param("id") {
+		case "0":
+			return NewHTTPError(http.StatusNotFound, "no such user")
+		case "x":
+			return errors.Join(NewHTTPError(http.StatusBadRequest, "bad id"), errors.New("db down"))
+		}
+		return c.String(http.StatusOK, "user")
+	})
+	r.GET("/health", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	for _, target := range []string{"/users/7", "/users/0", "/users/x", "/health"} {
+		req := httptest.NewRequest(http.MethodGet, target, nil)
+		req.RemoteAddr = "203.0.113.9:4242"
+		req.Header.Set("User-Agent", "test-agent")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+	e := events[0]
+	if e.Route != "GET /users/{id}" || e.Path != "/users/7" || e.Status != http.StatusOK || e.Bytes != 4 ||
+		e.IP != "203.0.113.9" || e.Identity != "alice" || e.RequestID != "01HREQ" || e.UserAgent != "test-agent" ||
+		e.Time.IsZero() || e.Error != "" {
+		t.Errorf("event = %+v", e)
+	}
+	if e := events[1]; e.Status != http.StatusNotFound || e.Error == "" {
+		t.Errorf("error event: status = %d, error = %q", e.Status, e.Error)
+	}
+	if e := events[2]; e.Status != http.StatusInternalServerError {
+		t.Errorf("joined error event: status = %d, want %d", e.Status, http.StatusInternalServerError)
+	}
+}
+
+func TestAccessEvents_Channel(t *testing.T) {
+	events := make(chan AccessEvent, 1)
+	dropped := 0
+	r := New()
+	r.Use(AccessEvents(AccessEventConfig{
+		Channel: events,
+		OnDrop:  func(AccessEvent) { dropped++ },
+	}))
+	r.GET("/", func(c *Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	for range 3 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if e := <-events; e.Status != http.StatusNoContent || dropped != 2 {
+		t.Errorf("status = %d, dropped = %d, want 204 and 2", e.Status, dropped)
+	}
+}
+
+func TestAccessEvents_NoSink(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("AccessEvents without a sink did not panic")
+		}
+	}()
+	AccessEvents(AccessEventConfig{})
+}
@@ -35,12 +35,25 @@ import (
 // Context keys for accessing authentication information in handlers.
 const (
 	// ContextKeyIdentity holds the authenticated identity (e.g., user ID, service name).
-	ContextKeyIdentity = "auth.identity"
+	// It is reported as ResponseEvent.Identity to rig.Router.OnResponse hooks.
+	ContextKeyIdentity = rig.IdentityKey
+
+	// ContextKeyRoles holds the roles ([]string) of the authenticated identity,
+	// as returned by the Roles function of the configuration. rig.RequireRole
+	// checks them.
+	ContextKeyRoles = rig.RolesKey
 
 	// ContextKeyMethod holds the authentication method used (e.g., "api_key", "bearer").
 	ContextKeyMethod = "auth.method"
 )
 
+// Message keys for the default error responses.
+// Register translations for these keys with rig.Router.SetTranslator to localize them.
+const (
+	MsgInvalidAPIKey      = "auth.invalid_api_key"
+	MsgInvalidBearerToken = "auth.invalid_bearer_token"
+)
+
 // ErrorResponse is the default error response structure.
 type ErrorResponse struct {
 	Error string `json:"error"`
@@ -51,9 +64,10 @@ type ErrorResponse struct {
 type ErrorHandler func(c *rig.Context) error
 
 // defaultErrorHandler returns a JSON error response with 401 status.
-func defaultErrorHandler(message string) ErrorHandler {
+// The message is translated to the request locale, falling back to the given text.
+func defaultErrorHandler(key, message string) ErrorHandler {
 	return func(c *rig.Context) error {
-		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: message})
+		return c.JSON(http.StatusUnauthorized, ErrorResponse{Error: c.Translate(key, message)})
 	}
 }
 
@@ -74,9 +88,20 @@ type APIKeyConfig struct {
 	// The identity is stored in the context under ContextKeyIdentity.
 	Validator func(key string) (identity string, valid bool)
 
+	// Roles returns the roles of an authenticated identity (e.g., "admin"),
+	// which are stored in the context under ContextKeyRoles for
+	// rig.RequireRole and rig.RolePermissions.
+	// If nil, no roles are stored.
+	Roles func(identity string) []string
+
 	// OnError is called when authentication fails.
 	// If nil, a default JSON error response is returned.
 	OnError ErrorHandler
+
+	// Skipper selects requests that are let through without an API key,
+	// unauthenticated.
+	// Default: nil (every request needs a key).
+	Skipper rig.Skipper
 }
 
 // APIKey creates middleware that authenticates requests using an API key.
@@ -93,11 +118,15 @@ func APIKey(config APIKeyConfig) rig.MiddlewareFunc {
 		config.Name = "X-API-Key"
 	}
 	if config.OnError == nil {
-		config.OnError = defaultErrorHandler("Invalid or missing API key")
+		config.OnError = defaultErrorHandler(MsgInvalidAPIKey, "Invalid or missing API key")
 	}
 
 	return func(next rig.HandlerFunc) rig.HandlerFunc {
 		return func(c *rig.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
 			var key string
 
 			switch strings.ToLower(config.Source) {
@@ -119,6 +148,9 @@ func APIKey(config APIKeyConfig) rig.MiddlewareFunc {
 			// Store auth info in context for downstream handlers
 			c.Set(ContextKeyIdentity, identity)
 			c.Set(ContextKeyMethod, "api_key")
+			if config.Roles != nil {
+				c.Set(ContextKeyRoles, config.Roles(identity))
+			}
 
 			return next(c)
 		}
@@ -166,9 +198,20 @@ type BearerConfig struct {
 	// Default: "API".
 	Realm string
 
+	// Roles returns the roles of an authenticated identity (e.g., "admin"),
+	// which are stored in the context under ContextKeyRoles for
+	// rig.RequireRole and rig.RolePermissions.
+	// If nil, no roles are stored.
+	Roles func(identity string) []string
+
 	// OnError is called when authentication fails.
 	// If nil, a default JSON error response is returned with WWW-Authenticate header.
 	OnError ErrorHandler
+
+	// Skipper selects requests that are let through without a token,
+	// unauthenticated.
+	// Default: nil (every request needs a token).
+	Skipper rig.Skipper
 }
 
 // Bearer creates middleware that authenticates requests using Bearer tokens.
@@ -184,11 +227,15 @@ func Bearer(config BearerConfig) rig.MiddlewareFunc {
 		config.Realm = "API"
 	}
 	if config.OnError == nil {
-		config.OnError = defaultErrorHandler("Invalid or missing bearer token")
+		config.OnError = defaultErrorHandler(MsgInvalidBearerToken, "Invalid or missing bearer token")
 	}
 
 	return func(next rig.HandlerFunc) rig.HandlerFunc {
 		return func(c *rig.Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
 			auth := c.GetHeader("Authorization")
 
 			// Check for "Bearer " prefix (case-insensitive as per RFC 6750)
@@ -212,6 +259,9 @@ func Bearer(config BearerConfig) rig.MiddlewareFunc {
 			// Store auth info in context for downstream handlers
 			c.Set(ContextKeyIdentity, identity)
 			c.Set(ContextKeyMethod, "bearer")
+			if config.Roles != nil {
+				c.Set(ContextKeyRoles, config.Roles(identity))
+			}
 
 			return next(c)
 		}
@@ -305,6 +305,98 @@ func TestBearer_WrongScheme(t *testing.T) {
 	}
 }
 
+func TestSkipper(t *testing.T) {
+	validator := func(string) (string, bool) { return "user", true }
+	middlewares := map[string]rig.MiddlewareFunc{
+		"api key": auth.APIKey(auth.APIKeyConfig{Validator: validator, Skipper: rig.MethodIs(http.MethodOptions)}),
+		"bearer":  auth.Bearer(auth.BearerConfig{Validator: validator, Skipper: rig.MethodIs(http.MethodOptions)}),
+	}
+
+	for name, mw := range middlewares {
+		t.Run(name, func(t *testing.T) {
+			r := rig.New()
+			r.Use(mw)
+			r.Handle("GET /api/protected", func(c *rig.Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+			r.Handle("OPTIONS /api/protected", func(c *rig.Context) error {
+				if auth.IsAuthenticated(c) {
+					t.Error("skipped request is authenticated")
+				}
+				return c.NoContent(http.StatusNoContent)
+			})
+
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/protected", nil))
+			if rec.Code != http.StatusUnauthorized {
+				t.Errorf("GET without credentials: expected status 401, got %d", rec.Code)
+			}
+
+			rec = httptest.NewRecorder()
+			r.ServeHTTP(rec, httptest.NewRequest(http.MethodOptions, "/api/protected", nil))
+			if rec.Code != http.StatusNoContent {
+				t.Errorf("skipped OPTIONS: expected status 204, got %d", rec.Code)
+			}
+		})
+	}
+}
+
+// --- Localization Tests ---
+
+func TestDefaultErrorHandler_Localized(t *testing.T) {
+	r := rig.New()
+	r.SetTranslator(rig.Catalog{
+		"es": {auth.MsgInvalidBearerToken: "Token inválido o ausente"},
+	})
+
+	api := r.Group("/api")
+	api.Use(auth.Bearer(auth.BearerConfig{
+		Validator: func(token string) (string, bool) { return "", false },
+	}))
+	api.GET("/protected", func(c *rig.Context) error { return nil })
+
+	req := httptest.NewRequest(http.MethodGet, "/api/protected", nil)
+	req.Header.Set("Accept-Language", "es-MX")
+	rec := httptest.NewRecorder()
+
+	r.ServeHTTP(rec, req)
+
+	var resp auth.ErrorResponse
+	_ = json.Unmarshal(rec.Body.Bytes(), &resp)
+
+	if resp.Error != "Token inválido o ausente" {
+		t.Errorf("expected localized error, got %q", resp.Error)
+	}
+}
+
+func TestRoles(t *testing.T) {
+	r := rig.New()
+	api := r.Group("/api")
+	api.Use(auth.Bearer(auth.BearerConfig{
+		Validator: func(token string) (string, bool) { return "user-" + token, true },
+		Roles: func(identity string) []string {
+			if identity == "user-root" {
+				return []string{"admin"}
+			}
+			return []string{"viewer"}
+		},
+	}))
+	api.Use(rig.RequireRole("admin"))
+	api.GET("/admin", func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, rig.Roles(c))
+	})
+
+	for token, want := range map[string]int{"root": http.StatusOK, "jane": http.StatusForbidden} {
+		req := httptest.NewRequest(http.MethodGet, "/api/admin", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		if rec.Code != want {
+			t.Errorf("token %q: status = %d, want %d", token, rec.Code, want)
+		}
+	}
+}
+
 // --- Helper Function Tests ---
 
 func TestHelperFunctions(t *testing.T) {
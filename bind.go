@@ -0,0 +1,780 @@
+package rig
+
+import (
+	"encoding"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"maps"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"net/url"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultMultipartMemory is the maximum number of bytes of a multipart
+// request body stored in memory by BindAny; the rest is stored on disk.
+const DefaultMultipartMemory = 32 << 20 // 32MB
+
+// ErrUnsupportedMediaType is returned by BindAny when no binder is
+// available for the request's Content-Type.
+var ErrUnsupportedMediaType = errors.New("rig: unsupported media type")
+
+// BinderFunc decodes the request body of c into v.
+// Register one with RegisterBinder or Router.RegisterBinder to support
+// custom media types.
+type BinderFunc func(c *Context, v any) error
+
+var (
+	bindersMu sync.RWMutex
+	binders   = map[string]BinderFunc{}
+)
+
+// BindError is returned by BindQuery, BindHeader, BindPath, and form binding
+// when a value cannot be converted to the type of its struct field.
+// It reports http.StatusBadRequest through StatusCode, so DefaultErrorHandler
+// answers with 400 when a handler returns it unchanged.
+type BindError struct {
+	// Source is where the value came from: "query", "form", "header", or "path".
+	Source string
+
+	// Name is the parameter name (e.g., "page" or "filter[age]").
+	Name string
+
+	// Value is the raw value that failed to convert.
+	Value string
+
+	// Err is the underlying conversion error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *BindError) Error() string {
+	return fmt.Sprintf("rig: invalid value %q for %s '%s': %v", e.Value, e.Source, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying conversion error.
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// StatusCode returns http.StatusBadRequest.
+func (e *BindError) StatusCode() int {
+	return http.StatusBadRequest
+}
+
+// QueryBindConfig controls how BindQuery maps query parameters onto struct fields.
+// Use DefaultQueryBindConfig() to get the defaults and Router.SetQueryBindConfig
+// to change them for a router.
+type QueryBindConfig struct {
+	// CommaSeparated splits values on commas when binding slice fields,
+	// so ?ids=1,2,3 binds the same as ?ids=1&ids=2&ids=3.
+	// Default: true.
+	CommaSeparated bool
+
+	// Brackets enables bracketed keys for nested data:
+	//   - ?ids[]=1&ids[]=2 binds to a slice field tagged `query:"ids"`
+	//   - ?filter[status]=active binds to a map or struct field tagged `query:"filter"`
+	//   - ?items[0][sku]=A1 (or items[0].sku) binds to a slice-of-structs field tagged `query:"items"`
+	// When false, nested keys use dot notation instead (?filter.status=active).
+	// Default: true.
+	Brackets bool
+}
+
+// DefaultQueryBindConfig returns the default query binding configuration,
+// which accepts both comma-separated and bracketed array parameters.
+func DefaultQueryBindConfig() QueryBindConfig {
+	return QueryBindConfig{
+		CommaSeparated: true,
+		Brackets:       true,
+	}
+}
+
+// SetQueryBindConfig sets how BindQuery parses query parameters for
+// requests handled by this router.
+func (r *Router) SetQueryBindConfig(config QueryBindConfig) {
+	r.queryBind = config
+}
+
+// BindQuery binds URL query parameters into the struct pointed to by v.
+// Fields are matched by their `query` tag; untagged fields are ignored,
+// except embedded structs whose fields are bound as if declared inline.
+//
+// Supported field types are strings, booleans, integers, floats,
+// time.Duration, types implementing encoding.TextUnmarshaler (including
+// time.Time in RFC 3339 format), pointers to these, slices of these,
+// maps with string keys, nested structs, and slices of structs bound from
+// indexed keys (?items[0][sku]=A1&items[1][sku]=B2, or items[0].sku).
+//
+// Example:
+//
+//	type ListParams struct {
+//	    IDs    []int             `query:"ids"`    // ?ids=1,2,3 or ?ids[]=1&ids[]=2
+//	    Filter map[string]string `query:"filter"` // ?filter[status]=active&filter[age.gt]=30
+//	    Page   int               `query:"page"`
+//	}
+//
+//	var params ListParams
+//	if err := c.BindQuery(&params); err != nil {
+//	    return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+//	}
+func (c *Context) BindQuery(v any) error {
+	return bindValues(v, c.queryParams(), "query", c.bindConfig())
+}
+
+// BindHeader binds request headers into the struct pointed to by v.
+// Fields are matched by their `header` tag; header names are case-insensitive.
+// Slice fields receive every value of a repeated header, and comma-separated
+// values (e.g., "X-Features: beta, dark-mode") are split into elements.
+// Supported field types are the same as for BindQuery, except maps and
+// nested structs.
+//
+// Example:
+//
+//	type RequestMeta struct {
+//	    TenantID      string   `header:"X-Tenant-ID"`
+//	    CorrelationID string   `header:"X-Correlation-ID"`
+//	    Features      []string `header:"X-Features"`
+//	}
+//
+//	var meta RequestMeta
+//	if err := c.BindHeader(&meta); err != nil {
+//	    return err
+//	}
+func (c *Context) BindHeader(v any) error {
+	b := &valueBinder{
+		values:       url.Values(c.request.Header),
+		tag:          "header",
+		config:       QueryBindConfig{CommaSeparated: true},
+		canonicalKey: textproto.CanonicalMIMEHeaderKey,
+	}
+	return b.bind(v)
+}
+
+// BindPath binds path parameters of the matched route into the struct
+// pointed to by v. Fields are matched by their `path` tag, using the wildcard
+// names from the route pattern (e.g., {id} or {path...}).
+//
+// A value that does not convert to its field type returns a *BindError,
+// which DefaultErrorHandler answers with 400 Bad Request.
+//
+// Example:
+//
+//	type PostParams struct {
+//	    UserID int    `path:"userID"`
+//	    Slug   string `path:"slug"`
+//	}
+//
+//	r.GET("/users/{userID}/posts/{slug}", func(c *rig.Context) error {
+//	    var p PostParams
+//	    if err := c.BindPath(&p); err != nil {
+//	        return err // 400 if userID is not an integer
+//	    }
+//	    ...
+//	})
+func (c *Context) BindPath(v any) error {
+	values := make(url.Values)
+	for _, name := range patternWildcards(c.request.Pattern) {
+		if value := c.request.PathValue(name); value != "" {
+			values[name] = []string{value}
+		}
+	}
+	return bindValues(v, values, "path", QueryBindConfig{})
+}
+
+// patternWildcards returns the wildcard names in a ServeMux pattern,
+// e.g., ["id", "rest"] for "GET /users/{id}/{rest...}".
+func patternWildcards(pattern string) []string {
+	var names []string
+	for {
+		start := strings.IndexByte(pattern, '{')
+		if start == -1 {
+			return names
+		}
+		end := strings.IndexByte(pattern[start:], '}')
+		if end == -1 {
+			return names
+		}
+		name := strings.TrimSuffix(pattern[start+1:start+end], "...")
+		if name != "" && name != "$" {
+			names = append(names, name)
+		}
+		pattern = pattern[start+end+1:]
+	}
+}
+
+// RegisterBinder registers a BinderFunc used by Bind and BindAny for the
+// given media type (e.g., "application/msgpack"). Registered binders take
+// precedence over the built-in JSON, XML, and form binders, so they can also
+// replace them.
+//
+// Example:
+//
+//	r.RegisterBinder("application/msgpack", func(c *rig.Context, v any) error {
+//	    return msgpack.NewDecoder(c.Request().Body).Decode(v)
+//	})
+func (r *Router) RegisterBinder(mediaType string, binder BinderFunc) {
+	if r.binders == nil {
+		r.binders = make(map[string]BinderFunc)
+	}
+	r.binders[strings.ToLower(mediaType)] = binder
+}
+
+// RegisterBinder registers a BinderFunc for the given media type (e.g.,
+// "application/vnd.custom+cbor") for all routers. Requests with that
+// Content-Type are then decoded by the binder in both Bind and BindAny.
+// Binders registered on a router with Router.RegisterBinder take precedence.
+// It is safe for concurrent use, but is typically called during startup.
+//
+// A binder must read the request body itself: calling c.Bind or c.BindAny
+// from within it would select the same binder again.
+//
+// Example:
+//
+//	rig.RegisterBinder("application/vnd.custom+cbor", func(c *rig.Context, v any) error {
+//	    return cbor.NewDecoder(c.Request().Body).Decode(v)
+//	})
+func RegisterBinder(mediaType string, binder BinderFunc) {
+	bindersMu.Lock()
+	defer bindersMu.Unlock()
+	binders[strings.ToLower(mediaType)] = binder
+}
+
+// customBinder returns the registered binder for the request's Content-Type:
+// the router's binder first, then the global one.
+func (c *Context) customBinder() (BinderFunc, bool) {
+	mediaType, _, err := mime.ParseMediaType(c.GetHeader("Content-Type"))
+	if err != nil {
+		return nil, false
+	}
+	if c.router != nil {
+		if binder, ok := c.router.binders[mediaType]; ok {
+			return binder, true
+		}
+	}
+	bindersMu.RLock()
+	defer bindersMu.RUnlock()
+	binder, ok := binders[mediaType]
+	return binder, ok
+}
+
+// BindAny decodes the request body into v based on the request's Content-Type:
+//   - application/json and */*+json: JSON (same as Bind)
+//   - application/xml, text/xml and */*+xml: XML
+//   - application/x-www-form-urlencoded: form fields, matched by `form` tags
+//   - multipart/form-data: form fields and files, matched by `form` tags
+//
+// Form fields are bound with the same rules as BindQuery. Fields of type
+// *multipart.FileHeader or []*multipart.FileHeader receive uploaded files.
+//
+// Binders registered with Router.RegisterBinder or RegisterBinder are
+// consulted first.
+// A request without a body is a no-op. An unknown Content-Type returns
+// an error wrapping ErrUnsupportedMediaType.
+//
+// Example:
+//
+//	type SignupForm struct {
+//	    Email  string                `form:"email" json:"email"`
+//	    Avatar *multipart.FileHeader `form:"avatar"`
+//	}
+//
+//	var form SignupForm
+//	if err := c.BindAny(&form); err != nil {
+//	    return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+//	}
+func (c *Context) BindAny(v any) error {
+	if c.request.Body == nil || c.request.Body == http.NoBody {
+		return nil
+	}
+
+	contentType := c.GetHeader("Content-Type")
+	if contentType == "" {
+		if c.request.ContentLength == 0 {
+			return nil
+		}
+		return fmt.Errorf("%w: missing Content-Type", ErrUnsupportedMediaType)
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("%w: %q", ErrUnsupportedMediaType, contentType)
+	}
+
+	if binder, ok := c.customBinder(); ok {
+		return bodyError(binder(c, v))
+	}
+
+	switch {
+	case mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"):
+		return c.bindJSON(v)
+	case mediaType == "application/xml" || mediaType == "text/xml" || strings.HasSuffix(mediaType, "+xml"):
+		return c.bindXML(v)
+	case mediaType == "application/x-www-form-urlencoded":
+		if err := c.request.ParseForm(); err != nil {
+			return bodyError(err)
+		}
+		return bindValues(v, c.request.PostForm, "form", c.bindConfig())
+	case mediaType == "multipart/form-data":
+		if err := c.request.ParseMultipartForm(DefaultMultipartMemory); err != nil {
+			return bodyError(err)
+		}
+		return bindMultipart(v, c.request.MultipartForm, c.bindConfig())
+	}
+
+	return fmt.Errorf("%w: %q", ErrUnsupportedMediaType, mediaType)
+}
+
+// bindXML decodes an XML request body into v and closes the body.
+func (c *Context) bindXML(v any) error {
+	defer func() { _ = c.request.Body.Close() }()
+	return bodyError(xml.NewDecoder(c.request.Body).Decode(v))
+}
+
+// bindConfig returns the router's binding configuration, or the defaults
+// for contexts created outside a router.
+func (c *Context) bindConfig() QueryBindConfig {
+	if c.router != nil {
+		return c.router.queryBind
+	}
+	return DefaultQueryBindConfig()
+}
+
+// bindMultipart binds multipart form values and files into v.
+func bindMultipart(v any, form *multipart.Form, config QueryBindConfig) error {
+	return (&valueBinder{values: form.Value, files: form.File, tag: "form", config: config}).bind(v)
+}
+
+// textUnmarshalerType is used to detect fields with custom text decoding.
+var textUnmarshalerType = reflect.TypeFor[encoding.TextUnmarshaler]()
+
+// durationType is used to parse time.Duration fields with time.ParseDuration.
+var durationType = reflect.TypeFor[time.Duration]()
+
+// Multipart file field types, populated from multipart.Form.File.
+var (
+	fileHeaderType      = reflect.TypeFor[*multipart.FileHeader]()
+	fileHeaderSliceType = reflect.TypeFor[[]*multipart.FileHeader]()
+)
+
+// valueBinder maps string values keyed by name (query parameters, form
+// fields, headers) onto struct fields selected by a struct tag.
+type valueBinder struct {
+	values url.Values
+	files  map[string][]*multipart.FileHeader
+	tag    string
+	config QueryBindConfig
+
+	// canonicalKey normalizes tag names before lookup (e.g., header names).
+	canonicalKey func(string) string
+}
+
+// bindValues binds values into the struct pointed to by v using the given tag.
+func bindValues(v any, values url.Values, tag string, config QueryBindConfig) error {
+	return (&valueBinder{values: values, tag: tag, config: config}).bind(v)
+}
+
+// bind binds into the struct pointed to by v.
+func (b *valueBinder) bind(v any) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return errors.New("rig: bind target must be a non-nil pointer to a struct")
+	}
+
+	if b.config.Brackets {
+		b.values = normalizeIndexedKeys(b.values)
+		b.files = normalizeIndexedKeys(b.files)
+	}
+
+	_, err := b.bindStruct(rv.Elem(), "")
+	return err
+}
+
+// indexedDotKey matches a dotted field name after an index, such as ".sku"
+// in "items[0].sku".
+var indexedDotKey = regexp.MustCompile(`\]\.([^.\[\]]+)`)
+
+// normalizeIndexedKeys rewrites keys such as "items[0].sku" to the bracket
+// form "items[0][sku]", so both notations bind in bracket mode. It returns
+// m unchanged if no key needs rewriting.
+func normalizeIndexedKeys[V any](m map[string][]V) map[string][]V {
+	var normalized map[string][]V
+	for k, values := range m {
+		if !strings.Contains(k, "].") {
+			continue
+		}
+		if normalized == nil {
+			normalized = make(map[string][]V, len(m))
+			for k, v := range m {
+				normalized[k] = v
+			}
+		}
+		nk := k
+		for {
+			next := indexedDotKey.ReplaceAllString(nk, "][$1]")
+			if next == nk {
+				break
+			}
+			nk = next
+		}
+		delete(normalized, k)
+		normalized[nk] = append(normalized[nk], values...)
+	}
+	if normalized == nil {
+		return m
+	}
+	return normalized
+}
+
+// key returns the lookup key for a field name nested under prefix.
+func (b *valueBinder) key(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if b.config.Brackets {
+		return prefix + "[" + name + "]"
+	}
+	return prefix + "." + name
+}
+
+// bindStruct binds every tagged field of sv. It reports whether any field was set.
+func (b *valueBinder) bindStruct(sv reflect.Value, prefix string) (bool, error) {
+	st := sv.Type()
+	found := false
+
+	for i := range st.NumField() {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := sv.Field(i)
+
+		name, _, _ := strings.Cut(sf.Tag.Get(b.tag), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			// Embedded structs are flattened into the parent
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				ok, err := b.bindStruct(fv, prefix)
+				if err != nil {
+					return found, err
+				}
+				found = found || ok
+			}
+			continue
+		}
+
+		ok, err := b.bindField(fv, b.key(prefix, name))
+		if err != nil {
+			return found, err
+		}
+		found = found || ok
+	}
+
+	return found, nil
+}
+
+// bindField binds the values stored under key into fv.
+// It reports whether any value was found.
+func (b *valueBinder) bindField(fv reflect.Value, key string) (bool, error) {
+	switch fv.Type() {
+	case fileHeaderType:
+		files := b.files[key]
+		if len(files) == 0 {
+			return false, nil
+		}
+		fv.Set(reflect.ValueOf(files[0]))
+		return true, nil
+	case fileHeaderSliceType:
+		files := b.files[key]
+		if len(files) == 0 {
+			return false, nil
+		}
+		fv.Set(reflect.ValueOf(files))
+		return true, nil
+	}
+
+	if isScalarType(fv.Type()) {
+		values := b.lookup(key)
+		if len(values) == 0 {
+			return false, nil
+		}
+		return true, b.setScalar(fv, values[0], key)
+	}
+
+	switch fv.Kind() {
+	case reflect.Slice:
+		if isStructType(fv.Type().Elem()) {
+			return b.bindStructList(fv, key)
+		}
+		values := b.lookupList(key)
+		if len(values) == 0 {
+			return false, nil
+		}
+		return true, b.setList(fv, values, key)
+
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String {
+			return false, fmt.Errorf("rig: unsupported map key type %s for %s '%s'", fv.Type().Key(), b.tag, key)
+		}
+		entries := b.lookupMap(key)
+		if len(entries) == 0 {
+			return false, nil
+		}
+		m := reflect.MakeMapWithSize(fv.Type(), len(entries))
+		for k, values := range entries {
+			elem := reflect.New(fv.Type().Elem()).Elem()
+			entryKey := b.key(key, k)
+			var err error
+			if elem.Kind() == reflect.Slice && !isScalarType(elem.Type()) {
+				err = b.setList(elem, b.splitList(values), entryKey)
+			} else {
+				err = b.setScalar(elem, values[0], entryKey)
+			}
+			if err != nil {
+				return false, err
+			}
+			m.SetMapIndex(reflect.ValueOf(k).Convert(fv.Type().Key()), elem)
+		}
+		fv.Set(m)
+		return true, nil
+
+	case reflect.Struct:
+		return b.bindStruct(fv, key)
+
+	case reflect.Pointer:
+		nv := reflect.New(fv.Type().Elem())
+		ok, err := b.bindField(nv.Elem(), key)
+		if ok && err == nil {
+			fv.Set(nv)
+		}
+		return ok, err
+	}
+
+	return false, fmt.Errorf("rig: unsupported field type %s for %s '%s'", fv.Type(), b.tag, key)
+}
+
+// bindStructList binds a slice of structs from indexed keys such as
+// items[0][sku] and items[1][sku]. Elements are ordered by index; gaps
+// (e.g., rows removed from a dynamic form) are skipped.
+func (b *valueBinder) bindStructList(fv reflect.Value, key string) (bool, error) {
+	indexes := b.indexes(key)
+	if len(indexes) == 0 {
+		return false, nil
+	}
+
+	slice := reflect.MakeSlice(fv.Type(), 0, len(indexes))
+	for _, i := range indexes {
+		elem := reflect.New(fv.Type().Elem()).Elem()
+		if _, err := b.bindField(elem, key+"["+strconv.Itoa(i)+"]"); err != nil {
+			return false, err
+		}
+		slice = reflect.Append(slice, elem)
+	}
+	fv.Set(slice)
+	return true, nil
+}
+
+// indexes returns the sorted, distinct indexes i of keys nested under
+// key[i], e.g., 0 and 1 for items[0][sku] and items[1].qty.
+func (b *valueBinder) indexes(key string) []int {
+	prefix := key + "["
+	seen := make(map[int]bool)
+	collect := func(k string) {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok {
+			return
+		}
+		digits, rest, ok := strings.Cut(rest, "]")
+		if !ok || rest == "" || (rest[0] != '[' && rest[0] != '.') {
+			return
+		}
+		if i, err := strconv.Atoi(digits); err == nil && i >= 0 {
+			seen[i] = true
+		}
+	}
+	for k := range b.values {
+		collect(k)
+	}
+	for k := range b.files {
+		collect(k)
+	}
+	return slices.Sorted(maps.Keys(seen))
+}
+
+// lookup returns the values stored under key.
+func (b *valueBinder) lookup(key string) []string {
+	if b.canonicalKey != nil {
+		key = b.canonicalKey(key)
+	}
+	return b.values[key]
+}
+
+// lookupList returns all values for a slice field, including bracketed
+// (key[]) values, split on commas if enabled.
+func (b *valueBinder) lookupList(key string) []string {
+	values := b.lookup(key)
+	if b.config.Brackets {
+		values = append(values[:len(values):len(values)], b.lookup(key+"[]")...)
+	}
+	return b.splitList(values)
+}
+
+// splitList splits comma-separated values if enabled.
+func (b *valueBinder) splitList(values []string) []string {
+	if !b.config.CommaSeparated {
+		return values
+	}
+
+	var result []string
+	for _, v := range values {
+		for part := range strings.SplitSeq(v, ",") {
+			if part = strings.TrimSpace(part); part != "" {
+				result = append(result, part)
+			}
+		}
+	}
+	return result
+}
+
+// lookupMap returns the single-level entries nested under key,
+// e.g., filter[status] and filter[age.gt] for key "filter".
+func (b *valueBinder) lookupMap(key string) map[string][]string {
+	open, closing := ".", ""
+	if b.config.Brackets {
+		open, closing = "[", "]"
+	}
+	prefix := key + open
+
+	entries := make(map[string][]string)
+	for k, values := range b.values {
+		rest, ok := strings.CutPrefix(k, prefix)
+		if !ok || len(values) == 0 {
+			continue
+		}
+		if rest, ok = strings.CutSuffix(rest, closing); !ok {
+			continue
+		}
+		// Only direct children belong to this map
+		if rest == "" || strings.ContainsAny(rest, "[]") || (!b.config.Brackets && strings.Contains(rest, ".")) {
+			continue
+		}
+		entries[rest] = values
+	}
+	return entries
+}
+
+// setList fills the slice fv with the parsed values.
+func (b *valueBinder) setList(fv reflect.Value, values []string, key string) error {
+	slice := reflect.MakeSlice(fv.Type(), len(values), len(values))
+	for i, s := range values {
+		if err := b.setScalar(slice.Index(i), s, key); err != nil {
+			return err
+		}
+	}
+	fv.Set(slice)
+	return nil
+}
+
+// isStructType reports whether t is a struct (or pointer to a struct) bound
+// field by field rather than from a single value.
+func isStructType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && !isScalarType(t)
+}
+
+// isScalarType reports whether t is bound from a single string value.
+func isScalarType(t reflect.Type) bool {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return true
+	}
+	switch t.Kind() {
+	case reflect.String, reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
+
+// setScalar parses s into fv, which must be a scalar type.
+func (b *valueBinder) setScalar(fv reflect.Value, s, key string) error {
+	if err := setScalarValue(fv, s); err != nil {
+		return &BindError{Source: b.tag, Name: key, Value: s, Err: err}
+	}
+	return nil
+}
+
+// setScalarValue parses s into fv according to its type.
+func setScalarValue(fv reflect.Value, s string) error {
+	if fv.Kind() == reflect.Pointer {
+		nv := reflect.New(fv.Type().Elem())
+		if err := setScalarValue(nv.Elem(), s); err != nil {
+			return err
+		}
+		fv.Set(nv)
+		return nil
+	}
+
+	if fv.CanAddr() {
+		if u, ok := fv.Addr().Interface().(encoding.TextUnmarshaler); ok {
+			return u.UnmarshalText([]byte(s))
+		}
+	}
+
+	if fv.Type() == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		fv.SetInt(int64(d))
+		return nil
+	}
+
+	switch fv.Kind() {
+	case reflect.String:
+		fv.SetString(s)
+	case reflect.Bool:
+		v, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(v)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(v)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(v)
+	case reflect.Float32, reflect.Float64:
+		v, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(v)
+	default:
+		return fmt.Errorf("unsupported type %s", fv.Type())
+	}
+	return nil
+}
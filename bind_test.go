@@ -0,0 +1,578 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+type bindQueryParams struct {
+	Page    int               `query:"page"`
+	Search  string            `query:"q"`
+	Active  *bool             `query:"active"`
+	IDs     []int             `query:"ids"`
+	Tags    []string          `query:"tags"`
+	Filter  map[string]string `query:"filter"`
+	Since   time.Time         `query:"since"`
+	Timeout time.Duration     `query:"timeout"`
+	Ignored string            `query:"-"`
+	NoTag   string
+}
+
+func bindQueryRequest(t *testing.T, r *Router, target string, v any) error {
+	t.Helper()
+
+	var bindErr error
+	r.GET("/bind", func(c *Context) error {
+		bindErr = c.BindQuery(v)
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, target, nil))
+	return bindErr
+}
+
+func TestContext_BindQuery(t *testing.T) {
+	var p bindQueryParams
+	err := bindQueryRequest(t, New(),
+		"/bind?page=2&q=go&active=true&ids=1,2,3&tags[]=a&tags[]=b"+
+			"&filter[status]=active&filter[age.gt]=30&since=2024-01-15T10:00:00Z&timeout=5s"+
+			"&Ignored=x&NoTag=y", &p)
+	if err != nil {
+		t.Fatalf("BindQuery() error = %v", err)
+	}
+
+	if p.Page != 2 || p.Search != "go" {
+		t.Errorf("Page, Search = %d, %q", p.Page, p.Search)
+	}
+	if p.Active == nil || !*p.Active {
+		t.Errorf("Active = %v, want true", p.Active)
+	}
+	if !reflect.DeepEqual(p.IDs, []int{1, 2, 3}) {
+		t.Errorf("IDs = %v, want [1 2 3]", p.IDs)
+	}
+	if !reflect.DeepEqual(p.Tags, []string{"a", "b"}) {
+		t.Errorf("Tags = %v, want [a b]", p.Tags)
+	}
+	wantFilter := map[string]string{"status": "active", "age.gt": "30"}
+	if !reflect.DeepEqual(p.Filter, wantFilter) {
+		t.Errorf("Filter = %v, want %v", p.Filter, wantFilter)
+	}
+	if !p.Since.Equal(time.Date(2024, 1, 15, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Since = %v", p.Since)
+	}
+	if p.Timeout != 5*time.Second {
+		t.Errorf("Timeout = %v, want 5s", p.Timeout)
+	}
+	if p.Ignored != "" || p.NoTag != "" {
+		t.Errorf("untagged fields should not be bound: %q, %q", p.Ignored, p.NoTag)
+	}
+}
+
+func TestContext_BindQuery_NestedStruct(t *testing.T) {
+	type Range struct {
+		Min int `query:"min"`
+		Max int `query:"max"`
+	}
+	type Pagination struct {
+		Page int `query:"page"`
+	}
+	type params struct {
+		Pagination
+		Price  Range  `query:"price"`
+		Rating *Range `query:"rating"`
+	}
+
+	var p params
+	err := bindQueryRequest(t, New(), "/bind?page=3&price[min]=10&price[max]=20", &p)
+	if err != nil {
+		t.Fatalf("BindQuery() error = %v", err)
+	}
+
+	if p.Page != 3 {
+		t.Errorf("Page = %d, want 3", p.Page)
+	}
+	if p.Price.Min != 10 || p.Price.Max != 20 {
+		t.Errorf("Price = %+v, want {10 20}", p.Price)
+	}
+	if p.Rating != nil {
+		t.Errorf("Rating = %+v, want nil", p.Rating)
+	}
+}
+
+func TestContext_BindQuery_RouterConfig(t *testing.T) {
+	type params struct {
+		Names  []string          `query:"names"`
+		Filter map[string]string `query:"filter"`
+	}
+
+	r := New()
+	r.SetQueryBindConfig(QueryBindConfig{CommaSeparated: false, Brackets: false})
+
+	var p params
+	err := bindQueryRequest(t, r, "/bind?names=Doe,+John&names[]=x&filter.status=active&filter[age]=1", &p)
+	if err != nil {
+		t.Fatalf("BindQuery() error = %v", err)
+	}
+
+	if !reflect.DeepEqual(p.Names, []string{"Doe, John"}) {
+		t.Errorf("Names = %q, want [\"Doe, John\"]", p.Names)
+	}
+	if !reflect.DeepEqual(p.Filter, map[string]string{"status": "active"}) {
+		t.Errorf("Filter = %v, want map[status:active]", p.Filter)
+	}
+}
+
+func TestContext_BindQuery_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		target  any
+		query   string
+		wantMsg string
+	}{
+		{"invalid int", &struct {
+			Page int `query:"page"`
+		}{}, "page=abc", `invalid value "abc" for query 'page'`},
+		{"invalid slice element", &struct {
+			IDs []int `query:"ids"`
+		}{}, "ids=1,x", `invalid value "x" for query 'ids'`},
+		{"invalid map value", &struct {
+			Limits map[string]int `query:"limit"`
+		}{}, "limit[max]=big", `invalid value "big" for query 'limit[max]'`},
+		{"invalid indexed field", &struct {
+			Items []struct {
+				Qty int `query:"qty"`
+			} `query:"items"`
+		}{}, "items[0][qty]=2&items[1][qty]=many", `invalid value "many" for query 'items[1][qty]'`},
+		{"unsupported type", &struct {
+			Ch chan int `query:"ch"`
+		}{}, "ch=1", "unsupported field type"},
+		{"not a pointer", struct{}{}, "", "non-nil pointer to a struct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/?"+tt.query, nil)
+			c := newContext(httptest.NewRecorder(), req)
+
+			err := c.BindQuery(tt.target)
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error = %q, want to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+type bindAnyUser struct {
+	Name  string   `json:"name" xml:"name" form:"name"`
+	Email string   `json:"email" xml:"email" form:"email"`
+	Roles []string `json:"roles" xml:"role" form:"roles"`
+}
+
+func TestContext_BindAny(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{"json", "application/json; charset=utf-8", `{"name":"Ada","email":"ada@example.com","roles":["admin","dev"]}`},
+		{"json suffix", "application/vnd.api+json", `{"name":"Ada","email":"ada@example.com","roles":["admin","dev"]}`},
+		{"xml", "application/xml", `<user><name>Ada</name><email>ada@example.com</email><role>admin</role><role>dev</role></user>`},
+		{"text xml", "text/xml", `<user><name>Ada</name><email>ada@example.com</email><role>admin</role><role>dev</role></user>`},
+		{"form", "application/x-www-form-urlencoded", "name=Ada&email=ada%40example.com&roles=admin,dev"},
+	}
+
+	want := bindAnyUser{Name: "Ada", Email: "ada@example.com", Roles: []string{"admin", "dev"}}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			c := newContext(httptest.NewRecorder(), req)
+
+			var got bindAnyUser
+			if err := c.BindAny(&got); err != nil {
+				t.Fatalf("BindAny() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("BindAny() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestContext_BindAny_IndexedForm(t *testing.T) {
+	type line struct {
+		SKU string `form:"sku"`
+		Qty int    `form:"qty"`
+	}
+	type order struct {
+		Customer string  `form:"customer"`
+		Items    []line  `form:"items"`
+		Extras   []*line `form:"extras"`
+	}
+
+	// Rows 1 and 3 were removed client-side; dotted and bracketed field
+	// names may be mixed.
+	body := "customer=Ada&items[0].sku=A1&items[0].qty=2&items[2][sku]=B2&items[2][qty]=1" +
+		"&items[4].sku=C3&extras[0][sku]=X9"
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	c := newContext(httptest.NewRecorder(), req)
+
+	var got order
+	if err := c.BindAny(&got); err != nil {
+		t.Fatalf("BindAny() error = %v", err)
+	}
+
+	want := order{
+		Customer: "Ada",
+		Items:    []line{{"A1", 2}, {"B2", 1}, {"C3", 0}},
+		Extras:   []*line{{SKU: "X9"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("BindAny() = %+v, want %+v", got, want)
+	}
+}
+
+func TestContext_BindQuery_IndexedDotted(t *testing.T) {
+	type line struct {
+		SKU string `query:"sku"`
+	}
+	var p struct {
+		Items []line `query:"items"`
+	}
+
+	r := New()
+	r.SetQueryBindConfig(QueryBindConfig{CommaSeparated: true})
+	if err := bindQueryRequest(t, r, "/bind?items[1].sku=B2&items[0].sku=A1", &p); err != nil {
+		t.Fatalf("BindQuery() error = %v", err)
+	}
+	if want := []line{{"A1"}, {"B2"}}; !reflect.DeepEqual(p.Items, want) {
+		t.Errorf("Items = %+v, want %+v", p.Items, want)
+	}
+}
+
+func TestContext_BindAny_Multipart(t *testing.T) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	_ = mw.WriteField("name", "Ada")
+	fw, _ := mw.CreateFormFile("avatar", "avatar.png")
+	_, _ = fw.Write([]byte("png-bytes"))
+	fw, _ = mw.CreateFormFile("docs", "a.txt")
+	_, _ = fw.Write([]byte("a"))
+	fw, _ = mw.CreateFormFile("docs", "b.txt")
+	_, _ = fw.Write([]byte("b"))
+	_ = mw.Close()
+
+	req := httptest.NewRequest(http.MethodPost, "/", &body)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	c := newContext(httptest.NewRecorder(), req)
+
+	var form struct {
+		Name   string                  `form:"name"`
+		Avatar *multipart.FileHeader   `form:"avatar"`
+		Docs   []*multipart.FileHeader `form:"docs"`
+	}
+	if err := c.BindAny(&form); err != nil {
+		t.Fatalf("BindAny() error = %v", err)
+	}
+
+	if form.Name != "Ada" {
+		t.Errorf("Name = %q, want %q", form.Name, "Ada")
+	}
+	if form.Avatar == nil || form.Avatar.Filename != "avatar.png" {
+		t.Errorf("Avatar = %+v, want avatar.png", form.Avatar)
+	}
+	if len(form.Docs) != 2 {
+		t.Errorf("len(Docs) = %d, want 2", len(form.Docs))
+	}
+}
+
+func TestContext_BindAny_UnsupportedMediaType(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+	}{
+		{"unknown", "application/octet-stream"},
+		{"missing", ""},
+		{"malformed", "text/;;"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader("data"))
+			if tt.contentType != "" {
+				req.Header.Set("Content-Type", tt.contentType)
+			}
+			c := newContext(httptest.NewRecorder(), req)
+
+			var v bindAnyUser
+			err := c.BindAny(&v)
+			if !errors.Is(err, ErrUnsupportedMediaType) {
+				t.Errorf("BindAny() error = %v, want ErrUnsupportedMediaType", err)
+			}
+		})
+	}
+}
+
+func TestContext_BindAny_NoBody(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	var v bindAnyUser
+	if err := c.BindAny(&v); err != nil {
+		t.Errorf("BindAny() with no body should not error, got %v", err)
+	}
+}
+
+func TestRouter_RegisterBinder(t *testing.T) {
+	r := New()
+	r.RegisterBinder("Text/CSV", func(c *Context, v any) error {
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(strings.TrimSpace(string(data)), ",")
+		u := v.(*bindAnyUser)
+		u.Name, u.Email = parts[0], parts[1]
+		return nil
+	})
+
+	var got bindAnyUser
+	var bindErr error
+	r.POST("/users", func(c *Context) error {
+		bindErr = c.BindAny(&got)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("Ada,ada@example.com"))
+	req.Header.Set("Content-Type", "text/csv; charset=utf-8")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if bindErr != nil {
+		t.Fatalf("BindAny() error = %v", bindErr)
+	}
+	if got.Name != "Ada" || got.Email != "ada@example.com" {
+		t.Errorf("BindAny() = %+v", got)
+	}
+}
+
+func TestRegisterBinder(t *testing.T) {
+	RegisterBinder("Application/VND.Test+CSV", func(c *Context, v any) error {
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return err
+		}
+		parts := strings.Split(strings.TrimSpace(string(data)), ",")
+		u := v.(*bindAnyUser)
+		u.Name, u.Email = parts[0], parts[1]
+		return nil
+	})
+	t.Cleanup(func() {
+		bindersMu.Lock()
+		delete(binders, "application/vnd.test+csv")
+		bindersMu.Unlock()
+	})
+
+	for _, bind := range []struct {
+		name string
+		fn   func(*Context, any) error
+	}{
+		{"Bind", (*Context).Bind},
+		{"BindAny", (*Context).BindAny},
+	} {
+		t.Run(bind.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("Ada,ada@example.com"))
+			req.Header.Set("Content-Type", "application/vnd.test+csv")
+			c := newContext(httptest.NewRecorder(), req)
+
+			var got bindAnyUser
+			if err := bind.fn(c, &got); err != nil {
+				t.Fatalf("%s() error = %v", bind.name, err)
+			}
+			if got.Name != "Ada" || got.Email != "ada@example.com" {
+				t.Errorf("%s() = %+v", bind.name, got)
+			}
+		})
+	}
+
+	t.Run("router binder takes precedence", func(t *testing.T) {
+		r := New()
+		r.RegisterBinder("application/vnd.test+csv", func(c *Context, v any) error {
+			v.(*bindAnyUser).Name = "router"
+			return nil
+		})
+
+		var got bindAnyUser
+		r.POST("/users", func(c *Context) error {
+			return c.Bind(&got)
+		})
+		req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader("Ada,ada@example.com"))
+		req.Header.Set("Content-Type", "application/vnd.test+csv")
+		r.ServeHTTP(httptest.NewRecorder(), req)
+
+		if got.Name != "router" {
+			t.Errorf("Name = %q, want router binder to be used", got.Name)
+		}
+	})
+}
+
+func TestContext_BindHeader(t *testing.T) {
+	type Tracing struct {
+		CorrelationID string `header:"x-correlation-id"`
+	}
+	type meta struct {
+		Tracing
+		TenantID string   `header:"X-Tenant-ID"`
+		Features []string `header:"X-Features"`
+		Version  int      `header:"X-Api-Version"`
+		Debug    *bool    `header:"X-Debug"`
+		Missing  string   `header:"X-Missing"`
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("X-Correlation-ID", "abc-123")
+	req.Header.Add("X-Features", "beta, dark-mode")
+	req.Header.Add("X-Features", "new-nav")
+	req.Header.Set("X-API-Version", "2")
+	c := newContext(httptest.NewRecorder(), req)
+
+	var m meta
+	if err := c.BindHeader(&m); err != nil {
+		t.Fatalf("BindHeader() error = %v", err)
+	}
+
+	if m.TenantID != "acme" {
+		t.Errorf("TenantID = %q, want %q", m.TenantID, "acme")
+	}
+	if m.CorrelationID != "abc-123" {
+		t.Errorf("CorrelationID = %q, want %q", m.CorrelationID, "abc-123")
+	}
+	if !reflect.DeepEqual(m.Features, []string{"beta", "dark-mode", "new-nav"}) {
+		t.Errorf("Features = %q", m.Features)
+	}
+	if m.Version != 2 {
+		t.Errorf("Version = %d, want 2", m.Version)
+	}
+	if m.Debug != nil || m.Missing != "" {
+		t.Errorf("missing headers should leave fields unset: %v, %q", m.Debug, m.Missing)
+	}
+}
+
+func TestContext_BindHeader_InvalidValue(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Api-Version", "two")
+	c := newContext(httptest.NewRecorder(), req)
+
+	var m struct {
+		Version int `header:"X-Api-Version"`
+	}
+	err := c.BindHeader(&m)
+	if err == nil || !strings.Contains(err.Error(), `invalid value "two" for header 'X-Api-Version'`) {
+		t.Errorf("BindHeader() error = %v", err)
+	}
+}
+
+func TestContext_BindPath(t *testing.T) {
+	type params struct {
+		UserID int    `path:"userID"`
+		Slug   string `path:"slug"`
+		Rest   string `path:"rest"`
+	}
+
+	r := New()
+	var got params
+	r.GET("/users/{userID}/posts/{slug}/{rest...}", func(c *Context) error {
+		if err := c.BindPath(&got); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusOK, got)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/42/posts/hello-world/a/b", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d; body = %s", w.Code, http.StatusOK, w.Body.String())
+	}
+	want := params{UserID: 42, Slug: "hello-world", Rest: "a/b"}
+	if got != want {
+		t.Errorf("BindPath() = %+v, want %+v", got, want)
+	}
+}
+
+func TestContext_BindPath_TypeMismatch(t *testing.T) {
+	r := New()
+	r.GET("/users/{id}", func(c *Context) error {
+		var p struct {
+			ID int `path:"id"`
+		}
+		return c.BindPath(&p)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/abc", nil))
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if !strings.Contains(w.Body.String(), `invalid value "abc" for path 'id'`) {
+		t.Errorf("body = %q", w.Body.String())
+	}
+}
+
+func TestBindError(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?page=abc", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	var p struct {
+		Page int `query:"page"`
+	}
+	err := c.BindQuery(&p)
+
+	var bindErr *BindError
+	if !errors.As(err, &bindErr) {
+		t.Fatalf("error = %T, want *BindError", err)
+	}
+	if bindErr.Source != "query" || bindErr.Name != "page" || bindErr.Value != "abc" {
+		t.Errorf("BindError = %+v", bindErr)
+	}
+	if bindErr.StatusCode() != http.StatusBadRequest {
+		t.Errorf("StatusCode() = %d, want %d", bindErr.StatusCode(), http.StatusBadRequest)
+	}
+	if !errors.Is(err, strconv.ErrSyntax) {
+		t.Error("BindError should unwrap to the conversion error")
+	}
+}
+
+func TestPatternWildcards(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    []string
+	}{
+		{"GET /users", nil},
+		{"GET /users/{id}", []string{"id"}},
+		{"/files/{dir}/{path...}", []string{"dir", "path"}},
+		{"GET /{$}", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			if got := patternWildcards(tt.pattern); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("patternWildcards() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// ErrRequestEntityTooLarge is returned by Bind, BindStrict, and BindAny when
+// the request body exceeds the limit set with MaxBodyBytes or
+// Router.SetMaxBodyBytes. DefaultErrorHandler answers it with 413.
+var ErrRequestEntityTooLarge = NewHTTPError(http.StatusRequestEntityTooLarge, "request entity too large")
+
+// SetMaxBodyBytes sets the default maximum size of request bodies, in bytes.
+// Reading past the limit fails, and binding errors wrap
+// ErrRequestEntityTooLarge. Handlers can raise or lower the limit for a
+// single request with c.MaxBodyBytes. Zero (the default) means no limit.
+//
+// Example:
+//
+//	r.SetMaxBodyBytes(1 << 20) // 1 MB
+func (r *Router) SetMaxBodyBytes(n int64) {
+	r.maxBodyBytes = n
+}
+
+// MaxBodyBytes limits the request body to n bytes, replacing the router's
+// default limit. A value of zero or less removes the limit. Call it before
+// the body is read, typically at the top of a handler or in middleware.
+//
+// Example:
+//
+//	r.POST("/uploads", func(c *rig.Context) error {
+//	    c.MaxBodyBytes(50 << 20) // Allow 50 MB on this route only
+//	    ...
+//	})
+func (c *Context) MaxBodyBytes(n int64) {
+	body := c.request.Body
+	if body == nil || body == http.NoBody {
+		return
+	}
+
+	// Limit the original body, so a per-request limit can exceed the default
+	if c.originalBody == nil {
+		c.originalBody = body
+	}
+	if n <= 0 {
+		c.request.Body = c.originalBody
+		return
+	}
+	c.request.Body = http.MaxBytesReader(c.writer, c.originalBody, n)
+}
+
+// bodyError converts errors caused by an oversized body into errors wrapping
+// ErrRequestEntityTooLarge; other errors are returned unchanged.
+func bodyError(err error) error {
+	var maxErr *http.MaxBytesError
+	if errors.As(err, &maxErr) {
+		return fmt.Errorf("%w (limit is %d bytes)", ErrRequestEntityTooLarge, maxErr.Limit)
+	}
+	return err
+}
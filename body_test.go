@@ -0,0 +1,104 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouter_SetMaxBodyBytes(t *testing.T) {
+	r := New()
+	r.SetMaxBodyBytes(16)
+
+	type payload struct {
+		Name string `json:"name"`
+	}
+	r.POST("/small", func(c *Context) error {
+		var p payload
+		if err := c.Bind(&p); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%s", p.Name)
+	})
+	r.POST("/large", func(c *Context) error {
+		c.MaxBodyBytes(1024)
+		var p payload
+		if err := c.Bind(&p); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%s", p.Name)
+	})
+
+	large := `{"name":"` + strings.Repeat("a", 64) + `"}`
+	tests := []struct {
+		path, body string
+		wantStatus int
+	}{
+		{"/small", `{"name":"ok"}`, http.StatusOK},
+		{"/small", large, http.StatusRequestEntityTooLarge},
+		{"/large", large, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body)))
+		if w.Code != tt.wantStatus {
+			t.Errorf("POST %s (%d bytes): status = %d, want %d (%s)",
+				tt.path, len(tt.body), w.Code, tt.wantStatus, w.Body.String())
+		}
+	}
+}
+
+func TestContext_MaxBodyBytes_BindErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		bind        func(c *Context, v any) error
+	}{
+		{"Bind", "application/json", `{"name":"` + strings.Repeat("a", 32) + `"}`, (*Context).Bind},
+		{"BindStrict", "application/json", `{"name":"` + strings.Repeat("a", 32) + `"}`, (*Context).BindStrict},
+		{"BindAny XML", "application/xml", "<p><name>" + strings.Repeat("a", 32) + "</name></p>", (*Context).BindAny},
+		{"BindAny form", "application/x-www-form-urlencoded", "name=" + strings.Repeat("a", 32), (*Context).BindAny},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			c := newContext(httptest.NewRecorder(), req)
+			c.MaxBodyBytes(8)
+
+			var v struct {
+				Name string `json:"name" xml:"name" form:"name"`
+			}
+			err := tt.bind(c, &v)
+			if !errors.Is(err, ErrRequestEntityTooLarge) {
+				t.Fatalf("error = %v, want ErrRequestEntityTooLarge", err)
+			}
+			var sc interface{ StatusCode() int }
+			if !errors.As(err, &sc) || sc.StatusCode() != http.StatusRequestEntityTooLarge {
+				t.Errorf("error should carry status 413")
+			}
+		})
+	}
+}
+
+func TestContext_MaxBodyBytes_RemoveLimit(t *testing.T) {
+	body := `{"name":"` + strings.Repeat("a", 32) + `"}`
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body)))
+	c.MaxBodyBytes(8)
+	c.MaxBodyBytes(0)
+
+	var v struct {
+		Name string `json:"name"`
+	}
+	if err := c.Bind(&v); err != nil {
+		t.Fatalf("Bind() error = %v", err)
+	}
+	if len(v.Name) != 32 {
+		t.Errorf("Name length = %d, want 32", len(v.Name))
+	}
+}
@@ -0,0 +1,158 @@
+package rig
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// CacheProfile is a Cache-Control header value. Use the predefined profiles
+// rather than writing header strings by hand; any other value can be
+// converted (e.g., CacheProfile("public, max-age=60, stale-while-revalidate=30")).
+type CacheProfile string
+
+// Predefined cache profiles.
+const (
+	// NoStore forbids storing the response anywhere. Use it for sensitive
+	// data such as account details and tokens.
+	NoStore CacheProfile = "no-store"
+
+	// Private lets only the client's own cache store the response, and
+	// makes it revalidate before every reuse. Use it for per-user data.
+	Private CacheProfile = "private, no-cache"
+
+	// Immutable lets every cache store the response for a year without
+	// revalidating. Use it for fingerprinted assets (e.g., app.3f9a1c.js),
+	// whose content never changes under the same URL.
+	Immutable CacheProfile = "public, max-age=31536000, immutable"
+)
+
+// PublicMaxAge returns a profile that lets every cache (browsers, proxies,
+// CDNs) reuse the response for d, rounded down to whole seconds.
+func PublicMaxAge(d time.Duration) CacheProfile {
+	return CacheProfile("public, max-age=" + strconv.FormatInt(int64(d/time.Second), 10))
+}
+
+// PrivateMaxAge returns a profile that lets only the client's own cache
+// reuse the response for d, rounded down to whole seconds.
+func PrivateMaxAge(d time.Duration) CacheProfile {
+	return CacheProfile("private, max-age=" + strconv.FormatInt(int64(d/time.Second), 10))
+}
+
+// CacheControlConfig defines the configuration for CacheControl middleware.
+type CacheControlConfig struct {
+	// Profile is the Cache-Control header value to set. Required.
+	Profile CacheProfile
+
+	// Skipper selects requests whose responses are left alone.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// CacheControl creates middleware that sets the Cache-Control header of
+// successful responses (2xx) and permanent redirects (301 and 308) to
+// profile, unless the handler sets one itself. Error responses and other
+// redirects are left alone, so a failure or a login redirect is never
+// cached for the lifetime of a page. Register it per route or group.
+//
+// Example:
+//
+//	api := r.Group("/api")
+//	api.Use(rig.CacheControl(rig.NoStore))
+//
+//	r.GET("/products", rig.CacheControl(rig.PublicMaxAge(5*time.Minute))(listProducts))
+//	r.GET("/me", rig.CacheControl(rig.Private)(getProfile))
+func CacheControl(profile CacheProfile) MiddlewareFunc {
+	return CacheControlWithConfig(CacheControlConfig{Profile: profile})
+}
+
+// CacheControlWithConfig creates Cache-Control middleware with custom
+// configuration. See CacheControl for the behavior. It panics if
+// config.Profile is empty.
+//
+// Example:
+//
+//	// Cache the catalog, except for preview requests
+//	catalog.Use(rig.CacheControlWithConfig(rig.CacheControlConfig{
+//	    Profile: rig.PublicMaxAge(time.Hour),
+//	    Skipper: func(c *rig.Context) bool {
+//	        return c.Query("preview") != ""
+//	    },
+//	}))
+func CacheControlWithConfig(config CacheControlConfig) MiddlewareFunc {
+	if config.Profile == "" {
+		panic("rig: CacheControl requires a Profile")
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			if !c.Written() {
+				c.writer.ResponseWriter = &cacheControlWriter{
+					ResponseWriter: c.writer.ResponseWriter,
+					profile:        string(config.Profile),
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// cacheable reports whether responses with status code get a Cache-Control
+// profile.
+func cacheable(code int) bool {
+	return code >= 200 && code < 300 ||
+		code == http.StatusMovedPermanently || code == http.StatusPermanentRedirect
+}
+
+// cacheControlWriter sets a default Cache-Control header when the response
+// headers are sent.
+type cacheControlWriter struct {
+	http.ResponseWriter
+	profile string
+	done    bool
+}
+
+// apply sets the header for responses with status code, unless it is set.
+func (w *cacheControlWriter) apply(code int) {
+	if w.done || code < 200 {
+		return
+	}
+	w.done = true
+	h := w.ResponseWriter.Header()
+	if cacheable(code) && h.Get("Cache-Control") == "" {
+		h.Set("Cache-Control", w.profile)
+	}
+}
+
+// WriteHeader sets the header and writes the status.
+func (w *cacheControlWriter) WriteHeader(code int) {
+	w.apply(code)
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write sets the header for an implicit 200 OK and writes b.
+func (w *cacheControlWriter) Write(b []byte) (int, error) {
+	w.apply(http.StatusOK)
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *cacheControlWriter) Flush() {
+	w.apply(http.StatusOK)
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *cacheControlWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,79 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCacheProfiles(t *testing.T) {
+	tests := []struct {
+		profile CacheProfile
+		want    string
+	}{
+		{NoStore, "no-store"},
+		{Private, "private, no-cache"},
+		{Immutable, "public, max-age=31536000, immutable"},
+		{PublicMaxAge(5 * time.Minute), "public, max-age=300"},
+		{PrivateMaxAge(1500 * time.Millisecond), "private, max-age=1"},
+	}
+	for _, tt := range tests {
+		if string(tt.profile) != tt.want {
+			t.Errorf("profile = %q, want %q", tt.profile, tt.want)
+		}
+	}
+}
+
+func TestCacheControl(t *testing.T) {
+	r := New()
+	cached := CacheControl(PublicMaxAge(time.Minute))
+	r.GET("/products", cached(func(c *Context) error {
+		return c.JSON(http.StatusOK, []string{"book"})
+	}))
+	r.GET("/custom", cached(func(c *Context) error {
+		c.SetHeader("Cache-Control", "no-cache")
+		return c.String(http.StatusOK, "custom")
+	}))
+	r.GET("/missing", cached(func(c *Context) error {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "not found"})
+	}))
+	r.GET("/fail", cached(func(c *Context) error {
+		return errors.New("boom")
+	}))
+	r.GET("/login", cached(func(c *Context) error {
+		c.Redirect(http.StatusFound, "/signin")
+		return nil
+	}))
+	r.GET("/old", cached(func(c *Context) error {
+		c.Redirect(http.StatusMovedPermanently, "/products")
+		return nil
+	}))
+	r.GET("/preview", CacheControlWithConfig(CacheControlConfig{
+		Profile: PublicMaxAge(time.Minute),
+		Skipper: func(c *Context) bool { return true },
+	})(func(c *Context) error {
+		return c.String(http.StatusOK, "preview")
+	}))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/products", "public, max-age=60"},
+		{"/custom", "no-cache"},
+		{"/missing", ""},
+		{"/fail", ""},
+		{"/login", ""},
+		{"/old", "public, max-age=60"},
+		{"/preview", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if got := w.Header().Get("Cache-Control"); got != tt.want {
+			t.Errorf("%s: Cache-Control = %q, want %q", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package rig
+
+import "slices"
+
+// MiddlewareOption configures middleware registered with UseNamed.
+type MiddlewareOption func(*registeredMiddleware)
+
+// Priority orders middleware registered with UseNamed: middleware with a
+// higher priority runs first (outermost), regardless of registration order.
+// Middleware with equal priorities runs in registration order, and Use
+// registers middleware with priority 0.
+func Priority(p int) MiddlewareOption {
+	return func(m *registeredMiddleware) {
+		m.priority = p
+	}
+}
+
+// registeredMiddleware is middleware registered on a router or group.
+type registeredMiddleware struct {
+	fn       MiddlewareFunc
+	name     string
+	priority int
+}
+
+// middlewareChain is a middleware stack in execution order (outermost
+// first), sorted by descending priority.
+type middlewareChain []registeredMiddleware
+
+// add returns ch with mw inserted after every middleware of the same or
+// higher priority. An empty name is derived from mw (see middlewareName).
+func (ch middlewareChain) add(mw MiddlewareFunc, name string, opts ...MiddlewareOption) middlewareChain {
+	m := registeredMiddleware{fn: mw, name: name}
+	for _, opt := range opts {
+		opt(&m)
+	}
+	if m.name == "" {
+		m.name = middlewareName(mw)
+	}
+	i := slices.IndexFunc(ch, func(other registeredMiddleware) bool {
+		return other.priority < m.priority
+	})
+	if i < 0 {
+		i = len(ch)
+	}
+	return slices.Insert(ch, i, m)
+}
+
+// apply wraps handler with the chain, so the first middleware executes
+// first (outermost wrapper).
+func (ch middlewareChain) apply(handler HandlerFunc) HandlerFunc {
+	for i := len(ch) - 1; i >= 0; i-- {
+		handler = ch[i].fn(handler)
+	}
+	return handler
+}
+
+// UseNamed registers middleware under a name, which route introspection
+// (Routes, Match, Middlewares) reports instead of a name derived from the
+// function. Options such as Priority control where it runs in the chain.
+//
+// Like Use, it only affects routes registered afterwards.
+//
+// Example:
+//
+//	r.UseNamed("logger", logger.New())
+//	r.UseNamed("cors", rig.DefaultCORS(), rig.Priority(10)) // Runs before the logger
+func (r *Router) UseNamed(name string, mw MiddlewareFunc, opts ...MiddlewareOption) {
+	r.middlewares = r.middlewares.add(mw, name, opts...)
+}
+
+// UseNamed registers middleware for the group's routes under a name. See
+// Router.UseNamed. Priorities order the group's middleware among itself;
+// router middleware always runs before group middleware.
+func (g *RouteGroup) UseNamed(name string, mw MiddlewareFunc, opts ...MiddlewareOption) {
+	g.middlewares = g.middlewares.add(mw, name, opts...)
+}
+
+// Middlewares returns the effective middleware chain of every registered
+// route, keyed by pattern, in execution order (outermost first). Chains
+// are fixed when a route is registered, so middleware added with Use
+// afterwards is missing from them, which is the usual reason middleware
+// does not run for a route.
+//
+// Example:
+//
+//	for pattern, chain := range r.Middlewares() {
+//	    fmt.Println(pattern, strings.Join(chain, " -> "))
+//	}
+//	// GET /admin/users cors -> rig.RecoverWithConfig -> logger -> auth.Bearer
+func (r *Router) Middlewares() map[string][]string {
+	chains := make(map[string][]string, len(r.routes))
+	for pattern, route := range r.routes {
+		chains[pattern] = slices.Clone(route.Middleware)
+	}
+	return chains
+}
@@ -0,0 +1,59 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+func TestRouter_UseNamed(t *testing.T) {
+	var order []string
+	trace := func(name string) MiddlewareFunc {
+		return func(next HandlerFunc) HandlerFunc {
+			return func(c *Context) error {
+				order = append(order, name)
+				return next(c)
+			}
+		}
+	}
+
+	r := New()
+	r.UseNamed("logger", trace("logger"))
+	r.UseNamed("cors", trace("cors"), Priority(10))
+	r.Use(trace("unnamed"))
+	r.UseNamed("recover", trace("recover"), Priority(100))
+
+	api := r.Group("/api")
+	api.UseNamed("auth", trace("auth"))
+	api.UseNamed("ratelimit", trace("ratelimit"), Priority(1))
+	api.GET("/users", func(c *Context) error {
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/users", nil))
+	want := []string{"recover", "cors", "logger", "unnamed", "ratelimit", "auth"}
+	if !slices.Equal(order, want) {
+		t.Errorf("execution order = %v, want %v", order, want)
+	}
+
+	chain := r.Middlewares()["GET /api/users"]
+	if len(chain) != len(want) || chain[0] != "recover" || chain[3] != "rig.TestRouter_UseNamed" || chain[5] != "auth" {
+		t.Errorf("Middlewares() = %v", chain)
+	}
+	if route := r.Match(http.MethodGet, "/api/users").Route; !slices.Equal(route.Middleware, chain) {
+		t.Errorf("Match middleware = %v, want %v", route.Middleware, chain)
+	}
+}
+
+func TestRouter_Middlewares_RegistrationOrder(t *testing.T) {
+	r := New()
+	r.GET("/early", func(c *Context) error { return nil })
+	r.UseNamed("late", func(next HandlerFunc) HandlerFunc { return next })
+	r.GET("/later", func(c *Context) error { return nil })
+
+	chains := r.Middlewares()
+	if len(chains["GET /early"]) != 0 || !slices.Equal(chains["GET /later"], []string{"late"}) {
+		t.Errorf("Middlewares() = %v", chains)
+	}
+}
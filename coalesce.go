@@ -0,0 +1,297 @@
+package rig
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// CoalesceConfig defines the configuration for Coalesce middleware.
+type CoalesceConfig struct {
+	// KeyFunc returns the key identifying identical requests. Requests for
+	// which it returns an empty string are not coalesced. Make sure every
+	// input the response depends on is part of the key.
+	// Default: CoalesceByURL.
+	KeyFunc func(c *Context) string
+
+	// MaxSize is the largest response, in bytes, that is shared. When the
+	// handler writes more, or flushes (e.g., c.Stream), its response is
+	// streamed to its own client and the waiting requests run the handler
+	// themselves.
+	// Default: 1 MB.
+	MaxSize int
+
+	// Skipper selects requests that are not coalesced.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// Coalesce creates middleware that collapses concurrent identical GET and
+// HEAD requests into a single handler execution: the first request runs
+// the handler, and requests with the same key arriving while it runs wait
+// for it and receive a copy of its response (status, the headers set by the
+// handler, and body) or of its error. This protects slow upstreams from
+// thundering herds, such as a cache expiring under load.
+//
+// Only requests in flight at the same time are coalesced; nothing is
+// cached afterwards. Requests with an Authorization or Cookie header are
+// not coalesced by default, since their responses may be personal.
+// Responses that set cookies, and errors from a canceled or timed-out
+// first request, are not shared: the waiting requests run the handler
+// themselves.
+//
+// Example:
+//
+//	r.GET("/products/{id}", rig.Coalesce()(getProduct))
+func Coalesce() MiddlewareFunc {
+	return CoalesceWithConfig(CoalesceConfig{})
+}
+
+// CoalesceWithConfig creates request coalescing middleware with custom
+// configuration. See Coalesce for the behavior.
+//
+// Example:
+//
+//	// Coalesce per tenant, including authenticated requests
+//	api.Use(rig.CoalesceWithConfig(rig.CoalesceConfig{
+//	    KeyFunc: func(c *rig.Context) string {
+//	        return c.GetHeader("X-Tenant-ID") + " " + c.Request().URL.RequestURI()
+//	    },
+//	}))
+func CoalesceWithConfig(config CoalesceConfig) MiddlewareFunc {
+	if config.KeyFunc == nil {
+		config.KeyFunc = CoalesceByURL
+	}
+	if config.MaxSize <= 0 {
+		config.MaxSize = 1 << 20
+	}
+
+	var (
+		mu    sync.Mutex
+		calls = make(map[string]*coalescedCall)
+	)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			method := c.Method()
+			if (method != http.MethodGet && method != http.MethodHead) || c.writer.status != 0 {
+				return next(c)
+			}
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			mu.Lock()
+			if call, ok := calls[key]; ok {
+				mu.Unlock()
+				select {
+				case <-call.done:
+				case <-c.Context().Done():
+					return c.Context().Err()
+				}
+				if !call.shared {
+					return next(c)
+				}
+				return call.replay(c)
+			}
+			call := &coalescedCall{done: make(chan struct{})}
+			calls[key] = call
+			mu.Unlock()
+
+			cw := &coalesceWriter{
+				ResponseWriter: c.writer.ResponseWriter,
+				maxSize:        config.MaxSize,
+			}
+			c.writer.ResponseWriter = cw
+			before := c.Header().Clone()
+			defer func() {
+				// Runs on panics too, letting the waiting requests run the
+				// handler themselves
+				c.writer.ResponseWriter = cw.ResponseWriter
+				mu.Lock()
+				delete(calls, key)
+				mu.Unlock()
+				close(call.done)
+			}()
+
+			err := next(c)
+			if cw.hijacked || cw.streaming {
+				return err
+			}
+			cw.finish()
+
+			// Responses setting cookies are personal, and a canceled or
+			// timed-out leader says nothing about the followers' requests:
+			// the waiting requests run the handler themselves
+			header := handlerHeader(before, cw.Header())
+			if _, ok := header["Set-Cookie"]; ok ||
+				errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+				return err
+			}
+			call.shared = true
+			call.status = cw.status
+			call.header = header
+			call.body = cw.buf
+			call.err = err
+			return err
+		}
+	}
+}
+
+// CoalesceByURL is a CoalesceConfig.KeyFunc that coalesces requests by
+// method, URL, and the Accept, Accept-Encoding, and Accept-Language
+// headers. Requests with an Authorization or Cookie header are not
+// coalesced.
+func CoalesceByURL(c *Context) string {
+	h := c.Request().Header
+	if h.Get("Authorization") != "" || h.Get("Cookie") != "" {
+		return ""
+	}
+	return strings.Join([]string{
+		c.Method(),
+		c.Request().URL.RequestURI(),
+		h.Get("Accept"),
+		h.Get("Accept-Encoding"),
+		h.Get("Accept-Language"),
+	}, "\n")
+}
+
+// handlerHeader returns the headers of after that were added or changed
+// since before. Headers set earlier in the chain, such as X-Request-ID,
+// belong to each request and are not shared.
+func handlerHeader(before, after http.Header) http.Header {
+	h := make(http.Header, len(after))
+	for name, values := range after {
+		if old, ok := before[name]; !ok || !slices.Equal(old, values) {
+			h[name] = slices.Clone(values)
+		}
+	}
+	return h
+}
+
+// coalescedCall is a handler execution shared by identical requests.
+type coalescedCall struct {
+	done chan struct{}
+
+	// Set before done is closed
+	shared bool // false if the response was streamed, hijacked, set cookies, or panicked
+	status int
+	header http.Header
+	body   []byte
+	err    error
+}
+
+// replay writes a copy of the shared response to c and returns the shared
+// error.
+func (call *coalescedCall) replay(c *Context) error {
+	h := c.Header()
+	for name, values := range call.header {
+		h[name] = append([]string(nil), values...)
+	}
+	if call.status != 0 {
+		c.writer.WriteHeader(call.status)
+	}
+	if len(call.body) > 0 {
+		_, _ = c.writer.Write(call.body)
+	}
+	return call.err
+}
+
+// coalesceWriter buffers a response to share it, falling back to streaming
+// when the response is flushed or grows past maxSize.
+type coalesceWriter struct {
+	http.ResponseWriter
+	maxSize int
+
+	status    int
+	buf       []byte
+	streaming bool
+	hijacked  bool
+}
+
+// WriteHeader records the status; headers are sent when the response is
+// finished or starts streaming. Informational (1xx) responses are sent
+// immediately.
+func (w *coalesceWriter) WriteHeader(code int) {
+	if code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers data until the response exceeds maxSize, then streams.
+func (w *coalesceWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	if len(w.buf)+len(b) <= w.maxSize {
+		w.buf = append(w.buf, b...)
+		return len(b), nil
+	}
+	w.stream()
+	return w.ResponseWriter.Write(b)
+}
+
+// stream sends the headers and the buffered data, and passes later writes
+// through.
+func (w *coalesceWriter) stream() {
+	w.streaming = true
+	w.finish()
+	w.buf = nil
+}
+
+// finish sends the buffered response.
+func (w *coalesceWriter) finish() {
+	if w.status == 0 {
+		return
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+}
+
+// Flush implements http.Flusher; flushed responses are streamed and not
+// shared.
+func (w *coalesceWriter) Flush() {
+	if w.hijacked {
+		return
+	}
+	if !w.streaming {
+		if w.status == 0 {
+			w.status = http.StatusOK
+		}
+		w.stream()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *coalesceWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *coalesceWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
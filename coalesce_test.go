@@ -0,0 +1,196 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// coalesceRouter returns a router whose /items handler blocks until release
+// is closed, and counts its executions.
+func coalesceRouter(handler HandlerFunc) (*Router, *atomic.Int32, chan struct{}, chan struct{}) {
+	var calls atomic.Int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	r := New()
+	r.Use(Coalesce())
+	r.GET("/items", func(c *Context) error {
+		calls.Add(1)
+		started <- struct{}{}
+		<-release
+		return handler(c)
+	})
+	return r, &calls, started, release
+}
+
+func TestCoalesce(t *testing.T) {
+	r, calls, started, release := coalesceRouter(func(c *Context) error {
+		c.SetHeader("X-Version", "7")
+		return c.JSON(http.StatusCreated, map[string]string{"name": "widget"})
+	})
+
+	var wg sync.WaitGroup
+	recorders := []*httptest.ResponseRecorder{serveAsync(r, "/items?page=1", &wg)}
+	<-started
+	for range 4 {
+		recorders = append(recorders, serveAsync(r, "/items?page=1", &wg))
+	}
+	// A different query is a different request
+	other := serveAsync(r, "/items?page=2", &wg)
+	<-started
+
+	time.Sleep(20 * time.Millisecond) // Let the followers queue up
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want 2", n)
+	}
+	for i, w := range append(recorders, other) {
+		if w.Code != http.StatusCreated || w.Header().Get("X-Version") != "7" || w.Body.String() != `{"name":"widget"}`+"\n" {
+			t.Errorf("response %d: status = %d, X-Version = %q, body = %q", i, w.Code, w.Header().Get("X-Version"), w.Body.String())
+		}
+	}
+}
+
+func TestCoalesce_SharedError(t *testing.T) {
+	r, calls, started, release := coalesceRouter(func(c *Context) error {
+		return NewHTTPError(http.StatusNotFound, "no such item")
+	})
+
+	var wg sync.WaitGroup
+	first := serveAsync(r, "/items", &wg)
+	<-started
+	second := serveAsync(r, "/items", &wg)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 1 {
+		t.Errorf("handler ran %d times, want 1", n)
+	}
+	for _, w := range []*httptest.ResponseRecorder{first, second} {
+		if w.Code != http.StatusNotFound {
+			t.Errorf("status = %d, want 404", w.Code)
+		}
+	}
+}
+
+func TestCoalesce_Streamed(t *testing.T) {
+	r, calls, started, release := coalesceRouter(func(c *Context) error {
+		return c.Stream(http.StatusOK, "text/plain", func(w io.Writer) error {
+			_, err := io.WriteString(w, "chunk")
+			return err
+		})
+	})
+
+	// A streamed response is not shared; the follower runs the handler
+	var wg sync.WaitGroup
+	first := serveAsync(r, "/items", &wg)
+	<-started
+	second := serveAsync(r, "/items", &wg)
+	time.Sleep(20 * time.Millisecond)
+	release <- struct{}{}
+	<-started
+	close(release)
+	wg.Wait()
+
+	if n := calls.Load(); n != 2 {
+		t.Errorf("handler ran %d times, want 2", n)
+	}
+	if first.Body.String() != "chunk" || second.Body.String() != "chunk" {
+		t.Errorf("bodies = %q, %q", first.Body.String(), second.Body.String())
+	}
+}
+
+func TestCoalesce_NotShared(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler HandlerFunc
+	}{
+		{"cookie", func(c *Context) error {
+			c.SetCookie(&http.Cookie{Name: "session", Value: "abc"})
+			return c.String(http.StatusOK, "ok")
+		}},
+		{"canceled", func(c *Context) error {
+			return context.Canceled
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, calls, started, release := coalesceRouter(tt.handler)
+
+			// The follower runs the handler itself
+			var wg sync.WaitGroup
+			serveAsync(r, "/items", &wg)
+			<-started
+			serveAsync(r, "/items", &wg)
+			time.Sleep(20 * time.Millisecond)
+			release <- struct{}{}
+			<-started
+			close(release)
+			wg.Wait()
+
+			if n := calls.Load(); n != 2 {
+				t.Errorf("handler ran %d times, want 2", n)
+			}
+		})
+	}
+}
+
+func TestCoalesce_PerRequestHeaders(t *testing.T) {
+	var ids atomic.Int32
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Request-ID", fmt.Sprint(ids.Add(1)))
+			return next(c)
+		}
+	})
+	r.Use(Coalesce())
+	r.GET("/items", func(c *Context) error {
+		started <- struct{}{}
+		<-release
+		c.SetHeader("X-Version", "7")
+		return c.String(http.StatusOK, "ok")
+	})
+
+	var wg sync.WaitGroup
+	first := serveAsync(r, "/items", &wg)
+	<-started
+	second := serveAsync(r, "/items", &wg)
+	time.Sleep(20 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	// Handler headers are shared; headers set before Coalesce are not
+	if first.Header().Get("X-Request-ID") == second.Header().Get("X-Request-ID") {
+		t.Errorf("X-Request-ID = %q for both requests", first.Header().Get("X-Request-ID"))
+	}
+	if second.Header().Get("X-Version") != "7" || second.Body.String() != "ok" {
+		t.Errorf("follower: X-Version = %q, body = %q", second.Header().Get("X-Version"), second.Body.String())
+	}
+}
+
+func TestCoalesceByURL(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/items?page=1", nil)
+	req.Header.Set("Accept", "application/json")
+	c := &Context{request: req}
+	if key := CoalesceByURL(c); key != "GET\n/items?page=1\napplication/json\n\n" {
+		t.Errorf("key = %q", key)
+	}
+
+	req.Header.Set("Cookie", "session=abc")
+	if key := CoalesceByURL(c); key != "" {
+		t.Errorf("key with cookie = %q, want none", key)
+	}
+}
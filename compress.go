@@ -0,0 +1,380 @@
+package rig
+
+import (
+	"bufio"
+	"compress/gzip"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// GzipConfig defines the configuration for Gzip middleware.
+type GzipConfig struct {
+	// Level is the gzip compression level, from gzip.BestSpeed (1) to
+	// gzip.BestCompression (9).
+	// Default: gzip.DefaultCompression.
+	Level int
+
+	// MinSize is the minimum response size in bytes worth compressing.
+	// Smaller responses are sent uncompressed. Responses are buffered up to
+	// this size to decide.
+	// Default: 1024.
+	MinSize int
+
+	// ContentTypes lists the media types to compress. An entry ending in
+	// "/*" matches a whole type (e.g., "text/*").
+	// Default: DefaultCompressibleTypes.
+	ContentTypes []string
+
+	// Skipper selects requests whose responses are never compressed.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// CompressConfig defines the configuration for Compress middleware.
+type CompressConfig struct {
+	GzipConfig
+
+	// Encodings lists additional content codings (e.g., br and zstd), in
+	// order of server preference. gzip is always available after them as
+	// the fallback.
+	Encodings []Encoding
+}
+
+// EncodingWriter is a compressor for one content coding. *gzip.Writer,
+// *flate.Writer, and the writers of popular brotli and zstd packages
+// implement it.
+type EncodingWriter interface {
+	io.WriteCloser
+
+	// Flush writes any buffered data to the underlying writer.
+	Flush() error
+
+	// Reset discards the writer's state and makes it write to w, so
+	// writers can be pooled.
+	Reset(w io.Writer)
+}
+
+// Encoding is a content coding Compress can produce.
+type Encoding struct {
+	// Name is the Accept-Encoding token (e.g., "br" or "zstd").
+	Name string
+
+	// New creates a writer; it is reset to the response before use.
+	// Writers are pooled and reused across responses.
+	New func() EncodingWriter
+}
+
+// DefaultCompressibleTypes are the media types Gzip compresses when
+// GzipConfig.ContentTypes is empty. Already-compressed formats (images,
+// video, archives) are excluded.
+var DefaultCompressibleTypes = []string{
+	"text/*",
+	"application/json",
+	"application/javascript",
+	"application/xml",
+	"application/yaml",
+	"application/wasm",
+	"image/svg+xml",
+}
+
+// Gzip creates middleware that compresses responses with gzip for clients
+// that accept it, using the default configuration.
+//
+// Example:
+//
+//	r := rig.New()
+//	r.Use(rig.Gzip())
+func Gzip() MiddlewareFunc {
+	return Compress(CompressConfig{})
+}
+
+// GzipWithConfig creates gzip middleware with custom configuration.
+// See Compress for the behavior.
+//
+// Example:
+//
+//	r.Use(rig.GzipWithConfig(rig.GzipConfig{
+//	    Level:   gzip.BestSpeed,
+//	    MinSize: 256,
+//	}))
+func GzipWithConfig(config GzipConfig) MiddlewareFunc {
+	return Compress(CompressConfig{GzipConfig: config})
+}
+
+// Compress creates middleware that compresses responses with the content
+// coding the client prefers in Accept-Encoding (honoring q-values and "*"),
+// among config.Encodings and gzip. Ties go to the server order: Encodings
+// first, then gzip. Encoders are plugged in from external packages, such as
+// github.com/andybalholm/brotli and github.com/klauspost/compress/zstd.
+//
+// Responses are left untouched when the client accepts no available coding,
+// the handler already set Content-Encoding, the content type is not listed,
+// the body is smaller than MinSize, or the response is bodiless (204, 304) or
+// partial (206). Hijacked connections (WebSockets) are passed through, and
+// responses already written by earlier middleware are not compressed.
+// Flushing (e.g., c.Stream) flushes the compressed stream.
+//
+// Example:
+//
+//	r.Use(rig.Compress(rig.CompressConfig{
+//	    Encodings: []rig.Encoding{
+//	        {Name: "br", New: func() rig.EncodingWriter { return brotli.NewWriter(nil) }},
+//	        {Name: "zstd", New: func() rig.EncodingWriter {
+//	            enc, _ := zstd.NewWriter(nil)
+//	            return enc
+//	        }},
+//	    },
+//	}))
+func Compress(config CompressConfig) MiddlewareFunc {
+	if config.Level == 0 {
+		config.Level = gzip.DefaultCompression
+	}
+	if config.MinSize == 0 {
+		config.MinSize = 1024
+	}
+	if len(config.ContentTypes) == 0 {
+		config.ContentTypes = DefaultCompressibleTypes
+	}
+	if _, err := gzip.NewWriterLevel(nil, config.Level); err != nil {
+		panic("rig: invalid gzip level " + strconv.Itoa(config.Level))
+	}
+
+	level := config.Level
+	encodings := slices.Concat(config.Encodings, []Encoding{{
+		Name: "gzip",
+		New: func() EncodingWriter {
+			gz, _ := gzip.NewWriterLevel(nil, level)
+			return gz
+		},
+	}})
+	names := make([]string, len(encodings))
+	pools := make([]*sync.Pool, len(encodings))
+	for i, enc := range encodings {
+		names[i] = strings.ToLower(enc.Name)
+		pools[i] = &sync.Pool{New: func() any { return enc.New() }}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			if c.writer.status != 0 {
+				return next(c)
+			}
+			i := negotiateEncoding(c.GetHeader("Accept-Encoding"), names)
+			if i < 0 {
+				return next(c)
+			}
+
+			cw := &compressWriter{
+				ResponseWriter: c.writer.ResponseWriter,
+				config:         &config.GzipConfig,
+				encoding:       names[i],
+				pool:           pools[i],
+			}
+			c.writer.ResponseWriter = cw
+			defer func() {
+				cw.close()
+				c.writer.ResponseWriter = cw.ResponseWriter
+			}()
+			return next(c)
+		}
+	}
+}
+
+// negotiateEncoding returns the index of the coding in offers that an
+// Accept-Encoding header prefers, or -1 if none is accepted. Explicit
+// entries override "*"; ties go to the earlier offer.
+func negotiateEncoding(header string, offers []string) int {
+	qs := make(map[string]float64)
+	for part := range strings.SplitSeq(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+		q := 1.0
+		if _, value, ok := strings.Cut(params, "q="); ok {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil {
+				q = v
+			}
+		}
+		qs[name] = q
+	}
+
+	best, bestQ := -1, 0.0
+	for i, offer := range offers {
+		q, ok := qs[offer]
+		if !ok {
+			q = qs["*"]
+		}
+		if q > bestQ {
+			best, bestQ = i, q
+		}
+	}
+	return best
+}
+
+// compressWriter buffers the start of a response to decide whether to
+// compress it, then writes it through an EncodingWriter or unchanged.
+type compressWriter struct {
+	http.ResponseWriter
+	config   *GzipConfig
+	encoding string
+	pool     *sync.Pool
+
+	status   int
+	buf      []byte
+	decided  bool
+	enc      EncodingWriter
+	hijacked bool
+}
+
+// WriteHeader records the status; headers are sent once the response is
+// known to be compressed or not. Informational (1xx) responses are sent
+// immediately.
+func (w *compressWriter) WriteHeader(code int) {
+	if code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers data until MinSize bytes are available, then compresses.
+func (w *compressWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if !w.decided {
+		w.buf = append(w.buf, b...)
+		if len(w.buf) < w.config.MinSize {
+			return len(b), nil
+		}
+		if err := w.decide(true); err != nil {
+			return 0, err
+		}
+		return len(b), nil
+	}
+	if w.enc != nil {
+		return w.enc.Write(b)
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// decide sends the headers, compressed if the response qualifies and
+// compress is true, then writes the buffered data.
+func (w *compressWriter) decide(compress bool) error {
+	w.decided = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+
+	h := w.Header()
+	if h.Get("Content-Type") == "" && len(w.buf) > 0 {
+		h.Set("Content-Type", http.DetectContentType(w.buf))
+	}
+	compressible := w.compressible()
+	if compressible {
+		h.Add("Vary", "Accept-Encoding")
+	}
+
+	if compress && compressible {
+		h.Set("Content-Encoding", w.encoding)
+		h.Del("Content-Length")
+		w.enc = w.pool.Get().(EncodingWriter)
+		w.enc.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+
+	if len(w.buf) == 0 {
+		return nil
+	}
+	var err error
+	if w.enc != nil {
+		_, err = w.enc.Write(w.buf)
+	} else {
+		_, err = w.ResponseWriter.Write(w.buf)
+	}
+	w.buf = nil
+	return err
+}
+
+// compressible reports whether the response may be compressed, based on the
+// status and headers.
+func (w *compressWriter) compressible() bool {
+	switch w.status {
+	case http.StatusNoContent, http.StatusNotModified, http.StatusPartialContent:
+		return false
+	}
+	h := w.Header()
+	if h.Get("Content-Encoding") != "" || h.Get("Content-Range") != "" {
+		return false
+	}
+	mediaType, _, err := mime.ParseMediaType(h.Get("Content-Type"))
+	if err != nil {
+		return false
+	}
+	for _, t := range w.config.ContentTypes {
+		if t == mediaType || strings.HasSuffix(t, "/*") && strings.HasPrefix(mediaType, t[:len(t)-1]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Flush sends the buffered data (compressed if the response qualifies) and
+// flushes the compressed stream.
+func (w *compressWriter) Flush() {
+	if w.hijacked {
+		return
+	}
+	if !w.decided {
+		_ = w.decide(true)
+	}
+	if w.enc != nil {
+		_ = w.enc.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker; hijacked connections are not compressed.
+func (w *compressWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *compressWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// close finishes the response: small buffered responses are sent
+// uncompressed, and the compressed stream is terminated.
+func (w *compressWriter) close() {
+	if w.hijacked {
+		return
+	}
+	if !w.decided && w.status != 0 {
+		_ = w.decide(false)
+	}
+	if w.enc != nil {
+		_ = w.enc.Close()
+		w.enc.Reset(nil)
+		w.pool.Put(w.enc)
+		w.enc = nil
+	}
+}
@@ -0,0 +1,235 @@
+package rig
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func gunzip(t *testing.T, body io.Reader) string {
+	t.Helper()
+	gz, err := gzip.NewReader(body)
+	if err != nil {
+		t.Fatalf("gzip.NewReader: %v", err)
+	}
+	data, err := io.ReadAll(gz)
+	if err != nil {
+		t.Fatalf("reading gzip body: %v", err)
+	}
+	return string(data)
+}
+
+func TestGzip(t *testing.T) {
+	large := strings.Repeat("compress me ", 200)
+
+	r := New()
+	r.Use(GzipWithConfig(GzipConfig{MinSize: 100}))
+	r.GET("/large", func(c *Context) error {
+		c.SetHeader("Content-Length", "2400")
+		return c.String(http.StatusCreated, "%s", large)
+	})
+	r.GET("/small", func(c *Context) error {
+		return c.String(http.StatusOK, "tiny")
+	})
+	r.GET("/image", func(c *Context) error {
+		c.Data(http.StatusOK, "image/png", []byte(large))
+		return nil
+	})
+	r.GET("/encoded", func(c *Context) error {
+		c.SetHeader("Content-Encoding", "br")
+		c.Data(http.StatusOK, "text/plain", []byte(large))
+		return nil
+	})
+	r.GET("/sniffed", func(c *Context) error {
+		_, err := c.WriteString("<html><body>" + large + "</body></html>")
+		return err
+	})
+
+	tests := []struct {
+		path       string
+		accept     string
+		compressed bool
+	}{
+		{"/large", "gzip, deflate, br", true},
+		{"/large", "", false},
+		{"/large", "gzip;q=0, *", false},
+		{"/large", "*", true},
+		{"/small", "gzip", false},
+		{"/image", "gzip", false},
+		{"/encoded", "gzip", false},
+		{"/sniffed", "gzip", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path+" "+tt.accept, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			r.ServeHTTP(w, req)
+
+			gotCompressed := w.Header().Get("Content-Encoding") == "gzip"
+			if gotCompressed != tt.compressed {
+				t.Fatalf("Content-Encoding = %q, compressed want %v", w.Header().Get("Content-Encoding"), tt.compressed)
+			}
+			if !tt.compressed {
+				return
+			}
+			if w.Header().Get("Content-Length") != "" {
+				t.Error("Content-Length should be removed from compressed responses")
+			}
+			if w.Header().Get("Vary") != "Accept-Encoding" {
+				t.Errorf("Vary = %q, want Accept-Encoding", w.Header().Get("Vary"))
+			}
+			if body := gunzip(t, w.Body); !strings.Contains(body, large) {
+				t.Errorf("decompressed body = %q", body)
+			}
+		})
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/large", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusCreated {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusCreated)
+	}
+}
+
+func TestGzip_ErrorHandlerAndStream(t *testing.T) {
+	r := New()
+	r.Use(Gzip())
+	r.GET("/error", func(c *Context) error {
+		return NewHTTPError(http.StatusNotFound, "missing")
+	})
+	r.GET("/stream", func(c *Context) error {
+		return c.Stream(http.StatusOK, "text/plain", func(w io.Writer) error {
+			_, err := io.WriteString(w, "chunk")
+			return err
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/error", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotFound || w.Body.String() != "missing" {
+		t.Errorf("error response = %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/stream", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+	if w.Header().Get("Content-Encoding") != "gzip" || !w.Flushed {
+		t.Fatalf("stream should be compressed and flushed, got %q flushed=%v", w.Header().Get("Content-Encoding"), w.Flushed)
+	}
+	if body := gunzip(t, w.Body); body != "chunk" {
+		t.Errorf("decompressed body = %q", body)
+	}
+}
+
+type hijackRecorder struct {
+	*httptest.ResponseRecorder
+	hijacked bool
+}
+
+func (h *hijackRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h.hijacked = true
+	return nil, nil, nil
+}
+
+func TestGzip_Hijack(t *testing.T) {
+	r := New()
+	r.Use(Gzip())
+	r.GET("/ws", func(c *Context) error {
+		_, _, err := http.NewResponseController(c.Writer()).Hijack()
+		return err
+	})
+
+	w := &hijackRecorder{ResponseRecorder: httptest.NewRecorder()}
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+	r.ServeHTTP(w, req)
+
+	if !w.hijacked {
+		t.Fatal("Hijack should reach the underlying writer")
+	}
+	if w.Header().Get("Content-Encoding") != "" || w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Error("nothing should be written after hijacking")
+	}
+}
+
+func TestGzipWithConfig_InvalidLevelPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("GzipWithConfig should panic on an invalid level")
+		}
+	}()
+	GzipWithConfig(GzipConfig{Level: 42})
+}
+
+func TestCompress_Encodings(t *testing.T) {
+	large := strings.Repeat("compress me ", 200)
+
+	r := New()
+	r.Use(Compress(CompressConfig{
+		Encodings: []Encoding{{
+			Name: "deflate",
+			New: func() EncodingWriter {
+				fw, _ := flate.NewWriter(nil, flate.BestSpeed)
+				return fw
+			},
+		}},
+	}))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "%s", large)
+	})
+
+	tests := []struct {
+		accept   string
+		encoding string
+	}{
+		{"gzip, deflate", "deflate"},
+		{"gzip, deflate;q=0.5", "gzip"},
+		{"br, zstd, gzip", "gzip"},
+		{"*", "deflate"},
+		{"deflate;q=0, *;q=0.1", "gzip"},
+		{"identity", ""},
+	}
+
+	for _, tt := range tests {
+		for range 2 { // The second request reuses a pooled writer
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.Header.Set("Accept-Encoding", tt.accept)
+			r.ServeHTTP(w, req)
+
+			if got := w.Header().Get("Content-Encoding"); got != tt.encoding {
+				t.Fatalf("Accept-Encoding %q: Content-Encoding = %q, want %q", tt.accept, got, tt.encoding)
+			}
+
+			var body string
+			switch tt.encoding {
+			case "deflate":
+				data, err := io.ReadAll(flate.NewReader(w.Body))
+				if err != nil {
+					t.Fatalf("reading deflate body: %v", err)
+				}
+				body = string(data)
+			case "gzip":
+				body = gunzip(t, w.Body)
+			default:
+				body = w.Body.String()
+			}
+			if body != large {
+				t.Errorf("Accept-Encoding %q: body mismatch", tt.accept)
+			}
+		}
+	}
+}
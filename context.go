@@ -3,16 +3,22 @@ package rig
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
 	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
 )
 
 // Context wraps http.ResponseWriter and *http.Request to provide
 // convenient helper methods for HTTP handlers.
 type Context struct {
-	writer  http.ResponseWriter
+	writer  *trackingWriter
 	request *http.Request
 
 	// written tracks whether the response has been written
@@ -22,14 +28,34 @@ type Context struct {
 	// It is lazily initialized to save memory on simple requests.
 	store map[string]any
 
+	// deps holds values registered with Provide, keyed by their type.
+	deps map[reflect.Type]any
+
+	// timedOut records that a deadline installed with WithTimeout fired.
+	timedOut bool
+
+	// panicReported records that a recovered panic was reported, so the
+	// error it is turned into is not reported again.
+	panicReported bool
+
 	// queryCache caches parsed query parameters to avoid re-parsing on each access.
 	queryCache url.Values
+
+	// router is the Router that dispatched this request (nil in standalone contexts).
+	router *Router
+
+	// originalBody is the request body before MaxBodyBytes limited it.
+	originalBody io.ReadCloser
 }
 
 // newContext creates a new Context from the given ResponseWriter and Request.
 func newContext(w http.ResponseWriter, r *http.Request) *Context {
+	tw, ok := w.(*trackingWriter)
+	if !ok {
+		tw = &trackingWriter{ResponseWriter: w}
+	}
 	return &Context{
-		writer:  w,
+		writer:  tw,
 		request: r,
 	}
 }
@@ -39,7 +65,10 @@ func (c *Context) Request() *http.Request {
 	return c.request
 }
 
-// Writer returns the underlying http.ResponseWriter.
+// Writer returns the response writer. It wraps the server's ResponseWriter
+// to track the status code and size of the response (see StatusCode and
+// BytesWritten) and supports http.Flusher, http.Hijacker, and
+// http.ResponseController.
 func (c *Context) Writer() http.ResponseWriter {
 	return c.writer
 }
@@ -64,6 +93,52 @@ func (c *Context) SetContext(ctx context.Context) {
 	c.request = c.request.WithContext(ctx)
 }
 
+// WithTimeout installs a deadline d from now on the request context, so
+// downstream calls made with c.Context() are bounded. It never extends a
+// deadline that is already set (e.g., by the Timeout middleware).
+//
+// The returned function cancels the deadline and restores the previous
+// context, so calls made afterwards are no longer bounded. Always call it,
+// typically with defer.
+//
+// Example:
+//
+//	cancel := c.WithTimeout(2 * time.Second)
+//	user, err := users.Get(c.Context(), id)
+//	cancel()
+//	if c.IsTimedOut() {
+//	    return rig.NewHTTPError(http.StatusGatewayTimeout, "user service timed out")
+//	}
+func (c *Context) WithTimeout(d time.Duration) context.CancelFunc {
+	prev := c.Context()
+	ctx, cancel := context.WithTimeout(prev, d)
+	c.SetContext(ctx)
+	return func() {
+		cancel()
+		if c.Context() == ctx {
+			// Remember that the deadline fired before restoring
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				c.timedOut = true
+			}
+			c.SetContext(prev)
+		}
+	}
+}
+
+// Err returns the error of the request context: nil while the request is
+// active, context.DeadlineExceeded after its deadline (see WithTimeout and
+// Timeout), or context.Canceled if the client went away.
+func (c *Context) Err() error {
+	return c.Context().Err()
+}
+
+// IsTimedOut reports whether the request context's deadline has passed,
+// or a deadline installed with WithTimeout passed before its cancel
+// function was called.
+func (c *Context) IsTimedOut() bool {
+	return c.timedOut || errors.Is(c.Err(), context.DeadlineExceeded)
+}
+
 // JSON writes a JSON response with the given status code.
 // It sets the Content-Type header to "application/json; charset=utf-8" and encodes
 // the provided value v to the response body.
@@ -84,19 +159,75 @@ func (c *Context) JSON(code int, v any) error {
 	return json.NewEncoder(c.writer).Encode(v)
 }
 
+// ErrInvalidCallback is returned by JSONP when the callback name is not a
+// valid JavaScript identifier path (400).
+var ErrInvalidCallback = NewHTTPError(http.StatusBadRequest, "invalid JSONP callback name")
+
+// jsonpCallback matches dotted JavaScript identifiers such as "cb" or "jQuery.handlers.done".
+var jsonpCallback = regexp.MustCompile(`^[A-Za-z_$][A-Za-z0-9_$]*(\.[A-Za-z_$][A-Za-z0-9_$]*)*$`)
+
+// maxCallbackLength limits JSONP callback names.
+const maxCallbackLength = 128
+
+// JSONP writes v as a JSONP response wrapped in a call to callback, for legacy
+// cross-domain consumers that cannot use CORS. If callback is empty, it falls
+// back to a plain JSON response.
+//
+// The callback name is validated (dotted JavaScript identifiers only, up to
+// 128 characters) to prevent script injection; invalid names return
+// ErrInvalidCallback without writing a response. The body is prefixed with
+// an empty comment and sent with X-Content-Type-Options: nosniff to guard
+// against content-sniffing attacks.
+//
+// Example:
+//
+//	r.GET("/api/widgets", func(c *rig.Context) error {
+//	    return c.JSONP(http.StatusOK, c.Query("callback"), widgets)
+//	})
+func (c *Context) JSONP(code int, callback string, v any) error {
+	if callback == "" {
+		return c.JSON(code, v)
+	}
+	if len(callback) > maxCallbackLength || !jsonpCallback.MatchString(callback) {
+		return ErrInvalidCallback
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	c.writer.Header().Set("Content-Type", "application/javascript; charset=utf-8")
+	c.writer.Header().Set("X-Content-Type-Options", "nosniff")
+	c.Status(code)
+	_, err = fmt.Fprintf(c.writer, "/**/ %s(%s);", callback, data)
+	return err
+}
+
 // Bind decodes the request body into the provided struct v.
 // It expects the request body to be JSON and handles closing the body.
 // The struct v should be a pointer.
 //
+// If a binder is registered for the request's Content-Type (see
+// RegisterBinder), Bind uses it instead of the JSON decoder.
+//
 // By default, unknown fields in the JSON are silently ignored.
 // For stricter APIs that should reject unknown fields, use BindStrict instead.
 func (c *Context) Bind(v any) error {
 	if c.request.Body == nil {
 		return nil
 	}
+	if binder, ok := c.customBinder(); ok {
+		return bodyError(binder(c, v))
+	}
+	return c.bindJSON(v)
+}
+
+// bindJSON decodes a JSON request body into v and closes the body.
+func (c *Context) bindJSON(v any) error {
 	defer func() { _ = c.request.Body.Close() }()
 
-	return json.NewDecoder(c.request.Body).Decode(v)
+	return bodyError(json.NewDecoder(c.request.Body).Decode(v))
 }
 
 // BindStrict decodes the request body into the provided struct v,
@@ -113,7 +244,7 @@ func (c *Context) BindStrict(v any) error {
 
 	decoder := json.NewDecoder(c.request.Body)
 	decoder.DisallowUnknownFields()
-	return decoder.Decode(v)
+	return bodyError(decoder.Decode(v))
 }
 
 // Status writes the HTTP status code to the response.
@@ -174,6 +305,25 @@ func (c *Context) File(filepath string) {
 	c.written = true
 }
 
+// FileFromFS writes the file at filepath in fsys into the response body,
+// like File but for an fs.FS such as an embed.FS. Content-Type detection,
+// range requests, and Last-Modified headers are handled by
+// http.ServeFileFS. A leading "/" in filepath is ignored.
+//
+// Example:
+//
+//	//go:embed assets
+//	var assets embed.FS
+//
+//	r.GET("/logo.png", func(c *rig.Context) error {
+//	    c.FileFromFS("assets/logo.png", assets)
+//	    return nil
+//	})
+func (c *Context) FileFromFS(filepath string, fsys fs.FS) {
+	http.ServeFileFS(c.writer, c.request, fsys, strings.TrimPrefix(filepath, "/"))
+	c.written = true
+}
+
 // Data writes raw bytes to the response with the specified status code
 // and content type.
 //
@@ -186,6 +336,90 @@ func (c *Context) Data(code int, contentType string, data []byte) {
 	_, _ = c.writer.Write(data)
 }
 
+// String writes a plain text response with the given status code.
+// If args are provided, format is interpolated with fmt.Sprintf;
+// otherwise it is written as-is.
+//
+// Example:
+//
+//	return c.String(http.StatusOK, "Hello, %s!", name)
+func (c *Context) String(code int, format string, args ...any) error {
+	c.writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	c.Status(code)
+	if len(args) > 0 {
+		_, err := fmt.Fprintf(c.writer, format, args...)
+		return err
+	}
+	_, err := io.WriteString(c.writer, format)
+	return err
+}
+
+// NoContent writes a response with the given status code and no body
+// (e.g., http.StatusNoContent after a successful DELETE).
+func (c *Context) NoContent(code int) error {
+	c.Status(code)
+	return nil
+}
+
+// HTMLBlob writes pre-rendered HTML bytes with the given status code.
+// It sets the Content-Type header to "text/html; charset=utf-8".
+func (c *Context) HTMLBlob(code int, html []byte) error {
+	c.writer.Header().Set("Content-Type", "text/html; charset=utf-8")
+	c.Status(code)
+	_, err := c.writer.Write(html)
+	return err
+}
+
+// Stream writes a response with the given status code and content type whose
+// body is produced by fn. Every write fn makes is flushed to the client
+// immediately (when the ResponseWriter supports flushing), so large exports
+// and chunked responses are sent as they are generated instead of being
+// buffered in memory. Stream returns the error returned by fn.
+//
+// Since the status code is sent before fn runs, errors returned by fn cannot
+// change it; the response is simply cut short. Handlers should check
+// c.Context().Done() in long-running loops to stop when the client goes away.
+//
+// Example:
+//
+//	return c.Stream(http.StatusOK, "text/csv", func(w io.Writer) error {
+//	    for row := range rows {
+//	        if _, err := fmt.Fprintf(w, "%s,%d\n", row.Name, row.Total); err != nil {
+//	            return err
+//	        }
+//	    }
+//	    return nil
+//	})
+func (c *Context) Stream(code int, contentType string, fn func(w io.Writer) error) error {
+	c.writer.Header().Set("Content-Type", contentType)
+	c.Status(code)
+
+	fw := &flushWriter{w: c.writer, rc: http.NewResponseController(c.writer)}
+	// Send the headers right away, before fn produces any output
+	fw.flush()
+	return fn(fw)
+}
+
+// flushWriter flushes the underlying ResponseWriter after every write.
+type flushWriter struct {
+	w  io.Writer
+	rc *http.ResponseController
+}
+
+func (fw *flushWriter) Write(p []byte) (int, error) {
+	n, err := fw.w.Write(p)
+	if err == nil {
+		fw.flush()
+	}
+	return n, err
+}
+
+// flush flushes buffered data to the client. Writers that don't support
+// flushing (http.ErrNotSupported) are written to normally.
+func (fw *flushWriter) flush() {
+	_ = fw.rc.Flush()
+}
+
 // Param returns the value of a path parameter from the request.
 // This uses Go 1.22+ PathValue feature.
 func (c *Context) Param(name string) string {
@@ -248,9 +482,25 @@ func (c *Context) Path() string {
 	return c.request.URL.Path
 }
 
-// Written returns true if the response has been written.
+// Written returns true if the response has been written, either through
+// a Context helper or directly through Writer.
 func (c *Context) Written() bool {
-	return c.written
+	return c.written || c.writer.status != 0
+}
+
+// StatusCode returns the status code of the response, or 200 if nothing has
+// been written yet (the status net/http sends by default).
+// Middleware can read it after calling the next handler.
+func (c *Context) StatusCode() int {
+	if c.writer.status == 0 {
+		return http.StatusOK
+	}
+	return c.writer.status
+}
+
+// BytesWritten returns the number of response body bytes written so far.
+func (c *Context) BytesWritten() int64 {
+	return c.writer.size
 }
 
 // Set stores a value in the context's key-value store.
@@ -3,12 +3,16 @@ package rig
 import (
 	"bytes"
 	"context"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"slices"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
 )
 
 func TestContext_JSON(t *testing.T) {
@@ -303,8 +307,46 @@ func TestContext_RequestAndWriter(t *testing.T) {
 		t.Error("Request() did not return the original request")
 	}
 
-	if c.Writer() != w {
-		t.Error("Writer() did not return the original writer")
+	// Writer wraps the original writer to track the response
+	if _, err := c.Writer().Write([]byte("ok")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if w.Body.String() != "ok" {
+		t.Errorf("write did not reach the original writer: %q", w.Body.String())
+	}
+	if rw, ok := c.Writer().(interface{ Unwrap() http.ResponseWriter }); !ok || rw.Unwrap() != w {
+		t.Error("Writer() should unwrap to the original writer")
+	}
+}
+
+func TestContext_StatusCodeAndBytesWritten(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if c.StatusCode() != http.StatusOK || c.BytesWritten() != 0 || c.Written() {
+		t.Errorf("before writing: status = %d, bytes = %d, written = %v",
+			c.StatusCode(), c.BytesWritten(), c.Written())
+	}
+
+	hints := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	hints.Writer().WriteHeader(http.StatusEarlyHints)
+	if hints.Written() {
+		t.Error("1xx responses should not count as written")
+	}
+
+	// Direct writes are tracked as well as helper writes
+	c.Writer().WriteHeader(http.StatusAccepted)
+	_, _ = c.Writer().Write([]byte("queued"))
+	_, _ = c.WriteString("!")
+
+	if c.StatusCode() != http.StatusAccepted {
+		t.Errorf("StatusCode() = %d, want %d", c.StatusCode(), http.StatusAccepted)
+	}
+	if c.BytesWritten() != 7 {
+		t.Errorf("BytesWritten() = %d, want 7", c.BytesWritten())
+	}
+	if !c.Written() {
+		t.Error("Written() should be true after direct writes")
 	}
 }
 
@@ -991,3 +1033,270 @@ func TestContext_Data(t *testing.T) {
 		t.Error("Written() should be true after Data")
 	}
 }
+
+func TestContext_String(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		args   []any
+		want   string
+	}{
+		{"formatted", "Hello, %s!", []any{"Alice"}, "Hello, Alice!"},
+		{"verbatim without args", "100% done", nil, "100% done"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if err := c.String(http.StatusAccepted, tt.format, tt.args...); err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+			if w.Code != http.StatusAccepted {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusAccepted)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "text/plain; charset=utf-8" {
+				t.Errorf("Content-Type = %q", ct)
+			}
+			if w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_FileFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"assets/app.css": {Data: []byte("body { color: red; }")},
+	}
+
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/app.css", nil))
+	c.FileFromFS("/assets/app.css", fsys)
+
+	if !c.Written() {
+		t.Error("Written() should be true after FileFromFS")
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "body { color: red; }" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/css; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	// Range requests are supported
+	req := httptest.NewRequest(http.MethodGet, "/app.css", nil)
+	req.Header.Set("Range", "bytes=0-3")
+	w = httptest.NewRecorder()
+	newContext(w, req).FileFromFS("assets/app.css", fsys)
+	if w.Code != http.StatusPartialContent || w.Body.String() != "body" {
+		t.Errorf("range: got %d %q", w.Code, w.Body.String())
+	}
+
+	w = httptest.NewRecorder()
+	newContext(w, httptest.NewRequest(http.MethodGet, "/missing.css", nil)).FileFromFS("assets/missing.css", fsys)
+	if w.Code != http.StatusNotFound {
+		t.Errorf("missing file: status = %d, want 404", w.Code)
+	}
+}
+
+func TestContext_NoContent(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodDelete, "/", nil))
+
+	if err := c.NoContent(http.StatusNoContent); err != nil {
+		t.Fatalf("NoContent() error = %v", err)
+	}
+	if w.Code != http.StatusNoContent {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+	if !c.Written() {
+		t.Error("Written() should be true after NoContent")
+	}
+}
+
+func TestContext_HTMLBlob(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	html := []byte("<h1>Hello</h1>")
+	if err := c.HTMLBlob(http.StatusOK, html); err != nil {
+		t.Fatalf("HTMLBlob() error = %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/html; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if !bytes.Equal(w.Body.Bytes(), html) {
+		t.Errorf("body = %q, want %q", w.Body.Bytes(), html)
+	}
+}
+
+// flushCounter records the body length at each flush.
+type flushCounter struct {
+	*httptest.ResponseRecorder
+	flushedAt []int
+}
+
+func (f *flushCounter) Flush() {
+	f.flushedAt = append(f.flushedAt, f.Body.Len())
+	f.ResponseRecorder.Flush()
+}
+
+func TestContext_Stream(t *testing.T) {
+	w := &flushCounter{ResponseRecorder: httptest.NewRecorder()}
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/export", nil))
+
+	err := c.Stream(http.StatusOK, "text/csv", func(out io.Writer) error {
+		for _, line := range []string{"a,1\n", "b,2\n"} {
+			if _, err := io.WriteString(out, line); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "text/csv" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if got := w.Body.String(); got != "a,1\nb,2\n" {
+		t.Errorf("body = %q", got)
+	}
+	// Headers are flushed first, then after every write
+	if want := []int{0, 4, 8}; !slices.Equal(w.flushedAt, want) {
+		t.Errorf("flushed at %v, want %v", w.flushedAt, want)
+	}
+}
+
+func TestContext_Stream_Error(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	errExport := errors.New("export failed")
+	err := c.Stream(http.StatusOK, "text/plain", func(out io.Writer) error {
+		_, _ = io.WriteString(out, "partial")
+		return errExport
+	})
+	if !errors.Is(err, errExport) {
+		t.Errorf("Stream() error = %v, want %v", err, errExport)
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "partial" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestContext_Stream_NoFlusher(t *testing.T) {
+	var buf bytes.Buffer
+	w := &plainWriter{header: make(http.Header), body: &buf}
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	err := c.Stream(http.StatusOK, "text/plain", func(out io.Writer) error {
+		_, err := io.WriteString(out, "ok")
+		return err
+	})
+	if err != nil {
+		t.Fatalf("Stream() error = %v", err)
+	}
+	if buf.String() != "ok" {
+		t.Errorf("body = %q", buf.String())
+	}
+}
+
+// plainWriter is a ResponseWriter without Flush support.
+type plainWriter struct {
+	header http.Header
+	body   *bytes.Buffer
+}
+
+func (w *plainWriter) Header() http.Header         { return w.header }
+func (w *plainWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+func (w *plainWriter) WriteHeader(int)             {}
+
+func TestContext_JSONP(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := c.JSONP(http.StatusOK, "jQuery.handlers.done_1", map[string]string{"name": "</script>"}); err != nil {
+		t.Fatalf("JSONP() error = %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/javascript; charset=utf-8" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("X-Content-Type-Options should be nosniff")
+	}
+	want := `/**/ jQuery.handlers.done_1({"name":"\u003c/script\u003e"});`
+	if w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
+
+func TestContext_JSONP_EmptyCallback(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := c.JSONP(http.StatusOK, "", map[string]int{"n": 1}); err != nil {
+		t.Fatalf("JSONP() error = %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+		t.Errorf("Content-Type = %q, want JSON fallback", ct)
+	}
+}
+
+func TestContext_JSONP_InvalidCallback(t *testing.T) {
+	callbacks := []string{
+		"alert(1)//",
+		"cb;evil",
+		"1abc",
+		"a..b",
+		"<script>",
+		strings.Repeat("a", maxCallbackLength+1),
+	}
+
+	for _, callback := range callbacks {
+		w := httptest.NewRecorder()
+		c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		if err := c.JSONP(http.StatusOK, callback, nil); err != ErrInvalidCallback {
+			t.Errorf("JSONP(%q) error = %v, want ErrInvalidCallback", callback, err)
+		}
+		if c.Written() {
+			t.Errorf("JSONP(%q) wrote a response", callback)
+		}
+	}
+}
+
+func TestContext_WithTimeout(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	parent := c.Context()
+
+	cancel := c.WithTimeout(time.Hour)
+	if _, ok := c.Context().Deadline(); !ok {
+		t.Error("WithTimeout should install a deadline")
+	}
+	if c.Err() != nil || c.IsTimedOut() {
+		t.Error("active request should not be timed out")
+	}
+	cancel()
+	if c.Context() != parent || c.IsTimedOut() {
+		t.Error("cancel should restore the previous context without marking a timeout")
+	}
+
+	cancel = c.WithTimeout(time.Millisecond)
+	<-c.Context().Done()
+	if !errors.Is(c.Err(), context.DeadlineExceeded) || !c.IsTimedOut() {
+		t.Errorf("Err() = %v, IsTimedOut() = %v after deadline", c.Err(), c.IsTimedOut())
+	}
+	cancel()
+	if c.Err() != nil {
+		t.Errorf("Err() = %v after cancel, want restored context", c.Err())
+	}
+	if !c.IsTimedOut() {
+		t.Error("IsTimedOut should report a deadline that fired before cancel")
+	}
+}
@@ -0,0 +1,259 @@
+package rig
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// MinKeyLength is the minimum length in bytes of a KeyRing secret.
+const MinKeyLength = 32
+
+// Errors returned by the signed and encrypted cookie helpers.
+var (
+	// ErrInvalidCookie is returned when a cookie fails signature verification
+	// or decryption with every key in the key ring.
+	ErrInvalidCookie = errors.New("rig: invalid or tampered cookie")
+
+	// ErrNoKeyRing is returned when signed or encrypted cookies are used
+	// without a key ring configured on the router.
+	ErrNoKeyRing = errors.New("rig: no key ring configured; call Router.SetKeyRing")
+)
+
+// cookieKey holds the keys derived from a single secret.
+type cookieKey struct {
+	sign []byte
+	aead cipher.AEAD
+}
+
+// KeyRing holds the secrets used to sign and encrypt cookies.
+// The first secret is used for new cookies; every secret is tried when
+// reading them. To rotate keys, prepend the new secret and keep the old
+// ones until cookies issued with them have expired.
+type KeyRing struct {
+	keys []cookieKey
+}
+
+// NewKeyRing creates a KeyRing from one or more secrets, newest first.
+// Each secret must be at least MinKeyLength bytes of random data.
+//
+// Example:
+//
+//	keys, err := rig.NewKeyRing(
+//	    []byte(os.Getenv("COOKIE_KEY")),          // current
+//	    []byte(os.Getenv("COOKIE_KEY_PREVIOUS")), // still accepted
+//	)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	r.SetKeyRing(keys)
+func NewKeyRing(secrets ...[]byte) (*KeyRing, error) {
+	if len(secrets) == 0 {
+		return nil, errors.New("rig: key ring requires at least one secret")
+	}
+
+	kr := &KeyRing{keys: make([]cookieKey, 0, len(secrets))}
+	for i, secret := range secrets {
+		if len(secret) < MinKeyLength {
+			return nil, fmt.Errorf("rig: key ring secret %d is %d bytes, need at least %d", i, len(secret), MinKeyLength)
+		}
+
+		// Derive independent keys so signing and encryption never share key material
+		block, err := aes.NewCipher(deriveKey(secret, "rig-cookie-encrypt"))
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		kr.keys = append(kr.keys, cookieKey{sign: deriveKey(secret, "rig-cookie-sign"), aead: aead})
+	}
+	return kr, nil
+}
+
+// deriveKey derives a 32-byte purpose-specific key from secret.
+func deriveKey(secret []byte, purpose string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(purpose))
+	return mac.Sum(nil)
+}
+
+// signature returns the signature of value for the cookie name.
+func (k cookieKey) signature(name, value string) []byte {
+	mac := hmac.New(sha256.New, k.sign)
+	mac.Write([]byte(name))
+	mac.Write([]byte{0})
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
+
+// SetKeyRing sets the KeyRing used by the signed and encrypted cookie helpers.
+func (r *Router) SetKeyRing(kr *KeyRing) {
+	r.keyRing = kr
+}
+
+// keyRing returns the router's key ring, or ErrNoKeyRing.
+func (c *Context) keyRing() (*KeyRing, error) {
+	if c.router == nil || c.router.keyRing == nil {
+		return nil, ErrNoKeyRing
+	}
+	return c.router.keyRing, nil
+}
+
+// Cookie returns the named cookie from the request,
+// or http.ErrNoCookie if it is not present.
+func (c *Context) Cookie(name string) (*http.Cookie, error) {
+	return c.request.Cookie(name)
+}
+
+// SetCookie adds a Set-Cookie header to the response.
+func (c *Context) SetCookie(cookie *http.Cookie) {
+	http.SetCookie(c.writer, cookie)
+}
+
+// SetSignedCookie sets a cookie whose value is signed with HMAC-SHA256.
+// The value remains readable by the client but cannot be modified without
+// detection. The signature covers the cookie name, so a signed value cannot
+// be replayed under a different cookie.
+//
+// Example:
+//
+//	err := c.SetSignedCookie(&http.Cookie{
+//	    Name:     "user_id",
+//	    Value:    "42",
+//	    Path:     "/",
+//	    HttpOnly: true,
+//	    Secure:   true,
+//	})
+func (c *Context) SetSignedCookie(cookie *http.Cookie) error {
+	kr, err := c.keyRing()
+	if err != nil {
+		return err
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString([]byte(cookie.Value))
+	sig := base64.RawURLEncoding.EncodeToString(kr.keys[0].signature(cookie.Name, payload))
+
+	signed := *cookie
+	signed.Value = payload + "." + sig
+	c.SetCookie(&signed)
+	return nil
+}
+
+// SignedCookie returns the verified value of a cookie set with SetSignedCookie.
+// It returns http.ErrNoCookie if the cookie is missing, or ErrInvalidCookie
+// if its signature does not match any key in the key ring.
+func (c *Context) SignedCookie(name string) (string, error) {
+	kr, err := c.keyRing()
+	if err != nil {
+		return "", err
+	}
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	payload, sigText, ok := strings.Cut(cookie.Value, ".")
+	if !ok {
+		return "", ErrInvalidCookie
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(sigText)
+	if err != nil {
+		return "", ErrInvalidCookie
+	}
+
+	for _, key := range kr.keys {
+		if hmac.Equal(sig, key.signature(name, payload)) {
+			value, err := base64.RawURLEncoding.DecodeString(payload)
+			if err != nil {
+				return "", ErrInvalidCookie
+			}
+			return string(value), nil
+		}
+	}
+	return "", ErrInvalidCookie
+}
+
+// SetEncryptedCookie sets a cookie whose value is encrypted and authenticated
+// with AES-256-GCM, so the client can neither read nor modify it.
+// Use it for small pieces of session-like data stored client-side.
+func (c *Context) SetEncryptedCookie(cookie *http.Cookie) error {
+	kr, err := c.keyRing()
+	if err != nil {
+		return err
+	}
+	value, err := kr.Encrypt([]byte(cookie.Value), []byte(cookie.Name))
+	if err != nil {
+		return err
+	}
+
+	encrypted := *cookie
+	encrypted.Value = value
+	c.SetCookie(&encrypted)
+	return nil
+}
+
+// EncryptedCookie returns the decrypted value of a cookie set with
+// SetEncryptedCookie. It returns http.ErrNoCookie if the cookie is missing,
+// or ErrInvalidCookie if it cannot be decrypted with any key in the key ring.
+func (c *Context) EncryptedCookie(name string) (string, error) {
+	kr, err := c.keyRing()
+	if err != nil {
+		return "", err
+	}
+	cookie, err := c.Cookie(name)
+	if err != nil {
+		return "", err
+	}
+
+	value, err := kr.Decrypt(cookie.Value, []byte(name))
+	if err != nil {
+		return "", err
+	}
+	return string(value), nil
+}
+
+// Encrypt encrypts and authenticates plaintext with AES-256-GCM using the
+// newest key, and returns it encoded as unpadded base64url, safe for cookie
+// values. additionalData (e.g., a cookie name) is authenticated but not
+// encrypted; the same value must be passed to Decrypt. It lets packages
+// such as sessions build on the router's key ring.
+func (kr *KeyRing) Encrypt(plaintext, additionalData []byte) (string, error) {
+	aead := kr.keys[0].aead
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := aead.Seal(nonce, nonce, plaintext, additionalData)
+	return base64.RawURLEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts a value produced by Encrypt, trying every key in the key
+// ring. It returns ErrInvalidCookie if the value cannot be decrypted or was
+// tampered with.
+func (kr *KeyRing) Decrypt(value string, additionalData []byte) ([]byte, error) {
+	sealed, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCookie
+	}
+
+	for _, key := range kr.keys {
+		nonceSize := key.aead.NonceSize()
+		if len(sealed) < nonceSize {
+			return nil, ErrInvalidCookie
+		}
+		plaintext, err := key.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], additionalData)
+		if err == nil {
+			return plaintext, nil
+		}
+	}
+	return nil, ErrInvalidCookie
+}
@@ -0,0 +1,166 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var (
+	testSecretA = bytes.Repeat([]byte("a"), MinKeyLength)
+	testSecretB = bytes.Repeat([]byte("b"), MinKeyLength)
+)
+
+// cookieRouter returns a router that sets a cookie on /set and reads it on /get.
+func cookieRouter(t *testing.T, kr *KeyRing, encrypted bool) *Router {
+	t.Helper()
+
+	r := New()
+	r.SetKeyRing(kr)
+	r.GET("/set", func(c *Context) error {
+		cookie := &http.Cookie{Name: "session", Value: "user=42; role=admin", Path: "/"}
+		if encrypted {
+			return c.SetEncryptedCookie(cookie)
+		}
+		return c.SetSignedCookie(cookie)
+	})
+	r.GET("/get", func(c *Context) error {
+		var value string
+		var err error
+		if encrypted {
+			value, err = c.EncryptedCookie("session")
+		} else {
+			value, err = c.SignedCookie("session")
+		}
+		if err != nil {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusOK, map[string]string{"value": value})
+	})
+	return r
+}
+
+// roundTrip sets a cookie with setter and reads it back with getter.
+func roundTrip(t *testing.T, setter, getter *Router, tamper func(string) string) *httptest.ResponseRecorder {
+	t.Helper()
+
+	w := httptest.NewRecorder()
+	setter.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/set", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("expected 1 cookie, got %d", len(cookies))
+	}
+	if tamper != nil {
+		cookies[0].Value = tamper(cookies[0].Value)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/get", nil)
+	req.AddCookie(cookies[0])
+	w = httptest.NewRecorder()
+	getter.ServeHTTP(w, req)
+	return w
+}
+
+func TestNewKeyRing(t *testing.T) {
+	if _, err := NewKeyRing(); err == nil {
+		t.Error("NewKeyRing() with no secrets should fail")
+	}
+	if _, err := NewKeyRing([]byte("short")); err == nil {
+		t.Error("NewKeyRing() with a short secret should fail")
+	}
+	if _, err := NewKeyRing(testSecretA, testSecretB); err != nil {
+		t.Errorf("NewKeyRing() error = %v", err)
+	}
+}
+
+func TestSignedCookie(t *testing.T) {
+	kr, _ := NewKeyRing(testSecretA)
+	r := cookieRouter(t, kr, false)
+
+	w := roundTrip(t, r, r, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"value":"user=42; role=admin"`) {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = roundTrip(t, r, r, func(v string) string { return "dXNlcj0x" + v[strings.Index(v, "."):] })
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("tampered cookie status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestEncryptedCookie(t *testing.T) {
+	kr, _ := NewKeyRing(testSecretA)
+	r := cookieRouter(t, kr, true)
+
+	w := roundTrip(t, r, r, nil)
+	if w.Code != http.StatusOK || !strings.Contains(w.Body.String(), `"value":"user=42; role=admin"`) {
+		t.Errorf("status = %d, body = %s", w.Code, w.Body.String())
+	}
+
+	w = roundTrip(t, r, r, func(v string) string { return v[:len(v)-2] + "AA" })
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("tampered cookie status = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCookie_KeyRotation(t *testing.T) {
+	for _, encrypted := range []bool{false, true} {
+		oldRing, _ := NewKeyRing(testSecretA)
+		rotatedRing, _ := NewKeyRing(testSecretB, testSecretA)
+		newOnlyRing, _ := NewKeyRing(testSecretB)
+
+		oldRouter := cookieRouter(t, oldRing, encrypted)
+
+		if w := roundTrip(t, oldRouter, cookieRouter(t, rotatedRing, encrypted), nil); w.Code != http.StatusOK {
+			t.Errorf("encrypted=%v: cookie from old key rejected after rotation: %d", encrypted, w.Code)
+		}
+		if w := roundTrip(t, oldRouter, cookieRouter(t, newOnlyRing, encrypted), nil); w.Code != http.StatusUnauthorized {
+			t.Errorf("encrypted=%v: cookie from retired key accepted: %d", encrypted, w.Code)
+		}
+	}
+}
+
+func TestSignedCookie_Errors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	if _, err := c.SignedCookie("session"); !errors.Is(err, ErrNoKeyRing) {
+		t.Errorf("SignedCookie() without key ring error = %v, want ErrNoKeyRing", err)
+	}
+	if err := c.SetEncryptedCookie(&http.Cookie{Name: "x"}); !errors.Is(err, ErrNoKeyRing) {
+		t.Errorf("SetEncryptedCookie() without key ring error = %v, want ErrNoKeyRing", err)
+	}
+
+	kr, _ := NewKeyRing(testSecretA)
+	c.router = &Router{keyRing: kr}
+	if _, err := c.SignedCookie("session"); !errors.Is(err, http.ErrNoCookie) {
+		t.Errorf("SignedCookie() missing cookie error = %v, want http.ErrNoCookie", err)
+	}
+
+	req.AddCookie(&http.Cookie{Name: "session", Value: "no-signature"})
+	if _, err := c.SignedCookie("session"); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("SignedCookie() malformed error = %v, want ErrInvalidCookie", err)
+	}
+	if _, err := c.EncryptedCookie("session"); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("EncryptedCookie() malformed error = %v, want ErrInvalidCookie", err)
+	}
+}
+
+func TestKeyRing_EncryptDecrypt(t *testing.T) {
+	oldRing, _ := NewKeyRing(testSecretA)
+	rotatedRing, _ := NewKeyRing(testSecretB, testSecretA)
+
+	value, err := oldRing.Encrypt([]byte("secret"), []byte("purpose"))
+	if err != nil {
+		t.Fatalf("Encrypt() error = %v", err)
+	}
+	if got, err := rotatedRing.Decrypt(value, []byte("purpose")); err != nil || string(got) != "secret" {
+		t.Errorf("Decrypt() = %q, %v; want secret", got, err)
+	}
+	if _, err := rotatedRing.Decrypt(value, []byte("other")); !errors.Is(err, ErrInvalidCookie) {
+		t.Errorf("Decrypt() with other additional data error = %v, want ErrInvalidCookie", err)
+	}
+}
@@ -0,0 +1,149 @@
+package rig
+
+import (
+	"encoding/json"
+	"expvar"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// EnablePprof registers the net/http/pprof profiling endpoints under prefix
+// (e.g., "/debug/pprof"): the index page, cmdline, profile, symbol, trace,
+// and the named profiles (heap, goroutine, allocs, block, mutex,
+// threadcreate). They are served through rig routing, so mw (e.g., IPFilter
+// or authentication) guards them like any route group, and profiling can be
+// enabled per environment. Profiles expose internals and cost CPU; never
+// expose them publicly without a guard.
+//
+// Example:
+//
+//	if os.Getenv("ENABLE_PPROF") == "true" {
+//	    r.EnablePprof("/debug/pprof", rig.IPFilter(rig.IPFilterConfig{
+//	        Allow: []string{"10.0.0.0/8"},
+//	    }))
+//	}
+//
+//	// go tool pprof http://localhost:8080/debug/pprof/heap
+func (r *Router) EnablePprof(prefix string, mw ...MiddlewareFunc) {
+	validatePath(prefix)
+	g := r.Group(strings.TrimSuffix(prefix, "/"))
+	g.Use(mw...)
+
+	g.GET("/{$}", httpHandler(http.HandlerFunc(pprof.Index)))
+	g.GET("/cmdline", httpHandler(http.HandlerFunc(pprof.Cmdline)))
+	g.GET("/profile", httpHandler(http.HandlerFunc(pprof.Profile)))
+	g.GET("/symbol", httpHandler(http.HandlerFunc(pprof.Symbol)))
+	g.POST("/symbol", httpHandler(http.HandlerFunc(pprof.Symbol)))
+	g.GET("/trace", httpHandler(http.HandlerFunc(pprof.Trace)))
+
+	// pprof.Index only serves named profiles under "/debug/pprof/", so
+	// serve them by name to support any prefix
+	g.GET("/{profile}", func(c *Context) error {
+		pprof.Handler(c.Param("profile")).ServeHTTP(c.Writer(), c.Request())
+		return nil
+	})
+}
+
+// httpHandler adapts an http.Handler to a HandlerFunc.
+func httpHandler(h http.Handler) HandlerFunc {
+	return func(c *Context) error {
+		h.ServeHTTP(c.Writer(), c.Request())
+		return nil
+	}
+}
+
+// RuntimeStats is the "rig" section of the document served by
+// DebugVarsHandler.
+type RuntimeStats struct {
+	// Goroutines is the number of goroutines that currently exist.
+	Goroutines int `json:"goroutines"`
+
+	// OpenConnections is the number of client connections the server holds
+	// open, idle ones included. It is only tracked for servers started with
+	// the Run methods, or whose ConnState is set to Router.ConnState.
+	OpenConnections int64 `json:"open_connections"`
+
+	// Routes is the number of registered routes.
+	Routes int `json:"routes"`
+
+	// GOMAXPROCS and NumCPU describe the CPUs available to the process.
+	GOMAXPROCS int `json:"gomaxprocs"`
+	NumCPU     int `json:"num_cpu"`
+
+	// HeapAllocBytes, SysBytes, and NumGC summarize the full memory
+	// statistics, which are served as "memstats".
+	HeapAllocBytes uint64 `json:"heap_alloc_bytes"`
+	SysBytes       uint64 `json:"sys_bytes"`
+	NumGC          uint32 `json:"num_gc"`
+
+	// UptimeSeconds is the time since the process started.
+	UptimeSeconds int64 `json:"uptime_seconds"`
+}
+
+// ConnState tracks open connections for the "open_connections" statistic
+// of DebugVarsHandler. The Run methods install it; set it as the ConnState
+// of servers created by hand.
+//
+// Example:
+//
+//	srv := &http.Server{Addr: ":8080", Handler: r, ConnState: r.ConnState}
+func (r *Router) ConnState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		r.openConns.Add(1)
+	case http.StateClosed, http.StateHijacked:
+		r.openConns.Add(-1)
+	}
+}
+
+// DebugVarsHandler returns a handler that serves the variables published
+// with the expvar package (including "cmdline" and "memstats"), plus a
+// "rig" section of RuntimeStats (goroutines, open connections, route
+// count, ...), as a single JSON object, for quick production diagnostics.
+// Reading memory statistics briefly stops the world, so do not poll it at
+// high frequency.
+//
+// The output reveals internals, so mount it behind authentication and
+// never expose it publicly.
+//
+// Example:
+//
+//	admin := r.Group("/admin")
+//	admin.Use(auth.APIKeySimple(os.Getenv("ADMIN_KEY")))
+//	admin.GET("/debug/vars", r.DebugVarsHandler())
+//
+//	// {"cmdline":["./server"],"memstats":{...},"requests_total":1024,
+//	//  "rig":{"goroutines":42,"open_connections":7,"routes":18,...}}
+func (r *Router) DebugVarsHandler() HandlerFunc {
+	return func(c *Context) error {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+
+		vars := make(map[string]any)
+		expvar.Do(func(kv expvar.KeyValue) {
+			// Reuse the memory statistics read above instead of reading
+			// them (and stopping the world) again
+			if kv.Key == "memstats" {
+				return
+			}
+			vars[kv.Key] = json.RawMessage(kv.Value.String())
+		})
+		vars["memstats"] = &ms
+		vars["rig"] = RuntimeStats{
+			Goroutines:      runtime.NumGoroutine(),
+			OpenConnections: r.openConns.Load(),
+			Routes:          len(r.routes),
+			GOMAXPROCS:      runtime.GOMAXPROCS(0),
+			NumCPU:          runtime.NumCPU(),
+			HeapAllocBytes:  ms.HeapAlloc,
+			SysBytes:        ms.Sys,
+			NumGC:           ms.NumGC,
+			UptimeSeconds:   int64(time.Since(startTime).Seconds()),
+		}
+		return c.JSON(http.StatusOK, vars)
+	}
+}
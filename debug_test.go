@@ -0,0 +1,81 @@
+package rig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouter_EnablePprof(t *testing.T) {
+	r := New()
+	r.EnablePprof("/internal/pprof/", func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if c.GetHeader("X-Debug") != "yes" {
+				return NewHTTPError(http.StatusForbidden, "forbidden")
+			}
+			return next(c)
+		}
+	})
+
+	tests := []struct {
+		target string
+		code   int
+		want   string
+	}{
+		{"/internal/pprof/", http.StatusOK, "goroutine"},
+		{"/internal/pprof/goroutine?debug=1", http.StatusOK, "goroutine profile:"},
+		{"/internal/pprof/heap?debug=1", http.StatusOK, "heap profile:"},
+		{"/internal/pprof/cmdline", http.StatusOK, ""},
+		{"/internal/pprof/nonexistent", http.StatusNotFound, "Unknown profile"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Header.Set("X-Debug", "yes")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.code || !strings.Contains(w.Body.String(), tt.want) {
+			t.Errorf("%s: status = %d, body starts %.80q", tt.target, w.Code, w.Body.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/internal/pprof/heap", nil))
+	if w.Code != http.StatusForbidden {
+		t.Errorf("without the guard header: status = %d, want 403", w.Code)
+	}
+}
+
+func TestRouter_DebugVarsHandler(t *testing.T) {
+	r := New()
+	r.GET("/users", func(c *Context) error { return nil })
+	r.GET("/debug/vars", r.DebugVarsHandler())
+
+	srv := httptest.NewUnstartedServer(r)
+	srv.Config.ConnState = r.ConnState
+	srv.Start()
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/debug/vars")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var vars struct {
+		Cmdline  []string       `json:"cmdline"`
+		Memstats map[string]any `json:"memstats"`
+		Rig      RuntimeStats   `json:"rig"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&vars); err != nil {
+		t.Fatal(err)
+	}
+	if len(vars.Cmdline) == 0 || vars.Memstats["HeapAlloc"] == nil {
+		t.Errorf("expvar variables missing: cmdline = %v, memstats has %d fields", vars.Cmdline, len(vars.Memstats))
+	}
+	if vars.Rig.Routes != 2 || vars.Rig.OpenConnections != 1 || vars.Rig.Goroutines == 0 {
+		t.Errorf("rig = %+v, want 2 routes and 1 open connection", vars.Rig)
+	}
+}
@@ -0,0 +1,169 @@
+package rig
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// DecompressConfig defines the configuration for Decompress middleware.
+type DecompressConfig struct {
+	// MaxSize is the maximum size of a decompressed request body, in bytes.
+	// It protects against decompression bombs: small bodies that expand to
+	// gigabytes. Reading past it fails with an error that binding reports
+	// as ErrRequestEntityTooLarge (413).
+	// Default: 10 MB.
+	MaxSize int64
+
+	// Decoders adds content codings (e.g., br or zstd) by name. Each
+	// function wraps a compressed body in a decompressing reader.
+	// gzip, x-gzip, and deflate are always supported.
+	Decoders map[string]func(io.Reader) (io.Reader, error)
+
+	// Skipper selects requests whose bodies are passed on still compressed,
+	// for handlers that store or proxy them as they are.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// Decompress creates middleware that transparently decompresses request
+// bodies sent with Content-Encoding gzip or deflate, so Bind and friends
+// see the plain payload. Decompressed bodies are limited to 10 MB.
+//
+// Requests with an unsupported Content-Encoding are answered with 415
+// Unsupported Media Type, and bodies that are not valid for their encoding
+// with 400 Bad Request. Router.SetMaxBodyBytes still limits the compressed
+// body; c.MaxBodyBytes, called after Decompress, limits the decompressed
+// one.
+//
+// Example:
+//
+//	r.Use(rig.Decompress())
+func Decompress() MiddlewareFunc {
+	return DecompressWithConfig(DecompressConfig{})
+}
+
+// DecompressWithConfig creates decompression middleware with custom
+// configuration.
+//
+// Example:
+//
+//	r.Use(rig.DecompressWithConfig(rig.DecompressConfig{
+//	    MaxSize: 50 << 20,
+//	    Decoders: map[string]func(io.Reader) (io.Reader, error){
+//	        "br": func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+//	    },
+//	}))
+func DecompressWithConfig(config DecompressConfig) MiddlewareFunc {
+	if config.MaxSize <= 0 {
+		config.MaxSize = 10 << 20
+	}
+	decoders := map[string]func(io.Reader) (io.Reader, error){
+		"gzip":    newGzipReader,
+		"x-gzip":  newGzipReader,
+		"deflate": newDeflateReader,
+	}
+	for name, decoder := range config.Decoders {
+		decoders[strings.ToLower(name)] = decoder
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			encoding := strings.ToLower(strings.TrimSpace(req.Header.Get("Content-Encoding")))
+			if encoding == "" || encoding == "identity" || req.Body == nil || req.Body == http.NoBody {
+				return next(c)
+			}
+
+			decoder, ok := decoders[encoding]
+			if !ok {
+				return NewHTTPError(http.StatusUnsupportedMediaType, "unsupported Content-Encoding "+encoding)
+			}
+			decoded, err := decoder(req.Body)
+			if err != nil {
+				return NewHTTPError(http.StatusBadRequest, "invalid "+encoding+" request body")
+			}
+
+			body := &decompressedBody{
+				Reader: decoded,
+				body:   req.Body,
+				limit:  config.MaxSize,
+				remain: config.MaxSize,
+			}
+			req.Body = body
+			if c.originalBody != nil {
+				c.originalBody = body
+			}
+			req.Header.Del("Content-Encoding")
+			req.Header.Del("Content-Length")
+			req.ContentLength = -1
+			return next(c)
+		}
+	}
+}
+
+// newGzipReader returns a reader decompressing gzip data from r.
+func newGzipReader(r io.Reader) (io.Reader, error) {
+	return gzip.NewReader(r)
+}
+
+// newDeflateReader returns a reader decompressing deflate data from r.
+// HTTP's deflate is zlib-wrapped, but some clients send raw deflate
+// streams; both are accepted.
+func newDeflateReader(r io.Reader) (io.Reader, error) {
+	br := bufio.NewReader(r)
+	header, err := br.Peek(2)
+	if err != nil {
+		return nil, err
+	}
+	// A zlib header uses compression method 8 and is a multiple of 31
+	if header[0]&0x0f == 8 && (uint16(header[0])<<8|uint16(header[1]))%31 == 0 {
+		return zlib.NewReader(br)
+	}
+	return flate.NewReader(br), nil
+}
+
+// decompressedBody is a decompressing request body limited to a maximum
+// decompressed size.
+type decompressedBody struct {
+	io.Reader
+	body   io.ReadCloser // the compressed body
+	limit  int64
+	remain int64
+}
+
+// Read reads decompressed data, failing with *http.MaxBytesError once more
+// than limit bytes have been read.
+func (b *decompressedBody) Read(p []byte) (int, error) {
+	if b.remain <= 0 {
+		// Probe whether the stream really continues past the limit
+		var probe [1]byte
+		if n, err := b.Reader.Read(probe[:]); n == 0 {
+			return 0, err
+		}
+		return 0, &http.MaxBytesError{Limit: b.limit}
+	}
+	if int64(len(p)) > b.remain {
+		p = p[:b.remain]
+	}
+	n, err := b.Reader.Read(p)
+	b.remain -= int64(n)
+	return n, err
+}
+
+// Close closes the decompressor, if it needs closing, and the compressed
+// body.
+func (b *decompressedBody) Close() error {
+	if closer, ok := b.Reader.(io.Closer); ok {
+		_ = closer.Close()
+	}
+	return b.body.Close()
+}
@@ -0,0 +1,154 @@
+package rig
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func compressBody(t *testing.T, encoding string, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.WriteCloser
+	switch encoding {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	case "flate":
+		w, _ = flate.NewWriter(&buf, flate.DefaultCompression)
+	}
+	if _, err := w.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDecompress(t *testing.T) {
+	payload := []byte(`{"name":"widget"}`)
+	tests := []struct {
+		name     string
+		encoding string
+		body     []byte
+		code     int
+		want     string
+	}{
+		{"gzip", "gzip", compressBody(t, "gzip", payload), http.StatusOK, "widget"},
+		{"x-gzip", "X-Gzip", compressBody(t, "gzip", payload), http.StatusOK, "widget"},
+		{"deflate zlib", "deflate", compressBody(t, "zlib", payload), http.StatusOK, "widget"},
+		{"deflate raw", "deflate", compressBody(t, "flate", payload), http.StatusOK, "widget"},
+		{"identity", "identity", payload, http.StatusOK, "widget"},
+		{"none", "", payload, http.StatusOK, "widget"},
+		{"unsupported", "br", payload, http.StatusUnsupportedMediaType, ""},
+		{"stacked", "gzip, deflate", payload, http.StatusUnsupportedMediaType, ""},
+		{"invalid gzip", "gzip", payload, http.StatusBadRequest, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Use(Decompress())
+			r.POST("/items", func(c *Context) error {
+				if tt.encoding != "identity" && c.GetHeader("Content-Encoding") != "" {
+					t.Error("Content-Encoding was not removed")
+				}
+				var item struct{ Name string }
+				if err := c.Bind(&item); err != nil {
+					return err
+				}
+				return c.String(http.StatusOK, "%s", item.Name)
+			})
+
+			req := httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.encoding != "" {
+				req.Header.Set("Content-Encoding", tt.encoding)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Fatalf("status = %d, want %d (body %q)", w.Code, tt.code, w.Body.String())
+			}
+			if tt.want != "" && w.Body.String() != tt.want {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecompress_MaxSize(t *testing.T) {
+	r := New()
+	r.Use(DecompressWithConfig(DecompressConfig{MaxSize: 1 << 10}))
+	r.POST("/items", func(c *Context) error {
+		var v map[string]string
+		if err := c.Bind(&v); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "ok")
+	})
+
+	// A small gzip body that expands far past the limit
+	bomb := compressBody(t, "gzip", []byte(`{"a":"`+strings.Repeat("a", 1<<20)+`"}`))
+	req := httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(bomb))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("status = %d, want 413", w.Code)
+	}
+
+	// A body of exactly MaxSize bytes is allowed
+	exact := `{"a":"` + strings.Repeat("a", 1<<10-8) + `"}`
+	req = httptest.NewRequest(http.MethodPost, "/items", bytes.NewReader(compressBody(t, "gzip", []byte(exact))))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Content-Encoding", "gzip")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("exact limit: status = %d, want 200", w.Code)
+	}
+}
+
+func TestDecompress_CustomDecoderAndBodyLimit(t *testing.T) {
+	r := New()
+	r.Use(DecompressWithConfig(DecompressConfig{
+		Decoders: map[string]func(io.Reader) (io.Reader, error){
+			"upper": func(r io.Reader) (io.Reader, error) {
+				data, err := io.ReadAll(r)
+				return strings.NewReader(strings.ToLower(string(data))), err
+			},
+		},
+	}))
+	r.POST("/echo", func(c *Context) error {
+		c.MaxBodyBytes(4)
+		data, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return bodyError(err)
+		}
+		return c.String(http.StatusOK, "%s", data)
+	})
+
+	for body, code := range map[string]int{"ABCD": http.StatusOK, "ABCDE": http.StatusRequestEntityTooLarge} {
+		req := httptest.NewRequest(http.MethodPost, "/echo", strings.NewReader(body))
+		req.Header.Set("Content-Encoding", "UPPER")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != code {
+			t.Errorf("%s: status = %d, want %d", body, w.Code, code)
+		}
+		if code == http.StatusOK && w.Body.String() != "abcd" {
+			t.Errorf("%s: body = %q", body, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package rig
+
+import (
+	"bufio"
+	"net"
+	"net/http"
+)
+
+// DefaultHeadersConfig defines the configuration for DefaultHeaders
+// middleware.
+type DefaultHeadersConfig struct {
+	// Set maps header names to values set on every response before the
+	// handler runs, so handlers can still override them (e.g., "Server",
+	// "Cache-Control").
+	Set map[string]string
+
+	// Remove lists headers deleted from every response right before it is
+	// sent, whoever set them (e.g., "X-Powered-By" from a proxied
+	// upstream). Removal wins over Set.
+	Remove []string
+
+	// Skipper selects requests whose responses are left untouched.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// DefaultHeaders creates middleware that sets headers on every response,
+// unless the handler sets them itself. Register it with the router's Use to
+// configure them once instead of in every handler.
+//
+// Example:
+//
+//	r.Use(rig.DefaultHeaders(map[string]string{
+//	    "Server":        "orders-api",
+//	    "Cache-Control": "no-store",
+//	}))
+func DefaultHeaders(headers map[string]string) MiddlewareFunc {
+	return DefaultHeadersWithConfig(DefaultHeadersConfig{Set: headers})
+}
+
+// DefaultHeadersWithConfig creates default headers middleware with custom
+// configuration, which can also remove headers from responses.
+//
+// Example:
+//
+//	r.Use(rig.DefaultHeadersWithConfig(rig.DefaultHeadersConfig{
+//	    Set:    map[string]string{"Server": "orders-api"},
+//	    Remove: []string{"X-Powered-By", "X-AspNet-Version"},
+//	}))
+func DefaultHeadersWithConfig(config DefaultHeadersConfig) MiddlewareFunc {
+	set := make(http.Header, len(config.Set))
+	for name, value := range config.Set {
+		set.Set(name, value)
+	}
+	remove := make([]string, len(config.Remove))
+	for i, name := range config.Remove {
+		remove[i] = http.CanonicalHeaderKey(name)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			h := c.Header()
+			for name, values := range set {
+				h[name] = append([]string(nil), values...)
+			}
+			if len(remove) > 0 && !c.Written() {
+				// Left installed, so responses written by the error handler
+				// are stripped too
+				c.writer.ResponseWriter = &stripHeaderWriter{
+					ResponseWriter: c.writer.ResponseWriter,
+					remove:         remove,
+				}
+			}
+			return next(c)
+		}
+	}
+}
+
+// stripHeaderWriter deletes headers when the response headers are sent.
+type stripHeaderWriter struct {
+	http.ResponseWriter
+	remove []string
+}
+
+// strip deletes the headers. Deleting them after the headers were sent has
+// no effect.
+func (w *stripHeaderWriter) strip() {
+	h := w.ResponseWriter.Header()
+	for _, name := range w.remove {
+		delete(h, name)
+	}
+}
+
+// WriteHeader strips the headers and writes the status.
+func (w *stripHeaderWriter) WriteHeader(code int) {
+	w.strip()
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write strips the headers and writes b.
+func (w *stripHeaderWriter) Write(b []byte) (int, error) {
+	w.strip()
+	return w.ResponseWriter.Write(b)
+}
+
+// Flush implements http.Flusher.
+func (w *stripHeaderWriter) Flush() {
+	w.strip()
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *stripHeaderWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *stripHeaderWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
@@ -0,0 +1,46 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestDefaultHeaders(t *testing.T) {
+	r := New()
+	r.Use(DefaultHeadersWithConfig(DefaultHeadersConfig{
+		Set:    map[string]string{"server": "orders-api", "Cache-Control": "no-store"},
+		Remove: []string{"x-powered-by"},
+	}))
+	r.GET("/orders", func(c *Context) error {
+		c.SetHeader("X-Powered-By", "PHP/5.6")
+		c.SetHeader("Cache-Control", "max-age=60")
+		return c.JSON(http.StatusOK, []string{})
+	})
+	r.GET("/fail", func(c *Context) error {
+		c.SetHeader("X-Powered-By", "PHP/5.6")
+		return errors.New("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders", nil))
+	if got := w.Header().Get("Server"); got != "orders-api" {
+		t.Errorf("Server = %q, want orders-api", got)
+	}
+	if got := w.Header().Get("Cache-Control"); got != "max-age=60" {
+		t.Errorf("Cache-Control = %q, want the handler's value", got)
+	}
+	if _, ok := w.Header()["X-Powered-By"]; ok {
+		t.Error("X-Powered-By should be removed")
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("Server") != "orders-api" {
+		t.Errorf("error response: status = %d, Server = %q", w.Code, w.Header().Get("Server"))
+	}
+	if _, ok := w.Header()["X-Powered-By"]; ok {
+		t.Error("X-Powered-By should be removed from error responses")
+	}
+}
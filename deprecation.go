@@ -0,0 +1,156 @@
+package rig
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DeprecationConfig defines the configuration for the Deprecated middleware.
+type DeprecationConfig struct {
+	// Date is when the route was (or will be) deprecated.
+	// It is sent as the Deprecation header in RFC 9745 format (e.g., "@1688169599").
+	// If zero, the header value is "true".
+	Date time.Time
+
+	// Sunset is when the route is expected to stop responding.
+	// It is sent as the Sunset header (RFC 8594) in HTTP-date format.
+	// If zero, no Sunset header is set.
+	Sunset time.Time
+
+	// Link is the URL of the replacement route or migration documentation.
+	// It is sent as a Link header with rel="successor-version".
+	// If empty, no Link header is set.
+	Link string
+
+	// Tracker records usage of the deprecated route.
+	// Share a single tracker across routes to build one report.
+	// If nil, usage is not recorded.
+	Tracker *DeprecationTracker
+}
+
+// Deprecated creates middleware that marks routes as deprecated.
+// It sets the Deprecation, Sunset, and Link headers on every response so
+// clients can discover the deprecation and migrate before the sunset date.
+//
+// Apply it to a group, or wrap individual handlers:
+//
+//	tracker := rig.NewDeprecationTracker()
+//	deprecated := rig.Deprecated(rig.DeprecationConfig{
+//	    Sunset:  time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC),
+//	    Link:    "https://api.example.com/v2/users",
+//	    Tracker: tracker,
+//	})
+//
+//	r.GET("/v1/users", deprecated(listUsersV1))
+//	r.GET("/admin/deprecations", tracker.Handler())
+func Deprecated(config DeprecationConfig) MiddlewareFunc {
+	// Pre-compute header values at middleware creation time
+	deprecation := "true"
+	if !config.Date.IsZero() {
+		deprecation = "@" + strconv.FormatInt(config.Date.Unix(), 10)
+	}
+
+	var sunset string
+	if !config.Sunset.IsZero() {
+		sunset = config.Sunset.UTC().Format(http.TimeFormat)
+	}
+
+	var link string
+	if config.Link != "" {
+		link = "<" + config.Link + `>; rel="successor-version"`
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("Deprecation", deprecation)
+			if sunset != "" {
+				c.SetHeader("Sunset", sunset)
+			}
+			if link != "" {
+				c.Header().Add("Link", link)
+			}
+
+			if config.Tracker != nil {
+				config.Tracker.record(routeKey(c), config)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// routeKey returns the matched route pattern, falling back to the request path.
+func routeKey(c *Context) string {
+	if pattern := c.Request().Pattern; pattern != "" {
+		return pattern
+	}
+	return c.Method() + " " + c.Path()
+}
+
+// DeprecationReport describes the usage of a single deprecated route.
+type DeprecationReport struct {
+	Route    string     `json:"route"`
+	Count    int64      `json:"count"`
+	LastSeen time.Time  `json:"last_seen"`
+	Sunset   *time.Time `json:"sunset,omitempty"`
+	Link     string     `json:"link,omitempty"`
+}
+
+// DeprecationTracker records how often deprecated routes are still being called.
+// It is safe for concurrent use.
+type DeprecationTracker struct {
+	mu     sync.Mutex
+	routes map[string]*DeprecationReport
+}
+
+// NewDeprecationTracker creates a new, empty DeprecationTracker.
+func NewDeprecationTracker() *DeprecationTracker {
+	return &DeprecationTracker{
+		routes: make(map[string]*DeprecationReport),
+	}
+}
+
+// record increments the usage count for the given route.
+func (t *DeprecationTracker) record(route string, config DeprecationConfig) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	entry, ok := t.routes[route]
+	if !ok {
+		entry = &DeprecationReport{Route: route, Link: config.Link}
+		if !config.Sunset.IsZero() {
+			sunset := config.Sunset
+			entry.Sunset = &sunset
+		}
+		t.routes[route] = entry
+	}
+	entry.Count++
+	entry.LastSeen = time.Now()
+}
+
+// Report returns a snapshot of deprecated-route usage, sorted by route.
+func (t *DeprecationTracker) Report() []DeprecationReport {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	report := make([]DeprecationReport, 0, len(t.routes))
+	for _, entry := range t.routes {
+		report = append(report, *entry)
+	}
+	sort.Slice(report, func(i, j int) bool {
+		return report[i].Route < report[j].Route
+	})
+	return report
+}
+
+// Handler returns a Rig HandlerFunc that serves the usage report as JSON.
+func (t *DeprecationTracker) Handler() HandlerFunc {
+	return func(c *Context) error {
+		return c.JSON(http.StatusOK, map[string]any{
+			"deprecations": t.Report(),
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package rig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDeprecated_Headers(t *testing.T) {
+	date := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	sunset := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New()
+	r.GET("/v1/users", Deprecated(DeprecationConfig{
+		Date:   date,
+		Sunset: sunset,
+		Link:   "https://api.example.com/v2/users",
+	})(func(c *Context) error {
+		return c.JSON(http.StatusOK, nil)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Deprecation"); got != "@1704067200" {
+		t.Errorf("Deprecation = %q, want %q", got, "@1704067200")
+	}
+	if got := w.Header().Get("Sunset"); got != "Sun, 01 Jun 2025 00:00:00 GMT" {
+		t.Errorf("Sunset = %q", got)
+	}
+	if got := w.Header().Get("Link"); got != `<https://api.example.com/v2/users>; rel="successor-version"` {
+		t.Errorf("Link = %q", got)
+	}
+}
+
+func TestDeprecated_MinimalConfig(t *testing.T) {
+	r := New()
+	r.GET("/old", Deprecated(DeprecationConfig{})(func(c *Context) error {
+		return nil
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/old", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := w.Header().Get("Deprecation"); got != "true" {
+		t.Errorf("Deprecation = %q, want %q", got, "true")
+	}
+	if w.Header().Get("Sunset") != "" {
+		t.Error("Sunset header should not be set")
+	}
+	if w.Header().Get("Link") != "" {
+		t.Error("Link header should not be set")
+	}
+}
+
+func TestDeprecationTracker_Report(t *testing.T) {
+	tracker := NewDeprecationTracker()
+	sunset := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	r := New()
+	v1 := r.Group("/v1")
+	v1.Use(Deprecated(DeprecationConfig{Sunset: sunset, Tracker: tracker}))
+	v1.GET("/users/{id}", func(c *Context) error { return nil })
+	v1.GET("/orders", func(c *Context) error { return nil })
+	r.GET("/admin/deprecations", tracker.Handler())
+
+	for _, path := range []string{"/v1/users/1", "/v1/users/2", "/v1/orders"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	report := tracker.Report()
+	if len(report) != 2 {
+		t.Fatalf("len(report) = %d, want 2", len(report))
+	}
+	if report[0].Route != "GET /v1/orders" || report[0].Count != 1 {
+		t.Errorf("report[0] = %+v", report[0])
+	}
+	if report[1].Route != "GET /v1/users/{id}" || report[1].Count != 2 {
+		t.Errorf("report[1] = %+v", report[1])
+	}
+	if report[1].Sunset == nil || !report[1].Sunset.Equal(sunset) {
+		t.Errorf("report[1].Sunset = %v, want %v", report[1].Sunset, sunset)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/deprecations", nil))
+
+	var body struct {
+		Deprecations []DeprecationReport `json:"deprecations"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	if len(body.Deprecations) != 2 {
+		t.Errorf("len(deprecations) = %d, want 2", len(body.Deprecations))
+	}
+}
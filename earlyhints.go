@@ -0,0 +1,138 @@
+package rig
+
+import (
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+)
+
+// preloadAs maps file extensions to the "as" attribute of preload links.
+var preloadAs = map[string]string{
+	".css":   "style",
+	".js":    "script",
+	".mjs":   "script",
+	".woff":  "font",
+	".woff2": "font",
+	".ttf":   "font",
+	".otf":   "font",
+	".png":   "image",
+	".jpg":   "image",
+	".jpeg":  "image",
+	".gif":   "image",
+	".svg":   "image",
+	".webp":  "image",
+	".avif":  "image",
+	".json":  "fetch",
+}
+
+// PreloadAs returns the "as" attribute of a preload link for the file
+// name, derived from its extension (e.g., "style" for ".css", "font" for
+// ".woff2"), or "" for unknown types. Context.EarlyHints and the preload
+// template functions of the render package use it, so Link headers and
+// link tags agree.
+func PreloadAs(name string) string {
+	return preloadAs[strings.ToLower(path.Ext(name))]
+}
+
+// EarlyHints sends a 103 Early Hints response with a Link header for each
+// link, so browsers can start fetching critical assets while the handler is
+// still preparing the page. Call it before doing slow work such as database
+// queries. The Link headers stay set and are sent again with the final
+// response.
+//
+// A link is either a complete Link header value (starting with "<"), an
+// origin such as "https://cdn.example.com" (sent as rel=preconnect), or a
+// URL to preload, whose "as" type is derived from its extension (fonts are
+// requested with crossorigin, as browsers require). EarlyHints does nothing
+// once the response has been written, or for HTTP/1.0 clients, which do not
+// understand informational responses.
+//
+// Example:
+//
+//	r.GET("/", func(c *rig.Context) error {
+//	    c.EarlyHints("/assets/app.css", "/assets/app.js", "https://fonts.example.com")
+//	    products, err := db.ListProducts(c.Context()) // Browser fetches assets meanwhile
+//	    // ...
+//	})
+func (c *Context) EarlyHints(links ...string) {
+	if len(links) == 0 || c.Written() || !c.request.ProtoAtLeast(1, 1) {
+		return
+	}
+	h := c.Header()
+	for _, link := range links {
+		h.Add("Link", linkValue(link))
+	}
+	c.writer.WriteHeader(http.StatusEarlyHints)
+}
+
+// linkValue converts a link passed to EarlyHints into a Link header value.
+func linkValue(link string) string {
+	if strings.HasPrefix(link, "<") {
+		return link
+	}
+	if u, err := url.Parse(link); err == nil && u.Host != "" && strings.Trim(u.Path, "/") == "" && u.RawQuery == "" {
+		return "<" + strings.TrimSuffix(link, "/") + ">; rel=preconnect"
+	}
+
+	value := "<" + link + ">; rel=preload"
+	p := link
+	if u, err := url.Parse(link); err == nil {
+		p = u.Path
+	}
+	if as := PreloadAs(p); as != "" {
+		value += "; as=" + as
+		if as == "font" {
+			value += "; crossorigin"
+		}
+	}
+	return value
+}
+
+// EarlyHintsConfig defines the configuration for EarlyHints middleware.
+type EarlyHintsConfig struct {
+	// Links are hinted for every GET request the middleware handles, in the
+	// forms accepted by Context.EarlyHints.
+	Links []string
+
+	// Routes maps route patterns, as registered (e.g., "GET /products/{id}"
+	// or "/docs/"), to links hinted for requests they match, after Links.
+	Routes map[string][]string
+
+	// Skipper selects requests that get no early hints.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// EarlyHints creates middleware that sends a 103 Early Hints response with
+// preload Link headers before running the handler, for GET requests. It
+// suits server-rendered pages whose stylesheets, scripts, and fonts are
+// known in advance; call Context.EarlyHints from a handler for links that
+// depend on the request.
+//
+// Example:
+//
+//	r.Use(rig.EarlyHints(rig.EarlyHintsConfig{
+//	    Links: []string{"/assets/app.css", "/assets/inter.woff2"},
+//	    Routes: map[string][]string{
+//	        "GET /dashboard": {"/assets/charts.js"},
+//	    },
+//	}))
+func EarlyHints(config EarlyHintsConfig) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			if c.Method() == http.MethodGet {
+				links := config.Links
+				if routeLinks, ok := config.Routes[c.Request().Pattern]; ok {
+					links = append(links[:len(links):len(links)], routeLinks...)
+				}
+				c.EarlyHints(links...)
+			}
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/http/httptrace"
+	"net/textproto"
+	"reflect"
+	"slices"
+	"testing"
+)
+
+// hintsRecorder records the Link headers sent with each 1xx response.
+type hintsRecorder struct {
+	*httptest.ResponseRecorder
+	hints [][]string
+}
+
+func (w *hintsRecorder) WriteHeader(code int) {
+	if code == http.StatusEarlyHints {
+		w.hints = append(w.hints, slices.Clone(w.Header().Values("Link")))
+		return
+	}
+	w.ResponseRecorder.WriteHeader(code)
+}
+
+func TestLinkValue(t *testing.T) {
+	tests := []struct {
+		link string
+		want string
+	}{
+		{"/assets/app.css", "</assets/app.css>; rel=preload; as=style"},
+		{"/assets/app.js?v=3", "</assets/app.js?v=3>; rel=preload; as=script"},
+		{"/fonts/inter.woff2", "</fonts/inter.woff2>; rel=preload; as=font; crossorigin"},
+		{"https://cdn.example.com/img/hero.webp", "<https://cdn.example.com/img/hero.webp>; rel=preload; as=image"},
+		{"https://fonts.example.com/", "<https://fonts.example.com>; rel=preconnect"},
+		{"/api/bootstrap", "</api/bootstrap>; rel=preload"},
+		{"</x.css>; rel=preload; as=style; nopush", "</x.css>; rel=preload; as=style; nopush"},
+	}
+	for _, tt := range tests {
+		if got := linkValue(tt.link); got != tt.want {
+			t.Errorf("linkValue(%q) = %q, want %q", tt.link, got, tt.want)
+		}
+	}
+}
+
+func TestPreloadAs(t *testing.T) {
+	for name, want := range map[string]string{
+		"css/app.css":       "style",
+		"fonts/Inter.WOFF2": "font",
+		"data/menu.json":    "fetch",
+		"docs/guide.pdf":    "",
+	} {
+		if got := PreloadAs(name); got != want {
+			t.Errorf("PreloadAs(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestContext_EarlyHints(t *testing.T) {
+	w := &hintsRecorder{ResponseRecorder: httptest.NewRecorder()}
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	c.EarlyHints("/app.css", "https://cdn.example.com")
+	if c.Written() {
+		t.Error("EarlyHints marked the response as written")
+	}
+	_ = c.String(http.StatusOK, "page")
+	c.EarlyHints("/late.js")
+
+	want := [][]string{{"</app.css>; rel=preload; as=style", "<https://cdn.example.com>; rel=preconnect"}}
+	if !reflect.DeepEqual(w.hints, want) {
+		t.Errorf("hints = %q, want %q", w.hints, want)
+	}
+	if w.Code != http.StatusOK || len(w.Header().Values("Link")) != 2 {
+		t.Errorf("final response: status = %d, Link = %q", w.Code, w.Header().Values("Link"))
+	}
+
+	old := &hintsRecorder{ResponseRecorder: httptest.NewRecorder()}
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	newContext(old, req).EarlyHints("/app.css")
+	if len(old.hints) != 0 {
+		t.Errorf("HTTP/1.0 hints = %q, want none", old.hints)
+	}
+}
+
+func TestEarlyHints(t *testing.T) {
+	r := New()
+	r.Use(EarlyHints(EarlyHintsConfig{
+		Links:  []string{"/app.css"},
+		Routes: map[string][]string{"GET /dashboard": {"/charts.js"}},
+	}))
+	ok := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+	r.GET("/", ok)
+	r.GET("/dashboard", ok)
+	r.POST("/dashboard", ok)
+
+	tests := []struct {
+		method, target string
+		want           [][]string
+	}{
+		{http.MethodGet, "/", [][]string{{"</app.css>; rel=preload; as=style"}}},
+		{http.MethodGet, "/dashboard", [][]string{{"</app.css>; rel=preload; as=style", "</charts.js>; rel=preload; as=script"}}},
+		{http.MethodPost, "/dashboard", nil},
+	}
+	for _, tt := range tests {
+		w := &hintsRecorder{ResponseRecorder: httptest.NewRecorder()}
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+		if !reflect.DeepEqual(w.hints, tt.want) {
+			t.Errorf("%s %s: hints = %q, want %q", tt.method, tt.target, w.hints, tt.want)
+		}
+	}
+}
+
+func TestEarlyHints_Server(t *testing.T) {
+	r := New()
+	r.Use(EarlyHints(EarlyHintsConfig{Links: []string{"/app.css"}}))
+	r.GET("/", func(c *Context) error { return c.String(http.StatusOK, "page") })
+	srv := httptest.NewServer(r)
+	defer srv.Close()
+
+	var got []string
+	trace := &httptrace.ClientTrace{
+		Got1xxResponse: func(code int, header textproto.MIMEHeader) error {
+			if code == http.StatusEarlyHints {
+				got = append(got, header.Values("Link")...)
+			}
+			return nil
+		},
+	}
+	req, _ := http.NewRequestWithContext(httptrace.WithClientTrace(t.Context(), trace), http.MethodGet, srv.URL, nil)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK || !slices.Equal(got, []string{"</app.css>; rel=preload; as=style"}) {
+		t.Errorf("status = %d, 103 Link = %q", resp.StatusCode, got)
+	}
+}
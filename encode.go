@@ -0,0 +1,217 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+// ErrNotAcceptable is returned by Render when no registered encoder produces
+// a media type the client accepts (406).
+var ErrNotAcceptable = NewHTTPError(http.StatusNotAcceptable, "")
+
+// EncoderFunc encodes v to w in a specific media type.
+// Register one with RegisterEncoder or Router.RegisterEncoder to let Render
+// produce that type.
+type EncoderFunc func(w io.Writer, v any) error
+
+var (
+	encodersMu     sync.RWMutex
+	globalEncoders []encoder
+)
+
+// encoder is a media type Render can produce.
+type encoder struct {
+	mediaType   string
+	contentType string
+	encode      EncoderFunc
+}
+
+// builtinEncoders are the formats Render supports without registration,
+// in order of preference when the client accepts any type.
+var builtinEncoders = []encoder{
+	{"application/json", "application/json; charset=utf-8", func(w io.Writer, v any) error {
+		return json.NewEncoder(w).Encode(v)
+	}},
+	{"application/xml", "application/xml; charset=utf-8", func(w io.Writer, v any) error {
+		if _, err := io.WriteString(w, xml.Header); err != nil {
+			return err
+		}
+		return xml.NewEncoder(w).Encode(v)
+	}},
+	{ContentTypeYAML, ContentTypeYAML, func(w io.Writer, v any) error {
+		data, err := MarshalYAML(v)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(data)
+		return err
+	}},
+}
+
+// RegisterEncoder registers an EncoderFunc for the given media type (e.g.,
+// "application/cbor") for all routers. Render, RenderAs, and the render
+// package's Auto helpers can then produce that type. Encoders registered on a
+// router with Router.RegisterEncoder take precedence. Registering a built-in
+// media type (application/json, application/xml, application/yaml) replaces
+// it. It is safe for concurrent use, but is typically called during startup.
+//
+// Example:
+//
+//	rig.RegisterEncoder("application/cbor", func(w io.Writer, v any) error {
+//	    return cbor.NewEncoder(w).Encode(v)
+//	})
+func RegisterEncoder(mediaType string, enc EncoderFunc) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+	globalEncoders = addEncoder(globalEncoders, mediaType, enc)
+}
+
+// RegisterEncoder registers an EncoderFunc used by Render for the given media
+// type (e.g., "application/msgpack"). Registering a built-in media type
+// (application/json, application/xml, application/yaml) replaces it.
+//
+// Example:
+//
+//	r.RegisterEncoder("application/msgpack", func(w io.Writer, v any) error {
+//	    return msgpack.NewEncoder(w).Encode(v)
+//	})
+func (r *Router) RegisterEncoder(mediaType string, enc EncoderFunc) {
+	r.encoders = addEncoder(r.encoders, mediaType, enc)
+}
+
+// addEncoder adds enc to encoders, replacing an encoder for the same media
+// type in place.
+func addEncoder(encoders []encoder, mediaType string, enc EncoderFunc) []encoder {
+	mediaType = strings.ToLower(mediaType)
+	for i := range encoders {
+		if encoders[i].mediaType == mediaType {
+			encoders[i].encode = enc
+			return encoders
+		}
+	}
+	return append(encoders, encoder{mediaType, mediaType, enc})
+}
+
+// encoders returns the encoders available to Render in order of preference:
+// the built-in formats (or their replacements), then registered formats in
+// registration order, globally registered formats first.
+func (c *Context) encoders() []encoder {
+	encodersMu.RLock()
+	registered := append([]encoder(nil), globalEncoders...)
+	encodersMu.RUnlock()
+	if c.router != nil {
+		for _, r := range c.router.encoders {
+			registered = addEncoder(registered, r.mediaType, r.encode)
+		}
+	}
+
+	result := make([]encoder, 0, len(builtinEncoders)+len(registered))
+	for _, b := range builtinEncoders {
+		for _, r := range registered {
+			if r.mediaType == b.mediaType {
+				b.encode = r.encode
+			}
+		}
+		result = append(result, b)
+	}
+	for _, r := range registered {
+		if !isBuiltinMediaType(r.mediaType) {
+			result = append(result, r)
+		}
+	}
+	return result
+}
+
+// isBuiltinMediaType reports whether mediaType has a built-in encoder.
+func isBuiltinMediaType(mediaType string) bool {
+	for _, b := range builtinEncoders {
+		if b.mediaType == mediaType {
+			return true
+		}
+	}
+	return false
+}
+
+// Render writes v with the given status code, encoded in the media type the
+// Accept header prefers (see Negotiate). JSON, XML, and YAML are built in;
+// other formats (MessagePack, CBOR, Protobuf, ...) are added with
+// RegisterEncoder or Router.RegisterEncoder. Requests without an Accept
+// header, or accepting any type, get JSON.
+//
+// If no encoder matches, Render returns ErrNotAcceptable (406) without
+// writing a response.
+//
+// Example:
+//
+//	r.GET("/users/{id}", func(c *rig.Context) error {
+//	    user, err := store.Get(c.Param("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    return c.Render(http.StatusOK, user)
+//	})
+func (c *Context) Render(code int, v any) error {
+	enc, ok := selectEncoder(c.GetHeader("Accept"), c.encoders())
+	if !ok {
+		return ErrNotAcceptable
+	}
+	return c.writeEncoded(code, enc, v)
+}
+
+// RenderAs writes v with the given status code, encoded in mediaType with
+// the built-in or registered encoder for it, regardless of the Accept header.
+// It returns ErrNotAcceptable if no encoder produces mediaType. Use it after
+// negotiating the response type yourself (see RenderTypes).
+func (c *Context) RenderAs(code int, mediaType string, v any) error {
+	mediaType = strings.ToLower(mediaType)
+	for _, enc := range c.encoders() {
+		if enc.mediaType == mediaType {
+			return c.writeEncoded(code, enc, v)
+		}
+	}
+	return ErrNotAcceptable
+}
+
+// RenderTypes returns the media types Render can produce for this request,
+// in order of preference: the built-in formats, then registered formats.
+func (c *Context) RenderTypes() []string {
+	encoders := c.encoders()
+	types := make([]string, len(encoders))
+	for i, enc := range encoders {
+		types[i] = enc.mediaType
+	}
+	return types
+}
+
+// writeEncoded writes v encoded with enc.
+func (c *Context) writeEncoded(code int, enc encoder, v any) error {
+	// Encode to a buffer first so encoding errors can still be reported
+	var buf bytes.Buffer
+	if err := enc.encode(&buf, v); err != nil {
+		return err
+	}
+
+	c.writer.Header().Add("Vary", "Accept")
+	c.writer.Header().Set("Content-Type", enc.contentType)
+	c.Status(code)
+	_, err := c.writer.Write(buf.Bytes())
+	return err
+}
+
+// selectEncoder returns the encoder the Accept header prefers (see
+// Negotiate). Encoders earlier in the list win ties.
+func selectEncoder(accept string, encoders []encoder) (encoder, bool) {
+	offers := make([]string, len(encoders))
+	for i, enc := range encoders {
+		offers[i] = enc.mediaType
+	}
+	if i := negotiate(accept, offers); i >= 0 {
+		return encoders[i], true
+	}
+	return encoder{}, false
+}
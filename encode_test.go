@@ -0,0 +1,194 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type renderItem struct {
+	Name string `json:"name" xml:"name"`
+}
+
+func renderRouter() *Router {
+	r := New()
+	r.RegisterEncoder("application/x-csv", func(w io.Writer, v any) error {
+		item := v.(renderItem)
+		_, err := fmt.Fprintf(w, "name\n%s\n", item.Name)
+		return err
+	})
+	r.GET("/item", func(c *Context) error {
+		return c.Render(http.StatusOK, renderItem{Name: "widget"})
+	})
+	return r
+}
+
+func TestContext_Render(t *testing.T) {
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"", "application/json; charset=utf-8", `{"name":"widget"}` + "\n"},
+		{"*/*", "application/json; charset=utf-8", `{"name":"widget"}` + "\n"},
+		{"application/xml", "application/xml; charset=utf-8", `<?xml version="1.0" encoding="UTF-8"?>` + "\n<renderItem><name>widget</name></renderItem>"},
+		{"application/yaml", ContentTypeYAML, "name: widget\n"},
+		{"text/html, application/x-csv;q=0.9", "application/x-csv", "name\nwidget\n"},
+		{"application/json;q=0, application/*", "application/xml; charset=utf-8", ""},
+	}
+
+	r := renderRouter()
+	for _, tt := range tests {
+		t.Run(tt.accept, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodGet, "/item", nil)
+			req.Header.Set("Accept", tt.accept)
+			r.ServeHTTP(w, req)
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+				t.Errorf("Content-Type = %q, want %q", ct, tt.contentType)
+			}
+			if tt.body != "" && w.Body.String() != tt.body {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.body)
+			}
+			if w.Header().Get("Vary") != "Accept" {
+				t.Errorf("Vary = %q, want Accept", w.Header().Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestContext_Render_NotAcceptable(t *testing.T) {
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/item", nil)
+	req.Header.Set("Accept", "image/png")
+	renderRouter().ServeHTTP(w, req)
+
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotAcceptable)
+	}
+}
+
+func TestRouter_RegisterEncoder_ReplacesBuiltin(t *testing.T) {
+	r := New()
+	r.RegisterEncoder("Application/JSON", func(w io.Writer, v any) error {
+		_, err := io.WriteString(w, "custom")
+		return err
+	})
+	r.GET("/", func(c *Context) error {
+		return c.Render(http.StatusOK, nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Body.String() != "custom" {
+		t.Errorf("body = %q, want replaced JSON encoder output", w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "application/json") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+}
+
+func TestContext_Render_EncoderError(t *testing.T) {
+	r := New()
+	r.RegisterEncoder("application/x-broken", func(w io.Writer, v any) error {
+		_, _ = io.WriteString(w, "partial")
+		return errors.New("encode failed")
+	})
+	r.GET("/", func(c *Context) error {
+		return c.Render(http.StatusOK, nil)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept", "application/x-broken")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if strings.Contains(w.Body.String(), "partial") {
+		t.Error("partial encoder output should not be written")
+	}
+}
+
+func TestRegisterEncoder(t *testing.T) {
+	RegisterEncoder("application/x-test-global", func(w io.Writer, v any) error {
+		_, err := io.WriteString(w, "global")
+		return err
+	})
+	t.Cleanup(func() {
+		encodersMu.Lock()
+		globalEncoders = nil
+		encodersMu.Unlock()
+	})
+
+	r := renderRouter()
+	r.GET("/override", func(c *Context) error {
+		return c.Render(http.StatusOK, nil)
+	})
+	r.RegisterEncoder("application/x-test-override", func(w io.Writer, v any) error {
+		_, err := io.WriteString(w, "router")
+		return err
+	})
+	RegisterEncoder("application/x-test-override", func(w io.Writer, v any) error {
+		_, err := io.WriteString(w, "global")
+		return err
+	})
+
+	tests := []struct {
+		path   string
+		accept string
+		body   string
+	}{
+		{"/item", "application/x-test-global", "global"},
+		{"/override", "application/x-test-override", "router"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		req.Header.Set("Accept", tt.accept)
+		r.ServeHTTP(w, req)
+
+		if w.Body.String() != tt.body {
+			t.Errorf("Accept %q: body = %q, want %q", tt.accept, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestContext_RenderAsAndRenderTypes(t *testing.T) {
+	r := renderRouter()
+	var types []string
+	r.GET("/as", func(c *Context) error {
+		types = c.RenderTypes()
+		return c.RenderAs(http.StatusCreated, "Application/X-CSV", renderItem{Name: "widget"})
+	})
+	r.GET("/missing", func(c *Context) error {
+		return c.RenderAs(http.StatusOK, "image/png", nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/as", nil))
+
+	if w.Code != http.StatusCreated || w.Body.String() != "name\nwidget\n" {
+		t.Errorf("RenderAs = %d %q", w.Code, w.Body.String())
+	}
+	want := []string{"application/json", "application/xml", ContentTypeYAML, "application/x-csv"}
+	if strings.Join(types, ",") != strings.Join(want, ",") {
+		t.Errorf("RenderTypes() = %v, want %v", types, want)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("RenderAs unknown type: status = %d, want %d", w.Code, http.StatusNotAcceptable)
+	}
+}
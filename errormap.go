@@ -0,0 +1,94 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrorMapper creates middleware that translates domain errors returned by
+// handlers (e.g., ErrNotFound, ErrConflict from a service layer) into
+// responses with the mapped status codes, so handlers can return them as
+// they are. Errors match with errors.Is, so wrapped errors are mapped too;
+// keys should not wrap one another.
+//
+// Mapped errors are answered with a JSON body {"error": message}, where
+// message is the error text for client errors (4xx) and the status text
+// for server errors, which must not leak internals. Unmapped errors are
+// passed on to the error handler. See MapError for the details.
+//
+// Example:
+//
+//	r.Use(rig.ErrorMapper(map[error]int{
+//	    orders.ErrNotFound:      http.StatusNotFound,
+//	    orders.ErrConflict:      http.StatusConflict,
+//	    orders.ErrInvalidState:  http.StatusUnprocessableEntity,
+//	    payments.ErrUnavailable: http.StatusServiceUnavailable,
+//	}))
+//
+//	r.GET("/orders/{id}", func(c *rig.Context) error {
+//	    order, err := store.Get(c.Context(), c.Param("id"))
+//	    if err != nil {
+//	        return err // 404 for orders.ErrNotFound
+//	    }
+//	    return c.JSON(http.StatusOK, order)
+//	})
+func ErrorMapper(mapping map[error]int) MiddlewareFunc {
+	return MapError(func(err error) (int, any) {
+		for target, code := range mapping {
+			if errors.Is(err, target) {
+				return code, nil
+			}
+		}
+		return 0, nil
+	})
+}
+
+// MapError creates middleware that translates errors returned by handlers
+// into responses. fn returns the status code for an error, or 0 to leave
+// it to the error handler, and optionally a body, which is sent as JSON. A
+// nil body is sent as {"error": message}, as in ErrorMapper.
+//
+// The response is written by the middleware, but the error is still
+// returned, so outer middleware (such as the logger) and response hooks see
+// it, while the error handler is skipped. Errors returned after the
+// response was written are passed on unchanged.
+//
+// Example:
+//
+//	r.Use(rig.MapError(func(err error) (int, any) {
+//	    var ve *orders.ValidationError
+//	    switch {
+//	    case errors.As(err, &ve):
+//	        return http.StatusUnprocessableEntity, map[string]any{"error": "invalid order", "fields": ve.Fields}
+//	    case errors.Is(err, orders.ErrNotFound):
+//	        return http.StatusNotFound, nil
+//	    }
+//	    return 0, nil
+//	}))
+func MapError(fn func(err error) (int, any)) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			err := next(c)
+			if err == nil || c.Written() {
+				return err
+			}
+
+			code, body := fn(err)
+			if code == 0 {
+				return err
+			}
+			if body == nil {
+				message := err.Error()
+				if code >= http.StatusInternalServerError {
+					message = strings.ToLower(http.StatusText(code))
+				}
+				body = map[string]string{"error": message}
+			}
+			if writeErr := c.JSON(code, body); writeErr != nil {
+				return writeErr
+			}
+			return err
+		}
+	}
+}
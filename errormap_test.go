@@ -0,0 +1,80 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+var (
+	errNotFound    = errors.New("order not found")
+	errUnavailable = errors.New("payments backend down at 10.0.0.7")
+)
+
+func TestErrorMapper(t *testing.T) {
+	var seen error
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			seen = next(c)
+			return seen
+		}
+	})
+	r.Use(ErrorMapper(map[error]int{
+		errNotFound:    http.StatusNotFound,
+		errUnavailable: http.StatusServiceUnavailable,
+	}))
+	r.GET("/orders/{id}", func(c *Context) error {
+		switch c.Param("id") {
+		case "missing":
+			return fmt.Errorf("loading order %s: %w", c.Param("id"), errNotFound)
+		case "down":
+			return errUnavailable
+		case "bug":
+			return errors.New("nil pointer")
+		}
+		return c.String(http.StatusOK, "order")
+	})
+
+	tests := []struct {
+		id   string
+		code int
+		body string
+	}{
+		{"missing", http.StatusNotFound, `{"error":"loading order missing: order not found"}` + "\n"},
+		{"down", http.StatusServiceUnavailable, `{"error":"service unavailable"}` + "\n"},
+		{"bug", http.StatusInternalServerError, "Internal Server Error"},
+		{"7", http.StatusOK, "order"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/orders/"+tt.id, nil))
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, want %d %q", tt.id, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+		if (tt.code != http.StatusOK) != (seen != nil) {
+			t.Errorf("%s: outer middleware saw error %v", tt.id, seen)
+		}
+	}
+}
+
+func TestMapError_Body(t *testing.T) {
+	r := New()
+	r.Use(MapError(func(err error) (int, any) {
+		if errors.Is(err, errNotFound) {
+			return http.StatusGone, map[string]string{"code": "ORDER_GONE"}
+		}
+		return 0, nil
+	}))
+	r.GET("/", func(c *Context) error {
+		return errNotFound
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusGone || w.Body.String() != `{"code":"ORDER_GONE"}`+"\n" {
+		t.Errorf("got %d %q", w.Code, w.Body.String())
+	}
+}
@@ -0,0 +1,219 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// HTTPError is an error that carries an HTTP status code.
+// Handlers can return it to choose the response status; DefaultErrorHandler
+// answers 4xx HTTPErrors with their status and message.
+//
+// Example:
+//
+//	if user == nil {
+//	    return rig.NewHTTPError(http.StatusNotFound, "user not found")
+//	}
+type HTTPError struct {
+	// Code is the HTTP status code (e.g., http.StatusNotFound).
+	Code int
+
+	// Message is the client-facing error message.
+	Message string
+}
+
+// NewHTTPError creates an HTTPError with the given status code.
+// If message is empty, the standard status text is used.
+func NewHTTPError(code int, message string) *HTTPError {
+	if message == "" {
+		message = http.StatusText(code)
+	}
+	return &HTTPError{Code: code, Message: message}
+}
+
+// Error implements the error interface.
+func (e *HTTPError) Error() string {
+	return e.Message
+}
+
+// StatusCode returns the HTTP status code.
+func (e *HTTPError) StatusCode() int {
+	return e.Code
+}
+
+// MultiError collects several errors returned together from a handler,
+// e.g., validation failures reported by different subsystems.
+// It behaves like errors.Join: errors.Is and errors.As inspect every error.
+//
+// Example:
+//
+//	var errs rig.MultiError
+//	if err := validateOrder(order); err != nil {
+//	    errs = append(errs, err)
+//	}
+//	if err := checkInventory(order); err != nil {
+//	    errs = append(errs, err)
+//	}
+//	return errs.Err()
+type MultiError []error
+
+// Error implements the error interface, joining all messages with "; ".
+func (m MultiError) Error() string {
+	msgs := make([]string, 0, len(m))
+	for _, err := range m {
+		if err != nil {
+			msgs = append(msgs, err.Error())
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// Unwrap returns the collected errors.
+func (m MultiError) Unwrap() []error {
+	return m
+}
+
+// Err returns m as an error, or nil if it holds no errors.
+// Return it from handlers instead of m so an empty MultiError is not
+// mistaken for a failure.
+func (m MultiError) Err() error {
+	for _, err := range m {
+		if err != nil {
+			return m
+		}
+	}
+	return nil
+}
+
+// SplitErrors flattens err into its individual errors. Errors combined with
+// errors.Join or MultiError (anything with an Unwrap() []error method) are
+// expanded recursively; any other error is returned as a single element.
+// It returns nil for a nil error.
+func SplitErrors(err error) []error {
+	if err == nil {
+		return nil
+	}
+
+	multi, ok := err.(interface{ Unwrap() []error })
+	if !ok {
+		return []error{err}
+	}
+
+	var result []error
+	for _, e := range multi.Unwrap() {
+		result = append(result, SplitErrors(e)...)
+	}
+	return result
+}
+
+// ErrorStatus returns the status code of the response to err, as sent by
+// DefaultErrorHandler: the status of client errors (4xx) with a
+// StatusCode() int method, such as *HTTPError, or 400 Bad Request when
+// errors joined with errors.Join or MultiError have differing 4xx statuses;
+// the status of a server error (5xx) with such a method; and 500 Internal
+// Server Error for any other error, including client errors joined with
+// other errors. It returns 200 for a nil error.
+//
+// Middleware that runs before the error handler, such as access logs, uses
+// it to report the status the client will get.
+//
+// Example:
+//
+//	err := next(c)
+//	status := c.StatusCode()
+//	if err != nil && !c.Written() {
+//	    status = rig.ErrorStatus(err)
+//	}
+func ErrorStatus(err error) int {
+	if err == nil {
+		return http.StatusOK
+	}
+	if code := clientErrorStatus(SplitErrors(err)); code != 0 {
+		return code
+	}
+	var sc interface{ StatusCode() int }
+	if errors.As(err, &sc) && sc.StatusCode() >= 500 && sc.StatusCode() < 600 {
+		return sc.StatusCode()
+	}
+	return http.StatusInternalServerError
+}
+
+// clientErrorStatus returns the 4xx status to answer errs with, or 0 if any
+// of them is not a client error. Errors with differing 4xx statuses are
+// answered with 400 Bad Request.
+func clientErrorStatus(errs []error) int {
+	code := 0
+	for _, err := range errs {
+		var sc interface{ StatusCode() int }
+		if !errors.As(err, &sc) {
+			return 0
+		}
+		s := sc.StatusCode()
+		if s < 400 || s >= 500 {
+			return 0
+		}
+		if code == 0 {
+			code = s
+		} else if code != s {
+			code = http.StatusBadRequest
+		}
+	}
+	return code
+}
+
+// HandleRouteErrors makes requests that match no route (404 Not Found) or
+// no method of a route (405 Method Not Allowed, with its Allow header) run
+// through the router's middleware and error handler, as an *HTTPError with
+// that status, instead of getting ServeMux's plain-text response. One error
+// handler then renders every error response, such as the error pages of
+// render.ErrorPages. The middleware is the router's at the time of the
+// request.
+//
+// Example:
+//
+//	r.Use(engine.Middleware())
+//	r.HandleRouteErrors()
+//	r.SetErrorHandler(render.ErrorPages(map[int]string{
+//	    http.StatusNotFound: "errors/404",
+//	}))
+func (r *Router) HandleRouteErrors() {
+	r.routeErrors = true
+}
+
+// routeErrorWriter replaces the 404 and 405 responses of ServeMux with the
+// response of the router's error handler.
+type routeErrorWriter struct {
+	http.ResponseWriter
+	router  *Router
+	req     *http.Request
+	handled bool
+}
+
+// WriteHeader intercepts 404 and 405 status codes and runs the error
+// handler instead.
+func (w *routeErrorWriter) WriteHeader(code int) {
+	if code != http.StatusNotFound && code != http.StatusMethodNotAllowed {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.handled = true
+	// Set by http.Error for its plain-text body
+	w.ResponseWriter.Header().Del("Content-Type")
+	handler := w.router.middlewares.apply(func(c *Context) error {
+		if code == http.StatusNotFound {
+			return NewHTTPError(code, c.Translate(MsgNotFound, "404 page not found"))
+		}
+		return NewHTTPError(code, c.Translate(MsgMethodNotAllowed, http.StatusText(code)))
+	})
+	w.router.wrap(handler)(w.ResponseWriter, w.req)
+}
+
+// Write discards the original body once the error handler has run.
+func (w *routeErrorWriter) Write(b []byte) (int, error) {
+	if w.handled {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
@@ -0,0 +1,223 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNewHTTPError(t *testing.T) {
+	err := NewHTTPError(http.StatusNotFound, "user not found")
+	if err.Error() != "user not found" || err.StatusCode() != http.StatusNotFound {
+		t.Errorf("NewHTTPError() = %+v", err)
+	}
+
+	err = NewHTTPError(http.StatusConflict, "")
+	if err.Error() != "Conflict" {
+		t.Errorf("Error() = %q, want %q", err.Error(), "Conflict")
+	}
+}
+
+func TestDefaultErrorHandler_HTTPError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{"client error", NewHTTPError(http.StatusNotFound, "user not found"), http.StatusNotFound, "user not found"},
+		{"server error hides message", NewHTTPError(http.StatusBadGateway, "upstream secret"), http.StatusBadGateway, "Bad Gateway"},
+		{"other error", errors.New("db down"), http.StatusInternalServerError, "Internal Server Error"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.GET("/", func(c *Context) error { return tt.err })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestMultiError(t *testing.T) {
+	errA := NewHTTPError(http.StatusBadRequest, "name is required")
+	errB := errors.New("inventory unavailable")
+
+	m := MultiError{errA, nil, errB}
+	if got := m.Error(); got != "name is required; inventory unavailable" {
+		t.Errorf("Error() = %q", got)
+	}
+	if !errors.Is(m, errB) {
+		t.Error("errors.Is should find wrapped errors")
+	}
+
+	var httpErr *HTTPError
+	if !errors.As(m, &httpErr) || httpErr != errA {
+		t.Error("errors.As should find wrapped *HTTPError")
+	}
+
+	if (MultiError{}).Err() != nil || (MultiError{nil}).Err() != nil {
+		t.Error("Err() should return nil for an empty MultiError")
+	}
+	if m.Err() == nil {
+		t.Error("Err() should return the MultiError when it holds errors")
+	}
+}
+
+func TestSplitErrors(t *testing.T) {
+	errA := errors.New("a")
+	errB := errors.New("b")
+	errC := errors.New("c")
+
+	tests := []struct {
+		name string
+		err  error
+		want []error
+	}{
+		{"nil", nil, nil},
+		{"single", errA, []error{errA}},
+		{"joined", errors.Join(errA, errB), []error{errA, errB}},
+		{"nested", MultiError{errA, errors.Join(errB, errC)}, []error{errA, errB, errC}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := SplitErrors(tt.err)
+			if len(got) != len(tt.want) {
+				t.Fatalf("len = %d, want %d", len(got), len(tt.want))
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("SplitErrors()[%d] = %v, want %v", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestErrorStatus(t *testing.T) {
+	notFound := NewHTTPError(http.StatusNotFound, "not found")
+	tests := []struct {
+		name string
+		err  error
+		want int
+	}{
+		{"nil", nil, http.StatusOK},
+		{"client error", notFound, http.StatusNotFound},
+		{"wrapped", fmt.Errorf("load user: %w", notFound), http.StatusNotFound},
+		{"differing client errors", errors.Join(notFound, NewHTTPError(http.StatusConflict, "conflict")), http.StatusBadRequest},
+		{"server error", NewHTTPError(http.StatusServiceUnavailable, "down"), http.StatusServiceUnavailable},
+		{"client and server errors", errors.Join(NewHTTPError(http.StatusBadRequest, "bad"), errors.New("db down")), http.StatusInternalServerError},
+		{"plain error", errors.New("db down"), http.StatusInternalServerError},
+	}
+
+	for _, tt := range tests {
+		if got := ErrorStatus(tt.err); got != tt.want {
+			t.Errorf("%s: ErrorStatus() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultErrorHandler_MultiError(t *testing.T) {
+	tests := []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			"same status",
+			errors.Join(NewHTTPError(http.StatusConflict, "email taken"), NewHTTPError(http.StatusConflict, "username taken")),
+			http.StatusConflict,
+			"email taken\nusername taken",
+		},
+		{
+			"mixed client statuses",
+			MultiError{NewHTTPError(http.StatusNotFound, "team not found"), &BindError{Source: "query", Name: "page", Value: "x", Err: errors.New("bad")}},
+			http.StatusBadRequest,
+			"team not found\nrig: invalid value \"x\" for query 'page': bad",
+		},
+		{
+			"includes server error",
+			errors.Join(NewHTTPError(http.StatusBadRequest, "bad input"), errors.New("db down")),
+			http.StatusInternalServerError,
+			"Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.GET("/", func(c *Context) error { return tt.err })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRouter_HandleRouteErrors(t *testing.T) {
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Middleware", "1")
+			return next(c)
+		}
+	})
+	r.GET("/users", func(c *Context) error { return c.NoContent(http.StatusNoContent) })
+	r.HandleRouteErrors()
+	r.SetErrorHandler(func(c *Context, err error) {
+		var httpErr *HTTPError
+		if !errors.As(err, &httpErr) {
+			t.Fatalf("error = %v, want *HTTPError", err)
+		}
+		_ = c.JSON(httpErr.Code, map[string]string{"error": httpErr.Message})
+	})
+
+	tests := []struct {
+		method, path string
+		status       int
+		body         string
+		allow        string
+	}{
+		{http.MethodGet, "/missing", http.StatusNotFound, `{"error":"404 page not found"}` + "\n", ""},
+		{http.MethodDelete, "/users", http.StatusMethodNotAllowed, `{"error":"Method Not Allowed"}` + "\n", "GET, HEAD"},
+		{http.MethodGet, "/users", http.StatusNoContent, "", ""},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, nil))
+
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s %s: %d %q, want %d %q", tt.method, tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if w.Header().Get("X-Middleware") != "1" {
+			t.Errorf("%s %s: middleware did not run", tt.method, tt.path)
+		}
+		if w.Header().Get("Allow") != tt.allow {
+			t.Errorf("%s %s: Allow = %q, want %q", tt.method, tt.path, w.Header().Get("Allow"), tt.allow)
+		}
+		if tt.status != http.StatusNoContent && w.Header().Get("Content-Type") != "application/json; charset=utf-8" {
+			t.Errorf("%s %s: Content-Type = %q", tt.method, tt.path, w.Header().Get("Content-Type"))
+		}
+	}
+}
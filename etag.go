@@ -0,0 +1,344 @@
+package rig
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"maps"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Errors returned by RequireIfMatch. Both are *HTTPError values, so
+// DefaultErrorHandler answers them with their status code.
+var (
+	// ErrPreconditionRequired is returned when a conditional request is
+	// required but the If-Match header is missing (428).
+	ErrPreconditionRequired = NewHTTPError(http.StatusPreconditionRequired,
+		"precondition required: send If-Match with the current ETag")
+
+	// ErrPreconditionFailed is returned when the If-Match header does not
+	// match the current version of the resource (412).
+	ErrPreconditionFailed = NewHTTPError(http.StatusPreconditionFailed,
+		"precondition failed: the resource has been modified")
+)
+
+// SetETag sets the ETag response header to the quoted version
+// (e.g., version "42" is sent as ETag: "42").
+// Call it on reads and after successful writes so clients always hold the
+// latest version for their next conditional update.
+func (c *Context) SetETag(version string) {
+	c.SetHeader("ETag", quoteETag(version))
+}
+
+// RequireIfMatch enforces optimistic concurrency for updates (PUT, PATCH, DELETE).
+// It compares the request's If-Match header with currentVersion:
+//   - missing If-Match: returns ErrPreconditionRequired (428)
+//   - no matching ETag: returns ErrPreconditionFailed (412)
+//   - match (or If-Match: *): returns nil
+//
+// In every case the ETag header is set to currentVersion, so a client that
+// lost the race can refetch or retry with the correct version.
+//
+// Example:
+//
+//	r.PUT("/orders/{id}", func(c *rig.Context) error {
+//	    order, err := store.Get(c.Param("id"))
+//	    if err != nil {
+//	        return err
+//	    }
+//	    if err := c.RequireIfMatch(order.Version); err != nil {
+//	        return err // 428 or 412
+//	    }
+//	    updated, err := store.Update(order)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    c.SetETag(updated.Version)
+//	    return c.JSON(http.StatusOK, updated)
+//	})
+func (c *Context) RequireIfMatch(currentVersion string) error {
+	current := quoteETag(currentVersion)
+	c.SetHeader("ETag", current)
+
+	ifMatch := c.GetHeader("If-Match")
+	if ifMatch == "" {
+		return ErrPreconditionRequired
+	}
+	if !etagMatches(ifMatch, current) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// IfNoneMatch reports whether the request's If-None-Match header matches
+// etag, using the weak comparison required by RFC 9110 (W/"x" matches "x").
+// When it returns true for a GET or HEAD request, the client's cached copy is
+// current and the handler can answer 304 Not Modified.
+func (c *Context) IfNoneMatch(etag string) bool {
+	header := c.GetHeader("If-None-Match")
+	if header == "" {
+		return false
+	}
+	current := strings.TrimPrefix(quoteETag(etag), "W/")
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == current {
+			return true
+		}
+	}
+	return false
+}
+
+// quoteETag formats version as a strong entity tag. Values that are already
+// quoted (or weak, W/"...") are returned unchanged.
+func quoteETag(version string) string {
+	if strings.HasPrefix(version, `"`) || strings.HasPrefix(version, `W/"`) {
+		return version
+	}
+	return `"` + version + `"`
+}
+
+// etagMatches reports whether the If-Match header value matches current
+// using the strong comparison required by RFC 9110 (weak tags never match).
+func etagMatches(header, current string) bool {
+	for tag := range strings.SplitSeq(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" {
+			return true
+		}
+		if strings.HasPrefix(tag, "W/") || strings.HasPrefix(current, "W/") {
+			continue
+		}
+		if tag == current {
+			return true
+		}
+	}
+	return false
+}
+
+// ETagConfig defines the configuration for ETag middleware.
+type ETagConfig struct {
+	// Weak sends weak ETags (W/"..."), which claim semantic rather than
+	// byte-for-byte equivalence. Use them when responses pass through
+	// transformations such as compression by a proxy.
+	// Default: false (strong ETags).
+	Weak bool
+
+	// MaxSize is the largest response, in bytes, that is buffered to compute
+	// an ETag. Larger responses, and responses flushed by the handler (e.g.,
+	// c.Stream), are streamed as they are written, without an ETag.
+	// Default: 1 MB.
+	MaxSize int
+
+	// Skipper selects requests whose responses get no ETag and are never
+	// buffered.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// ETag creates middleware that adds an ETag to successful GET and HEAD
+// responses, computed as a SHA-256 hash of the body, and answers requests
+// whose If-None-Match header matches with 304 Not Modified and no body. The
+// handler still runs, but clients skip downloading unchanged content.
+//
+// Responses whose handler set an ETag (e.g., with c.SetETag) keep it, and
+// responses other than 200 OK are sent unchanged. If the handler panics,
+// the buffered response is dropped, so Recover can answer. Register it per
+// group to enable it for some routes only. Registered before Compress, it
+// computes the ETag over the compressed body, so each encoding gets its own
+// tag.
+//
+// Example:
+//
+//	api := r.Group("/api")
+//	api.Use(rig.ETag())
+func ETag() MiddlewareFunc {
+	return ETagWithConfig(ETagConfig{})
+}
+
+// ETagWithConfig creates ETag middleware with custom configuration.
+//
+// Example:
+//
+//	r.Use(rig.ETagWithConfig(rig.ETagConfig{
+//	    Weak:    true,
+//	    MaxSize: 256 << 10,
+//	}))
+func ETagWithConfig(config ETagConfig) MiddlewareFunc {
+	if config.MaxSize <= 0 {
+		config.MaxSize = 1 << 20
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			method := c.Method()
+			if (method != http.MethodGet && method != http.MethodHead) || c.writer.status != 0 {
+				return next(c)
+			}
+
+			ew := &etagWriter{
+				ResponseWriter: c.writer.ResponseWriter,
+				config:         &config,
+				header:         c.writer.Header().Clone(),
+			}
+			c.writer.ResponseWriter = ew
+			sent := false
+			defer func() {
+				c.writer.ResponseWriter = ew.ResponseWriter
+				if !sent {
+					// Panicked: drop the partial response so Recover can answer
+					ew.discard(c)
+					return
+				}
+				if ew.notModified(c) {
+					c.writer.status = http.StatusNotModified
+					c.writer.size = 0
+				}
+			}()
+			err := next(c)
+			sent = true
+			return err
+		}
+	}
+}
+
+// etagWriter buffers a response to compute its ETag, falling back to
+// streaming when the response is flushed or grows past MaxSize.
+type etagWriter struct {
+	http.ResponseWriter
+	config *ETagConfig
+	header http.Header // the headers before the handler ran
+
+	status    int
+	buf       []byte
+	streaming bool
+	hijacked  bool
+}
+
+// WriteHeader records the status; headers are sent when the response is
+// finished or starts streaming. Informational (1xx) responses are sent
+// immediately.
+func (w *etagWriter) WriteHeader(code int) {
+	if code < 200 {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers data until the response exceeds MaxSize, then streams.
+func (w *etagWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	if len(w.buf)+len(b) <= w.config.MaxSize {
+		w.buf = append(w.buf, b...)
+		return len(b), nil
+	}
+	if err := w.stream(); err != nil {
+		return 0, err
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// stream sends the headers and the buffered data, and passes later writes
+// through without an ETag.
+func (w *etagWriter) stream() error {
+	w.streaming = true
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// Flush implements http.Flusher; flushed responses are streamed without an
+// ETag.
+func (w *etagWriter) Flush() {
+	if w.hijacked {
+		return
+	}
+	if !w.streaming {
+		_ = w.stream()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *etagWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, rw, err := http.NewResponseController(w.ResponseWriter).Hijack()
+	if err == nil {
+		w.hijacked = true
+	}
+	return conn, rw, err
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *etagWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// discard drops a buffered response and the headers the handler set,
+// unless part of it was already sent.
+func (w *etagWriter) discard(c *Context) {
+	if w.hijacked || w.streaming {
+		return
+	}
+	w.status = 0
+	w.buf = nil
+	h := w.ResponseWriter.Header()
+	clear(h)
+	maps.Copy(h, w.header)
+	c.written = false
+	c.writer.status = 0
+	c.writer.size = 0
+}
+
+// notModified finishes a buffered response: it sets the ETag of 200
+// responses and sends either the response or 304 Not Modified, which it
+// reports.
+func (w *etagWriter) notModified(c *Context) bool {
+	if w.hijacked || w.streaming || w.status == 0 {
+		return false
+	}
+
+	h := w.Header()
+	if w.status == http.StatusOK {
+		etag := h.Get("ETag")
+		if etag == "" {
+			sum := sha256.Sum256(w.buf)
+			etag = `"` + hex.EncodeToString(sum[:16]) + `"`
+			if w.config.Weak {
+				etag = "W/" + etag
+			}
+			h.Set("ETag", etag)
+		}
+		if c.IfNoneMatch(etag) {
+			h.Del("Content-Length")
+			w.ResponseWriter.WriteHeader(http.StatusNotModified)
+			return true
+		}
+	}
+
+	w.ResponseWriter.WriteHeader(w.status)
+	if len(w.buf) > 0 {
+		_, _ = w.ResponseWriter.Write(w.buf)
+	}
+	return false
+}
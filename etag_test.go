@@ -0,0 +1,252 @@
+package rig
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContext_SetETag(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"42", `"42"`},
+		{`"abc"`, `"abc"`},
+		{`W/"abc"`, `W/"abc"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+			c.SetETag(tt.version)
+
+			if got := w.Header().Get("ETag"); got != tt.want {
+				t.Errorf("ETag = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_RequireIfMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		ifMatch string
+		wantErr error
+	}{
+		{"missing", "", ErrPreconditionRequired},
+		{"match", `"v2"`, nil},
+		{"match in list", `"v1", "v2"`, nil},
+		{"wildcard", "*", nil},
+		{"stale", `"v1"`, ErrPreconditionFailed},
+		{"weak never matches", `W/"v2"`, ErrPreconditionFailed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			c := newContext(w, req)
+
+			err := c.RequireIfMatch("v2")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("RequireIfMatch() error = %v, want %v", err, tt.wantErr)
+			}
+			if got := w.Header().Get("ETag"); got != `"v2"` {
+				t.Errorf("ETag = %q, want %q", got, `"v2"`)
+			}
+		})
+	}
+}
+
+func TestContext_RequireIfMatch_Status(t *testing.T) {
+	r := New()
+	r.PUT("/orders/1", func(c *Context) error {
+		if err := c.RequireIfMatch("7"); err != nil {
+			return err
+		}
+		c.SetETag("8")
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	tests := []struct {
+		ifMatch    string
+		wantStatus int
+		wantETag   string
+	}{
+		{"", http.StatusPreconditionRequired, `"7"`},
+		{`"6"`, http.StatusPreconditionFailed, `"7"`},
+		{`"7"`, http.StatusOK, `"8"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.ifMatch, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPut, "/orders/1", nil)
+			if tt.ifMatch != "" {
+				req.Header.Set("If-Match", tt.ifMatch)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("ETag"); got != tt.wantETag {
+				t.Errorf("ETag = %q, want %q", got, tt.wantETag)
+			}
+		})
+	}
+}
+
+func TestContext_IfNoneMatch(t *testing.T) {
+	tests := []struct {
+		name        string
+		ifNoneMatch string
+		etag        string
+		want        bool
+	}{
+		{"missing", "", `W/"v2"`, false},
+		{"weak match", `W/"v2"`, `W/"v2"`, true},
+		{"strong header matches weak tag", `"v2"`, `W/"v2"`, true},
+		{"unquoted version", `"v2"`, "v2", true},
+		{"in list", `"v1", W/"v2"`, `"v2"`, true},
+		{"wildcard", "*", "v2", true},
+		{"stale", `W/"v1"`, `W/"v2"`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.ifNoneMatch != "" {
+				req.Header.Set("If-None-Match", tt.ifNoneMatch)
+			}
+			c := newContext(httptest.NewRecorder(), req)
+
+			if got := c.IfNoneMatch(tt.etag); got != tt.want {
+				t.Errorf("IfNoneMatch(%q) = %v, want %v", tt.etag, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestETag(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+	api.Use(ETag())
+	api.GET("/items", func(c *Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"name": "widget"})
+	})
+	api.GET("/versioned", func(c *Context) error {
+		c.SetETag("v7")
+		return c.String(http.StatusOK, "versioned")
+	})
+	api.GET("/missing", func(c *Context) error {
+		return NewHTTPError(http.StatusNotFound, "not found")
+	})
+	api.POST("/items", func(c *Context) error {
+		return c.String(http.StatusCreated, "created")
+	})
+	r.GET("/plain", func(c *Context) error {
+		return c.String(http.StatusOK, "plain")
+	})
+
+	serve := func(method, target, ifNoneMatch string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve(http.MethodGet, "/api/items", "")
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.HasPrefix(etag, `"`) || w.Body.String() != `{"name":"widget"}`+"\n" {
+		t.Fatalf("first request: status = %d, ETag = %q, body = %q", w.Code, etag, w.Body.String())
+	}
+
+	w = serve(http.MethodGet, "/api/items", etag)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("matching If-None-Match: status = %d, ETag = %q, body = %q", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+
+	w = serve(http.MethodGet, "/api/items", `"stale"`)
+	if w.Code != http.StatusOK || w.Body.Len() == 0 {
+		t.Errorf("stale If-None-Match: status = %d", w.Code)
+	}
+
+	w = serve(http.MethodGet, "/api/versioned", `"v7"`)
+	if w.Code != http.StatusNotModified || w.Header().Get("ETag") != `"v7"` {
+		t.Errorf("handler ETag: status = %d, ETag = %q", w.Code, w.Header().Get("ETag"))
+	}
+
+	for _, tt := range []struct{ method, target string }{
+		{http.MethodGet, "/api/missing"},
+		{http.MethodPost, "/api/items"},
+		{http.MethodGet, "/plain"},
+	} {
+		if w := serve(tt.method, tt.target, "*"); w.Header().Get("ETag") != "" || w.Code == http.StatusNotModified {
+			t.Errorf("%s %s: status = %d, ETag = %q", tt.method, tt.target, w.Code, w.Header().Get("ETag"))
+		}
+	}
+}
+
+func TestETag_Panic(t *testing.T) {
+	r := New()
+	r.Use(RecoverWithConfig(RecoverConfig{Logger: func(any, []byte) {}}))
+	r.Use(ETag())
+	r.GET("/items", func(c *Context) error {
+		c.SetHeader("X-Partial", "true")
+		_ = c.JSON(http.StatusOK, map[string]string{"name": "widget"})
+		panic("boom")
+	})
+
+	// The partial response is dropped, so Recover can answer
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	if w.Code != http.StatusInternalServerError || w.Header().Get("ETag") != "" || w.Header().Get("X-Partial") != "" {
+		t.Errorf("status = %d, ETag = %q, X-Partial = %q, want 500 without them", w.Code, w.Header().Get("ETag"), w.Header().Get("X-Partial"))
+	}
+	if strings.Contains(w.Body.String(), "widget") {
+		t.Errorf("body = %q, want no partial response", w.Body.String())
+	}
+}
+
+func TestETagWithConfig(t *testing.T) {
+	r := New()
+	r.Use(ETagWithConfig(ETagConfig{Weak: true, MaxSize: 8}))
+	r.GET("/small", func(c *Context) error {
+		return c.String(http.StatusOK, "small")
+	})
+	r.GET("/large", func(c *Context) error {
+		return c.String(http.StatusOK, "larger than eight bytes")
+	})
+	r.GET("/stream", func(c *Context) error {
+		return c.Stream(http.StatusOK, "text/plain", func(w io.Writer) error {
+			_, err := w.Write([]byte("a"))
+			return err
+		})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/small", nil))
+	if etag := w.Header().Get("ETag"); !strings.HasPrefix(etag, `W/"`) || w.Body.String() != "small" {
+		t.Errorf("small: ETag = %q, body = %q", etag, w.Body.String())
+	}
+
+	for _, target := range []string{"/large", "/stream"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusOK || w.Header().Get("ETag") != "" || w.Body.Len() == 0 {
+			t.Errorf("%s: status = %d, ETag = %q, body = %q", target, w.Code, w.Header().Get("ETag"), w.Body.String())
+		}
+	}
+}
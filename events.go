@@ -0,0 +1,145 @@
+package rig
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"reflect"
+	"sync"
+)
+
+// EventBus is an in-process publish/subscribe bus. Subscribers are keyed by
+// the Go type of the event, and each published event is delivered to every
+// subscriber of its type on its own goroutine, so HTTP handlers can trigger
+// side effects (emails, cache invalidation, audit records) without waiting
+// for them or importing a message broker.
+//
+// Events are not persisted: those still being handled when the process
+// exits are lost. Use a real broker or an outbox for delivery guarantees.
+type EventBus struct {
+	mu          sync.Mutex
+	subscribers map[reflect.Type][]func(context.Context, any) error
+	inflight    int
+	idle        chan struct{}
+
+	// OnError is called when a subscriber returns an error or panics.
+	// Default: logs with the standard log package.
+	OnError func(event any, err error)
+}
+
+// NewEventBus creates an empty EventBus. Most applications use the shared
+// bus returned by Events instead.
+func NewEventBus() *EventBus {
+	return &EventBus{
+		subscribers: make(map[reflect.Type][]func(context.Context, any) error),
+	}
+}
+
+var defaultEventBus = NewEventBus()
+
+// Events returns the application-wide EventBus. RunGracefully and
+// RunWithGracefulShutdown drain it after the server stops accepting requests.
+//
+// Example:
+//
+//	type UserCreated struct{ ID, Email string }
+//
+//	rig.Subscribe(rig.Events(), func(ctx context.Context, e UserCreated) error {
+//	    return mailer.SendWelcome(ctx, e.Email)
+//	})
+//
+//	r.POST("/users", func(c *rig.Context) error {
+//	    // ... create the user
+//	    rig.Events().Publish(c.Context(), UserCreated{ID: id, Email: email})
+//	    return c.JSON(http.StatusCreated, user)
+//	})
+func Events() *EventBus {
+	return defaultEventBus
+}
+
+// Subscribe registers handler for events of type T published on bus.
+// Subscribe is typically called during startup; it is safe for concurrent use.
+func Subscribe[T any](bus *EventBus, handler func(ctx context.Context, event T) error) {
+	t := reflect.TypeFor[T]()
+
+	bus.mu.Lock()
+	defer bus.mu.Unlock()
+	bus.subscribers[t] = append(bus.subscribers[t], func(ctx context.Context, event any) error {
+		return handler(ctx, event.(T))
+	})
+}
+
+// Publish delivers event asynchronously to every subscriber of its type and
+// returns immediately. Subscribers receive a context carrying the values of
+// ctx (e.g., the request ID) but not its cancellation, since they usually
+// run after the request has completed.
+func (b *EventBus) Publish(ctx context.Context, event any) {
+	b.mu.Lock()
+	handlers := b.subscribers[reflect.TypeOf(event)]
+	if len(handlers) == 0 {
+		b.mu.Unlock()
+		return
+	}
+	if b.inflight == 0 {
+		b.idle = make(chan struct{})
+	}
+	b.inflight += len(handlers)
+	b.mu.Unlock()
+
+	ctx = context.WithoutCancel(ctx)
+	for _, handler := range handlers {
+		go b.deliver(ctx, handler, event)
+	}
+}
+
+// deliver runs a single subscriber and reports its error or panic.
+func (b *EventBus) deliver(ctx context.Context, handler func(context.Context, any) error, event any) {
+	defer b.done()
+	defer func() {
+		if p := recover(); p != nil {
+			b.reportError(event, fmt.Errorf("rig: event subscriber panicked: %v", p))
+		}
+	}()
+
+	if err := handler(ctx, event); err != nil {
+		b.reportError(event, err)
+	}
+}
+
+// done marks one delivery as finished.
+func (b *EventBus) done() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.inflight--
+	if b.inflight == 0 {
+		close(b.idle)
+	}
+}
+
+func (b *EventBus) reportError(event any, err error) {
+	if b.OnError != nil {
+		b.OnError(event, err)
+		return
+	}
+	log.Printf("[RIG] event %T: %v", event, err)
+}
+
+// Drain waits until every published event has been handled, including
+// events published by subscribers while draining, or until ctx is done.
+func (b *EventBus) Drain(ctx context.Context) error {
+	for {
+		b.mu.Lock()
+		if b.inflight == 0 {
+			b.mu.Unlock()
+			return nil
+		}
+		idle := b.idle
+		b.mu.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
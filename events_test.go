@@ -0,0 +1,138 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type userCreated struct{ ID string }
+
+type orderPlaced struct{ Total int }
+
+type ctxKey string
+
+func TestEventBus_Publish(t *testing.T) {
+	bus := NewEventBus()
+
+	var mu sync.Mutex
+	var got []string
+	record := func(s string) {
+		mu.Lock()
+		defer mu.Unlock()
+		got = append(got, s)
+	}
+
+	Subscribe(bus, func(ctx context.Context, e userCreated) error {
+		record("welcome:" + e.ID + ":" + ctx.Value(ctxKey("rid")).(string))
+		return nil
+	})
+	Subscribe(bus, func(ctx context.Context, e userCreated) error {
+		record("audit:" + e.ID)
+		return nil
+	})
+	Subscribe(bus, func(ctx context.Context, e orderPlaced) error {
+		record("order")
+		return nil
+	})
+
+	// Subscribers must not see the request's cancellation
+	ctx, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey("rid"), "r1"))
+	bus.Publish(ctx, userCreated{ID: "42"})
+	cancel()
+	bus.Publish(context.Background(), "no subscribers")
+
+	if err := bus.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(got) != 2 {
+		t.Fatalf("delivered %v, want the two userCreated subscribers", got)
+	}
+	for _, want := range []string{"welcome:42:r1", "audit:42"} {
+		found := false
+		for _, g := range got {
+			found = found || g == want
+		}
+		if !found {
+			t.Errorf("missing delivery %q in %v", want, got)
+		}
+	}
+}
+
+func TestEventBus_Errors(t *testing.T) {
+	bus := NewEventBus()
+
+	var mu sync.Mutex
+	var errs []error
+	bus.OnError = func(event any, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs = append(errs, err)
+	}
+
+	Subscribe(bus, func(ctx context.Context, e userCreated) error {
+		return errors.New("smtp down")
+	})
+	Subscribe(bus, func(ctx context.Context, e userCreated) error {
+		panic("nil map")
+	})
+
+	bus.Publish(context.Background(), userCreated{ID: "1"})
+	if err := bus.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(errs) != 2 {
+		t.Errorf("OnError called %d times, want 2: %v", len(errs), errs)
+	}
+}
+
+func TestEventBus_DrainWaitsForChainedEvents(t *testing.T) {
+	bus := NewEventBus()
+
+	var delivered atomic.Bool
+	Subscribe(bus, func(ctx context.Context, e userCreated) error {
+		time.Sleep(20 * time.Millisecond)
+		bus.Publish(ctx, orderPlaced{Total: 1})
+		return nil
+	})
+	Subscribe(bus, func(ctx context.Context, e orderPlaced) error {
+		time.Sleep(20 * time.Millisecond)
+		delivered.Store(true)
+		return nil
+	})
+
+	bus.Publish(context.Background(), userCreated{})
+	if err := bus.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain() error = %v", err)
+	}
+	if !delivered.Load() {
+		t.Error("Drain() returned before the chained event was handled")
+	}
+}
+
+func TestEventBus_DrainTimeout(t *testing.T) {
+	bus := NewEventBus()
+	release := make(chan struct{})
+	defer close(release)
+
+	Subscribe(bus, func(ctx context.Context, e userCreated) error {
+		<-release
+		return nil
+	})
+	bus.Publish(context.Background(), userCreated{})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := bus.Drain(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Drain() error = %v, want DeadlineExceeded", err)
+	}
+}
@@ -6,6 +6,11 @@ require github.com/cloudresty/rig v0.0.0
 
 require github.com/cloudresty/rig/render v0.0.0
 
+require (
+	github.com/fsnotify/fsnotify v1.10.1 // indirect
+	golang.org/x/sys v0.13.0 // indirect
+)
+
 replace github.com/cloudresty/rig => ../../
 
 replace github.com/cloudresty/rig/render => ../../render
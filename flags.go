@@ -0,0 +1,371 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FlagProvider reports whether feature flags are enabled. Implementations
+// must be safe for concurrent use. Adapt a hosted flag service (e.g.,
+// LaunchDarkly, Unleash) by implementing it, or use EnvFlags, FileFlags,
+// HTTPFlags, or FlagMap.
+type FlagProvider interface {
+	Enabled(ctx context.Context, name string) (bool, error)
+}
+
+// FlagProviderFunc adapts a function to the FlagProvider interface.
+type FlagProviderFunc func(ctx context.Context, name string) (bool, error)
+
+// Enabled calls f(ctx, name).
+func (f FlagProviderFunc) Enabled(ctx context.Context, name string) (bool, error) {
+	return f(ctx, name)
+}
+
+// FlagMap is a static FlagProvider. Flags that are not in the map are
+// disabled. It is handy in tests.
+type FlagMap map[string]bool
+
+// Enabled implements FlagProvider.
+func (m FlagMap) Enabled(_ context.Context, name string) (bool, error) {
+	return m[name], nil
+}
+
+// EnvFlags returns a FlagProvider that reads flags from environment
+// variables named prefix followed by the flag name in upper case, with
+// characters other than letters and digits replaced by underscores. With
+// prefix "FEATURE_", flag "new-checkout" is read from FEATURE_NEW_CHECKOUT.
+// Values are parsed with strconv.ParseBool ("1", "true", ...); unset
+// variables disable the flag.
+func EnvFlags(prefix string) FlagProvider {
+	return FlagProviderFunc(func(_ context.Context, name string) (bool, error) {
+		value, ok := os.LookupEnv(prefix + envFlagName(name))
+		if !ok || value == "" {
+			return false, nil
+		}
+		enabled, err := strconv.ParseBool(value)
+		if err != nil {
+			return false, fmt.Errorf("rig: flag %q: %w", name, err)
+		}
+		return enabled, nil
+	})
+}
+
+// envFlagName converts a flag name to an environment variable suffix.
+func envFlagName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z':
+			return r - 'a' + 'A'
+		case r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		default:
+			return '_'
+		}
+	}, name)
+}
+
+// FileFlags returns a FlagProvider that reads flags from a JSON file mapping
+// flag names to booleans, such as a mounted Kubernetes ConfigMap:
+//
+//	{"new-checkout": true, "beta-search": false}
+//
+// The file is read again whenever its modification time changes, so flags
+// can be flipped without a restart. If it cannot be read or parsed, the
+// flags last read successfully are kept and Enabled returns the error.
+func FileFlags(path string) FlagProvider {
+	return &fileFlags{path: path}
+}
+
+// fileFlags is the FlagProvider returned by FileFlags.
+type fileFlags struct {
+	path    string
+	mu      sync.Mutex
+	modTime time.Time
+	flags   map[string]bool
+}
+
+// Enabled implements FlagProvider.
+func (f *fileFlags) Enabled(_ context.Context, name string) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	info, err := os.Stat(f.path)
+	if err == nil && !info.ModTime().Equal(f.modTime) {
+		var data []byte
+		if data, err = os.ReadFile(f.path); err == nil {
+			var flags map[string]bool
+			if err = json.Unmarshal(data, &flags); err == nil {
+				f.flags = flags
+				f.modTime = info.ModTime()
+			}
+		}
+	}
+	if err != nil {
+		return f.flags[name], fmt.Errorf("rig: reading flags from %s: %w", f.path, err)
+	}
+	return f.flags[name], nil
+}
+
+// HTTPFlagsConfig defines the configuration for HTTPFlags.
+type HTTPFlagsConfig struct {
+	// URL answers GET requests with a JSON object mapping flag names to
+	// booleans. Required.
+	URL string
+
+	// Header is added to every request (e.g., an Authorization header).
+	Header http.Header
+
+	// Client sends the requests.
+	// Default: an http.Client with a 5 second timeout.
+	Client *http.Client
+
+	// TTL is how long fetched flags are cached.
+	// Default: 30 seconds.
+	TTL time.Duration
+
+	// Timeout bounds each fetch. Fetches are not tied to the request that
+	// started them, so a canceled request does not fail the lookups of
+	// others.
+	// Default: 5 seconds.
+	Timeout time.Duration
+}
+
+// HTTPFlags returns a FlagProvider that fetches flags from a remote JSON
+// endpoint and caches them for config.TTL. Once the flags expire, they keep
+// being served while one background fetch refreshes them, so lookups never
+// wait for the endpoint after the first fetch; until then, lookups wait for
+// it, or until their context is done. If a refresh fails, the flags last
+// fetched successfully keep being served until the next attempt, one TTL
+// later; Enabled only returns an error when no flags have been fetched yet.
+// It panics if config.URL is empty.
+func HTTPFlags(config HTTPFlagsConfig) FlagProvider {
+	if config.URL == "" {
+		panic("rig: HTTPFlags requires a URL")
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: 5 * time.Second}
+	}
+	if config.TTL <= 0 {
+		config.TTL = 30 * time.Second
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 5 * time.Second
+	}
+	return &httpFlags{config: config}
+}
+
+// httpFlags is the FlagProvider returned by HTTPFlags.
+type httpFlags struct {
+	config  HTTPFlagsConfig
+	mu      sync.Mutex
+	expires time.Time
+	flags   map[string]bool
+	err     error         // Error of the last fetch
+	pending chan struct{} // Closed when the fetch in progress ends
+}
+
+// Enabled implements FlagProvider.
+func (h *httpFlags) Enabled(ctx context.Context, name string) (bool, error) {
+	h.mu.Lock()
+	if time.Now().Before(h.expires) {
+		defer h.mu.Unlock()
+		return h.flags[name], nil
+	}
+
+	pending := h.pending
+	if pending == nil {
+		pending = make(chan struct{})
+		h.pending = pending
+		go h.refresh(pending)
+	}
+	if h.flags != nil {
+		// Serve the expired flags while they are refreshed
+		defer h.mu.Unlock()
+		return h.flags[name], nil
+	}
+	h.mu.Unlock()
+
+	select {
+	case <-pending:
+	case <-ctx.Done():
+		return false, ctx.Err()
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.flags == nil {
+		return false, h.err
+	}
+	return h.flags[name], nil
+}
+
+// refresh fetches the flags and closes pending when done. Until flags have
+// been fetched, a failed fetch is retried by the next lookup; after that,
+// one TTL later.
+func (h *httpFlags) refresh(pending chan struct{}) {
+	ctx, cancel := context.WithTimeout(context.Background(), h.config.Timeout)
+	defer cancel()
+	flags, err := h.fetch(ctx)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	defer close(pending)
+	h.pending = nil
+	h.err = err
+	if err == nil {
+		h.flags = flags
+	}
+	if h.flags != nil {
+		h.expires = time.Now().Add(h.config.TTL)
+	}
+}
+
+// fetch requests the flags from the configured URL.
+func (h *httpFlags) fetch(ctx context.Context) (map[string]bool, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, h.config.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("rig: fetching flags: %w", err)
+	}
+	for k, v := range h.config.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := h.config.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("rig: fetching flags: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("rig: fetching flags: unexpected status %s", resp.Status)
+	}
+	flags := make(map[string]bool)
+	if err := json.NewDecoder(resp.Body).Decode(&flags); err != nil {
+		return nil, fmt.Errorf("rig: fetching flags: %w", err)
+	}
+	return flags, nil
+}
+
+// flagsKey is the context store key holding the FlagProvider set by
+// FeatureGate, which takes precedence over the router's.
+const flagsKey = "rig.flags"
+
+// SetFlagProvider sets the FlagProvider used by FlagEnabled and by
+// FeatureGate middleware created without one.
+func (r *Router) SetFlagProvider(provider FlagProvider) {
+	r.flags = provider
+}
+
+// flagProvider returns the FlagProvider for c, or nil if none is set.
+func flagProvider(c *Context) FlagProvider {
+	if provider, err := GetType[FlagProvider](c, flagsKey); err == nil {
+		return provider
+	}
+	if c.router != nil {
+		return c.router.flags
+	}
+	return nil
+}
+
+// FlagEnabled reports whether the named feature flag is enabled for the
+// request, for in-handler branching. It uses the provider of the enclosing
+// FeatureGate, or else the router's (see Router.SetFlagProvider). Flags are
+// disabled if no provider is set or the provider fails.
+//
+// Example:
+//
+//	r.GET("/checkout", func(c *rig.Context) error {
+//	    if rig.FlagEnabled(c, "new-checkout") {
+//	        return newCheckout(c)
+//	    }
+//	    return legacyCheckout(c)
+//	})
+func FlagEnabled(c *Context, name string) bool {
+	provider := flagProvider(c)
+	if provider == nil {
+		return false
+	}
+	enabled, err := provider.Enabled(c.Context(), name)
+	return err == nil && enabled
+}
+
+// FeatureGateConfig defines the configuration for FeatureGate middleware.
+type FeatureGateConfig struct {
+	// Flag is the name of the feature flag guarding the routes. Required.
+	Flag string
+
+	// Provider reports whether Flag is enabled.
+	// Default: the router's provider (see Router.SetFlagProvider).
+	Provider FlagProvider
+
+	// Status is the status code answered while the flag is disabled.
+	// http.StatusNotFound hides the endpoint entirely; use
+	// http.StatusForbidden to signal that it exists but is not available.
+	// Default: http.StatusNotFound.
+	Status int
+}
+
+// FeatureGate creates middleware that answers requests with 404 Not Found
+// while the named feature flag is disabled, so new endpoints can be shipped
+// dark and turned on without a deploy. If provider is nil, the router's
+// provider is used (see Router.SetFlagProvider). Requests are rejected if
+// the provider fails.
+//
+// Handlers behind the gate can branch on other flags from the same
+// provider with FlagEnabled.
+//
+// Example:
+//
+//	flags := rig.EnvFlags("FEATURE_")
+//	v2 := r.Group("/v2")
+//	v2.Use(rig.FeatureGate("api-v2", flags))
+func FeatureGate(flag string, provider FlagProvider) MiddlewareFunc {
+	return FeatureGateWithConfig(FeatureGateConfig{Flag: flag, Provider: provider})
+}
+
+// FeatureGateWithConfig creates feature gate middleware with custom
+// configuration. It panics if config.Flag is empty.
+//
+// Example:
+//
+//	r.Use(rig.FeatureGateWithConfig(rig.FeatureGateConfig{
+//	    Flag:     "beta-reports",
+//	    Provider: rig.FileFlags("/etc/flags/flags.json"),
+//	    Status:   http.StatusForbidden,
+//	}))
+func FeatureGateWithConfig(config FeatureGateConfig) MiddlewareFunc {
+	if config.Flag == "" {
+		panic("rig: FeatureGate requires a flag name")
+	}
+	if config.Status == 0 {
+		config.Status = http.StatusNotFound
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			provider := config.Provider
+			if provider == nil {
+				provider = flagProvider(c)
+			} else {
+				c.Set(flagsKey, provider)
+			}
+			if provider == nil {
+				return errors.New("rig: FeatureGate: no flag provider set")
+			}
+
+			enabled, err := provider.Enabled(c.Context(), config.Flag)
+			if err != nil || !enabled {
+				return NewHTTPError(config.Status, http.StatusText(config.Status))
+			}
+			return next(c)
+		}
+	}
+}
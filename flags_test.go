@@ -0,0 +1,236 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestFeatureGate(t *testing.T) {
+	flags := FlagMap{"api-v2": true, "beta-search": true}
+
+	r := New()
+	r.GET("/v2/users", FeatureGate("api-v2", flags)(func(c *Context) error {
+		return c.String(http.StatusOK, "search:%t", FlagEnabled(c, "beta-search"))
+	}))
+	r.GET("/v3/users", FeatureGate("api-v3", flags)(func(c *Context) error {
+		return c.String(http.StatusOK, "v3")
+	}))
+	r.GET("/reports", FeatureGateWithConfig(FeatureGateConfig{Flag: "reports", Provider: flags, Status: http.StatusForbidden})(func(c *Context) error {
+		return c.String(http.StatusOK, "reports")
+	}))
+
+	tests := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"/v2/users", http.StatusOK, "search:true"},
+		{"/v3/users", http.StatusNotFound, "Not Found"},
+		{"/reports", http.StatusForbidden, "Forbidden"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("%s: got %d %q, want %d %q", tt.target, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestFeatureGate_RouterProvider(t *testing.T) {
+	failing := FlagProviderFunc(func(context.Context, string) (bool, error) {
+		return true, errors.New("flag service down")
+	})
+
+	r := New()
+	r.SetFlagProvider(FlagMap{"dark": true})
+	r.GET("/dark", FeatureGate("dark", nil)(func(c *Context) error {
+		return c.String(http.StatusOK, "%t %t", FlagEnabled(c, "dark"), FlagEnabled(c, "other"))
+	}))
+	r.GET("/failing", FeatureGate("dark", failing)(func(c *Context) error {
+		return c.String(http.StatusOK, "reached")
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/dark", nil))
+	if w.Code != http.StatusOK || w.Body.String() != "true false" {
+		t.Errorf("router provider: got %d %q, want 200 \"true false\"", w.Code, w.Body.String())
+	}
+
+	// Provider errors fail closed
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/failing", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("failing provider: status = %d, want 404", w.Code)
+	}
+
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if FlagEnabled(c, "dark") {
+		t.Error("FlagEnabled() without a provider = true, want false")
+	}
+}
+
+func TestEnvFlags(t *testing.T) {
+	t.Setenv("FEATURE_NEW_CHECKOUT", "true")
+	t.Setenv("FEATURE_BETA", "0")
+	t.Setenv("FEATURE_BROKEN", "maybe")
+	flags := EnvFlags("FEATURE_")
+
+	tests := []struct {
+		name    string
+		want    bool
+		wantErr bool
+	}{
+		{"new-checkout", true, false},
+		{"beta", false, false},
+		{"unset", false, false},
+		{"broken", false, true},
+	}
+	for _, tt := range tests {
+		got, err := flags.Enabled(context.Background(), tt.name)
+		if got != tt.want || (err != nil) != tt.wantErr {
+			t.Errorf("Enabled(%q) = %t, %v; want %t, error %t", tt.name, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestFileFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "flags.json")
+	write := func(data string, modTime time.Time) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, modTime, modTime); err != nil {
+			t.Fatal(err)
+		}
+	}
+	ctx := context.Background()
+	now := time.Now()
+
+	flags := FileFlags(path)
+	if _, err := flags.Enabled(ctx, "a"); err == nil {
+		t.Error("expected error for missing file")
+	}
+
+	write(`{"a": true}`, now)
+	if got, err := flags.Enabled(ctx, "a"); !got || err != nil {
+		t.Errorf("Enabled(a) = %t, %v; want true", got, err)
+	}
+
+	// Changes are picked up when the modification time changes
+	write(`{"a": false, "b": true}`, now.Add(time.Second))
+	if got, _ := flags.Enabled(ctx, "a"); got {
+		t.Error("Enabled(a) = true after update, want false")
+	}
+
+	// Invalid files keep the previous flags
+	write(`{"a": tru`, now.Add(2*time.Second))
+	if got, err := flags.Enabled(ctx, "b"); !got || err == nil {
+		t.Errorf("Enabled(b) = %t, %v; want true with error", got, err)
+	}
+}
+
+func TestHTTPFlags(t *testing.T) {
+	var requests atomic.Int32
+	var fail atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		requests.Add(1)
+		if req.Header.Get("Authorization") != "Bearer token" {
+			t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+		}
+		if fail.Load() {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"new-checkout": true}`))
+	}))
+	defer srv.Close()
+
+	provider := HTTPFlags(HTTPFlagsConfig{
+		URL:    srv.URL,
+		Header: http.Header{"Authorization": {"Bearer token"}},
+		TTL:    time.Hour,
+	})
+	ctx := context.Background()
+
+	for range 2 {
+		if got, err := provider.Enabled(ctx, "new-checkout"); !got || err != nil {
+			t.Fatalf("Enabled() = %t, %v; want true", got, err)
+		}
+	}
+	if n := requests.Load(); n != 1 {
+		t.Errorf("requests = %d, want 1 (cached)", n)
+	}
+
+	// Failed refreshes keep serving the last flags
+	fail.Store(true)
+	expireFlags(provider)
+	if got, err := provider.Enabled(ctx, "new-checkout"); !got || err != nil {
+		t.Errorf("stale Enabled() = %t, %v; want true", got, err)
+	}
+
+	failing := HTTPFlags(HTTPFlagsConfig{URL: srv.URL, Header: http.Header{"Authorization": {"Bearer token"}}})
+	if _, err := failing.Enabled(ctx, "new-checkout"); err == nil {
+		t.Error("expected error when no flags have been fetched")
+	}
+}
+
+func TestHTTPFlags_SlowEndpoint(t *testing.T) {
+	var requests atomic.Int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if requests.Add(1) > 1 {
+			<-release
+		}
+		_, _ = w.Write([]byte(`{"new-checkout": true}`))
+	}))
+	defer srv.Close()
+	defer close(release)
+
+	provider := HTTPFlags(HTTPFlagsConfig{URL: srv.URL, TTL: time.Hour})
+	if got, err := provider.Enabled(context.Background(), "new-checkout"); !got || err != nil {
+		t.Fatalf("Enabled() = %t, %v; want true", got, err)
+	}
+
+	// Expired flags are served without waiting for the refresh
+	expireFlags(provider)
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	for range 3 {
+		if got, err := provider.Enabled(ctx, "new-checkout"); !got || err != nil {
+			t.Fatalf("stale Enabled() = %t, %v; want true", got, err)
+		}
+	}
+	if ctx.Err() != nil {
+		t.Error("lookup waited for the refresh")
+	}
+
+	// A canceled lookup does not fail the first fetch for others
+	first := HTTPFlags(HTTPFlagsConfig{URL: srv.URL})
+	canceled, cancelNow := context.WithCancel(context.Background())
+	cancelNow()
+	if _, err := first.Enabled(canceled, "new-checkout"); !errors.Is(err, context.Canceled) {
+		t.Errorf("canceled Enabled() error = %v, want context.Canceled", err)
+	}
+	release <- struct{}{} // the refresh above
+	release <- struct{}{}
+	if got, err := first.Enabled(context.Background(), "new-checkout"); !got || err != nil {
+		t.Errorf("Enabled() after a canceled lookup = %t, %v; want true", got, err)
+	}
+}
+
+// expireFlags makes the flags of an HTTPFlags provider expire.
+func expireFlags(provider FlagProvider) {
+	h := provider.(*httpFlags)
+	h.mu.Lock()
+	h.expires = time.Time{}
+	h.mu.Unlock()
+}
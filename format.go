@@ -0,0 +1,351 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LocaleFormat describes how dates, numbers, and currency amounts are written
+// in a locale. Month and day names are not localized; layouts should use
+// numeric elements only.
+type LocaleFormat struct {
+	// DateLayout is the time layout for dates (e.g., "02.01.2006").
+	DateLayout string
+
+	// DateTimeLayout is the time layout for dates with a time of day.
+	DateTimeLayout string
+
+	// DecimalSeparator separates the integer and fractional parts (e.g., ",").
+	DecimalSeparator string
+
+	// GroupSeparator separates groups of thousands (e.g., ".").
+	GroupSeparator string
+
+	// CurrencySuffix places the currency symbol after the amount
+	// ("12,50 €") instead of before it ("€12.50").
+	CurrencySuffix bool
+}
+
+// builtinLocaleFormats holds formats for common locales, keyed by lowercase tag.
+var builtinLocaleFormats = map[string]LocaleFormat{
+	"en":    {"01/02/2006", "01/02/2006 3:04 PM", ".", ",", false},
+	"en-gb": {"02/01/2006", "02/01/2006 15:04", ".", ",", false},
+	"de":    {"02.01.2006", "02.01.2006 15:04", ",", ".", true},
+	"fr":    {"02/01/2006", "02/01/2006 15:04", ",", "\u00a0", true},
+	"es":    {"02/01/2006", "02/01/2006 15:04", ",", ".", true},
+	"it":    {"02/01/2006", "02/01/2006 15:04", ",", ".", true},
+	"pt":    {"02/01/2006", "02/01/2006 15:04", ",", ".", true},
+	"nl":    {"02-01-2006", "02-01-2006 15:04", ",", ".", false},
+	"pl":    {"02.01.2006", "02.01.2006 15:04", ",", "\u00a0", true},
+	"ru":    {"02.01.2006", "02.01.2006 15:04", ",", "\u00a0", true},
+	"sv":    {"2006-01-02", "2006-01-02 15:04", ",", "\u00a0", true},
+	"ja":    {"2006/01/02", "2006/01/02 15:04", ".", ",", false},
+	"zh":    {"2006/01/02", "2006/01/02 15:04", ".", ",", false},
+}
+
+var (
+	localeFormatsMu sync.RWMutex
+	localeFormats   = map[string]LocaleFormat{}
+)
+
+// RegisterLocaleFormat adds or replaces the format for a locale (e.g., "de-CH").
+// Registered formats take precedence over the built-in ones.
+// It is safe for concurrent use, but is typically called during startup.
+func RegisterLocaleFormat(locale string, format LocaleFormat) {
+	localeFormatsMu.Lock()
+	defer localeFormatsMu.Unlock()
+	localeFormats[strings.ToLower(locale)] = format
+}
+
+// lookupLocaleFormat returns the format for a single locale tag.
+func lookupLocaleFormat(locale string) (LocaleFormat, bool) {
+	localeFormatsMu.RLock()
+	f, ok := localeFormats[locale]
+	localeFormatsMu.RUnlock()
+	if ok {
+		return f, true
+	}
+	f, ok = builtinLocaleFormats[locale]
+	return f, ok
+}
+
+// currencies maps ISO 4217 codes to their symbol and minor unit digits.
+var currencies = map[string]struct {
+	symbol   string
+	decimals int
+}{
+	"USD": {"$", 2},
+	"EUR": {"€", 2},
+	"GBP": {"£", 2},
+	"JPY": {"¥", 0},
+	"CNY": {"¥", 2},
+	"INR": {"₹", 2},
+	"KRW": {"₩", 0},
+	"BRL": {"R$", 2},
+	"RUB": {"₽", 2},
+	"PLN": {"zł", 2},
+	"CHF": {"CHF", 2},
+	"SEK": {"kr", 2},
+}
+
+// Formatter formats dates, numbers, and currency amounts for a locale.
+// Get one for the current request with c.Formatter, or for any locale with
+// NewFormatter.
+type Formatter struct {
+	// Locale is the locale the formatter was created for.
+	Locale string
+
+	format LocaleFormat
+}
+
+// NewFormatter returns a Formatter for locale. Lookup order: the exact locale
+// (e.g., "pt-BR"), its base language ("pt"), and DefaultLocale.
+func NewFormatter(locale string) Formatter {
+	tag := strings.ToLower(locale)
+	if f, ok := lookupLocaleFormat(tag); ok {
+		return Formatter{Locale: locale, format: f}
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		if f, ok := lookupLocaleFormat(base); ok {
+			return Formatter{Locale: locale, format: f}
+		}
+	}
+	f, _ := lookupLocaleFormat(DefaultLocale)
+	return Formatter{Locale: locale, format: f}
+}
+
+// Formatter returns a Formatter for the request locale (see Locale).
+//
+// Example:
+//
+//	f := c.Formatter()
+//	msg := fmt.Sprintf("Order placed on %s, total %s",
+//	    f.Date(order.CreatedAt), f.Currency(order.Total, "EUR"))
+func (c *Context) Formatter() Formatter {
+	return NewFormatter(c.Locale())
+}
+
+// Date formats t as a date (e.g., "01/02/2025" in en, "02.01.2025" in de).
+func (f Formatter) Date(t time.Time) string {
+	return t.Format(f.format.DateLayout)
+}
+
+// DateTime formats t as a date with a time of day.
+func (f Formatter) DateTime(t time.Time) string {
+	return t.Format(f.format.DateTimeLayout)
+}
+
+// Number formats v with the given number of decimals, using the locale's
+// decimal and group separators (e.g., 1234.5 with 2 decimals is "1,234.50"
+// in en and "1.234,50" in de).
+func (f Formatter) Number(v float64, decimals int) string {
+	s := strconv.FormatFloat(math.Abs(v), 'f', decimals, 64)
+	intPart, fracPart, _ := strings.Cut(s, ".")
+
+	var b strings.Builder
+	if v < 0 && strings.Trim(s, "0.") != "" {
+		b.WriteByte('-')
+	}
+	for i, digit := range intPart {
+		if i > 0 && (len(intPart)-i)%3 == 0 {
+			b.WriteString(f.format.GroupSeparator)
+		}
+		b.WriteRune(digit)
+	}
+	if fracPart != "" {
+		b.WriteString(f.format.DecimalSeparator)
+		b.WriteString(fracPart)
+	}
+	return b.String()
+}
+
+// Currency formats amount in the given ISO 4217 currency (e.g., "EUR"),
+// using the currency's symbol and minor units where known.
+// Unknown currencies are written with their code and two decimals.
+func (f Formatter) Currency(amount float64, code string) string {
+	code = strings.ToUpper(code)
+	symbol, decimals := code, 2
+	if c, ok := currencies[code]; ok {
+		symbol, decimals = c.symbol, c.decimals
+	}
+
+	number := f.Number(amount, decimals)
+	if f.format.CurrencySuffix {
+		return number + "\u00a0" + symbol
+	}
+	if len(symbol) > 1 && symbol == code {
+		symbol += "\u00a0"
+	}
+	if rest, negative := strings.CutPrefix(number, "-"); negative {
+		return "-" + symbol + rest
+	}
+	return symbol + number
+}
+
+// formatValue formats v according to a `format` struct tag value:
+// "date", "datetime", "number" or "number:<decimals>", and "currency:<code>".
+func (f Formatter) formatValue(v reflect.Value, tag string) (string, bool) {
+	for v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return "", false
+		}
+		v = v.Elem()
+	}
+
+	kind, arg, _ := strings.Cut(tag, ":")
+	switch kind {
+	case "date", "datetime":
+		t, ok := v.Interface().(time.Time)
+		if !ok {
+			return "", false
+		}
+		if kind == "date" {
+			return f.Date(t), true
+		}
+		return f.DateTime(t), true
+	case "number", "currency":
+		var n float64
+		switch v.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			n = float64(v.Int())
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			n = float64(v.Uint())
+		case reflect.Float32, reflect.Float64:
+			n = v.Float()
+		default:
+			return "", false
+		}
+		if kind == "currency" {
+			return f.Currency(n, arg), true
+		}
+		decimals, err := strconv.Atoi(arg)
+		if err != nil {
+			decimals = 0
+		}
+		return f.Number(n, decimals), true
+	}
+	return "", false
+}
+
+// LocalizedJSON writes v as JSON like JSON, but struct fields tagged with
+// `format:"..."` are written as strings formatted for the request locale
+// instead of their default encoding (e.g., RFC 3339 for time.Time).
+// Supported tag values are "date", "datetime", "number" (optionally
+// "number:<decimals>"), and "currency:<code>". Object keys are written in
+// sorted order.
+//
+// Example:
+//
+//	type Invoice struct {
+//	    Number string    `json:"number"`
+//	    Issued time.Time `json:"issued" format:"date"`
+//	    Total  float64   `json:"total" format:"currency:EUR"`
+//	}
+//
+//	// Accept-Language: de → {"issued":"02.01.2025","number":"A-1","total":"1.234,50 €"}
+//	return c.LocalizedJSON(http.StatusOK, invoice)
+func (c *Context) LocalizedJSON(code int, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var tree any
+	if err := dec.Decode(&tree); err != nil {
+		return err
+	}
+
+	return c.JSON(code, c.Formatter().localize(reflect.ValueOf(v), tree))
+}
+
+// localize replaces the values of format-tagged fields in tree, the decoded
+// JSON form of v.
+func (f Formatter) localize(v reflect.Value, tree any) any {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return tree
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		if obj, ok := tree.(map[string]any); ok {
+			f.localizeStruct(v, obj)
+		}
+	case reflect.Slice, reflect.Array:
+		if items, ok := tree.([]any); ok {
+			for i := range min(v.Len(), len(items)) {
+				items[i] = f.localize(v.Index(i), items[i])
+			}
+		}
+	case reflect.Map:
+		if obj, ok := tree.(map[string]any); ok {
+			iter := v.MapRange()
+			for iter.Next() {
+				key := fmt.Sprint(iter.Key().Interface())
+				if item, ok := obj[key]; ok {
+					obj[key] = f.localize(iter.Value(), item)
+				}
+			}
+		}
+	}
+	return tree
+}
+
+// localizeStruct localizes the fields of struct v in obj, following the
+// encoding/json field naming rules.
+func (f Formatter) localizeStruct(v reflect.Value, obj map[string]any) {
+	t := v.Type()
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() && !field.Anonymous {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(jsonTag, ",")
+
+		// Embedded structs without a name are flattened into the parent object
+		if field.Anonymous && name == "" {
+			fv := v.Field(i)
+			if fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					continue
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct {
+				f.localizeStruct(fv, obj)
+				continue
+			}
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		item, ok := obj[name]
+		if !ok {
+			continue
+		}
+		if format := field.Tag.Get("format"); format != "" {
+			if s, ok := f.formatValue(v.Field(i), format); ok {
+				obj[name] = s
+			}
+			continue
+		}
+		obj[name] = f.localize(v.Field(i), item)
+	}
+}
@@ -0,0 +1,139 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFormatter(t *testing.T) {
+	ts := time.Date(2025, time.March, 4, 17, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		locale   string
+		date     string
+		dateTime string
+		number   string
+		currency string
+	}{
+		{"en", "03/04/2025", "03/04/2025 5:30 PM", "1,234,567.89", "€1,234,567.89"},
+		{"en-GB", "04/03/2025", "04/03/2025 17:30", "1,234,567.89", "€1,234,567.89"},
+		{"de-AT", "04.03.2025", "04.03.2025 17:30", "1.234.567,89", "1.234.567,89\u00a0€"},
+		{"fr", "04/03/2025", "04/03/2025 17:30", "1\u00a0234\u00a0567,89", "1\u00a0234\u00a0567,89\u00a0€"},
+		{"xx", "03/04/2025", "03/04/2025 5:30 PM", "1,234,567.89", "€1,234,567.89"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			f := NewFormatter(tt.locale)
+			if got := f.Date(ts); got != tt.date {
+				t.Errorf("Date() = %q, want %q", got, tt.date)
+			}
+			if got := f.DateTime(ts); got != tt.dateTime {
+				t.Errorf("DateTime() = %q, want %q", got, tt.dateTime)
+			}
+			if got := f.Number(1234567.891, 2); got != tt.number {
+				t.Errorf("Number() = %q, want %q", got, tt.number)
+			}
+			if got := f.Currency(1234567.891, "eur"); got != tt.currency {
+				t.Errorf("Currency() = %q, want %q", got, tt.currency)
+			}
+		})
+	}
+}
+
+func TestFormatter_NumberEdgeCases(t *testing.T) {
+	f := NewFormatter("en")
+
+	tests := []struct {
+		v        float64
+		decimals int
+		want     string
+	}{
+		{0, 0, "0"},
+		{999, 0, "999"},
+		{1000, 0, "1,000"},
+		{-1234.5, 1, "-1,234.5"},
+		{-0.001, 2, "0.00"},
+	}
+	for _, tt := range tests {
+		if got := f.Number(tt.v, tt.decimals); got != tt.want {
+			t.Errorf("Number(%v, %d) = %q, want %q", tt.v, tt.decimals, got, tt.want)
+		}
+	}
+
+	if got := f.Currency(1500, "JPY"); got != "¥1,500" {
+		t.Errorf("Currency(JPY) = %q, want %q", got, "¥1,500")
+	}
+	if got := f.Currency(-12.5, "USD"); got != "-$12.50" {
+		t.Errorf("Currency(negative) = %q, want %q", got, "-$12.50")
+	}
+	if got := f.Currency(12.5, "XYZ"); got != "XYZ\u00a012.50" {
+		t.Errorf("Currency(unknown) = %q, want %q", got, "XYZ\u00a012.50")
+	}
+}
+
+func TestRegisterLocaleFormat(t *testing.T) {
+	RegisterLocaleFormat("de-CH", LocaleFormat{
+		DateLayout:       "02.01.2006",
+		DateTimeLayout:   "02.01.2006 15:04",
+		DecimalSeparator: ".",
+		GroupSeparator:   "'",
+		CurrencySuffix:   false,
+	})
+
+	if got := NewFormatter("de-ch").Number(1234.5, 2); got != "1'234.50" {
+		t.Errorf("Number() = %q, want %q", got, "1'234.50")
+	}
+	if got := NewFormatter("de").Number(1234.5, 2); got != "1.234,50" {
+		t.Errorf("built-in de format changed: %q", got)
+	}
+}
+
+func TestContext_LocalizedJSON(t *testing.T) {
+	type Line struct {
+		SKU   string  `json:"sku"`
+		Price float64 `json:"price" format:"currency:EUR"`
+	}
+	type Audit struct {
+		Updated time.Time `json:"updated" format:"datetime"`
+	}
+	type Invoice struct {
+		Audit
+		Number  string     `json:"number"`
+		Issued  time.Time  `json:"issued" format:"date"`
+		Paid    *time.Time `json:"paid,omitempty" format:"date"`
+		Weight  int        `json:"weight" format:"number"`
+		Raw     time.Time  `json:"raw"`
+		Lines   []Line     `json:"lines"`
+		Secret  string     `json:"-"`
+		Comment string
+	}
+
+	ts := time.Date(2025, time.March, 4, 17, 30, 0, 0, time.UTC)
+	invoice := Invoice{
+		Audit:   Audit{Updated: ts},
+		Number:  "A-1",
+		Issued:  ts,
+		Weight:  12000,
+		Raw:     ts,
+		Lines:   []Line{{SKU: "X1", Price: 1234.5}},
+		Comment: "ok",
+	}
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Language", "de-DE,de;q=0.9")
+	c := newContext(w, req)
+
+	if err := c.LocalizedJSON(http.StatusOK, &invoice); err != nil {
+		t.Fatalf("LocalizedJSON() error = %v", err)
+	}
+
+	want := `{"Comment":"ok","issued":"04.03.2025","lines":[{"price":"1.234,50` + "\u00a0" + `€","sku":"X1"}],` +
+		`"number":"A-1","raw":"2025-03-04T17:30:00Z","updated":"04.03.2025 17:30","weight":"12.000"}` + "\n"
+	if w.Body.String() != want {
+		t.Errorf("body =\n%s\nwant\n%s", w.Body.String(), want)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"net/http"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -50,6 +51,7 @@ type Health struct {
 	readiness []healthCheck
 	liveness  []healthCheck
 	config    HealthConfig
+	warmingUp atomic.Bool
 }
 
 // NewHealth creates a new Health manager with default configuration.
@@ -126,8 +128,26 @@ func (h *Health) LiveHandler() HandlerFunc {
 }
 
 // ReadyHandler returns a Rig HandlerFunc for readiness probes.
+// While the application is warming up (see SetWarmingUp), it reports
+// 503 Service Unavailable without running the readiness checks.
 func (h *Health) ReadyHandler() HandlerFunc {
-	return h.handle(&h.readiness)
+	checks := h.handle(&h.readiness)
+	return func(c *Context) error {
+		if h.warmingUp.Load() {
+			return c.JSON(http.StatusServiceUnavailable, map[string]any{
+				"status": http.StatusText(http.StatusServiceUnavailable),
+				"checks": map[string]string{"warmup": "FAIL: warming up"},
+			})
+		}
+		return checks(c)
+	}
+}
+
+// SetWarmingUp marks the application as warming up (not ready) or done.
+// The server calls it automatically when ServerConfig.Health is set and
+// warm-up is configured.
+func (h *Health) SetWarmingUp(warming bool) {
+	h.warmingUp.Store(warming)
 }
 
 // checkResult holds the result of a single health check.
@@ -0,0 +1,116 @@
+package rig
+
+import (
+	"net/http"
+	"time"
+)
+
+// IdentityKey is the context key under which authentication middleware
+// stores the authenticated identity (see the auth package). ResponseEvent
+// reports it as Identity.
+const IdentityKey = "auth.identity"
+
+// ResponseEvent describes a completed request. It is passed to the hooks
+// registered with Router.OnResponse.
+type ResponseEvent struct {
+	// Request is the request that was served. Its body has been consumed.
+	Request *http.Request
+
+	// Route is the matched route pattern (e.g., "GET /users/{id}"), or empty
+	// if no route matched.
+	Route string
+
+	// Status is the response status code. It is 500 if the handler panicked
+	// before writing a response.
+	Status int
+
+	// Size is the number of response body bytes written.
+	Size int64
+
+	// Duration is the time from the start of routing until the handler
+	// returned.
+	Duration time.Duration
+
+	// Identity is the authenticated identity stored under IdentityKey, if any.
+	Identity string
+
+	// Owner is the team owning the route (see Owner), if any.
+	Owner string
+
+	// Err is the error returned by the handler, if any.
+	Err error
+
+	// Panic is the value the handler panicked with, if any. The panic is
+	// re-raised after the hooks run.
+	Panic any
+
+	// Context is the handler's context, or nil if no route matched.
+	// Values set with c.Set are available through it.
+	Context *Context
+}
+
+// ResponseHook is called once for every request after the response has been
+// written. See Router.OnResponse.
+type ResponseHook func(ResponseEvent)
+
+// OnResponse registers a hook that is called exactly once for every request
+// served by the router, after the handler has returned, including requests
+// that matched no route and requests whose handler panicked. It is the single
+// integration point for audit logs, billing metering, and analytics.
+//
+// Hooks run synchronously in registration order on the request goroutine,
+// after the response has been written but before the connection is reused;
+// hand slow work (e.g., writing to an outbox table) off to a queue.
+//
+// Example:
+//
+//	r.OnResponse(func(e rig.ResponseEvent) {
+//	    metering.Record(e.Identity, e.Route, e.Status, e.Size, e.Duration)
+//	})
+func (r *Router) OnResponse(hook ResponseHook) {
+	r.responseHooks = append(r.responseHooks, hook)
+}
+
+// serveWithHooks serves req while tracking the response, then calls the
+// response hooks, even if the handler panics.
+func (r *Router) serveWithHooks(w http.ResponseWriter, req *http.Request) {
+	start := time.Now()
+	tw := &trackingWriter{ResponseWriter: w}
+
+	defer func() {
+		p := recover()
+		event := ResponseEvent{
+			Request:  req,
+			Route:    req.Pattern,
+			Status:   tw.status,
+			Size:     tw.size,
+			Duration: time.Since(start),
+			Err:      tw.err,
+			Panic:    p,
+			Context:  tw.ctx,
+		}
+		if tw.ctx != nil {
+			event.Request = tw.ctx.request
+			event.Route = tw.ctx.request.Pattern
+			if identity, ok := tw.ctx.Get(IdentityKey); ok {
+				event.Identity, _ = identity.(string)
+			}
+			event.Owner = OwnerOf(tw.ctx)
+		}
+		if event.Status == 0 {
+			event.Status = http.StatusOK
+			if p != nil {
+				event.Status = http.StatusInternalServerError
+			}
+		}
+
+		for _, hook := range r.responseHooks {
+			hook(event)
+		}
+		if p != nil {
+			panic(p)
+		}
+	}()
+
+	r.serve(tw, req)
+}
@@ -0,0 +1,116 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRouter_OnResponse(t *testing.T) {
+	r := New()
+	var events []ResponseEvent
+	r.OnResponse(func(e ResponseEvent) { events = append(events, e) })
+
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(IdentityKey, "user-42")
+			return next(c)
+		}
+	})
+	r.GET("/users/{id}", func(c *Context) error {
+		return c.String(http.StatusCreated, "hello")
+	})
+	r.GET("/fail", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users/1", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fail", nil))
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+
+	if len(events) != 3 {
+		t.Fatalf("got %d events, want 3", len(events))
+	}
+
+	e := events[0]
+	if e.Route != "GET /users/{id}" || e.Status != http.StatusCreated || e.Size != 5 ||
+		e.Identity != "user-42" || e.Context == nil || e.Err != nil {
+		t.Errorf("matched route event = %+v", e)
+	}
+	if e.Duration <= 0 {
+		t.Errorf("Duration = %v, want > 0", e.Duration)
+	}
+
+	e = events[1]
+	if e.Status != http.StatusInternalServerError || e.Err == nil || e.Err.Error() != "boom" {
+		t.Errorf("error event = %+v", e)
+	}
+
+	e = events[2]
+	if e.Route != "" || e.Status != http.StatusNotFound || e.Context != nil {
+		t.Errorf("unmatched event = %+v", e)
+	}
+}
+
+func TestRouter_OnResponse_Panic(t *testing.T) {
+	r := New()
+	var events []ResponseEvent
+	r.OnResponse(func(e ResponseEvent) { events = append(events, e) })
+	r.GET("/panic", func(c *Context) error {
+		panic("kaboom")
+	})
+
+	func() {
+		defer func() {
+			if p := recover(); p != "kaboom" {
+				t.Errorf("panic should be re-raised, got %v", p)
+			}
+		}()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+	}()
+
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	if events[0].Panic != "kaboom" || events[0].Status != http.StatusInternalServerError {
+		t.Errorf("panic event = %+v", events[0])
+	}
+}
+
+func TestRouter_OnResponse_WithRecover(t *testing.T) {
+	r := New()
+	var events []ResponseEvent
+	r.OnResponse(func(e ResponseEvent) { events = append(events, e) })
+	r.Use(RecoverWithConfig(RecoverConfig{Logger: func(any, []byte) {}}))
+	r.GET("/panic", func(c *Context) error {
+		panic("kaboom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if len(events) != 1 || events[0].Status != http.StatusInternalServerError || events[0].Panic != nil {
+		t.Errorf("events = %+v", events)
+	}
+}
+
+func TestRouter_OnResponse_KeepsWriterInterfaces(t *testing.T) {
+	dir := writeStaticFile(t, "big.txt", strings.Repeat("x", 4096))
+
+	r := New()
+	var size int64
+	r.OnResponse(func(e ResponseEvent) { size = e.Size })
+	r.Static("/assets/", dir)
+
+	w := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/big.txt", nil))
+
+	if !w.readFromCalled {
+		t.Error("ReadFrom of the underlying writer should be used")
+	}
+	if size != 4096 {
+		t.Errorf("Size = %d, want 4096", size)
+	}
+}
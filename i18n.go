@@ -0,0 +1,158 @@
+package rig
+
+import (
+	"net/http"
+	"strings"
+)
+
+// Message keys for responses produced by rig itself.
+// Register translations for these keys in a Catalog to localize them.
+const (
+	MsgInternalServerError = "rig.internal_server_error"
+	MsgRequestTimeout      = "rig.request_timeout"
+	MsgNotFound            = "rig.not_found"
+	MsgMethodNotAllowed    = "rig.method_not_allowed"
+	MsgTooManyRequests     = "rig.too_many_requests"
+	MsgForbidden           = "rig.forbidden"
+	MsgServiceUnavailable  = "rig.service_unavailable"
+	MsgUnauthorized        = "rig.unauthorized"
+)
+
+// DefaultLocale is the locale used when a request does not specify one
+// and the locale rig falls back to when a translation is missing.
+const DefaultLocale = "en"
+
+// localeKey is the context store key holding the locale set via SetLocale.
+const localeKey = "rig.locale"
+
+// Translator translates message keys into a given locale.
+// It returns false if no translation exists for the key and locale.
+type Translator interface {
+	Translate(locale, key string) (string, bool)
+}
+
+// Catalog is a simple in-memory Translator keyed by locale, then message key.
+//
+// Example:
+//
+//	catalog := rig.Catalog{
+//	    "de": {
+//	        rig.MsgInternalServerError: "Interner Serverfehler",
+//	        rig.MsgNotFound:            "Nicht gefunden",
+//	    },
+//	}
+//	r.SetTranslator(catalog)
+type Catalog map[string]map[string]string
+
+// Translate implements Translator.
+func (c Catalog) Translate(locale, key string) (string, bool) {
+	msg, ok := c[locale][key]
+	return msg, ok
+}
+
+// SetTranslator sets the Translator used to localize messages produced by
+// rig (error handler, Recover, Timeout, and 404/405 responses) and by
+// handlers calling c.Translate. Messages fall back to English when no
+// translation exists for the request locale.
+func (r *Router) SetTranslator(t Translator) {
+	r.translator = t
+}
+
+// SetLocale overrides the locale used to translate messages for this request.
+// Use this from middleware that resolves the locale from a user profile,
+// cookie, or URL prefix.
+func (c *Context) SetLocale(locale string) {
+	c.Set(localeKey, locale)
+}
+
+// Locale returns the locale for this request.
+// It returns the locale set via SetLocale, or the first language tag of the
+// Accept-Language header, or DefaultLocale if neither is present.
+func (c *Context) Locale() string {
+	if v, ok := c.Get(localeKey); ok {
+		if locale, ok := v.(string); ok && locale != "" {
+			return locale
+		}
+	}
+	if locale := primaryLanguage(c.GetHeader("Accept-Language")); locale != "" {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// Translate returns the message for key in the request locale.
+// Lookup order: the exact locale (e.g., "pt-BR"), its base language ("pt"),
+// DefaultLocale, and finally the given fallback text.
+//
+// Example:
+//
+//	return c.JSON(http.StatusBadRequest, map[string]string{
+//	    "error": c.Translate("orders.invalid_quantity", "Invalid quantity"),
+//	})
+func (c *Context) Translate(key, fallback string) string {
+	if c.router == nil || c.router.translator == nil {
+		return fallback
+	}
+	translator := c.router.translator
+
+	locale := c.Locale()
+	if msg, ok := translator.Translate(locale, key); ok {
+		return msg
+	}
+	if base, _, found := strings.Cut(locale, "-"); found {
+		if msg, ok := translator.Translate(base, key); ok {
+			return msg
+		}
+	}
+	if msg, ok := translator.Translate(DefaultLocale, key); ok {
+		return msg
+	}
+	return fallback
+}
+
+// primaryLanguage returns the first language tag in an Accept-Language header,
+// ignoring quality values and the "*" wildcard.
+func primaryLanguage(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	tag, _, _ := strings.Cut(first, ";")
+	tag = strings.TrimSpace(tag)
+	if tag == "*" {
+		return ""
+	}
+	return tag
+}
+
+// localizedErrorWriter replaces the plain-text body of the 404 and 405
+// responses written by http.ServeMux with a translated message.
+type localizedErrorWriter struct {
+	http.ResponseWriter
+	ctx      *Context
+	replaced bool
+}
+
+// WriteHeader intercepts 404 and 405 status codes and writes the translated body.
+func (w *localizedErrorWriter) WriteHeader(code int) {
+	var msg string
+	switch code {
+	case http.StatusNotFound:
+		msg = w.ctx.Translate(MsgNotFound, "404 page not found")
+	case http.StatusMethodNotAllowed:
+		msg = w.ctx.Translate(MsgMethodNotAllowed, http.StatusText(code))
+	default:
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+
+	w.replaced = true
+	w.ResponseWriter.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(code)
+	_, _ = w.ResponseWriter.Write([]byte(msg + "\n"))
+}
+
+// Write discards the original body once it has been replaced.
+func (w *localizedErrorWriter) Write(b []byte) (int, error) {
+	if w.replaced {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
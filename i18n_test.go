@@ -0,0 +1,154 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+var testCatalog = Catalog{
+	"en": {
+		MsgNotFound: "Not Found",
+	},
+	"de": {
+		MsgInternalServerError: "Interner Serverfehler",
+		MsgNotFound:            "Nicht gefunden",
+		MsgMethodNotAllowed:    "Methode nicht erlaubt",
+	},
+}
+
+func TestContext_Locale(t *testing.T) {
+	tests := []struct {
+		name   string
+		header string
+		set    string
+		want   string
+	}{
+		{"default", "", "", DefaultLocale},
+		{"accept-language", "de-DE,de;q=0.9,en;q=0.8", "", "de-DE"},
+		{"wildcard", "*", "", DefaultLocale},
+		{"explicit", "de", "fr", "fr"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set("Accept-Language", tt.header)
+			}
+			c := newContext(httptest.NewRecorder(), req)
+			if tt.set != "" {
+				c.SetLocale(tt.set)
+			}
+
+			if got := c.Locale(); got != tt.want {
+				t.Errorf("Locale() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_Translate(t *testing.T) {
+	tests := []struct {
+		name   string
+		locale string
+		key    string
+		want   string
+	}{
+		{"exact locale", "de", MsgNotFound, "Nicht gefunden"},
+		{"base language", "de-AT", MsgNotFound, "Nicht gefunden"},
+		{"english fallback", "fr", MsgNotFound, "Not Found"},
+		{"text fallback", "fr", "unknown.key", "fallback"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+			c.router = &Router{translator: testCatalog}
+			c.SetLocale(tt.locale)
+
+			if got := c.Translate(tt.key, "fallback"); got != tt.want {
+				t.Errorf("Translate() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContext_Translate_NoTranslator(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if got := c.Translate(MsgNotFound, "fallback"); got != "fallback" {
+		t.Errorf("Translate() = %q, want %q", got, "fallback")
+	}
+}
+
+func TestRouter_SetTranslator_ErrorHandler(t *testing.T) {
+	r := New()
+	r.SetTranslator(testCatalog)
+	r.GET("/fail", func(c *Context) error {
+		return errors.New("boom")
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/fail", nil)
+	req.Header.Set("Accept-Language", "de")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if w.Body.String() != "Interner Serverfehler" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "Interner Serverfehler")
+	}
+}
+
+func TestRouter_SetTranslator_NotFoundAndMethodNotAllowed(t *testing.T) {
+	r := New()
+	r.SetTranslator(testCatalog)
+	r.GET("/users", func(c *Context) error { return nil })
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		lang       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"404 de", http.MethodGet, "/missing", "de", http.StatusNotFound, "Nicht gefunden"},
+		{"404 en", http.MethodGet, "/missing", "", http.StatusNotFound, "Not Found"},
+		{"405 de", http.MethodPost, "/users", "de", http.StatusMethodNotAllowed, "Methode nicht erlaubt"},
+		{"405 fallback", http.MethodPost, "/users", "fr", http.StatusMethodNotAllowed, "Method Not Allowed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := strings.TrimSpace(w.Body.String()); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRouter_NoTranslator_NotFoundUnchanged(t *testing.T) {
+	r := New()
+
+	req := httptest.NewRequest(http.MethodGet, "/missing", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if got := strings.TrimSpace(w.Body.String()); got != "404 page not found" {
+		t.Errorf("body = %q, want %q", got, "404 page not found")
+	}
+}
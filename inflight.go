@@ -0,0 +1,216 @@
+package rig
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// MaxInFlightConfig defines the configuration for MaxInFlight middleware.
+type MaxInFlightConfig struct {
+	// Limit is the maximum number of requests handled at the same time for
+	// each key. Required.
+	Limit int
+
+	// Queue is the number of requests per key that may wait for a free slot
+	// once Limit is reached. Further requests are rejected right away.
+	// Default: 0 (no waiting).
+	Queue int
+
+	// Timeout is how long a queued request waits for a slot before it is
+	// rejected.
+	// Default: 1 second.
+	Timeout time.Duration
+
+	// KeyFunc returns the key whose requests share a limit, such as the
+	// route or a tenant ID. Requests for which it returns an empty string
+	// are not limited.
+	// Default: a single limit for all requests.
+	KeyFunc func(c *Context) string
+
+	// RetryAfter is the delay suggested to rejected clients in the
+	// Retry-After header.
+	// Default: 1 second.
+	RetryAfter time.Duration
+
+	// OnLimit is called when a request is rejected, after the Retry-After
+	// header has been set.
+	// Default: a JSON response with 503 Service Unavailable.
+	OnLimit func(c *Context) error
+
+	// Skipper selects requests that are not limited and do not take a slot,
+	// such as health checks.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// MaxInFlight creates middleware that bounds the number of requests handled
+// at the same time to n, protecting slow dependencies (databases, upstream
+// APIs) from overload. Up to queue requests wait at most timeout for a free
+// slot; the rest are answered with 503 Service Unavailable and a
+// Retry-After header. It panics if n is not positive.
+//
+// Registered with Use, the limit is shared by the routes of the router or
+// group; wrap a handler to limit a single route. Use MaxInFlightWithConfig
+// to limit per key.
+//
+// Example:
+//
+//	// At most 100 concurrent requests; 50 more may wait up to 2 seconds
+//	r.Use(rig.MaxInFlight(100, 50, 2*time.Second))
+//
+//	// At most 4 concurrent report exports
+//	r.POST("/reports/export", rig.MaxInFlight(4, 0, 0)(exportReport))
+func MaxInFlight(n, queue int, timeout time.Duration) MiddlewareFunc {
+	return MaxInFlightWithConfig(MaxInFlightConfig{Limit: n, Queue: queue, Timeout: timeout})
+}
+
+// MaxInFlightWithConfig creates concurrency limiting middleware with custom
+// configuration. See MaxInFlight for the behavior.
+//
+// Example:
+//
+//	// At most 10 concurrent requests per route, and per tenant
+//	r.Use(rig.MaxInFlightWithConfig(rig.MaxInFlightConfig{
+//	    Limit:   10,
+//	    Queue:   20,
+//	    KeyFunc: rig.InFlightByRoute,
+//	}))
+//	api.Use(rig.MaxInFlightWithConfig(rig.MaxInFlightConfig{
+//	    Limit:   10,
+//	    KeyFunc: rig.RateLimitByHeader("X-Tenant-ID"),
+//	}))
+func MaxInFlightWithConfig(config MaxInFlightConfig) MiddlewareFunc {
+	if config.Limit <= 0 {
+		panic("rig: MaxInFlight requires a positive Limit")
+	}
+	if config.Queue < 0 {
+		config.Queue = 0
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = time.Second
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = func(*Context) string { return "*" }
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.OnLimit == nil {
+		config.OnLimit = func(c *Context) error {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{
+				"error": c.Translate(MsgServiceUnavailable, "service unavailable"),
+			})
+		}
+	}
+
+	limiter := &inFlightLimiter{
+		config: &config,
+		slots:  make(map[string]*inFlightSlots),
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			slots, ok := limiter.acquire(c, key)
+			if !ok {
+				c.SetHeader("Retry-After", ceilSeconds(config.RetryAfter))
+				return config.OnLimit(c)
+			}
+			defer limiter.release(key, slots)
+			return next(c)
+		}
+	}
+}
+
+// InFlightByRoute is a MaxInFlightConfig.KeyFunc that gives every route its
+// own limit.
+func InFlightByRoute(c *Context) string {
+	return c.Request().Pattern
+}
+
+// inFlightLimiter tracks the requests in flight for each key.
+type inFlightLimiter struct {
+	config *MaxInFlightConfig
+
+	mu    sync.Mutex
+	slots map[string]*inFlightSlots
+}
+
+// inFlightSlots is the semaphore of one key. Keys without requests are
+// removed, so memory use is bounded by the number of requests in flight.
+type inFlightSlots struct {
+	sem     chan struct{}
+	waiting int // requests queued for a slot
+	users   int // requests holding or waiting for a slot
+}
+
+// acquire takes a slot for key, queueing if allowed, and reports whether it
+// succeeded.
+func (l *inFlightLimiter) acquire(c *Context, key string) (*inFlightSlots, bool) {
+	l.mu.Lock()
+	s, ok := l.slots[key]
+	if !ok {
+		s = &inFlightSlots{sem: make(chan struct{}, l.config.Limit)}
+		l.slots[key] = s
+	}
+	s.users++
+
+	select {
+	case s.sem <- struct{}{}:
+		l.mu.Unlock()
+		return s, true
+	default:
+	}
+	if s.waiting >= l.config.Queue {
+		l.done(key, s)
+		l.mu.Unlock()
+		return nil, false
+	}
+	s.waiting++
+	l.mu.Unlock()
+
+	timer := time.NewTimer(l.config.Timeout)
+	defer timer.Stop()
+	var acquired bool
+	select {
+	case s.sem <- struct{}{}:
+		acquired = true
+	case <-timer.C:
+	case <-c.Context().Done():
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	s.waiting--
+	if !acquired {
+		l.done(key, s)
+		return nil, false
+	}
+	return s, true
+}
+
+// release frees the slot of a finished request.
+func (l *inFlightLimiter) release(key string, s *inFlightSlots) {
+	<-s.sem
+	l.mu.Lock()
+	l.done(key, s)
+	l.mu.Unlock()
+}
+
+// done drops a request from s, removing the key once it is unused. The
+// caller must hold l.mu.
+func (l *inFlightLimiter) done(key string, s *inFlightSlots) {
+	s.users--
+	if s.users == 0 {
+		delete(l.slots, key)
+	}
+}
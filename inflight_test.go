@@ -0,0 +1,152 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// blockingRouter returns a router whose /slow handler blocks until release
+// is closed, and a channel receiving a value each time it starts.
+func blockingRouter(mw MiddlewareFunc) (*Router, chan struct{}, chan struct{}) {
+	started := make(chan struct{}, 10)
+	release := make(chan struct{})
+	r := New()
+	r.Use(mw)
+	r.GET("/slow/{id}", func(c *Context) error {
+		started <- struct{}{}
+		<-release
+		return c.String(http.StatusOK, "done")
+	})
+	r.GET("/fast", func(c *Context) error {
+		return c.String(http.StatusOK, "fast")
+	})
+	return r, started, release
+}
+
+func serveAsync(r *Router, target string, wg *sync.WaitGroup) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	}()
+	return w
+}
+
+func TestMaxInFlight(t *testing.T) {
+	r, started, release := blockingRouter(MaxInFlight(2, 0, 0))
+
+	var wg sync.WaitGroup
+	first := serveAsync(r, "/slow/1", &wg)
+	second := serveAsync(r, "/slow/2", &wg)
+	<-started
+	<-started
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("saturated: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w.Body.String() != `{"error":"service unavailable"}`+"\n" {
+		t.Errorf("saturated: body = %q", w.Body.String())
+	}
+
+	close(release)
+	wg.Wait()
+	if first.Code != http.StatusOK || second.Code != http.StatusOK {
+		t.Errorf("in-flight requests: status = %d, %d", first.Code, second.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("after release: status = %d", w.Code)
+	}
+}
+
+func TestMaxInFlight_Queue(t *testing.T) {
+	r, started, release := blockingRouter(MaxInFlight(1, 1, 5*time.Second))
+
+	var wg sync.WaitGroup
+	first := serveAsync(r, "/slow/1", &wg)
+	<-started
+
+	// Of two more requests, one is queued and the other overflows the queue
+	codes := make(chan int, 2)
+	for range 2 {
+		go func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+			codes <- w.Code
+		}()
+	}
+	if code := <-codes; code != http.StatusServiceUnavailable {
+		t.Errorf("overflow: status = %d, want 503", code)
+	}
+
+	close(release)
+	wg.Wait()
+	if code := <-codes; code != http.StatusOK || first.Code != http.StatusOK {
+		t.Errorf("first status = %d, queued status = %d, want both 200", first.Code, code)
+	}
+}
+
+func TestMaxInFlight_QueueTimeout(t *testing.T) {
+	r, started, release := blockingRouter(MaxInFlight(1, 1, 20*time.Millisecond))
+	defer close(release)
+
+	var wg sync.WaitGroup
+	serveAsync(r, "/slow/1", &wg)
+	<-started
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want 503 after queue timeout", w.Code)
+	}
+}
+
+func TestMaxInFlightWithConfig_PerKey(t *testing.T) {
+	limited := 0
+	r, started, release := blockingRouter(MaxInFlightWithConfig(MaxInFlightConfig{
+		Limit:   1,
+		KeyFunc: InFlightByRoute,
+		OnLimit: func(c *Context) error {
+			limited++
+			return c.String(http.StatusServiceUnavailable, "busy")
+		},
+	}))
+
+	var wg sync.WaitGroup
+	serveAsync(r, "/slow/1", &wg)
+	<-started
+
+	// Another route has its own limit
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("other route: status = %d", w.Code)
+	}
+
+	// The same route, with another parameter, shares the limit
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/slow/2", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "busy" || limited != 1 {
+		t.Errorf("same route: status = %d, body = %q, OnLimit calls = %d", w.Code, w.Body.String(), limited)
+	}
+
+	close(release)
+	wg.Wait()
+}
+
+func TestMaxInFlight_InvalidLimit(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MaxInFlight(0, ...) did not panic")
+		}
+	}()
+	MaxInFlight(0, 0, 0)
+}
@@ -0,0 +1,96 @@
+package rig
+
+import (
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// startTime is when the process started (approximately: when the package
+// was initialized).
+var startTime = time.Now()
+
+// BuildInfo is the JSON document served by InfoHandler.
+type BuildInfo struct {
+	// Version is the main module version, or InfoConfig.Version if set.
+	// Builds from a local checkout report "(devel)".
+	Version string `json:"version"`
+
+	// Revision, RevisionTime, and Modified describe the VCS commit the
+	// binary was built from (vcs.revision, vcs.time, and vcs.modified).
+	Revision     string `json:"revision,omitempty"`
+	RevisionTime string `json:"revision_time,omitempty"`
+	Modified     bool   `json:"modified,omitempty"`
+
+	// GoVersion is the Go version the binary was built with.
+	GoVersion string `json:"go_version"`
+
+	// StartTime is when the process started; Uptime is the time since.
+	StartTime     time.Time `json:"start_time"`
+	Uptime        string    `json:"uptime"`
+	UptimeSeconds int64     `json:"uptime_seconds"`
+
+	// Extra holds the InfoConfig.Extra values, if any.
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+// InfoConfig defines the configuration for InfoHandler.
+type InfoConfig struct {
+	// Version overrides the module version, e.g., with a release tag set
+	// at build time through -ldflags "-X main.version=v1.2.3".
+	// Default: the main module version from runtime/debug.ReadBuildInfo.
+	Version string
+
+	// Extra adds custom fields (e.g., environment or region).
+	Extra map[string]string
+}
+
+// InfoHandler returns a handler that serves build and runtime information
+// as JSON: version, VCS revision (from runtime/debug.ReadBuildInfo), Go
+// version, start time, and uptime. It is opt-in; mount it where platform
+// tooling expects it, typically /info or /version.
+//
+// Example:
+//
+//	r.GET("/version", rig.InfoHandler(rig.InfoConfig{Version: version}))
+//
+//	// {"version":"v1.2.3","revision":"4f2a9c1...","go_version":"go1.25.0",
+//	//  "start_time":"2025-01-15T10:30:45Z","uptime":"2h5m0s","uptime_seconds":7500}
+func InfoHandler(config ...InfoConfig) HandlerFunc {
+	cfg := InfoConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+
+	// Build information does not change, so read it once
+	info := BuildInfo{
+		Version:   cfg.Version,
+		GoVersion: runtime.Version(),
+		StartTime: startTime,
+		Extra:     cfg.Extra,
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.time":
+				info.RevisionTime = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+
+	return func(c *Context) error {
+		result := info
+		uptime := time.Since(startTime)
+		result.Uptime = uptime.Round(time.Second).String()
+		result.UptimeSeconds = int64(uptime.Seconds())
+		return c.JSON(http.StatusOK, result)
+	}
+}
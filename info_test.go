@@ -0,0 +1,56 @@
+package rig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"testing"
+)
+
+func TestInfoHandler(t *testing.T) {
+	r := New()
+	r.GET("/version", InfoHandler(InfoConfig{
+		Version: "v1.2.3",
+		Extra:   map[string]string{"region": "eu-west-1"},
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/version", nil))
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	var info BuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if info.Version != "v1.2.3" {
+		t.Errorf("Version = %q, want v1.2.3", info.Version)
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if !info.StartTime.Equal(startTime) || info.Uptime == "" || info.UptimeSeconds < 0 {
+		t.Errorf("StartTime = %v, Uptime = %q", info.StartTime, info.Uptime)
+	}
+	if info.Extra["region"] != "eu-west-1" {
+		t.Errorf("Extra = %v", info.Extra)
+	}
+}
+
+func TestInfoHandler_DefaultVersion(t *testing.T) {
+	r := New()
+	r.GET("/info", InfoHandler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/info", nil))
+
+	var info BuildInfo
+	if err := json.Unmarshal(w.Body.Bytes(), &info); err != nil {
+		t.Fatalf("failed to parse response: %v", err)
+	}
+	if info.GoVersion == "" || info.Extra != nil {
+		t.Errorf("info = %+v", info)
+	}
+}
@@ -0,0 +1,68 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestIPFilter(t *testing.T) {
+	tests := []struct {
+		name       string
+		config     IPFilterConfig
+		remoteAddr string
+		forwarded  string
+		code       int
+	}{
+		{"allowed range", IPFilterConfig{Allow: []string{"10.0.0.0/8"}}, "10.1.2.3:1234", "", http.StatusOK},
+		{"allowed address", IPFilterConfig{Allow: []string{"203.0.113.7"}}, "203.0.113.7:1234", "", http.StatusOK},
+		{"not allowed", IPFilterConfig{Allow: []string{"10.0.0.0/8"}}, "192.0.2.1:1234", "", http.StatusForbidden},
+		{"deny wins", IPFilterConfig{Allow: []string{"10.0.0.0/8"}, Deny: []string{"10.9.0.0/16"}}, "10.9.1.1:1234", "", http.StatusForbidden},
+		{"deny only", IPFilterConfig{Deny: []string{"192.0.2.0/24"}}, "198.51.100.1:1234", "", http.StatusOK},
+		{"ipv6", IPFilterConfig{Allow: []string{"2001:db8::/32"}}, "[2001:db8::1]:1234", "", http.StatusOK},
+		{"ipv4-mapped", IPFilterConfig{Allow: []string{"10.0.0.0/8"}}, "[::ffff:10.0.0.1]:1234", "", http.StatusOK},
+		{"unknown address", IPFilterConfig{Allow: []string{"10.0.0.0/8"}}, "@", "", http.StatusForbidden},
+		{"forwarded by trusted proxy", IPFilterConfig{Allow: []string{"203.0.113.0/24"}, TrustedProxies: []string{"10.0.0.1"}},
+			"10.0.0.1:1234", "203.0.113.7", http.StatusOK},
+		{"forwarded by untrusted peer", IPFilterConfig{Allow: []string{"203.0.113.0/24"}, TrustedProxies: []string{"10.0.0.1"}},
+			"192.0.2.1:1234", "203.0.113.7", http.StatusForbidden},
+		{"forwarded without trusted proxies", IPFilterConfig{Allow: []string{"203.0.113.0/24"}},
+			"10.0.0.1:1234", "203.0.113.7", http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Use(IPFilter(tt.config))
+			r.GET("/admin", func(c *Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/admin", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("X-Forwarded-For", tt.forwarded)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.code {
+				t.Errorf("status = %d, want %d", w.Code, tt.code)
+			}
+			if tt.code == http.StatusForbidden && w.Body.String() != `{"error":"forbidden"}`+"\n" {
+				t.Errorf("body = %q", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestIPFilter_InvalidRange(t *testing.T) {
+	defer func() {
+		p := recover()
+		if msg, _ := p.(string); !strings.Contains(msg, "invalid denied address 10.0.0.0/33") {
+			t.Errorf("panic = %v", p)
+		}
+	}()
+	IPFilter(IPFilterConfig{Deny: []string{"10.0.0.0/33"}})
+}
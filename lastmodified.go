@@ -0,0 +1,103 @@
+package rig
+
+import (
+	"net/http"
+	"time"
+)
+
+// LastModifiedConfig defines the configuration for LastModified middleware.
+type LastModifiedConfig struct {
+	// Source returns when the data served by the route last changed, such
+	// as an updated_at column or a cache's load time. It runs before the
+	// handler, so it must be much cheaper than the handler. A zero time
+	// disables the middleware for the request; an error is returned as the
+	// request's error. Required.
+	Source func(c *Context) (time.Time, error)
+
+	// Skipper selects requests that are not answered conditionally.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// LastModified creates middleware that sets a Last-Modified header on GET
+// and HEAD responses from source, and answers requests whose
+// If-Modified-Since header is not older with 304 Not Modified, without
+// running the handler. It suits read-heavy routes whose data changes
+// infrequently. It panics if source is nil.
+//
+// Requests with an If-None-Match header are left to the handler (or ETag),
+// since entity tags take precedence over dates (RFC 9110).
+//
+// Example:
+//
+//	r.GET("/catalog", rig.LastModified(func(c *rig.Context) (time.Time, error) {
+//	    return catalog.UpdatedAt(c.Context())
+//	})(listCatalog))
+func LastModified(source func(c *Context) (time.Time, error)) MiddlewareFunc {
+	return LastModifiedWithConfig(LastModifiedConfig{Source: source})
+}
+
+// LastModifiedWithConfig creates Last-Modified middleware with custom
+// configuration. See LastModified for the behavior.
+//
+// Example:
+//
+//	api.Use(rig.LastModifiedWithConfig(rig.LastModifiedConfig{
+//	    Source: func(c *rig.Context) (time.Time, error) {
+//	        return settings.LoadedAt(), nil
+//	    },
+//	    Skipper: rig.MethodIs(http.MethodPost),
+//	}))
+func LastModifiedWithConfig(config LastModifiedConfig) MiddlewareFunc {
+	if config.Source == nil {
+		panic("rig: LastModified requires a Source")
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			method := c.Method()
+			if method != http.MethodGet && method != http.MethodHead {
+				return next(c)
+			}
+
+			modified, err := config.Source(c)
+			if err != nil {
+				return err
+			}
+			if modified.IsZero() {
+				return next(c)
+			}
+
+			c.SetHeader("Last-Modified", modified.UTC().Format(http.TimeFormat))
+			if c.GetHeader("If-None-Match") == "" && !c.IfModifiedSince(modified) {
+				return c.NoContent(http.StatusNotModified)
+			}
+			return next(c)
+		}
+	}
+}
+
+// IfModifiedSince reports whether the resource, last modified at modified,
+// changed after the request's If-Modified-Since date. It returns true when
+// the header is missing or invalid. Dates have a one-second resolution, so
+// modified is truncated to the second. When it returns false for a GET or
+// HEAD request, the client's cached copy is current and the handler can
+// answer 304 Not Modified.
+//
+// Example:
+//
+//	c.SetHeader("Last-Modified", post.UpdatedAt.UTC().Format(http.TimeFormat))
+//	if !c.IfModifiedSince(post.UpdatedAt) {
+//	    return c.NoContent(http.StatusNotModified)
+//	}
+func (c *Context) IfModifiedSince(modified time.Time) bool {
+	since, err := http.ParseTime(c.GetHeader("If-Modified-Since"))
+	if err != nil {
+		return true
+	}
+	return modified.Truncate(time.Second).After(since)
+}
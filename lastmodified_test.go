@@ -0,0 +1,67 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestLastModified(t *testing.T) {
+	modified := time.Date(2026, 3, 1, 12, 0, 0, 500, time.UTC)
+	calls := 0
+	r := New()
+	r.GET("/catalog", LastModified(func(c *Context) (time.Time, error) {
+		return modified, nil
+	})(func(c *Context) error {
+		calls++
+		return c.JSON(http.StatusOK, []string{"book"})
+	}))
+
+	serve := func(header, value string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, "/catalog", nil)
+		if header != "" {
+			req.Header.Set(header, value)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := serve("", "")
+	if w.Code != http.StatusOK || w.Header().Get("Last-Modified") != "Sun, 01 Mar 2026 12:00:00 GMT" {
+		t.Errorf("first request: status = %d, Last-Modified = %q", w.Code, w.Header().Get("Last-Modified"))
+	}
+
+	w = serve("If-Modified-Since", "Sun, 01 Mar 2026 12:00:00 GMT")
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 {
+		t.Errorf("cached copy current: status = %d, body = %q", w.Code, w.Body.String())
+	}
+	if calls != 1 {
+		t.Errorf("handler ran %d times, want 1 (not for 304)", calls)
+	}
+
+	if w = serve("If-Modified-Since", "Sat, 28 Feb 2026 12:00:00 GMT"); w.Code != http.StatusOK {
+		t.Errorf("cached copy stale: status = %d", w.Code)
+	}
+	if w = serve("If-None-Match", `"v1"`); w.Code != http.StatusOK {
+		t.Errorf("If-None-Match takes precedence: status = %d", w.Code)
+	}
+}
+
+func TestLastModified_SourceError(t *testing.T) {
+	r := New()
+	r.GET("/catalog", LastModified(func(c *Context) (time.Time, error) {
+		return time.Time{}, errors.New("db down")
+	})(func(c *Context) error {
+		t.Error("handler should not run")
+		return nil
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/catalog", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
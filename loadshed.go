@@ -0,0 +1,184 @@
+package rig
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadShedConfig defines the configuration for LoadShed middleware. At least
+// one of MaxInFlight, MaxLatency, and Probe is required; requests are shed
+// when any of them signals overload.
+type LoadShedConfig struct {
+	// MaxInFlight is the number of requests handled at the same time above
+	// which new requests are shed.
+	// Default: 0 (no limit).
+	MaxInFlight int
+
+	// MaxLatency is the average latency, over the previous Window, above
+	// which requests are shed. Rising latency is the sign of requests
+	// queueing for CPU, connections, or locks. A window without completed
+	// requests averages zero, so traffic is let through again after a
+	// window of shedding.
+	// Default: 0 (latency is not watched).
+	MaxLatency time.Duration
+
+	// Window is the period over which latency is averaged.
+	// Default: 1 second.
+	Window time.Duration
+
+	// Probe reports whether the service is overloaded by any other measure,
+	// such as CPU usage or an exhausted database pool. It is called for
+	// every request, so it must be cheap; cache expensive measurements.
+	// Default: nil.
+	Probe func() bool
+
+	// RetryAfter is the delay suggested to shed clients in the Retry-After
+	// header.
+	// Default: 1 second.
+	RetryAfter time.Duration
+
+	// OnShed is called when a request is shed, after the Retry-After header
+	// has been set.
+	// Default: a JSON response with 503 Service Unavailable.
+	OnShed func(c *Context) error
+
+	// Skipper selects requests that are never shed and not measured, such
+	// as health checks.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// LoadShed creates middleware that sheds load when more than maxInFlight
+// requests are being handled, answering the excess with 503 Service
+// Unavailable and a Retry-After header, instead of letting every request
+// slow down. Unlike MaxInFlight, requests never wait for a slot. It panics
+// if maxInFlight is not positive.
+//
+// Example:
+//
+//	r.Use(rig.LoadShed(500))
+func LoadShed(maxInFlight int) MiddlewareFunc {
+	if maxInFlight <= 0 {
+		panic("rig: LoadShed requires a positive limit")
+	}
+	return LoadShedWithConfig(LoadShedConfig{MaxInFlight: maxInFlight})
+}
+
+// LoadShedWithConfig creates load shedding middleware with custom
+// configuration, shedding on in-flight requests, latency, or a custom probe.
+// Register it early, so shed requests cost as little as possible. It panics
+// if no signal is configured.
+//
+// Example:
+//
+//	r.Use(rig.LoadShedWithConfig(rig.LoadShedConfig{
+//	    MaxInFlight: 500,
+//	    MaxLatency:  250 * time.Millisecond,
+//	    Probe: func() bool {
+//	        return db.Stats().WaitCount > 100
+//	    },
+//	    RetryAfter: 5 * time.Second,
+//	    Skipper:    rig.PathPrefix("/health"),
+//	}))
+func LoadShedWithConfig(config LoadShedConfig) MiddlewareFunc {
+	if config.MaxInFlight <= 0 && config.MaxLatency <= 0 && config.Probe == nil {
+		panic("rig: LoadShed requires MaxInFlight, MaxLatency, or Probe")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Second
+	}
+	if config.RetryAfter <= 0 {
+		config.RetryAfter = time.Second
+	}
+	if config.OnShed == nil {
+		config.OnShed = func(c *Context) error {
+			return c.JSON(http.StatusServiceUnavailable, map[string]string{
+				"error": c.Translate(MsgServiceUnavailable, "service unavailable"),
+			})
+		}
+	}
+
+	var inFlight atomic.Int64
+	latency := &latencyWindow{window: config.Window}
+
+	overloaded := func() bool {
+		if config.MaxInFlight > 0 && inFlight.Load() >= int64(config.MaxInFlight) {
+			return true
+		}
+		if config.MaxLatency > 0 && latency.average(time.Now()) > config.MaxLatency {
+			return true
+		}
+		return config.Probe != nil && config.Probe()
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			if overloaded() {
+				c.SetHeader("Retry-After", ceilSeconds(config.RetryAfter))
+				return config.OnShed(c)
+			}
+
+			inFlight.Add(1)
+			start := time.Now()
+			defer func() {
+				inFlight.Add(-1)
+				if config.MaxLatency > 0 {
+					end := time.Now()
+					latency.record(end, end.Sub(start))
+				}
+			}()
+			return next(c)
+		}
+	}
+}
+
+// latencyWindow averages request latencies over fixed windows, reporting
+// the average of the previous window.
+type latencyWindow struct {
+	window time.Duration
+
+	mu       sync.Mutex
+	start    time.Time     // start of the current window
+	sum      time.Duration // latencies recorded in the current window
+	count    int
+	previous time.Duration // average of the previous window
+}
+
+// record adds a latency measured at now.
+func (w *latencyWindow) record(now time.Time, d time.Duration) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.advance(now)
+	w.sum += d
+	w.count++
+}
+
+// average returns the average latency of the window before now's.
+func (w *latencyWindow) average(now time.Time) time.Duration {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.advance(now)
+	return w.previous
+}
+
+// advance moves to now's window. A window without requests averages zero,
+// as does the previous one if whole windows passed without requests. The
+// caller must hold w.mu.
+func (w *latencyWindow) advance(now time.Time) {
+	elapsed := now.Sub(w.start)
+	if elapsed < w.window {
+		return
+	}
+	w.previous = 0
+	if w.count > 0 && elapsed < 2*w.window {
+		w.previous = w.sum / time.Duration(w.count)
+	}
+	w.start = now.Truncate(w.window)
+	w.sum, w.count = 0, 0
+}
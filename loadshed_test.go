@@ -0,0 +1,92 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadShed_InFlight(t *testing.T) {
+	r, started, release := blockingRouter(LoadShed(1))
+
+	var wg sync.WaitGroup
+	first := serveAsync(r, "/slow/1", &wg)
+	<-started
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "1" {
+		t.Errorf("overloaded: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	close(release)
+	wg.Wait()
+	if first.Code != http.StatusOK {
+		t.Errorf("in-flight request: status = %d", first.Code)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fast", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("after release: status = %d", w.Code)
+	}
+}
+
+func TestLoadShed_Probe(t *testing.T) {
+	var overloaded atomic.Bool
+	r := New()
+	r.Use(LoadShedWithConfig(LoadShedConfig{
+		Probe:      overloaded.Load,
+		RetryAfter: 5 * time.Second,
+		Skipper:    PathPrefix("/health"),
+	}))
+	r.GET("/work", func(c *Context) error { return c.NoContent(http.StatusNoContent) })
+	r.GET("/health", func(c *Context) error { return c.NoContent(http.StatusNoContent) })
+
+	serve := func(path string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w
+	}
+
+	if w := serve("/work"); w.Code != http.StatusNoContent {
+		t.Errorf("healthy: status = %d", w.Code)
+	}
+	overloaded.Store(true)
+	if w := serve("/work"); w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "5" {
+		t.Errorf("overloaded: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := serve("/health"); w.Code != http.StatusNoContent {
+		t.Errorf("skipped: status = %d", w.Code)
+	}
+}
+
+func TestLatencyWindow(t *testing.T) {
+	w := &latencyWindow{window: time.Second}
+	base := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	w.record(base, 100*time.Millisecond)
+	w.record(base.Add(500*time.Millisecond), 300*time.Millisecond)
+	if got := w.average(base.Add(900 * time.Millisecond)); got != 0 {
+		t.Errorf("first window: average = %v, want 0", got)
+	}
+	if got := w.average(base.Add(1500 * time.Millisecond)); got != 200*time.Millisecond {
+		t.Errorf("second window: average = %v, want 200ms", got)
+	}
+	// No requests completed in the second window
+	if got := w.average(base.Add(2500 * time.Millisecond)); got != 0 {
+		t.Errorf("third window: average = %v, want 0", got)
+	}
+}
+
+func TestLoadShedWithConfig_RequiresSignal(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic without a signal")
+		}
+	}()
+	LoadShedWithConfig(LoadShedConfig{})
+}
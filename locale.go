@@ -0,0 +1,216 @@
+package rig
+
+import (
+	"cmp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// LocaleConfig defines the configuration for NegotiateLocale middleware.
+type LocaleConfig struct {
+	// Supported lists the locales the application supports, as language
+	// tags (e.g., "en", "pt-BR"), in order of preference. Required.
+	Supported []string
+
+	// Default is the locale used when the request has no Accept-Language
+	// header or accepts none of Supported.
+	// Default: the first supported locale.
+	Default string
+
+	// Preferred returns a locale chosen explicitly for the request, such as
+	// from a cookie, a query parameter, or the user's profile. If it is
+	// supported, it takes precedence over Accept-Language.
+	// Default: nil (only Accept-Language is used).
+	Preferred func(c *Context) string
+
+	// Skipper selects requests whose locale is not negotiated.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// NegotiateLocale creates middleware that picks the supported locale best
+// matching the request's Accept-Language header, honoring q-values, and
+// stores it with c.SetLocale, so c.Locale and c.Translate use it. The
+// response gets a Content-Language header and "Vary: Accept-Language".
+// Requests accepting none of the locales get the first one. It panics if
+// supported is empty.
+//
+// A language range matches a supported locale with the same tag, equal to
+// its base language ("pt-BR" matches "pt"), extending it ("pt" matches
+// "pt-BR"), or sharing its base language ("pt-PT" matches "pt-BR"), in that
+// order of preference.
+//
+// Example:
+//
+//	r.Use(rig.NegotiateLocale("en", "de", "pt-BR"))
+//	r.SetTranslator(catalog)
+//
+//	// Accept-Language: pt-PT, de;q=0.8 selects "pt-BR"
+func NegotiateLocale(supported ...string) MiddlewareFunc {
+	return NegotiateLocaleWithConfig(LocaleConfig{Supported: supported})
+}
+
+// NegotiateLocaleWithConfig creates locale negotiation middleware with
+// custom configuration. See NegotiateLocale for the behavior.
+//
+// Example:
+//
+//	r.Use(rig.NegotiateLocaleWithConfig(rig.LocaleConfig{
+//	    Supported: []string{"en", "fr", "de"},
+//	    Preferred: func(c *rig.Context) string {
+//	        if cookie, err := c.Cookie("lang"); err == nil {
+//	            return cookie.Value
+//	        }
+//	        return ""
+//	    },
+//	}))
+func NegotiateLocaleWithConfig(config LocaleConfig) MiddlewareFunc {
+	if len(config.Supported) == 0 {
+		panic("rig: NegotiateLocale requires Supported locales")
+	}
+	if config.Default == "" {
+		config.Default = config.Supported[0]
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			locale := ""
+			if config.Preferred != nil {
+				locale = matchLocale(config.Preferred(c), config.Supported)
+			}
+			if locale == "" && c.GetHeader("Accept-Language") != "" {
+				locale = c.NegotiateLanguage(config.Supported...)
+			}
+			if locale == "" {
+				locale = config.Default
+			}
+
+			c.SetLocale(locale)
+			h := c.Header()
+			h.Set("Content-Language", locale)
+			h.Add("Vary", "Accept-Language")
+			return next(c)
+		}
+	}
+}
+
+// NegotiateLanguage returns the offer (a language tag such as "en" or
+// "pt-BR") that best matches the request's Accept-Language header. Offers
+// are in order of server preference, which breaks ties. It returns the
+// first offer if the request has no Accept-Language header, and "" if no
+// offer is acceptable.
+//
+// Example:
+//
+//	switch c.NegotiateLanguage("en", "de") {
+//	case "de":
+//	    return c.File("docs/index.de.html")
+//	default:
+//	    return c.File("docs/index.en.html")
+//	}
+func (c *Context) NegotiateLanguage(offers ...string) string {
+	if len(offers) == 0 {
+		return ""
+	}
+	ranges := parseAcceptLanguage(c.GetHeader("Accept-Language"))
+	if len(ranges) == 0 {
+		return offers[0]
+	}
+
+	var excluded []string
+	for _, r := range ranges {
+		if r.q == 0 {
+			excluded = append(excluded, r.tag)
+		}
+	}
+	for _, r := range ranges {
+		if r.q == 0 {
+			continue
+		}
+		if r.tag == "*" {
+			for _, offer := range offers {
+				if !slices.Contains(excluded, strings.ToLower(offer)) {
+					return offer
+				}
+			}
+			continue
+		}
+		if offer := matchLocale(r.tag, offers); offer != "" && !slices.Contains(excluded, strings.ToLower(offer)) {
+			return offer
+		}
+	}
+	return ""
+}
+
+// languageRange is a language range from an Accept-Language header.
+type languageRange struct {
+	tag string  // lowercased, e.g., "pt-br", "de", "*"
+	q   float64 // quality, 0 to 1
+}
+
+// parseAcceptLanguage parses an Accept-Language header into language
+// ranges, sorted by descending quality and then header order. Ranges with
+// an invalid quality are treated as q=1.
+func parseAcceptLanguage(header string) []languageRange {
+	var ranges []languageRange
+	for part := range strings.SplitSeq(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+
+		q := 1.0
+		if key, value, ok := strings.Cut(params, "="); ok && strings.TrimSpace(key) == "q" {
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && v >= 0 && v <= 1 {
+				q = v
+			}
+		}
+		ranges = append(ranges, languageRange{tag, q})
+	}
+	slices.SortStableFunc(ranges, func(a, b languageRange) int {
+		return cmp.Compare(b.q, a.q)
+	})
+	return ranges
+}
+
+// matchLocale returns the offer matching the language tag: the offer equal
+// to it, else the offer equal to its base language, else the first offer
+// extending it, else the first offer sharing its base language. It returns
+// "" if none matches. Tags compare case-insensitively.
+func matchLocale(tag string, offers []string) string {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if tag == "" {
+		return ""
+	}
+	for _, offer := range offers {
+		if strings.EqualFold(offer, tag) {
+			return offer
+		}
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		for _, offer := range offers {
+			if strings.EqualFold(offer, base) {
+				return offer
+			}
+		}
+	}
+	for _, offer := range offers {
+		if strings.HasPrefix(strings.ToLower(offer), tag+"-") {
+			return offer
+		}
+	}
+	if base, _, found := strings.Cut(tag, "-"); found {
+		for _, offer := range offers {
+			if strings.HasPrefix(strings.ToLower(offer), base+"-") {
+				return offer
+			}
+		}
+	}
+	return ""
+}
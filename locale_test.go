@@ -0,0 +1,80 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestNegotiateLocale(t *testing.T) {
+	r := New()
+	r.SetTranslator(Catalog{
+		"de":    {"greeting": "Hallo"},
+		"pt-BR": {"greeting": "Olá"},
+	})
+	r.Use(NegotiateLocale("en", "de", "pt-BR"))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "%s %s", c.Locale(), c.Translate("greeting", "Hello"))
+	})
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		{"", "en Hello"},
+		{"de-AT, en;q=0.5", "de Hallo"},
+		{"fr, pt-PT;q=0.9, de;q=0.8", "pt-BR Olá"},
+		{"en;q=0.1, de;q=0.9", "de Hallo"},
+		{"fr", "en Hello"},
+		{"*, en;q=0", "de Hallo"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.accept != "" {
+			req.Header.Set("Accept-Language", tt.accept)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Body.String() != tt.want {
+			t.Errorf("Accept-Language %q: got %q, want %q", tt.accept, w.Body.String(), tt.want)
+		}
+		if locale, _, _ := strings.Cut(tt.want, " "); w.Header().Get("Content-Language") != locale {
+			t.Errorf("Accept-Language %q: Content-Language = %q", tt.accept, w.Header().Get("Content-Language"))
+		}
+		if w.Header().Get("Vary") != "Accept-Language" {
+			t.Errorf("Vary = %q", w.Header().Get("Vary"))
+		}
+	}
+}
+
+func TestNegotiateLocaleWithConfig_Preferred(t *testing.T) {
+	r := New()
+	r.Use(NegotiateLocaleWithConfig(LocaleConfig{
+		Supported: []string{"en", "fr"},
+		Default:   "fr",
+		Preferred: func(c *Context) string { return c.Query("lang") },
+	}))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "%s", c.Locale())
+	})
+
+	tests := []struct {
+		target, accept, want string
+	}{
+		{"/?lang=EN", "fr", "en"},
+		{"/?lang=es", "en", "en"}, // unsupported preference
+		{"/", "", "fr"},
+		{"/", "es", "fr"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.target, nil)
+		req.Header.Set("Accept-Language", tt.accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s with %q: locale = %q, want %q", tt.target, tt.accept, w.Body.String(), tt.want)
+		}
+	}
+}
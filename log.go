@@ -0,0 +1,62 @@
+package rig
+
+import (
+	"log/slog"
+)
+
+// RequestIDKey is the context key under which the requestid middleware
+// stores the request ID. Logger adds it as the "request_id" attribute.
+const RequestIDKey = "request_id"
+
+// LoggerKey is the context key under which the logger middleware stores the
+// base *slog.Logger returned (with request attributes) by Logger.
+const LoggerKey = "rig.logger"
+
+// Logger returns a *slog.Logger for the request, carrying the attributes
+// "request_id", "method", "route", "identity", and "owner" (each only when
+// known), and "blocked_user_agent" for requests tagged by UserAgentFilter.
+// It is based on the logger stored under LoggerKey by the logger middleware
+// (see logger.Config.Slog), or slog.Default otherwise.
+//
+// Attributes are read when Logger is called, so identities and owners set
+// by middleware that runs after the logger are included.
+//
+// Example:
+//
+//	r.POST("/orders", func(c *rig.Context) error {
+//	    log := rig.Logger(c)
+//	    log.Info("creating order", "items", len(order.Items))
+//	    // {"msg":"creating order","request_id":"01H...","method":"POST","route":"POST /orders","items":3}
+//	    return orders.Create(c.Context(), log, order)
+//	})
+func Logger(c *Context) *slog.Logger {
+	base := slog.Default()
+	if v, ok := c.Get(LoggerKey); ok {
+		if l, ok := v.(*slog.Logger); ok && l != nil {
+			base = l
+		}
+	}
+
+	attrs := make([]any, 0, 8)
+	if id, ok := c.Get(RequestIDKey); ok {
+		if s, ok := id.(string); ok && s != "" {
+			attrs = append(attrs, slog.String("request_id", s))
+		}
+	}
+	attrs = append(attrs, slog.String("method", c.Method()))
+	if route := c.request.Pattern; route != "" {
+		attrs = append(attrs, slog.String("route", route))
+	}
+	if identity, ok := c.Get(IdentityKey); ok {
+		if s, ok := identity.(string); ok && s != "" {
+			attrs = append(attrs, slog.String("identity", s))
+		}
+	}
+	if owner := OwnerOf(c); owner != "" {
+		attrs = append(attrs, slog.String("owner", owner))
+	}
+	if UserAgentBlocked(c) {
+		attrs = append(attrs, slog.Bool("blocked_user_agent", true))
+	}
+	return base.With(attrs...)
+}
@@ -0,0 +1,67 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(LoggerKey, base)
+			c.Set(RequestIDKey, "req-1")
+			return next(c)
+		}
+	})
+	// Identity is set after the logger is stored, as auth middleware would
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(IdentityKey, "user-42")
+			return next(c)
+		}
+	})
+	r.POST("/orders/{id}", func(c *Context) error {
+		Logger(c).Info("creating order", "items", 3)
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/orders/7", nil))
+
+	var entry map[string]any
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("invalid log output %q: %v", buf.String(), err)
+	}
+	want := map[string]any{
+		"msg":        "creating order",
+		"request_id": "req-1",
+		"method":     "POST",
+		"route":      "POST /orders/{id}",
+		"identity":   "user-42",
+		"items":      float64(3),
+	}
+	for key, value := range want {
+		if entry[key] != value {
+			t.Errorf("%s = %v, want %v", key, entry[key], value)
+		}
+	}
+}
+
+func TestLogger_Default(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	l := Logger(c)
+	if l == nil {
+		t.Fatal("Logger() returned nil")
+	}
+	if l.Handler() == nil {
+		t.Error("Logger() should wrap slog.Default()")
+	}
+}
@@ -6,6 +6,8 @@
 //   - Request latency
 //   - Client IP address
 //   - Request ID (if available from requestid middleware)
+//   - Route owner (if set with rig.Owner)
+//   - Durations of segments recorded with rig.OnSegment
 //
 // # Basic Usage
 //
@@ -26,23 +28,34 @@
 //   - FormatText (default): Human-readable text format
 //   - FormatJSON: Structured JSON format for log aggregation systems
 //
-// # Status Code Tracking
+// # Slow Requests
+//
+// SlowThreshold marks slow requests in the log. With SlowStack, the stack of
+// a handler still running at the threshold is logged as well, without
+// interrupting it:
+//
+//	r.Use(logger.New(logger.Config{
+//	    SlowThreshold: 2 * time.Second,
+//	    SlowStack:     true,
+//	}))
 //
-// Note: Due to the design of the rig framework, the logger cannot capture
-// the exact HTTP status code. It infers the status based on whether an
-// error was returned from the handler:
-//   - No error: 200 OK
-//   - Error returned: 500 Internal Server Error
+// # Status Code Tracking
 //
-// For more accurate status tracking, consider using a custom response writer
-// wrapper in your application.
+// The logged status is the one written to the response (c.StatusCode()).
+// When a handler returns an error without writing a response, the router's
+// error handler writes it after the logger runs, so the status is inferred
+// from the error with rig.ErrorStatus, as DefaultErrorHandler sends it.
 package logger
 
 import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
+	"maps"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/cloudresty/rig"
@@ -77,9 +90,34 @@ type Config struct {
 	// Example: []string{"/health", "/ready", "/metrics"}
 	SkipPaths []string
 
+	// Skipper selects further requests that should not be logged, for
+	// conditions other than the path (e.g., OPTIONS requests or uptime
+	// probes identified by their User-Agent).
+	// Default: nil
+	Skipper rig.Skipper
+
 	// TimeFormat specifies the format for timestamps.
 	// Default: "2006-01-02 15:04:05"
 	TimeFormat string
+
+	// Slog is the base logger returned (with request attributes) by
+	// rig.Logger in handlers and services.
+	// Default: nil (rig.Logger uses slog.Default()).
+	Slog *slog.Logger
+
+	// SlowThreshold marks requests that take longer than this as slow
+	// ("slow": true in JSON, "SLOW" in text).
+	// Default: 0 (disabled)
+	SlowThreshold time.Duration
+
+	// SlowStack logs the stack of the handler goroutine once a request has
+	// been running for SlowThreshold, while the handler keeps running, so
+	// chronically slow endpoints can be diagnosed in production. Capturing
+	// stacks briefly stops the world, so keep SlowThreshold well above
+	// normal latencies. Register the logger after rig.Timeout, which runs
+	// the rest of the chain on a new goroutine.
+	// Default: false
+	SlowStack bool
 }
 
 // LogEntry represents a single log entry in JSON format.
@@ -94,13 +132,31 @@ type LogEntry struct {
 	RequestID string `json:"request_id,omitempty"`
 	Error     string `json:"error,omitempty"`
 	UserAgent string `json:"user_agent,omitempty"`
+	Owner     string `json:"owner,omitempty"`
+	Slow      bool   `json:"slow,omitempty"`
+
+	// Segments maps the names of segments recorded with rig.OnSegment to
+	// their total duration in milliseconds.
+	Segments map[string]float64 `json:"segments_ms,omitempty"`
+}
+
+// StackEntry is logged when SlowStack is enabled and a request is still
+// running after SlowThreshold. Stack is the handler goroutine's stack trace.
+type StackEntry struct {
+	Timestamp string `json:"timestamp"`
+	Message   string `json:"message"`
+	Elapsed   string `json:"elapsed"`
+	Method    string `json:"method"`
+	Path      string `json:"path"`
+	RequestID string `json:"request_id,omitempty"`
+	Stack     string `json:"stack"`
 }
 
 // New creates a new logger middleware with the given configuration.
 //
 // The middleware logs each request after it completes, including:
 //   - Timestamp
-//   - HTTP status code (inferred from error)
+//   - HTTP status code (as written, or inferred from a returned error)
 //   - Request latency
 //   - Client IP address
 //   - HTTP method and path
@@ -132,15 +188,31 @@ func New(config ...Config) rig.MiddlewareFunc {
 
 	return func(next rig.HandlerFunc) rig.HandlerFunc {
 		return func(c *rig.Context) error {
+			// Make the configured logger available through rig.Logger,
+			// including on skipped paths
+			if cfg.Slog != nil {
+				c.Set(rig.LoggerKey, cfg.Slog)
+			}
+
 			// Check if path should be skipped
-			if skipPaths[c.Path()] {
+			if skipPaths[c.Path()] || (cfg.Skipper != nil && cfg.Skipper(c)) {
 				return next(c)
 			}
 
 			start := time.Now()
 
+			// Capture the handler's stack if it is still running at the
+			// slow threshold
+			var stop func()
+			if cfg.SlowStack && cfg.SlowThreshold > 0 {
+				stop = watchSlow(c, cfg, goroutineID())
+			}
+
 			// Execute the handler
 			err := next(c)
+			if stop != nil {
+				stop()
+			}
 
 			// Calculate latency
 			latency := time.Since(start)
@@ -151,10 +223,12 @@ func New(config ...Config) rig.MiddlewareFunc {
 			// Get client IP
 			clientIP := getClientIP(c)
 
-			// Infer status code from error
-			status := 200
-			if err != nil {
-				status = 500
+			// Use the written status; if the handler returned an error, the
+			// router's error handler writes the response after this middleware,
+			// so infer the status from the error
+			status := c.StatusCode()
+			if err != nil && !c.Written() {
+				status = rig.ErrorStatus(err)
 			}
 
 			// Build log entry
@@ -168,6 +242,15 @@ func New(config ...Config) rig.MiddlewareFunc {
 				Path:      c.Path(),
 				RequestID: reqID,
 				UserAgent: c.GetHeader("User-Agent"),
+				Owner:     rig.OwnerOf(c),
+				Slow:      cfg.SlowThreshold > 0 && latency > cfg.SlowThreshold,
+			}
+
+			for _, seg := range rig.Segments(c) {
+				if entry.Segments == nil {
+					entry.Segments = make(map[string]float64)
+				}
+				entry.Segments[seg.Name] += float64(seg.Duration.Microseconds()) / 1000
 			}
 
 			if err != nil {
@@ -187,6 +270,41 @@ func New(config ...Config) rig.MiddlewareFunc {
 	}
 }
 
+// watchSlow logs the stack of goroutine id (the one running the handler)
+// if the request is still running after cfg.SlowThreshold. The returned
+// function stops the watch; if the stack is being logged, it waits for that
+// to finish so the stack precedes the request's log entry.
+func watchSlow(c *rig.Context, cfg Config, id []byte) func() {
+	// Read the request before the handler runs; the timer must not touch
+	// the Context concurrently with it
+	entry := StackEntry{
+		Message:   "slow request still running",
+		Elapsed:   formatLatency(cfg.SlowThreshold),
+		Method:    c.Method(),
+		Path:      c.Path(),
+		RequestID: requestid.Get(c),
+	}
+
+	done := make(chan struct{})
+	timer := time.AfterFunc(cfg.SlowThreshold, func() {
+		defer close(done)
+		entry.Timestamp = time.Now().Format(cfg.TimeFormat)
+		entry.Stack = goroutineStack(id)
+		switch cfg.Format {
+		case FormatJSON:
+			_ = json.NewEncoder(cfg.Output).Encode(entry)
+		default:
+			_, _ = fmt.Fprintf(cfg.Output, "%s | SLOW | %10s | %s %s | %s\n%s\n",
+				entry.Timestamp, entry.Elapsed, entry.Method, entry.Path, entry.Message, entry.Stack)
+		}
+	})
+	return func() {
+		if !timer.Stop() {
+			<-done
+		}
+	}
+}
+
 // writeText writes a log entry in text format.
 func writeText(w io.Writer, entry LogEntry) {
 	// Format: timestamp | status | latency | client_ip | method path [request_id]
@@ -203,10 +321,27 @@ func writeText(w io.Writer, entry LogEntry) {
 		line += fmt.Sprintf(" [%s]", entry.RequestID)
 	}
 
+	if len(entry.Segments) > 0 {
+		names := slices.Sorted(maps.Keys(entry.Segments))
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%s=%s", name, formatLatency(time.Duration(entry.Segments[name]*float64(time.Millisecond))))
+		}
+		line += " | " + strings.Join(parts, " ")
+	}
+
+	if entry.Owner != "" {
+		line += fmt.Sprintf(" | owner: %s", entry.Owner)
+	}
+
 	if entry.Error != "" {
 		line += fmt.Sprintf(" | error: %s", entry.Error)
 	}
 
+	if entry.Slow {
+		line += " | SLOW"
+	}
+
 	_, _ = fmt.Fprintln(w, line)
 }
 
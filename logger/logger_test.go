@@ -4,6 +4,8 @@ import (
 	"bytes"
 	"encoding/json"
 	"errors"
+	"io"
+	"log/slog"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -118,6 +120,32 @@ func TestNew_SkipPaths(t *testing.T) {
 	}
 }
 
+func TestNew_Skipper(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := rig.New()
+	r.Use(New(Config{
+		Output:  &buf,
+		Skipper: rig.HeaderEquals("User-Agent", "uptime-probe"),
+	}))
+	r.GET("/", func(c *rig.Context) error {
+		c.Status(http.StatusOK)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "uptime-probe")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if buf.Len() > 0 {
+		t.Error("Expected no log for skipped request")
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if buf.Len() == 0 {
+		t.Error("Expected log for non-skipped request")
+	}
+}
+
 func TestNew_ErrorLogging(t *testing.T) {
 	var buf bytes.Buffer
 
@@ -148,6 +176,47 @@ func TestNew_ErrorLogging(t *testing.T) {
 	}
 }
 
+func TestNew_LogsWrittenStatus(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler rig.HandlerFunc
+		want    int
+	}{
+		{"written status", func(c *rig.Context) error {
+			return c.JSON(http.StatusCreated, map[string]string{"id": "1"})
+		}, http.StatusCreated},
+		{"not found error", func(c *rig.Context) error {
+			return rig.NewHTTPError(http.StatusNotFound, "no such user")
+		}, http.StatusNotFound},
+		{"joined client and server errors", func(c *rig.Context) error {
+			return errors.Join(rig.NewHTTPError(http.StatusBadRequest, "bad id"), errors.New("db down"))
+		}, http.StatusInternalServerError},
+		{"error after writing", func(c *rig.Context) error {
+			c.Status(http.StatusAccepted)
+			return errors.New("late failure")
+		}, http.StatusAccepted},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			r := rig.New()
+			r.Use(New(Config{Format: FormatJSON, Output: &buf}))
+			r.GET("/", tt.handler)
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+			var entry LogEntry
+			if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+				t.Fatalf("Failed to parse JSON log: %v", err)
+			}
+			if entry.Status != tt.want {
+				t.Errorf("Status = %d, want %d", entry.Status, tt.want)
+			}
+		})
+	}
+}
+
 func TestNew_WithRequestID(t *testing.T) {
 	var buf bytes.Buffer
 
@@ -231,3 +300,169 @@ func TestFormatLatency(t *testing.T) {
 		})
 	}
 }
+
+func TestNew_Slog(t *testing.T) {
+	var buf bytes.Buffer
+	base := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	r := rig.New()
+	r.Use(New(Config{Output: io.Discard, Slog: base, SkipPaths: []string{"/health"}}))
+	r.GET("/health", func(c *rig.Context) error {
+		rig.Logger(c).Info("probe")
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if !strings.Contains(buf.String(), `"msg":"probe"`) || !strings.Contains(buf.String(), `"route":"GET /health"`) {
+		t.Errorf("rig.Logger should use the configured slog.Logger, got %q", buf.String())
+	}
+}
+
+func slowHandler(c *rig.Context) error {
+	time.Sleep(50 * time.Millisecond)
+	return c.String(http.StatusOK, "done")
+}
+
+func TestNew_SlowThreshold(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := rig.New()
+	r.Use(New(Config{
+		Format:        FormatJSON,
+		Output:        &buf,
+		SlowThreshold: 10 * time.Millisecond,
+	}))
+	r.GET("/slow", slowHandler)
+	r.GET("/fast", func(c *rig.Context) error {
+		return c.String(http.StatusOK, "done")
+	})
+
+	for _, path := range []string{"/slow", "/fast"} {
+		buf.Reset()
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+
+		var entry LogEntry
+		if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+			t.Fatalf("%s: failed to parse log entry: %v", path, err)
+		}
+		if entry.Slow != (path == "/slow") {
+			t.Errorf("%s: Slow = %v", path, entry.Slow)
+		}
+	}
+}
+
+func TestNew_SlowStack(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := rig.New()
+	r.Use(New(Config{
+		Format:        FormatJSON,
+		Output:        &buf,
+		SlowThreshold: 10 * time.Millisecond,
+		SlowStack:     true,
+	}))
+	r.GET("/slow", slowHandler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/slow", nil))
+
+	// The handler is not interrupted
+	if rec.Body.String() != "done" {
+		t.Errorf("body = %q, want handler to complete", rec.Body.String())
+	}
+
+	dec := json.NewDecoder(&buf)
+	var stack StackEntry
+	if err := dec.Decode(&stack); err != nil {
+		t.Fatalf("failed to parse stack entry: %v", err)
+	}
+	if stack.Path != "/slow" || stack.Method != http.MethodGet {
+		t.Errorf("stack entry = %s %s", stack.Method, stack.Path)
+	}
+	if !strings.Contains(stack.Stack, "slowHandler") {
+		t.Errorf("stack does not contain the handler:\n%s", stack.Stack)
+	}
+
+	var entry LogEntry
+	if err := dec.Decode(&entry); err != nil {
+		t.Fatalf("failed to parse log entry: %v", err)
+	}
+	if !entry.Slow || entry.Status != http.StatusOK {
+		t.Errorf("entry = %+v, want slow 200", entry)
+	}
+}
+
+func TestNew_SlowStack_FastRequest(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := rig.New()
+	r.Use(New(Config{
+		Output:        &buf,
+		SlowThreshold: time.Second,
+		SlowStack:     true,
+	}))
+	r.GET("/fast", func(c *rig.Context) error {
+		return c.String(http.StatusOK, "done")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/fast", nil))
+
+	if strings.Contains(buf.String(), "SLOW") || strings.Count(buf.String(), "\n") != 1 {
+		t.Errorf("fast request should log a single line, got:\n%s", buf.String())
+	}
+}
+
+func TestNew_Owner(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := rig.New()
+	r.Use(New(Config{
+		Format: FormatJSON,
+		Output: &buf,
+	}))
+	api := r.Group("/api")
+	api.Use(rig.Owner("team-search"))
+	api.GET("/search", func(c *rig.Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/api/search", nil))
+
+	var entry LogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log entry: %v", err)
+	}
+	if entry.Owner != "team-search" {
+		t.Errorf("Owner = %q, want team-search", entry.Owner)
+	}
+}
+
+func TestNew_Segments(t *testing.T) {
+	var buf bytes.Buffer
+
+	r := rig.New()
+	r.Use(New(Config{
+		Format: FormatJSON,
+		Output: &buf,
+	}))
+	r.GET("/report", func(c *rig.Context) error {
+		for range 2 {
+			_ = rig.OnSegment(c, "db", func() error {
+				time.Sleep(time.Millisecond)
+				return nil
+			})
+		}
+		return c.String(http.StatusOK, "ok")
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/report", nil))
+
+	var entry LogEntry
+	if err := json.Unmarshal(buf.Bytes(), &entry); err != nil {
+		t.Fatalf("failed to parse log entry: %v", err)
+	}
+	if entry.Segments["db"] < 2 {
+		t.Errorf("Segments = %v, want db >= 2ms", entry.Segments)
+	}
+}
@@ -0,0 +1,43 @@
+package logger
+
+import (
+	"bytes"
+	"runtime"
+)
+
+// goroutineID returns the ID of the calling goroutine, parsed from the
+// "goroutine N [running]:" header of its stack trace.
+func goroutineID() []byte {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	buf = bytes.TrimPrefix(buf, []byte("goroutine "))
+	if i := bytes.IndexByte(buf, ' '); i > 0 {
+		return buf[:i]
+	}
+	return nil
+}
+
+// goroutineStack returns the stack trace of the goroutine with the given ID,
+// or an empty string if it no longer exists. It captures the stacks of all
+// goroutines, which briefly stops the world, so it is only called for slow
+// requests.
+func goroutineStack(id []byte) string {
+	buf := make([]byte, 64<<10)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	header := append([]byte("goroutine "), id...)
+	header = append(header, " ["...)
+	for block := range bytes.SplitSeq(buf, []byte("\n\n")) {
+		if bytes.HasPrefix(block, header) {
+			return string(block)
+		}
+	}
+	return ""
+}
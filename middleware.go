@@ -2,14 +2,35 @@ package rig
 
 import (
 	"context"
+	"errors"
 	"log"
 	"net/http"
 	"runtime/debug"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 )
 
+// Skipper reports whether a middleware should pass a request straight to the
+// next handler. The configurations of the built-in middleware accept one, so
+// individual requests can bypass a middleware without restructuring route
+// groups; it is the per-middleware equivalent of Unless. The predicates
+// PathPrefix, MethodIs, and HeaderEquals can be used as Skippers.
+//
+// Example:
+//
+//	r.Use(rig.TimeoutWithConfig(rig.TimeoutConfig{
+//	    Timeout: 5 * time.Second,
+//	    Skipper: rig.PathPrefix("/events"), // Long-lived streams
+//	}))
+//	r.Use(rig.GzipWithConfig(rig.GzipConfig{
+//	    Skipper: func(c *rig.Context) bool {
+//	        return c.GetHeader("Upgrade") != "" || c.Path() == "/metrics"
+//	    },
+//	}))
+type Skipper func(c *Context) bool
+
 // RecoverConfig defines the configuration for the Recover middleware.
 type RecoverConfig struct {
 	// Logger is called when a panic is recovered.
@@ -19,6 +40,39 @@ type RecoverConfig struct {
 	//   config.Logger = func(err any, stack []byte) {}
 	// Default: logs to stderr with "[RIG] PANIC:" prefix
 	Logger func(err any, stack []byte)
+
+	// Reporter receives every recovered panic with request metadata, for
+	// error tracking services. See WebhookReporter.
+	// Default: the router's Reporter (see Router.SetReporter), if any
+	Reporter PanicReporter
+
+	// StackSize limits the stack trace passed to Logger and Reporter, in
+	// bytes. Deep stacks can otherwise flood logs.
+	// Default: 0 (the full stack)
+	StackSize int
+
+	// LogStack controls whether Logger receives the stack trace. Point it
+	// to false to pass a nil stack, for logs that only need the panic
+	// value; Reporter receives the stack either way.
+	// Default: nil (the stack is logged)
+	LogStack *bool
+
+	// Handler writes the response for a recovered panic; its error is
+	// handled like a handler error, by the router's error handler.
+	// Default: a JSON response with 500 Internal Server Error
+	Handler func(c *Context, err any) error
+
+	// DisableBrokenPipeReport ignores panics caused by writing to a client
+	// that has gone away (broken pipe or connection reset): they are not
+	// logged or reported, and no response is attempted. Such panics are
+	// noise rather than bugs.
+	// Default: false
+	DisableBrokenPipeReport bool
+
+	// Skipper selects requests whose panics are not recovered here, such as
+	// routes guarded by their own recovery.
+	// Default: nil (no request is skipped)
+	Skipper Skipper
 }
 
 // Recover creates middleware that recovers from panics and returns a 500 error.
@@ -36,7 +90,8 @@ func Recover() MiddlewareFunc {
 }
 
 // RecoverWithConfig creates recover middleware with custom configuration.
-// This allows you to customize panic logging (e.g., send to structured logger).
+// This allows you to customize panic logging (e.g., send to structured logger),
+// the response, and which panics are worth reporting.
 //
 // Example:
 //
@@ -47,6 +102,11 @@ func Recover() MiddlewareFunc {
 //	            "stack", string(stack),
 //	        )
 //	    },
+//	    StackSize:               8 << 10,
+//	    DisableBrokenPipeReport: true,
+//	    Handler: func(c *rig.Context, err any) error {
+//	        return render.HTML(c, http.StatusInternalServerError, "errors/500", nil)
+//	    },
 //	}))
 func RecoverWithConfig(config RecoverConfig) MiddlewareFunc {
 	if config.Logger == nil {
@@ -55,25 +115,60 @@ func RecoverWithConfig(config RecoverConfig) MiddlewareFunc {
 		}
 	}
 
+	if config.Handler == nil {
+		// Return a generic error to the client (don't leak internal details)
+		config.Handler = func(c *Context, _ any) error {
+			return c.JSON(http.StatusInternalServerError, map[string]string{
+				"error": c.Translate(MsgInternalServerError, "Internal Server Error"),
+			})
+		}
+	}
+
 	return func(next HandlerFunc) HandlerFunc {
-		return func(c *Context) error {
+		return func(c *Context) (err error) {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
 			defer func() {
-				if err := recover(); err != nil {
-					// Log panic using configured logger
-					config.Logger(err, debug.Stack())
-
-					// Return a generic error to the client (don't leak internal details)
-					c.Status(http.StatusInternalServerError)
-					_ = c.JSON(http.StatusInternalServerError, map[string]string{
-						"error": "Internal Server Error",
-					})
+				p := recover()
+				if p == nil {
+					return
+				}
+				if config.DisableBrokenPipeReport && isBrokenPipe(p) {
+					err = nil
+					return
+				}
+
+				// Log and report panic using configured logger and reporter
+				stack := debug.Stack()
+				if config.StackSize > 0 && len(stack) > config.StackSize {
+					stack = stack[:config.StackSize]
+				}
+				if config.LogStack != nil && !*config.LogStack {
+					config.Logger(p, nil)
+				} else {
+					config.Logger(p, stack)
 				}
+				reportPanic(c, config.Reporter, p, stack)
+
+				err = config.Handler(c, p)
 			}()
 			return next(c)
 		}
 	}
 }
 
+// isBrokenPipe reports whether a panic value is an error caused by a client
+// closing the connection (EPIPE or ECONNRESET).
+func isBrokenPipe(p any) bool {
+	err, ok := p.(error)
+	if !ok {
+		return false
+	}
+	return errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)
+}
+
 // CORSConfig defines the configuration for CORS middleware.
 type CORSConfig struct {
 	// AllowOrigins is a list of origins that are allowed to access the resource.
@@ -88,6 +183,11 @@ type CORSConfig struct {
 
 	// AllowHeaders is a list of headers that can be used during the request.
 	AllowHeaders []string
+
+	// Skipper selects requests that get no CORS headers and whose OPTIONS
+	// requests reach the route instead of being answered as preflights.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
 }
 
 // wildcardPattern represents a parsed wildcard origin pattern.
@@ -145,6 +245,9 @@ func DefaultCORS() MiddlewareFunc {
 }
 
 // CORS creates middleware that sets the necessary headers for Cross-Origin requests.
+// It answers OPTIONS preflights with 204 No Content. No OPTIONS routes are
+// needed: the router passes preflights for paths without one through the
+// middleware of the route for the requested method.
 //
 // Supports wildcard subdomains in AllowOrigins:
 //
@@ -183,6 +286,10 @@ func CORS(config CORSConfig) MiddlewareFunc {
 
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
 			origin := c.GetHeader("Origin")
 			allowOrigin := ""
 
@@ -227,6 +334,11 @@ type TimeoutConfig struct {
 	// OnTimeout is called when the handler times out.
 	// If nil, a default JSON response with 504 Gateway Timeout is returned.
 	OnTimeout func(c *Context) error
+
+	// Skipper selects requests that run without a deadline, such as
+	// long-lived streams and WebSocket upgrades.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
 }
 
 // Timeout creates middleware that cancels the request context if the handler
@@ -282,13 +394,17 @@ func TimeoutWithConfig(config TimeoutConfig) MiddlewareFunc {
 	if config.OnTimeout == nil {
 		config.OnTimeout = func(c *Context) error {
 			return c.JSON(http.StatusGatewayTimeout, map[string]string{
-				"error": "request timed out",
+				"error": c.Translate(MsgRequestTimeout, "request timed out"),
 			})
 		}
 	}
 
 	return func(next HandlerFunc) HandlerFunc {
 		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
 			// Create a context with timeout
 			ctx, cancel := context.WithTimeout(c.Context(), config.Timeout)
 			defer cancel()
@@ -332,3 +448,76 @@ func TimeoutWithConfig(config TimeoutConfig) MiddlewareFunc {
 		}
 	}
 }
+
+// When creates middleware that applies mw only to requests for which
+// predicate returns true. Other requests go straight to the next handler.
+//
+// Example:
+//
+//	// Only log API traffic
+//	r.Use(rig.When(rig.PathPrefix("/api"), logger.New()))
+//
+//	// Enable a middleware based on configuration
+//	r.Use(rig.When(func(c *rig.Context) bool { return debug }, dumpRequests))
+func When(predicate func(*Context) bool, mw MiddlewareFunc) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		wrapped := mw(next)
+		return func(c *Context) error {
+			if predicate(c) {
+				return wrapped(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// Unless creates middleware that applies mw to every request except those
+// for which predicate returns true.
+//
+// Example:
+//
+//	// Require authentication everywhere except the public docs
+//	r.Use(rig.Unless(rig.PathPrefix("/docs"), auth.Bearer(config)))
+func Unless(predicate func(*Context) bool, mw MiddlewareFunc) MiddlewareFunc {
+	return When(func(c *Context) bool { return !predicate(c) }, mw)
+}
+
+// PathPrefix returns a predicate for When and Unless that matches requests
+// whose URL path starts with any of the given prefixes.
+func PathPrefix(prefixes ...string) func(*Context) bool {
+	return func(c *Context) bool {
+		path := c.Path()
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(path, prefix) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// MethodIs returns a predicate for When and Unless that matches requests
+// using any of the given HTTP methods.
+func MethodIs(methods ...string) func(*Context) bool {
+	return func(c *Context) bool {
+		for _, m := range methods {
+			if strings.EqualFold(c.Method(), m) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// HeaderEquals returns a predicate for When and Unless that matches requests
+// whose header key has the given value. An empty value matches any request
+// where the header is present.
+func HeaderEquals(key, value string) func(*Context) bool {
+	return func(c *Context) bool {
+		got := c.GetHeader(key)
+		if value == "" {
+			return got != ""
+		}
+		return got == value
+	}
+}
@@ -3,9 +3,13 @@ package rig
 import (
 	"context"
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"os"
 	"strings"
+	"syscall"
 	"testing"
 	"time"
 )
@@ -139,6 +143,98 @@ func TestRecoverWithConfig_NilLogger_UsesDefault(t *testing.T) {
 	}
 }
 
+func TestRecoverWithConfig_StackAndHandler(t *testing.T) {
+	var loggedStack []byte
+	var reported PanicReport
+	logStack := false
+	r := New()
+	r.Use(RecoverWithConfig(RecoverConfig{
+		Logger: func(_ any, stack []byte) {
+			loggedStack = stack
+		},
+		Reporter: PanicReporterFunc(func(_ context.Context, report PanicReport) {
+			reported = report
+		}),
+		StackSize: 64,
+		LogStack:  &logStack,
+		Handler: func(c *Context, err any) error {
+			return NewHTTPError(http.StatusTeapot, fmt.Sprint(err))
+		},
+	}))
+	r.GET("/panic", func(_ *Context) error {
+		panic("handled")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusTeapot || !strings.Contains(w.Body.String(), "handled") {
+		t.Errorf("status = %d, body = %q, want the Handler's error", w.Code, w.Body.String())
+	}
+	if loggedStack != nil {
+		t.Errorf("logged stack = %q, want nil", loggedStack)
+	}
+	if len(reported.Stack) != 64 {
+		t.Errorf("reported stack length = %d, want 64", len(reported.Stack))
+	}
+}
+
+func TestRecoverWithConfig_LogStack(t *testing.T) {
+	yes, no := true, false
+	tests := []struct {
+		name      string
+		logStack  *bool
+		wantStack bool
+	}{
+		{"default", nil, true},
+		{"true", &yes, true},
+		{"false", &no, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var loggedStack []byte
+			r := New()
+			r.Use(RecoverWithConfig(RecoverConfig{
+				Logger:   func(_ any, stack []byte) { loggedStack = stack },
+				LogStack: tt.logStack,
+			}))
+			r.GET("/panic", func(_ *Context) error { panic("boom") })
+
+			r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+			if got := len(loggedStack) > 0; got != tt.wantStack {
+				t.Errorf("stack logged = %v, want %v", got, tt.wantStack)
+			}
+		})
+	}
+}
+
+func TestRecoverWithConfig_BrokenPipe(t *testing.T) {
+	brokenPipe := &net.OpError{Op: "write", Net: "tcp", Err: os.NewSyscallError("write", syscall.EPIPE)}
+
+	for _, disable := range []bool{false, true} {
+		logged := false
+		r := New()
+		r.Use(RecoverWithConfig(RecoverConfig{
+			Logger:                  func(any, []byte) { logged = true },
+			DisableBrokenPipeReport: disable,
+		}))
+		r.GET("/download", func(_ *Context) error {
+			panic(brokenPipe)
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/download", nil))
+
+		if logged == disable {
+			t.Errorf("DisableBrokenPipeReport = %v: logged = %v", disable, logged)
+		}
+		if disable && w.Body.Len() != 0 {
+			t.Errorf("DisableBrokenPipeReport = true: body = %q, want none", w.Body.String())
+		}
+	}
+}
+
 func TestCORS_AllowAllOrigins(t *testing.T) {
 	r := New()
 	r.Use(DefaultCORS())
@@ -475,6 +571,60 @@ func TestCORS_WildcardPreflight(t *testing.T) {
 
 // --- Timeout Middleware Tests ---
 
+func TestCORS_AutomaticPreflight(t *testing.T) {
+	r := New()
+	ran := false
+	handler := func(c *Context) error {
+		ran = true
+		return c.String(http.StatusOK, "ok")
+	}
+	api := r.Group("/api")
+	api.Use(CORS(CORSConfig{
+		AllowOrigins: []string{"https://example.com"},
+		AllowMethods: []string{"GET", "PUT"},
+		AllowHeaders: []string{"Content-Type"},
+	}))
+	api.PUT("/users/{id}", handler)
+	r.GET("/internal", handler)
+
+	tests := []struct {
+		name          string
+		path          string
+		requestMethod string
+		wantStatus    int
+		wantCORS      bool
+	}{
+		{"preflight", "/api/users/42", "PUT", http.StatusNoContent, true},
+		{"unregistered method", "/api/users/42", "DELETE", http.StatusMethodNotAllowed, false},
+		{"route without cors", "/internal", "GET", http.StatusMethodNotAllowed, false},
+		{"not a preflight", "/api/users/42", "", http.StatusMethodNotAllowed, false},
+		{"unknown path", "/api/missing", "GET", http.StatusNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ran = false
+			req := httptest.NewRequest(http.MethodOptions, tt.path, nil)
+			req.Header.Set("Origin", "https://example.com")
+			if tt.requestMethod != "" {
+				req.Header.Set("Access-Control-Request-Method", tt.requestMethod)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if got := w.Header().Get("Access-Control-Allow-Methods") != ""; got != tt.wantCORS {
+				t.Errorf("Access-Control-Allow-Methods set = %v, want %v", got, tt.wantCORS)
+			}
+			if ran {
+				t.Error("route handler ran for a preflight")
+			}
+		})
+	}
+}
+
 func TestTimeout_HandlerCompletesBeforeTimeout(t *testing.T) {
 	r := New()
 	r.Use(Timeout(1 * time.Second))
@@ -620,3 +770,193 @@ func TestTimeout_ContextPassedToExternalCalls(t *testing.T) {
 		t.Error("context should have a deadline set by Timeout middleware")
 	}
 }
+
+func TestWhen(t *testing.T) {
+	mark := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.SetHeader("X-Applied", "true")
+			return next(c)
+		}
+	}
+
+	r := New()
+	r.Use(When(PathPrefix("/api", "/admin"), mark))
+	r.GET("/api/users", func(c *Context) error { return nil })
+	r.GET("/public", func(c *Context) error { return nil })
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/api/users", "true"},
+		{"/public", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+			}
+			if got := w.Header().Get("X-Applied"); got != tt.want {
+				t.Errorf("X-Applied = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnless(t *testing.T) {
+	deny := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			return c.JSON(http.StatusUnauthorized, nil)
+		}
+	}
+
+	r := New()
+	r.Use(Unless(HeaderEquals("X-Internal", "yes"), deny))
+	r.GET("/", func(c *Context) error { return nil })
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("status without header = %d, want %d", w.Code, http.StatusUnauthorized)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("X-Internal", "yes")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("status with header = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/users", nil)
+	req.Header.Set("X-Feature", "beta")
+	c := newContext(httptest.NewRecorder(), req)
+
+	tests := []struct {
+		name      string
+		predicate func(*Context) bool
+		want      bool
+	}{
+		{"path prefix match", PathPrefix("/api"), true},
+		{"path prefix miss", PathPrefix("/admin"), false},
+		{"method match", MethodIs("get", "post"), true},
+		{"method miss", MethodIs(http.MethodGet), false},
+		{"header value match", HeaderEquals("X-Feature", "beta"), true},
+		{"header value miss", HeaderEquals("X-Feature", "alpha"), false},
+		{"header present", HeaderEquals("X-Feature", ""), true},
+		{"header absent", HeaderEquals("X-Other", ""), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.predicate(c); got != tt.want {
+				t.Errorf("predicate() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSkipper(t *testing.T) {
+	skip := Skipper(HeaderEquals("X-Skip", "yes"))
+	tests := []struct {
+		name   string
+		mw     MiddlewareFunc
+		header string // set by the middleware unless skipped
+	}{
+		{"cors", CORS(CORSConfig{AllowOrigins: []string{"*"}, Skipper: skip}), "Access-Control-Allow-Origin"},
+		{"secure headers", SecureHeaders(SecureHeadersConfig{Skipper: skip}), "X-Frame-Options"},
+		{"gzip", GzipWithConfig(GzipConfig{MinSize: 1, Skipper: skip}), "Content-Encoding"},
+		{"etag", ETagWithConfig(ETagConfig{Skipper: skip}), "ETag"},
+		{"rate limit", RateLimit(RateLimitConfig{Rate: 1, Skipper: skip}), "RateLimit-Limit"},
+		{"early hints", EarlyHints(EarlyHintsConfig{Links: []string{"/app.css"}, Skipper: skip}), "Link"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Use(tt.mw)
+			r.GET("/", func(c *Context) error {
+				return c.String(http.StatusOK, "hello")
+			})
+
+			for _, skipped := range []bool{false, true} {
+				req := httptest.NewRequest(http.MethodGet, "/", nil)
+				req.Header.Set("Origin", "https://example.com")
+				req.Header.Set("Accept-Encoding", "gzip")
+				if skipped {
+					req.Header.Set("X-Skip", "yes")
+				}
+				w := httptest.NewRecorder()
+				r.ServeHTTP(w, req)
+
+				if got := w.Header().Get(tt.header) != ""; got == skipped {
+					t.Errorf("skipped = %v: %s set = %v", skipped, tt.header, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSkipper_BlockingMiddleware(t *testing.T) {
+	skip := Skipper(PathPrefix("/health"))
+	tests := []struct {
+		name string
+		mw   MiddlewareFunc
+	}{
+		{"ip filter", IPFilter(IPFilterConfig{Allow: []string{"10.0.0.0/8"}, Skipper: skip})},
+		{"timeout", TimeoutWithConfig(TimeoutConfig{Timeout: time.Nanosecond, Skipper: skip})},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Use(tt.mw)
+			r.GET("/api", func(c *Context) error {
+				// Once timed out, the response belongs to the middleware
+				select {
+				case <-c.Context().Done():
+					return nil
+				case <-time.After(time.Second):
+					return c.String(http.StatusOK, "ok")
+				}
+			})
+			r.GET("/health", func(c *Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api", nil))
+			if w.Code == http.StatusOK {
+				t.Errorf("/api: status = %d, want the middleware to respond", w.Code)
+			}
+
+			w = httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/health", nil))
+			if w.Code != http.StatusOK {
+				t.Errorf("/health: status = %d, want 200", w.Code)
+			}
+		})
+	}
+}
+
+func TestRecoverWithConfig_Skipper(t *testing.T) {
+	r := New()
+	r.Use(RecoverWithConfig(RecoverConfig{
+		Logger:  func(any, []byte) {},
+		Skipper: PathPrefix("/debug"),
+	}))
+	r.GET("/debug/crash", func(c *Context) error { panic("boom") })
+
+	defer func() {
+		if recover() == nil {
+			t.Error("panic on a skipped path was recovered")
+		}
+	}()
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/debug/crash", nil))
+}
@@ -0,0 +1,197 @@
+package rig
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ErrMirrorDropped is passed to MirrorConfig.OnError when a request is not
+// mirrored because MaxConcurrent mirrored requests are already in flight.
+var ErrMirrorDropped = errors.New("rig: mirror dropped, too many mirrored requests in flight")
+
+// MirrorConfig defines the configuration for the Mirror middleware.
+type MirrorConfig struct {
+	// Target is the base URL of the shadow service (e.g., "http://users-v2:8080").
+	// The request path and query string are appended to it.
+	Target string
+
+	// Percentage is the share of requests to mirror, from 0 to 100.
+	// Default: 100 (mirror every request).
+	Percentage float64
+
+	// Disabled stops mirroring without removing the middleware, such as
+	// when the share of requests to mirror, read from configuration, is 0
+	// (a zero Percentage means the default of 100).
+	// Default: false.
+	Disabled bool
+
+	// MaxConcurrent is the maximum number of mirrored requests in flight.
+	// When it is reached, further requests are not mirrored and OnError
+	// receives ErrMirrorDropped, so a slow shadow target cannot pile up
+	// goroutines and memory.
+	// Default: 100.
+	MaxConcurrent int
+
+	// Timeout is the maximum duration of a mirrored request.
+	// Default: 5 seconds.
+	Timeout time.Duration
+
+	// Client is the HTTP client used to send mirrored requests.
+	// Default: a client with no timeout of its own (Timeout is applied per request).
+	Client *http.Client
+
+	// MaxBodySize is the largest request body, in bytes, that is mirrored.
+	// Requests with larger bodies are served as usual but not mirrored, so
+	// uploads are never buffered in memory.
+	// Default: 1 MB.
+	MaxBodySize int64
+
+	// OnError is called when a mirrored request fails.
+	// If nil, failures are logged to stderr using the standard log package.
+	OnError func(err error)
+
+	// Skipper selects requests that are never mirrored, such as health
+	// checks or endpoints with side effects the shadow must not see.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// Mirror creates middleware that asynchronously copies a sample of requests
+// (method, path, query, headers, and body up to MaxBodySize) to a shadow
+// target. Responses from the shadow target are discarded, and failures never
+// affect the primary response, so new service versions can be exercised with
+// real production traffic.
+//
+// Mirrored requests carry an "X-Rig-Mirror: true" header so the shadow
+// service can tell them apart (e.g., to skip side effects like sending emails).
+//
+// Example:
+//
+//	r.Use(rig.Mirror(rig.MirrorConfig{
+//	    Target:     "http://users-v2.internal:8080",
+//	    Percentage: 10, // mirror 10% of requests
+//	}))
+func Mirror(config MirrorConfig) MiddlewareFunc {
+	if config.Percentage == 0 {
+		config.Percentage = 100
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 5 * time.Second
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{}
+	}
+	if config.MaxBodySize <= 0 {
+		config.MaxBodySize = 1 << 20
+	}
+	if config.MaxConcurrent <= 0 {
+		config.MaxConcurrent = 100
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) {
+			log.Printf("[RIG] MIRROR: %v", err)
+		}
+	}
+	target := strings.TrimSuffix(config.Target, "/")
+	inFlight := make(chan struct{}, config.MaxConcurrent)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Disabled || config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+			if config.Percentage < 100 && rand.Float64()*100 >= config.Percentage {
+				return next(c)
+			}
+
+			req := c.Request()
+			if req.ContentLength > config.MaxBodySize {
+				return next(c)
+			}
+
+			// Buffer the body so both the handler and the mirror can read it
+			var body []byte
+			if req.Body != nil && req.Body != http.NoBody {
+				var err error
+				body, err = io.ReadAll(io.LimitReader(req.Body, config.MaxBodySize+1))
+				if err != nil {
+					_ = req.Body.Close()
+					return bodyError(err)
+				}
+				if int64(len(body)) > config.MaxBodySize {
+					// Too large to mirror: hand the handler the whole body
+					req.Body = &prefixedBody{io.MultiReader(bytes.NewReader(body), req.Body), req.Body}
+					if c.originalBody != nil {
+						// The limit already counted the bytes read, so
+						// c.MaxBodyBytes continues from the same point
+						c.originalBody = &prefixedBody{io.MultiReader(bytes.NewReader(body), c.originalBody), c.originalBody}
+					}
+					return next(c)
+				}
+				_ = req.Body.Close()
+				req.Body = io.NopCloser(bytes.NewReader(body))
+				if c.originalBody != nil {
+					c.originalBody = req.Body
+				}
+			}
+
+			url := target + req.URL.Path
+			if req.URL.RawQuery != "" {
+				url += "?" + req.URL.RawQuery
+			}
+			header := req.Header.Clone()
+
+			select {
+			case inFlight <- struct{}{}:
+				go func() {
+					defer func() { <-inFlight }()
+					sendMirror(config, req.Method, url, header, body)
+				}()
+			default:
+				config.OnError(ErrMirrorDropped)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// sendMirror sends a single mirrored request and discards the response.
+func sendMirror(config MirrorConfig, method, url string, header http.Header, body []byte) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(body))
+	if err != nil {
+		config.OnError(err)
+		return
+	}
+	req.Header = header
+	req.Header.Set("X-Rig-Mirror", "true")
+
+	resp, err := config.Client.Do(req)
+	if err != nil {
+		config.OnError(err)
+		return
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+}
+
+// prefixedBody is a request body whose first bytes were already read.
+type prefixedBody struct {
+	io.Reader
+	body io.ReadCloser
+}
+
+// Close closes the original body.
+func (b *prefixedBody) Close() error {
+	return b.body.Close()
+}
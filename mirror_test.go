@@ -0,0 +1,244 @@
+package rig
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type mirroredRequest struct {
+	method string
+	uri    string
+	body   string
+	header http.Header
+}
+
+func TestMirror(t *testing.T) {
+	received := make(chan mirroredRequest, 1)
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		received <- mirroredRequest{r.Method, r.URL.RequestURI(), string(body), r.Header}
+		w.WriteHeader(http.StatusTeapot)
+	}))
+	defer shadow.Close()
+
+	r := New()
+	r.Use(Mirror(MirrorConfig{Target: shadow.URL + "/"}))
+
+	var handlerBody string
+	r.POST("/orders", func(c *Context) error {
+		b, _ := io.ReadAll(c.Request().Body)
+		handlerBody = string(b)
+		return c.JSON(http.StatusCreated, map[string]string{"status": "ok"})
+	})
+
+	req := httptest.NewRequest(http.MethodPost, "/orders?dry=1", strings.NewReader(`{"sku":"X1"}`))
+	req.Header.Set("X-Tenant-ID", "acme")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusCreated {
+		t.Errorf("primary status = %d, want %d", w.Code, http.StatusCreated)
+	}
+	if handlerBody != `{"sku":"X1"}` {
+		t.Errorf("handler body = %q, want original body", handlerBody)
+	}
+
+	select {
+	case m := <-received:
+		if m.method != http.MethodPost || m.uri != "/orders?dry=1" {
+			t.Errorf("mirrored %s %s, want POST /orders?dry=1", m.method, m.uri)
+		}
+		if m.body != `{"sku":"X1"}` {
+			t.Errorf("mirrored body = %q", m.body)
+		}
+		if m.header.Get("X-Tenant-ID") != "acme" || m.header.Get("X-Rig-Mirror") != "true" {
+			t.Errorf("mirrored headers = %v", m.header)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("request was not mirrored")
+	}
+}
+
+func TestMirror_Percentage(t *testing.T) {
+	var count atomic.Int32
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	defer shadow.Close()
+
+	r := New()
+	r.Use(Mirror(MirrorConfig{Target: shadow.URL, Percentage: 0.0001}))
+	r.GET("/", func(c *Context) error { return nil })
+
+	for range 50 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if n := count.Load(); n > 1 {
+		t.Errorf("mirrored %d requests, expected almost none", n)
+	}
+}
+
+func TestMirror_Disabled(t *testing.T) {
+	var count atomic.Int32
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	defer shadow.Close()
+
+	r := New()
+	r.Use(Mirror(MirrorConfig{Target: shadow.URL, Disabled: true}))
+	r.GET("/", func(c *Context) error { return nil })
+
+	for range 10 {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	time.Sleep(50 * time.Millisecond)
+
+	if n := count.Load(); n != 0 {
+		t.Errorf("mirrored %d requests, want 0", n)
+	}
+}
+
+func TestMirror_MaxConcurrent(t *testing.T) {
+	release := make(chan struct{})
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer shadow.Close()
+	defer close(release)
+
+	var dropped atomic.Int32
+	r := New()
+	r.Use(Mirror(MirrorConfig{
+		Target:        shadow.URL,
+		MaxConcurrent: 2,
+		OnError: func(err error) {
+			if errors.Is(err, ErrMirrorDropped) {
+				dropped.Add(1)
+			}
+		},
+	}))
+	r.GET("/", func(c *Context) error { return c.NoContent(http.StatusNoContent) })
+
+	for range 5 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusNoContent {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusNoContent)
+		}
+	}
+
+	if n := dropped.Load(); n != 3 {
+		t.Errorf("dropped %d mirrors, want 3", n)
+	}
+}
+
+func TestMirror_ShadowFailureDoesNotAffectResponse(t *testing.T) {
+	errs := make(chan error, 1)
+
+	r := New()
+	r.Use(Mirror(MirrorConfig{
+		Target:  "http://127.0.0.1:1",
+		Timeout: time.Second,
+		OnError: func(err error) { errs <- err },
+	}))
+	r.GET("/", func(c *Context) error {
+		return c.JSON(http.StatusOK, nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	select {
+	case err := <-errs:
+		if err == nil {
+			t.Error("OnError called with nil error")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("OnError was not called")
+	}
+}
+
+func TestMirror_MaxBodySize(t *testing.T) {
+	var count atomic.Int32
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		count.Add(1)
+	}))
+	defer shadow.Close()
+
+	r := New()
+	r.Use(Mirror(MirrorConfig{
+		Target:      shadow.URL,
+		MaxBodySize: 8,
+		Skipper:     PathPrefix("/health"),
+	}))
+	var handlerBody string
+	r.POST("/upload", func(c *Context) error {
+		b, _ := io.ReadAll(c.Request().Body)
+		handlerBody = string(b)
+		return c.NoContent(http.StatusNoContent)
+	})
+	r.GET("/health", func(c *Context) error { return c.NoContent(http.StatusNoContent) })
+
+	// Unknown length, so the size is only found out while reading
+	req := httptest.NewRequest(http.MethodPost, "/upload", io.NopCloser(strings.NewReader("0123456789abcdef")))
+	req.ContentLength = -1
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	time.Sleep(50 * time.Millisecond)
+
+	if handlerBody != "0123456789abcdef" {
+		t.Errorf("handler body = %q, want the whole body", handlerBody)
+	}
+	if n := count.Load(); n != 0 {
+		t.Errorf("mirrored %d requests, want 0", n)
+	}
+}
+
+func TestMirror_MaxBodyBytes(t *testing.T) {
+	shadow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer shadow.Close()
+
+	r := New()
+	r.SetMaxBodyBytes(16)
+	r.Use(Mirror(MirrorConfig{Target: shadow.URL}))
+	r.POST("/uploads", func(c *Context) error {
+		c.MaxBodyBytes(1024)
+		b, err := io.ReadAll(c.Request().Body)
+		if err != nil {
+			return bodyError(err)
+		}
+		return c.String(http.StatusOK, "%s", b)
+	})
+
+	tests := []struct {
+		body       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"0123456789", http.StatusOK, "0123456789"},
+		{strings.Repeat("a", 64), http.StatusRequestEntityTooLarge, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/uploads", strings.NewReader(tt.body)))
+		if w.Code != tt.wantStatus {
+			t.Errorf("POST %d bytes: status = %d, want %d", len(tt.body), w.Code, tt.wantStatus)
+		}
+		if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+			t.Errorf("POST %d bytes: handler read %q, want %q", len(tt.body), w.Body.String(), tt.wantBody)
+		}
+	}
+}
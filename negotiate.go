@@ -0,0 +1,117 @@
+package rig
+
+import (
+	"strconv"
+	"strings"
+)
+
+// acceptRange is a media range from an Accept header.
+type acceptRange struct {
+	mediaRange string  // e.g., "text/html", "text/*", "*/*"
+	q          float64 // quality, 0 to 1
+	position   int     // index in the header, for tie-breaking
+}
+
+// parseAccept parses an Accept header into media ranges. Ranges with an
+// invalid quality are treated as q=1.
+func parseAccept(header string) []acceptRange {
+	var ranges []acceptRange
+	for part := range strings.SplitSeq(header, ",") {
+		mediaRange, params, _ := strings.Cut(part, ";")
+		mediaRange = strings.ToLower(strings.TrimSpace(mediaRange))
+		if mediaRange == "" {
+			continue
+		}
+		if mediaRange == "*" {
+			mediaRange = "*/*" // Sent by some old clients
+		}
+
+		q := 1.0
+		for param := range strings.SplitSeq(params, ";") {
+			key, value, _ := strings.Cut(param, "=")
+			if strings.TrimSpace(key) != "q" {
+				continue
+			}
+			if v, err := strconv.ParseFloat(strings.TrimSpace(value), 64); err == nil && v >= 0 && v <= 1 {
+				q = v
+			}
+			break
+		}
+		ranges = append(ranges, acceptRange{mediaRange, q, len(ranges)})
+	}
+	return ranges
+}
+
+// rangeSpecificity returns how specifically mediaRange matches mediaType
+// (2 for an exact match, 1 for "type/*", 0 for "*/*"), or -1 if it does not
+// match.
+func rangeSpecificity(mediaRange, mediaType string) int {
+	switch {
+	case mediaRange == mediaType:
+		return 2
+	case mediaRange == "*/*":
+		return 0
+	}
+	if prefix, ok := strings.CutSuffix(mediaRange, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+		return 1
+	}
+	return -1
+}
+
+// negotiate returns the index of the offer the Accept header prefers, or -1
+// if none is acceptable. Each offer gets the quality of the most specific
+// range matching it (RFC 9110, Section 12.5.1). Ties go to the offer whose
+// range appears first in the header, then to the earlier offer. An empty
+// header accepts the first offer.
+func negotiate(header string, offers []string) int {
+	if len(offers) == 0 {
+		return -1
+	}
+	ranges := parseAccept(header)
+	if len(ranges) == 0 {
+		return 0
+	}
+
+	best, bestQ, bestPos := -1, 0.0, 0
+	for i, offer := range offers {
+		mediaType, _, _ := strings.Cut(offer, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+
+		match, specificity := acceptRange{}, -1
+		for _, r := range ranges {
+			if s := rangeSpecificity(r.mediaRange, mediaType); s > specificity {
+				match, specificity = r, s
+			}
+		}
+		if specificity < 0 || match.q == 0 {
+			continue
+		}
+		if best == -1 || match.q > bestQ || (match.q == bestQ && match.position < bestPos) {
+			best, bestQ, bestPos = i, match.q, match.position
+		}
+	}
+	return best
+}
+
+// Negotiate returns the offer (a media type such as "application/json") that
+// best matches the request's Accept header, honoring q-values and wildcards.
+// Offers are in order of server preference, which breaks ties. It returns the
+// first offer if the request has no Accept header, and "" if no offer is
+// acceptable (the handler may then answer 406 with ErrNotAcceptable).
+//
+// Example:
+//
+//	switch c.Negotiate("text/html", "application/json") {
+//	case "text/html":
+//	    return render.HTML(c, http.StatusOK, "users", users)
+//	case "application/json":
+//	    return c.JSON(http.StatusOK, users)
+//	default:
+//	    return rig.ErrNotAcceptable
+//	}
+func (c *Context) Negotiate(offers ...string) string {
+	if i := negotiate(c.GetHeader("Accept"), offers); i >= 0 {
+		return offers[i]
+	}
+	return ""
+}
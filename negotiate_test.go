@@ -0,0 +1,45 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContext_Negotiate(t *testing.T) {
+	tests := []struct {
+		name   string
+		accept string
+		offers []string
+		want   string
+	}{
+		{"no header", "", []string{"text/html", "application/json"}, "text/html"},
+		{"exact", "application/json", []string{"text/html", "application/json"}, "application/json"},
+		{"browser", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8",
+			[]string{"application/json", "text/html"}, "text/html"},
+		{"q-values", "text/html;q=0.5, application/json", []string{"text/html", "application/json"}, "application/json"},
+		{"any", "*/*", []string{"text/html", "application/json"}, "text/html"},
+		{"header order breaks ties", "application/json, text/html", []string{"text/html", "application/json"}, "application/json"},
+		{"subtype wildcard", "text/*", []string{"application/json", "text/plain"}, "text/plain"},
+		{"most specific range wins", "text/*;q=0.9, text/plain;q=0.1", []string{"text/plain", "text/csv"}, "text/csv"},
+		{"q=0 excludes", "application/json;q=0, */*", []string{"application/json", "application/xml"}, "application/xml"},
+		{"case insensitive", "Application/JSON", []string{"application/json"}, "application/json"},
+		{"offer parameters", "text/html", []string{"text/html; charset=utf-8"}, "text/html; charset=utf-8"},
+		{"none acceptable", "image/png", []string{"text/html", "application/json"}, ""},
+		{"no offers", "*/*", nil, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.accept != "" {
+				req.Header.Set("Accept", tt.accept)
+			}
+			c := newContext(httptest.NewRecorder(), req)
+
+			if got := c.Negotiate(tt.offers...); got != tt.want {
+				t.Errorf("Negotiate(%v) = %q, want %q", tt.offers, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,219 @@
+package rig
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"path"
+	"strings"
+	"unicode/utf8"
+)
+
+// EncodedSlashPolicy controls how percent-encoded slashes (%2F) in request
+// paths are routed.
+type EncodedSlashPolicy int
+
+const (
+	// EncodedSlashAllow keeps ServeMux's behavior: %2F is part of a path
+	// segment, so "/a%2Fb" does not match "/a/b" but a wildcard such as
+	// "/files/{name}" receives "a/b".
+	EncodedSlashAllow EncodedSlashPolicy = iota
+
+	// EncodedSlashReject answers requests whose path contains %2F with 400.
+	EncodedSlashReject
+
+	// EncodedSlashDecode treats %2F as a path separator, so "/a%2Fb" is
+	// routed like "/a/b".
+	EncodedSlashDecode
+)
+
+// CleanPathPolicy controls how request paths with repeated slashes or dot
+// segments (e.g., "/a//b/../c") are handled.
+type CleanPathPolicy int
+
+const (
+	// CleanPathDefault keeps ServeMux's behavior: such requests are
+	// redirected to the clean path with 307 Temporary Redirect, so clients
+	// make two requests every time.
+	CleanPathDefault CleanPathPolicy = iota
+
+	// CleanPathRewrite routes the clean path without a redirect, so clients
+	// building URLs sloppily are served directly.
+	CleanPathRewrite
+
+	// CleanPathRedirect redirects to the clean path with 308 Permanent
+	// Redirect, which clients follow with the same method and body and may
+	// cache.
+	CleanPathRedirect
+)
+
+// DuplicateQueryPolicy controls how repeated query parameters
+// (e.g., "?page=1&page=2") are handled.
+type DuplicateQueryPolicy int
+
+const (
+	// DuplicateQueryAllow keeps every value (the default). Query returns the
+	// first value; QueryArray and BindQuery see all of them.
+	DuplicateQueryAllow DuplicateQueryPolicy = iota
+
+	// DuplicateQueryFirst keeps only the first value of each parameter.
+	DuplicateQueryFirst
+
+	// DuplicateQueryLast keeps only the last value of each parameter.
+	DuplicateQueryLast
+
+	// DuplicateQueryReject answers requests with repeated parameters with 400.
+	DuplicateQueryReject
+)
+
+// NormalizeConfig defines opt-in request normalization applied by the Router
+// before routing. Every option is disabled by default, so routing behaves
+// exactly like http.ServeMux unless a rule is enabled explicitly.
+type NormalizeConfig struct {
+	// LowercasePaths lowercases request paths before matching, making routes
+	// case-insensitive. Register routes with lowercase paths when enabled.
+	// Path parameters receive the lowercased values.
+	LowercasePaths bool
+
+	// CleanPaths controls how paths with repeated slashes or dot segments
+	// are handled ("/a//b/../c" is cleaned to "/a/c"). Trailing slashes are
+	// kept.
+	// Default: CleanPathDefault (ServeMux behavior).
+	CleanPaths CleanPathPolicy
+
+	// EncodedSlashes controls how %2F in paths is routed.
+	// Default: EncodedSlashAllow (ServeMux behavior).
+	EncodedSlashes EncodedSlashPolicy
+
+	// DuplicateQuery controls how repeated query parameters are handled.
+	// Note that DuplicateQueryFirst and DuplicateQueryLast re-encode the query
+	// string, sorting parameters by key.
+	// Default: DuplicateQueryAllow.
+	DuplicateQuery DuplicateQueryPolicy
+
+	// ValidateUTF8 answers requests whose decoded path is not valid UTF-8
+	// with 400 instead of routing them.
+	ValidateUTF8 bool
+}
+
+// SetNormalization enables request normalization for the router.
+// Rejected requests are passed to the router's error handler as *HTTPError
+// values with status 400. Normalization is applied by Router.ServeHTTP; the
+// ServeMux returned by Handler does not normalize.
+//
+// Example:
+//
+//	r.SetNormalization(rig.NormalizeConfig{
+//	    CleanPaths:     rig.CleanPathRewrite,
+//	    LowercasePaths: true,
+//	    EncodedSlashes: rig.EncodedSlashReject,
+//	    DuplicateQuery: rig.DuplicateQueryReject,
+//	    ValidateUTF8:   true,
+//	})
+func (r *Router) SetNormalization(config NormalizeConfig) {
+	r.normalize = &config
+}
+
+// normalizeRequest applies config to req. It returns the request to route,
+// which is a shallow copy when anything changed, or an error if the request
+// must be rejected.
+func normalizeRequest(req *http.Request, config *NormalizeConfig) (*http.Request, error) {
+	u := *req.URL
+	changed := false
+
+	if config.ValidateUTF8 && !utf8.ValidString(u.Path) {
+		return nil, NewHTTPError(http.StatusBadRequest, "invalid UTF-8 in request path")
+	}
+
+	if config.EncodedSlashes != EncodedSlashAllow && u.RawPath != "" &&
+		strings.Contains(strings.ToUpper(u.RawPath), "%2F") {
+		if config.EncodedSlashes == EncodedSlashReject {
+			return nil, NewHTTPError(http.StatusBadRequest, "encoded slash in request path")
+		}
+		// Path is already decoded; dropping RawPath makes "/" a separator
+		u.RawPath = ""
+		changed = true
+	}
+
+	if config.CleanPaths == CleanPathRewrite {
+		if clean := cleanPath(u.Path); clean != u.Path {
+			u.Path = clean
+			if u.RawPath != "" {
+				u.RawPath = cleanPath(u.RawPath)
+			}
+			changed = true
+		}
+	}
+
+	if config.LowercasePaths {
+		if lower := strings.ToLower(u.Path); lower != u.Path {
+			u.Path = lower
+			u.RawPath = strings.ToLower(u.RawPath)
+			changed = true
+		}
+	}
+
+	if config.DuplicateQuery != DuplicateQueryAllow && u.RawQuery != "" {
+		query, err := url.ParseQuery(u.RawQuery)
+		if err != nil {
+			return nil, NewHTTPError(http.StatusBadRequest, "invalid query string")
+		}
+		deduplicated := false
+		for key, values := range query {
+			if len(values) < 2 {
+				continue
+			}
+			switch config.DuplicateQuery {
+			case DuplicateQueryReject:
+				return nil, NewHTTPError(http.StatusBadRequest,
+					fmt.Sprintf("duplicate query parameter '%s'", key))
+			case DuplicateQueryFirst:
+				query[key] = values[:1]
+			case DuplicateQueryLast:
+				query[key] = values[len(values)-1:]
+			}
+			deduplicated = true
+		}
+		if deduplicated {
+			u.RawQuery = query.Encode()
+			changed = true
+		}
+	}
+
+	if !changed {
+		return req, nil
+	}
+	normalized := new(http.Request)
+	*normalized = *req
+	normalized.URL = &u
+	return normalized, nil
+}
+
+// cleanRedirect redirects req to its clean path with 308 Permanent Redirect
+// if its path is not clean, and reports whether it did.
+func cleanRedirect(w http.ResponseWriter, req *http.Request) bool {
+	clean := cleanPath(req.URL.Path)
+	if clean == req.URL.Path || req.Method == http.MethodConnect {
+		return false
+	}
+	u := url.URL{Path: clean, RawQuery: req.URL.RawQuery}
+	http.Redirect(w, req, u.RequestURI(), http.StatusPermanentRedirect)
+	return true
+}
+
+// cleanPath returns the canonical form of p, as ServeMux does: with a
+// leading slash, without repeated slashes or dot segments, and keeping a
+// trailing slash.
+func cleanPath(p string) string {
+	if p == "" {
+		return "/"
+	}
+	if p[0] != '/' {
+		p = "/" + p
+	}
+	clean := path.Clean(p)
+	if p[len(p)-1] == '/' && clean != "/" {
+		clean += "/"
+	}
+	return clean
+}
@@ -0,0 +1,161 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func normalizeRouter(config NormalizeConfig) *Router {
+	r := New()
+	r.SetNormalization(config)
+	r.GET("/users/profile", func(c *Context) error {
+		return c.String(http.StatusOK, "profile")
+	})
+	r.GET("/a/b", func(c *Context) error {
+		return c.String(http.StatusOK, "ab")
+	})
+	r.GET("/files/{name}", func(c *Context) error {
+		return c.String(http.StatusOK, "file:%s", c.Param("name"))
+	})
+	r.GET("/search", func(c *Context) error {
+		return c.String(http.StatusOK, "%v", c.QueryArray("tag"))
+	})
+	return r
+}
+
+func serveNormalized(r *Router, target string) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+	return w
+}
+
+func TestNormalization_Disabled(t *testing.T) {
+	r := normalizeRouter(NormalizeConfig{})
+
+	tests := []struct {
+		target string
+		code   int
+		body   string
+	}{
+		{"/Users/Profile", http.StatusNotFound, ""},
+		{"/a%2Fb", http.StatusNotFound, ""},
+		{"/files/x%2Fy", http.StatusOK, "file:x/y"},
+		{"/search?tag=a&tag=b", http.StatusOK, "[a b]"},
+	}
+	for _, tt := range tests {
+		w := serveNormalized(r, tt.target)
+		if w.Code != tt.code || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: got %d %q, want %d %q", tt.target, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestNormalization_LowercasePaths(t *testing.T) {
+	r := normalizeRouter(NormalizeConfig{LowercasePaths: true})
+
+	w := serveNormalized(r, "/Users/PROFILE")
+	if w.Code != http.StatusOK || w.Body.String() != "profile" {
+		t.Errorf("got %d %q, want 200 profile", w.Code, w.Body.String())
+	}
+}
+
+func TestNormalization_EncodedSlashes(t *testing.T) {
+	t.Run("reject", func(t *testing.T) {
+		r := normalizeRouter(NormalizeConfig{EncodedSlashes: EncodedSlashReject})
+
+		if w := serveNormalized(r, "/files/x%2fy"); w.Code != http.StatusBadRequest {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusBadRequest)
+		}
+		if w := serveNormalized(r, "/files/x%20y"); w.Code != http.StatusOK {
+			t.Errorf("other escapes should be allowed, got %d", w.Code)
+		}
+	})
+
+	t.Run("decode", func(t *testing.T) {
+		r := normalizeRouter(NormalizeConfig{EncodedSlashes: EncodedSlashDecode})
+
+		w := serveNormalized(r, "/a%2Fb")
+		if w.Code != http.StatusOK || w.Body.String() != "ab" {
+			t.Errorf("got %d %q, want 200 ab", w.Code, w.Body.String())
+		}
+	})
+}
+
+func TestNormalization_DuplicateQuery(t *testing.T) {
+	tests := []struct {
+		policy DuplicateQueryPolicy
+		code   int
+		body   string
+	}{
+		{DuplicateQueryFirst, http.StatusOK, "[a]"},
+		{DuplicateQueryLast, http.StatusOK, "[c]"},
+		{DuplicateQueryReject, http.StatusBadRequest, "duplicate query parameter 'tag'"},
+	}
+
+	for _, tt := range tests {
+		r := normalizeRouter(NormalizeConfig{DuplicateQuery: tt.policy})
+		w := serveNormalized(r, "/search?tag=a&tag=b&tag=c&q=x")
+		if w.Code != tt.code || w.Body.String() != tt.body {
+			t.Errorf("policy %d: got %d %q, want %d %q", tt.policy, w.Code, w.Body.String(), tt.code, tt.body)
+		}
+	}
+}
+
+func TestNormalization_ValidateUTF8(t *testing.T) {
+	r := normalizeRouter(NormalizeConfig{ValidateUTF8: true})
+
+	if w := serveNormalized(r, "/files/%ff%fe"); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid UTF-8 status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+	if w := serveNormalized(r, "/files/caf%C3%A9"); w.Code != http.StatusOK || w.Body.String() != "file:café" {
+		t.Errorf("valid UTF-8: got %d %q", w.Code, w.Body.String())
+	}
+}
+
+func TestNormalization_DoesNotMutateRequest(t *testing.T) {
+	r := normalizeRouter(NormalizeConfig{LowercasePaths: true, DuplicateQuery: DuplicateQueryFirst})
+
+	req := httptest.NewRequest(http.MethodGet, "/USERS/profile?tag=a&tag=b", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	if req.URL.Path != "/USERS/profile" || req.URL.RawQuery != "tag=a&tag=b" {
+		t.Errorf("original request modified: %s?%s", req.URL.Path, req.URL.RawQuery)
+	}
+}
+
+func TestNormalization_CleanPaths(t *testing.T) {
+	tests := []struct {
+		policy   CleanPathPolicy
+		method   string
+		code     int
+		location string
+	}{
+		{CleanPathDefault, http.MethodGet, http.StatusTemporaryRedirect, "/a/b?x=1"},
+		{CleanPathRewrite, http.MethodGet, http.StatusOK, ""},
+		{CleanPathRewrite, http.MethodPost, http.StatusOK, ""},
+		{CleanPathRedirect, http.MethodPost, http.StatusPermanentRedirect, "/a/b?x=1"},
+	}
+	for _, tt := range tests {
+		r := normalizeRouter(NormalizeConfig{CleanPaths: tt.policy})
+		r.POST("/a/b", func(c *Context) error {
+			return c.String(http.StatusOK, "posted")
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, "/a//c/../b?x=1", nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("policy %d, %s: got %d Location %q, want %d %q",
+				tt.policy, tt.method, w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+
+	// Clean paths and trailing slashes are left alone
+	r := normalizeRouter(NormalizeConfig{CleanPaths: CleanPathRedirect})
+	if w := serveNormalized(r, "/a/b"); w.Code != http.StatusOK {
+		t.Errorf("clean path: got %d", w.Code)
+	}
+	if got := cleanPath("/a/./b/"); got != "/a/b/" {
+		t.Errorf("cleanPath = %q, want /a/b/", got)
+	}
+}
@@ -0,0 +1,35 @@
+package rig
+
+// OwnerKey is the context key under which Owner stores the team that owns
+// the current route. Use OwnerOf to read it.
+const OwnerKey = "rig.owner"
+
+// Owner creates middleware that tags routes with the team (or person) that
+// owns them. The owner is included in Logger attributes, PanicReport,
+// ResponseEvent (e.g., as a metrics label), and the logger package's
+// entries, so errors and alerts identify the responsible team.
+//
+// Use it on a router, a group, or a single route; the innermost Owner wins.
+//
+// Example:
+//
+//	payments := r.Group("/payments")
+//	payments.Use(rig.Owner("team-payments"))
+//
+//	// A single route owned by another team
+//	payments.GET("/fx-rates", rig.Owner("team-treasury")(fxRatesHandler))
+func Owner(team string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(OwnerKey, team)
+			return next(c)
+		}
+	}
+}
+
+// OwnerOf returns the owner of the current route set with Owner, or "" if
+// none was set.
+func OwnerOf(c *Context) string {
+	owner, _ := GetType[string](c, OwnerKey)
+	return owner
+}
@@ -0,0 +1,77 @@
+package rig
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOwner(t *testing.T) {
+	var logs bytes.Buffer
+	var events []ResponseEvent
+	var report PanicReport
+
+	r := New()
+	r.Use(RecoverWithConfig(RecoverConfig{
+		Logger: func(any, []byte) {},
+		Reporter: PanicReporterFunc(func(_ context.Context, p PanicReport) {
+			report = p
+		}),
+	}))
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(LoggerKey, slog.New(slog.NewJSONHandler(&logs, nil)))
+			return next(c)
+		}
+	})
+	r.OnResponse(func(e ResponseEvent) {
+		events = append(events, e)
+	})
+
+	payments := r.Group("/payments")
+	payments.Use(Owner("team-payments"))
+	payments.GET("/charges", func(c *Context) error {
+		Logger(c).Info("listing charges")
+		return c.String(http.StatusOK, "%s", OwnerOf(c))
+	})
+	payments.GET("/fx-rates", Owner("team-treasury")(func(c *Context) error {
+		panic("rates unavailable")
+	}))
+	r.GET("/unowned", func(c *Context) error {
+		return c.String(http.StatusOK, "%s", OwnerOf(c))
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/payments/charges", nil))
+	if w.Body.String() != "team-payments" {
+		t.Errorf("OwnerOf = %q, want team-payments", w.Body.String())
+	}
+	if !strings.Contains(logs.String(), `"owner":"team-payments"`) {
+		t.Errorf("Logger should include the owner:\n%s", logs.String())
+	}
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/payments/fx-rates", nil))
+	if report.Owner != "team-treasury" {
+		t.Errorf("PanicReport.Owner = %q, want innermost owner team-treasury", report.Owner)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/unowned", nil))
+	if w.Body.String() != "" {
+		t.Errorf("OwnerOf for unowned route = %q, want empty", w.Body.String())
+	}
+
+	want := []string{"team-payments", "team-treasury", ""}
+	if len(events) != len(want) {
+		t.Fatalf("got %d response events, want %d", len(events), len(want))
+	}
+	for i, e := range events {
+		if e.Owner != want[i] {
+			t.Errorf("event %d Owner = %q, want %q", i, e.Owner, want[i])
+		}
+	}
+}
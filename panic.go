@@ -0,0 +1,317 @@
+package rig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// PanicReport describes a panic recovered by Recover. It is passed to the
+// configured PanicReporter.
+type PanicReport struct {
+	// Time is when the panic was recovered.
+	Time time.Time `json:"time"`
+
+	// Value is the value the handler panicked with.
+	Value any `json:"-"`
+
+	// Error is Value formatted with fmt.Sprint.
+	Error string `json:"error"`
+
+	// Stack is the stack trace of the panicking goroutine.
+	Stack string `json:"stack"`
+
+	// Method and Path describe the request.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Route is the matched route pattern (e.g., "GET /users/{id}").
+	Route string `json:"route,omitempty"`
+
+	// RequestID is the ID stored under RequestIDKey, if any.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Identity is the authenticated identity stored under IdentityKey, if any.
+	Identity string `json:"identity,omitempty"`
+
+	// Owner is the team owning the route (see Owner), if any.
+	Owner string `json:"owner,omitempty"`
+
+	// RemoteAddr and UserAgent describe the client.
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+}
+
+// newPanicReport builds the report for a panic recovered while serving c.
+func newPanicReport(c *Context, value any, stack []byte) PanicReport {
+	report := PanicReport{
+		Time:       time.Now(),
+		Value:      value,
+		Error:      fmt.Sprint(value),
+		Stack:      string(stack),
+		Method:     c.Method(),
+		Path:       c.Path(),
+		Route:      c.request.Pattern,
+		RemoteAddr: c.request.RemoteAddr,
+		UserAgent:  c.GetHeader("User-Agent"),
+		Owner:      OwnerOf(c),
+	}
+	if id, ok := c.Get(RequestIDKey); ok {
+		report.RequestID, _ = id.(string)
+	}
+	if identity, ok := c.Get(IdentityKey); ok {
+		report.Identity, _ = identity.(string)
+	}
+	return report
+}
+
+// ErrorReport describes an error returned by a handler and answered with a
+// server error status (5xx). It is passed to the router's Reporter.
+type ErrorReport struct {
+	// Time is when the error was handled.
+	Time time.Time `json:"time"`
+
+	// Err is the error returned by the handler.
+	Err error `json:"-"`
+
+	// Error is Err's message.
+	Error string `json:"error"`
+
+	// Status is the response status.
+	Status int `json:"status"`
+
+	// Stack is the stack trace recorded by the error, if it carries one
+	// through a Stack() []byte method (as errors from github.com/go-errors
+	// do). Plain errors record no stack.
+	Stack string `json:"stack,omitempty"`
+
+	// Method and Path describe the request.
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Route is the matched route pattern (e.g., "GET /users/{id}").
+	Route string `json:"route,omitempty"`
+
+	// RequestID is the ID stored under RequestIDKey, if any.
+	RequestID string `json:"request_id,omitempty"`
+
+	// Identity is the authenticated identity stored under IdentityKey, if any.
+	Identity string `json:"identity,omitempty"`
+
+	// Owner is the team owning the route (see Owner), if any.
+	Owner string `json:"owner,omitempty"`
+
+	// RemoteAddr and UserAgent describe the client.
+	RemoteAddr string `json:"remote_addr,omitempty"`
+	UserAgent  string `json:"user_agent,omitempty"`
+}
+
+// newErrorReport builds the report for err, returned while serving c.
+func newErrorReport(c *Context, err error) ErrorReport {
+	report := ErrorReport{
+		Time:       time.Now(),
+		Err:        err,
+		Error:      err.Error(),
+		Status:     c.StatusCode(),
+		Method:     c.Method(),
+		Path:       c.Path(),
+		Route:      c.request.Pattern,
+		RemoteAddr: c.request.RemoteAddr,
+		UserAgent:  c.GetHeader("User-Agent"),
+		Owner:      OwnerOf(c),
+	}
+	var st interface{ Stack() []byte }
+	if errors.As(err, &st) {
+		report.Stack = string(st.Stack())
+	}
+	if id, ok := c.Get(RequestIDKey); ok {
+		report.RequestID, _ = id.(string)
+	}
+	if identity, ok := c.Get(IdentityKey); ok {
+		report.Identity, _ = identity.(string)
+	}
+	return report
+}
+
+// PanicReporter sends recovered panics to an error tracking service (Sentry,
+// Rollbar, a webhook, ...). Set one in RecoverConfig.Reporter, or set a
+// Reporter on the router to receive server errors too.
+//
+// ReportPanic is called on the request goroutine before the 500 response is
+// written; implementations should hand slow work off to a goroutine. ctx is
+// the request context with cancellation removed, so it carries request-scoped
+// values (e.g., trace spans) and remains usable after the request completes.
+type PanicReporter interface {
+	ReportPanic(ctx context.Context, report PanicReport)
+}
+
+// PanicReporterFunc adapts a function to the PanicReporter interface.
+type PanicReporterFunc func(ctx context.Context, report PanicReport)
+
+// ReportPanic calls f(ctx, report).
+func (f PanicReporterFunc) ReportPanic(ctx context.Context, report PanicReport) {
+	f(ctx, report)
+}
+
+// NopPanicReporter is a PanicReporter that discards reports. Set it in
+// RecoverConfig.Reporter to keep panics from reaching the router's Reporter.
+var NopPanicReporter PanicReporter = PanicReporterFunc(func(context.Context, PanicReport) {})
+
+// Reporter sends both recovered panics and server errors to an error
+// tracking service, so an integration (Sentry, Rollbar, Bugsnag, ...) is a
+// single adapter. Set one with Router.SetReporter.
+//
+// ReportError is called for errors returned by handlers that are answered
+// with a 5xx status, after the response is written; client errors (4xx) are
+// not reported. The same rules as for ReportPanic apply: hand slow work off
+// to a goroutine, and use ctx for request-scoped values.
+type Reporter interface {
+	PanicReporter
+	ReportError(ctx context.Context, report ErrorReport)
+}
+
+// SetReporter sets the router's Reporter. It receives server errors returned
+// by handlers, and panics recovered by Recover (and Preset) when
+// RecoverConfig.Reporter is not set. A panic is reported once, not again as
+// the error it is turned into.
+//
+// Example:
+//
+//	type sentryReporter struct{}
+//
+//	func (sentryReporter) ReportPanic(ctx context.Context, report rig.PanicReport) {
+//	    sentry.GetHubFromContext(ctx).RecoverWithContext(ctx, report.Value)
+//	}
+//
+//	func (sentryReporter) ReportError(ctx context.Context, report rig.ErrorReport) {
+//	    sentry.GetHubFromContext(ctx).CaptureException(report.Err)
+//	}
+//
+//	r.SetReporter(sentryReporter{})
+//	r.Use(rig.Recover())
+func (r *Router) SetReporter(reporter Reporter) {
+	r.reporter = reporter
+}
+
+// reportPanic sends a panic recovered while serving c to reporter, or to the
+// router's Reporter if reporter is nil, and marks it as reported.
+func reportPanic(c *Context, reporter PanicReporter, value any, stack []byte) {
+	c.panicReported = true
+	if reporter == nil && c.router != nil && c.router.reporter != nil {
+		reporter = c.router.reporter
+	}
+	if reporter != nil {
+		reporter.ReportPanic(context.WithoutCancel(c.Context()), newPanicReport(c, value, stack))
+	}
+}
+
+// WebhookConfig defines the configuration for WebhookReporter.
+type WebhookConfig struct {
+	// URL receives each PanicReport and ErrorReport as a JSON POST request.
+	// Required.
+	URL string
+
+	// Header is added to every request (e.g., an Authorization header).
+	Header http.Header
+
+	// Client sends the requests.
+	// Default: http.DefaultClient.
+	Client *http.Client
+
+	// Timeout limits each request.
+	// Default: 10 seconds.
+	Timeout time.Duration
+
+	// OnError is called when a report cannot be delivered (including non-2xx
+	// responses).
+	// Default: logs to stderr with "[RIG] panic report failed:" prefix.
+	OnError func(err error)
+}
+
+// WebhookReporter creates a Reporter that POSTs each report as JSON to
+// config.URL. Reports are sent asynchronously, so a slow endpoint does not
+// delay the 500 response. Error reports can be told apart by their "status"
+// field.
+//
+// Example:
+//
+//	r.Use(rig.RecoverWithConfig(rig.RecoverConfig{
+//	    Reporter: rig.WebhookReporter(rig.WebhookConfig{
+//	        URL:    "https://alerts.example.com/hooks/panics",
+//	        Header: http.Header{"Authorization": {"Bearer " + token}},
+//	    }),
+//	}))
+func WebhookReporter(config WebhookConfig) Reporter {
+	if config.Client == nil {
+		config.Client = http.DefaultClient
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.OnError == nil {
+		config.OnError = func(err error) {
+			log.Printf("[RIG] panic report failed: %v", err)
+		}
+	}
+
+	return webhookReporter{config}
+}
+
+// webhookReporter is the Reporter returned by WebhookReporter.
+type webhookReporter struct {
+	config WebhookConfig
+}
+
+// ReportPanic implements PanicReporter.
+func (w webhookReporter) ReportPanic(ctx context.Context, report PanicReport) {
+	w.send(ctx, report)
+}
+
+// ReportError implements Reporter.
+func (w webhookReporter) ReportError(ctx context.Context, report ErrorReport) {
+	w.send(ctx, report)
+}
+
+// send posts report in the background.
+func (w webhookReporter) send(ctx context.Context, report any) {
+	body, err := json.Marshal(report)
+	if err != nil {
+		w.config.OnError(err)
+		return
+	}
+	go func() {
+		if err := postReport(ctx, w.config, body); err != nil {
+			w.config.OnError(err)
+		}
+	}()
+}
+
+// postReport sends one JSON report to the webhook.
+func postReport(ctx context.Context, config WebhookConfig, body []byte) error {
+	ctx, cancel := context.WithTimeout(ctx, config.Timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, config.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range config.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := config.Client.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook responded with status %d", resp.StatusCode)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+package rig
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRecover_Reporter(t *testing.T) {
+	type ctxKey struct{}
+
+	var got PanicReport
+	var gotCtx context.Context
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(RequestIDKey, "req-1")
+			c.Set(IdentityKey, "user-42")
+			c.SetContext(context.WithValue(c.Context(), ctxKey{}, "trace"))
+			return next(c)
+		}
+	})
+	r.Use(RecoverWithConfig(RecoverConfig{
+		Logger: func(any, []byte) {},
+		Reporter: PanicReporterFunc(func(ctx context.Context, report PanicReport) {
+			got, gotCtx = report, ctx
+		}),
+	}))
+	r.GET("/orders/{id}", func(c *Context) error {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/orders/7", nil)
+	req.Header.Set("User-Agent", "test-agent")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if got.Value != "boom" || got.Error != "boom" {
+		t.Errorf("Value = %v, Error = %q", got.Value, got.Error)
+	}
+	if got.Method != http.MethodGet || got.Path != "/orders/7" || got.Route != "GET /orders/{id}" {
+		t.Errorf("request = %s %s (%s)", got.Method, got.Path, got.Route)
+	}
+	if got.RequestID != "req-1" || got.Identity != "user-42" || got.UserAgent != "test-agent" {
+		t.Errorf("metadata = %+v", got)
+	}
+	if !strings.Contains(got.Stack, "panic") {
+		t.Error("Stack should contain the stack trace")
+	}
+	if gotCtx.Value(ctxKey{}) != "trace" {
+		t.Error("reporter context should carry request values")
+	}
+}
+
+func TestWebhookReporter(t *testing.T) {
+	received := make(chan PanicReport, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Authorization = %q", req.Header.Get("Authorization"))
+		}
+		var report PanicReport
+		if err := json.NewDecoder(req.Body).Decode(&report); err != nil {
+			t.Errorf("decode report: %v", err)
+		}
+		received <- report
+	}))
+	defer server.Close()
+
+	reporter := WebhookReporter(WebhookConfig{
+		URL:    server.URL,
+		Header: http.Header{"Authorization": {"Bearer secret"}},
+	})
+	reporter.ReportPanic(context.Background(), PanicReport{Error: "boom", Path: "/orders/7"})
+
+	select {
+	case report := <-received:
+		if report.Error != "boom" || report.Path != "/orders/7" {
+			t.Errorf("report = %+v", report)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("webhook was not called")
+	}
+}
+
+func TestWebhookReporter_OnError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	errs := make(chan error, 1)
+	reporter := WebhookReporter(WebhookConfig{
+		URL:     server.URL,
+		OnError: func(err error) { errs <- err },
+	})
+	reporter.ReportPanic(context.Background(), PanicReport{Error: "boom"})
+
+	select {
+	case err := <-errs:
+		if !strings.Contains(err.Error(), "502") {
+			t.Errorf("err = %v, want status in message", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("OnError was not called")
+	}
+}
+
+// recordingReporter records the reports it receives.
+type recordingReporter struct {
+	panics []PanicReport
+	errors []ErrorReport
+}
+
+func (r *recordingReporter) ReportPanic(_ context.Context, report PanicReport) {
+	r.panics = append(r.panics, report)
+}
+
+func (r *recordingReporter) ReportError(_ context.Context, report ErrorReport) {
+	r.errors = append(r.errors, report)
+}
+
+// stackError is an error carrying a stack trace.
+type stackError struct{ error }
+
+func (stackError) Stack() []byte { return []byte("goroutine 1 [running]") }
+
+func TestSetReporter_Errors(t *testing.T) {
+	reporter := &recordingReporter{}
+	r := New()
+	r.SetReporter(reporter)
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(RequestIDKey, "req-1")
+			return next(c)
+		}
+	})
+	r.GET("/fail", func(c *Context) error {
+		return fmt.Errorf("load order: %w", stackError{errors.New("db down")})
+	})
+	r.GET("/missing", func(c *Context) error {
+		return NewHTTPError(http.StatusNotFound, "not found")
+	})
+	r.GET("/unavailable", func(c *Context) error {
+		_ = c.JSON(http.StatusServiceUnavailable, map[string]string{"error": "busy"})
+		return errors.New("upstream busy")
+	})
+
+	for _, path := range []string{"/fail", "/missing", "/unavailable"} {
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	if len(reporter.errors) != 2 {
+		t.Fatalf("reported %d errors, want 2 (client errors are not reported)", len(reporter.errors))
+	}
+	got := reporter.errors[0]
+	if got.Error != "load order: db down" || got.Status != http.StatusInternalServerError {
+		t.Errorf("report = %q (%d)", got.Error, got.Status)
+	}
+	if got.Route != "GET /fail" || got.RequestID != "req-1" {
+		t.Errorf("request = %s, request ID %q", got.Route, got.RequestID)
+	}
+	if got.Stack != "goroutine 1 [running]" {
+		t.Errorf("Stack = %q, want the error's stack", got.Stack)
+	}
+	if reporter.errors[1].Status != http.StatusServiceUnavailable || reporter.errors[1].Stack != "" {
+		t.Errorf("second report = %+v", reporter.errors[1])
+	}
+}
+
+func TestSetReporter_Panics(t *testing.T) {
+	reporter := &recordingReporter{}
+	r := New()
+	r.SetReporter(reporter)
+	r.Use(RecoverWithConfig(RecoverConfig{
+		Logger: func(any, []byte) {},
+		Handler: func(c *Context, err any) error {
+			return fmt.Errorf("panic: %v", err)
+		},
+	}))
+	r.GET("/panic", func(c *Context) error {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	if len(reporter.panics) != 1 || reporter.panics[0].Error != "boom" {
+		t.Errorf("panics = %+v", reporter.panics)
+	}
+	if len(reporter.errors) != 0 {
+		t.Errorf("panic was also reported as an error: %+v", reporter.errors)
+	}
+}
@@ -0,0 +1,160 @@
+package rig
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// paramError builds a descriptive error for a missing or malformed parameter.
+func paramError(kind, name, value, want string, err error) error {
+	if value == "" {
+		return fmt.Errorf("rig: %s parameter '%s' is missing", kind, name)
+	}
+	return fmt.Errorf("rig: %s parameter '%s' is not a valid %s: %w", kind, name, want, err)
+}
+
+// ParamInt returns the value of a path parameter parsed as an int.
+// It returns an error if the parameter is missing or not a valid integer.
+//
+// Example:
+//
+//	id, err := c.ParamInt("id")
+//	if err != nil {
+//	    return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+//	}
+func (c *Context) ParamInt(name string) (int, error) {
+	value := c.Param(name)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, paramError("path", name, value, "integer", err)
+	}
+	return n, nil
+}
+
+// ParamIntDefault returns the value of a path parameter parsed as an int,
+// or the default value if the parameter is missing or not a valid integer.
+func (c *Context) ParamIntDefault(name string, defaultValue int) int {
+	n, err := c.ParamInt(name)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// QueryInt returns the value of a query string parameter parsed as an int.
+// It returns an error if the parameter is missing or not a valid integer.
+func (c *Context) QueryInt(key string) (int, error) {
+	value := c.Query(key)
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, paramError("query", key, value, "integer", err)
+	}
+	return n, nil
+}
+
+// QueryIntDefault returns the value of a query string parameter parsed as an int,
+// or the default value if the parameter is missing or not a valid integer.
+//
+// Example:
+//
+//	page := c.QueryIntDefault("page", 1)
+func (c *Context) QueryIntDefault(key string, defaultValue int) int {
+	n, err := c.QueryInt(key)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// QueryInt64 returns the value of a query string parameter parsed as an int64.
+// It returns an error if the parameter is missing or not a valid integer.
+func (c *Context) QueryInt64(key string) (int64, error) {
+	value := c.Query(key)
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, paramError("query", key, value, "integer", err)
+	}
+	return n, nil
+}
+
+// QueryInt64Default returns the value of a query string parameter parsed as an int64,
+// or the default value if the parameter is missing or not a valid integer.
+func (c *Context) QueryInt64Default(key string, defaultValue int64) int64 {
+	n, err := c.QueryInt64(key)
+	if err != nil {
+		return defaultValue
+	}
+	return n
+}
+
+// QueryBool returns the value of a query string parameter parsed as a bool.
+// It accepts the values understood by strconv.ParseBool
+// ("1", "t", "true", "0", "f", "false", and their upper-case forms).
+// It returns an error if the parameter is missing or not a valid boolean.
+func (c *Context) QueryBool(key string) (bool, error) {
+	value := c.Query(key)
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		return false, paramError("query", key, value, "boolean", err)
+	}
+	return b, nil
+}
+
+// QueryBoolDefault returns the value of a query string parameter parsed as a bool,
+// or the default value if the parameter is missing or not a valid boolean.
+func (c *Context) QueryBoolDefault(key string, defaultValue bool) bool {
+	b, err := c.QueryBool(key)
+	if err != nil {
+		return defaultValue
+	}
+	return b
+}
+
+// QueryFloat returns the value of a query string parameter parsed as a float64.
+// It returns an error if the parameter is missing or not a valid number.
+func (c *Context) QueryFloat(key string) (float64, error) {
+	value := c.Query(key)
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, paramError("query", key, value, "number", err)
+	}
+	return f, nil
+}
+
+// QueryFloatDefault returns the value of a query string parameter parsed as a float64,
+// or the default value if the parameter is missing or not a valid number.
+func (c *Context) QueryFloatDefault(key string, defaultValue float64) float64 {
+	f, err := c.QueryFloat(key)
+	if err != nil {
+		return defaultValue
+	}
+	return f
+}
+
+// QueryTime returns the value of a query string parameter parsed as a time.Time
+// using the given layout (e.g., time.RFC3339 or "2006-01-02").
+// It returns an error if the parameter is missing or does not match the layout.
+//
+// Example:
+//
+//	since, err := c.QueryTime("since", time.RFC3339)
+func (c *Context) QueryTime(key, layout string) (time.Time, error) {
+	value := c.Query(key)
+	t, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, paramError("query", key, value, "time", err)
+	}
+	return t, nil
+}
+
+// QueryTimeDefault returns the value of a query string parameter parsed as a
+// time.Time using the given layout, or the default value if the parameter is
+// missing or does not match the layout.
+func (c *Context) QueryTimeDefault(key, layout string, defaultValue time.Time) time.Time {
+	t, err := c.QueryTime(key, layout)
+	if err != nil {
+		return defaultValue
+	}
+	return t
+}
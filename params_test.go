@@ -0,0 +1,128 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestContext_ParamInt(t *testing.T) {
+	r := New()
+
+	var got int
+	var gotErr error
+	r.GET("/items/{id}", func(c *Context) error {
+		got, gotErr = c.ParamInt("id")
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/42", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if gotErr != nil || got != 42 {
+		t.Errorf("ParamInt() = %d, %v; want 42, nil", got, gotErr)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/items/abc", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if gotErr == nil {
+		t.Error("ParamInt() expected error for non-integer value")
+	} else if !strings.Contains(gotErr.Error(), "'id' is not a valid integer") {
+		t.Errorf("ParamInt() error = %q", gotErr)
+	}
+}
+
+func TestContext_ParamIntDefault(t *testing.T) {
+	r := New()
+
+	var got int
+	r.GET("/items/{id}", func(c *Context) error {
+		got = c.ParamIntDefault("id", 7)
+		return nil
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/items/abc", nil)
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if got != 7 {
+		t.Errorf("ParamIntDefault() = %d, want 7", got)
+	}
+}
+
+func TestContext_TypedQuery(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet,
+		"/?page=3&big=9000000000&active=true&ratio=0.5&since=2024-01-15&bad=x", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	if n, err := c.QueryInt("page"); err != nil || n != 3 {
+		t.Errorf("QueryInt() = %d, %v; want 3, nil", n, err)
+	}
+	if n, err := c.QueryInt64("big"); err != nil || n != 9000000000 {
+		t.Errorf("QueryInt64() = %d, %v; want 9000000000, nil", n, err)
+	}
+	if b, err := c.QueryBool("active"); err != nil || !b {
+		t.Errorf("QueryBool() = %v, %v; want true, nil", b, err)
+	}
+	if f, err := c.QueryFloat("ratio"); err != nil || f != 0.5 {
+		t.Errorf("QueryFloat() = %v, %v; want 0.5, nil", f, err)
+	}
+	want := time.Date(2024, 1, 15, 0, 0, 0, 0, time.UTC)
+	if ts, err := c.QueryTime("since", "2006-01-02"); err != nil || !ts.Equal(want) {
+		t.Errorf("QueryTime() = %v, %v; want %v, nil", ts, err, want)
+	}
+}
+
+func TestContext_TypedQuery_Errors(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?bad=x", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	tests := []struct {
+		name    string
+		call    func() error
+		wantMsg string
+	}{
+		{"int missing", func() error { _, err := c.QueryInt("missing"); return err }, "'missing' is missing"},
+		{"int invalid", func() error { _, err := c.QueryInt("bad"); return err }, "not a valid integer"},
+		{"int64 invalid", func() error { _, err := c.QueryInt64("bad"); return err }, "not a valid integer"},
+		{"bool invalid", func() error { _, err := c.QueryBool("bad"); return err }, "not a valid boolean"},
+		{"float invalid", func() error { _, err := c.QueryFloat("bad"); return err }, "not a valid number"},
+		{"time invalid", func() error { _, err := c.QueryTime("bad", time.RFC3339); return err }, "not a valid time"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call()
+			if err == nil {
+				t.Fatal("expected error")
+			}
+			if !strings.Contains(err.Error(), tt.wantMsg) {
+				t.Errorf("error = %q, want to contain %q", err, tt.wantMsg)
+			}
+		})
+	}
+}
+
+func TestContext_TypedQuery_Defaults(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/?page=2&bad=x", nil)
+	c := newContext(httptest.NewRecorder(), req)
+
+	if got := c.QueryIntDefault("page", 1); got != 2 {
+		t.Errorf("QueryIntDefault(present) = %d, want 2", got)
+	}
+	if got := c.QueryIntDefault("bad", 1); got != 1 {
+		t.Errorf("QueryIntDefault(invalid) = %d, want 1", got)
+	}
+	if got := c.QueryInt64Default("missing", 5); got != 5 {
+		t.Errorf("QueryInt64Default() = %d, want 5", got)
+	}
+	if got := c.QueryBoolDefault("missing", true); !got {
+		t.Error("QueryBoolDefault() = false, want true")
+	}
+	if got := c.QueryFloatDefault("bad", 1.5); got != 1.5 {
+		t.Errorf("QueryFloatDefault() = %v, want 1.5", got)
+	}
+	def := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	if got := c.QueryTimeDefault("missing", time.RFC3339, def); !got.Equal(def) {
+		t.Errorf("QueryTimeDefault() = %v, want %v", got, def)
+	}
+}
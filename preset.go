@@ -0,0 +1,212 @@
+package rig
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"runtime/debug"
+	"time"
+)
+
+// Environment selects the middleware stack installed by Preset.
+type Environment string
+
+const (
+	// Production installs Recover, RealIP, SecureHeaders, and JSON request
+	// logs. Errors are answered with generic messages.
+	Production Environment = "production"
+
+	// Development installs Recover, text request logs, and verbose errors
+	// (error messages and panic stacks in responses). The render package
+	// reloads templates on each request, as with its DevMode option.
+	Development Environment = "development"
+)
+
+// EnvironmentKey is the context key under which Preset stores the
+// Environment. Use EnvironmentOf to read it.
+const EnvironmentKey = "rig.environment"
+
+// PresetConfig overrides parts of the stack installed by Preset.
+// Empty fields use the environment's defaults.
+type PresetConfig struct {
+	// Slog is the logger for request logs and recovered panics. It is also
+	// returned (with request attributes) by Logger in handlers.
+	// Default: a JSON handler (Production) or text handler (Development)
+	// writing to os.Stdout.
+	Slog *slog.Logger
+
+	// SkipPaths is a list of URL paths that are not logged.
+	// Example: []string{"/health", "/ready"}
+	SkipPaths []string
+
+	// SecureHeaders configures SecureHeaders in Production.
+	// Default: SecureHeadersConfig{} (see SecureHeaders for its defaults).
+	SecureHeaders SecureHeadersConfig
+
+	// TrustedProxies configures RealIP in Production.
+	// Default: DefaultTrustedProxies.
+	TrustedProxies []string
+
+	// ProxyHeader is the header the trusted proxies write the client
+	// address to (see RealIPConfig.Header).
+	// Default: X-Forwarded-For, or X-Real-IP for requests without it.
+	ProxyHeader string
+
+	// PanicReporter receives recovered panics (see RecoverConfig.Reporter).
+	// Default: the router's Reporter (see Router.SetReporter), if any.
+	PanicReporter PanicReporter
+}
+
+// Preset creates middleware that installs a sensible stack for env, so new
+// services start safe by default:
+//   - Production: RealIP, JSON request logs, Recover, SecureHeaders
+//   - Development: text request logs, Recover and error handling that
+//     write error messages and panic stacks to the response
+//
+// Register it first; middleware added afterwards runs inside the preset and
+// can add to or override it (e.g., a second SecureHeaders replaces headers).
+// Any other value of env is treated as Production.
+//
+// Example:
+//
+//	r := rig.New()
+//	r.Use(rig.Preset(rig.Environment(os.Getenv("APP_ENV")), rig.PresetConfig{
+//	    SkipPaths: []string{"/health", "/ready"},
+//	}))
+func Preset(env Environment, config ...PresetConfig) MiddlewareFunc {
+	cfg := PresetConfig{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if env != Development {
+		env = Production
+	}
+
+	if cfg.Slog == nil {
+		if env == Development {
+			cfg.Slog = slog.New(slog.NewTextHandler(os.Stdout, nil))
+		} else {
+			cfg.Slog = slog.New(slog.NewJSONHandler(os.Stdout, nil))
+		}
+	}
+
+	var stack []MiddlewareFunc
+	if env == Development {
+		stack = []MiddlewareFunc{
+			requestLog(cfg.Slog, cfg.SkipPaths),
+			verboseErrors(cfg.Slog, cfg.PanicReporter),
+		}
+	} else {
+		stack = []MiddlewareFunc{
+			RealIP(RealIPConfig{TrustedProxies: cfg.TrustedProxies, Header: cfg.ProxyHeader}),
+			requestLog(cfg.Slog, cfg.SkipPaths),
+			RecoverWithConfig(RecoverConfig{
+				Logger: func(err any, stack []byte) {
+					cfg.Slog.Error("panic recovered", "error", err, "stack", string(stack))
+				},
+				Reporter: cfg.PanicReporter,
+			}),
+			SecureHeaders(cfg.SecureHeaders),
+		}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		for i := len(stack) - 1; i >= 0; i-- {
+			next = stack[i](next)
+		}
+		return func(c *Context) error {
+			c.Set(EnvironmentKey, env)
+			return next(c)
+		}
+	}
+}
+
+// EnvironmentOf returns the Environment stored by Preset, or "" if Preset
+// is not in use.
+func EnvironmentOf(c *Context) Environment {
+	env, _ := GetType[Environment](c, EnvironmentKey)
+	return env
+}
+
+// requestLog creates middleware that logs each request through Logger, at
+// level Error for 5xx responses, Warn for 4xx, and Info otherwise.
+func requestLog(logger *slog.Logger, skipPaths []string) MiddlewareFunc {
+	skip := make(map[string]bool, len(skipPaths))
+	for _, path := range skipPaths {
+		skip[path] = true
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(LoggerKey, logger)
+			if skip[c.Path()] {
+				return next(c)
+			}
+
+			start := time.Now()
+			err := next(c)
+
+			// The error handler writes the response after this middleware,
+			// so infer the status from the error
+			status := c.StatusCode()
+			if err != nil && !c.Written() {
+				status = ErrorStatus(err)
+			}
+
+			level := slog.LevelInfo
+			switch {
+			case status >= 500:
+				level = slog.LevelError
+			case status >= 400:
+				level = slog.LevelWarn
+			}
+			attrs := []any{
+				"path", c.Path(),
+				"status", status,
+				"latency", time.Since(start),
+				"client_ip", c.Request().RemoteAddr,
+			}
+			for _, seg := range Segments(c) {
+				attrs = append(attrs, "segment."+seg.Name, seg.Duration)
+			}
+			if err != nil {
+				attrs = append(attrs, "error", err.Error())
+			}
+			Logger(c).Log(c.Context(), level, "request", attrs...)
+			return err
+		}
+	}
+}
+
+// verboseErrors creates middleware for development that answers server
+// errors and panics with their message (and stack) instead of a generic
+// "Internal Server Error". Client errors (4xx) are left to the error
+// handler. The error is still returned, so it is logged and reaches response
+// hooks.
+func verboseErrors(logger *slog.Logger, reporter PanicReporter) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) (err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					stack := debug.Stack()
+					logger.Error("panic recovered", "error", p, "stack", string(stack))
+					reportPanic(c, reporter, p, stack)
+					err = fmt.Errorf("panic: %v", p)
+					if !c.Written() {
+						_ = c.String(http.StatusInternalServerError, "%v\n\n%s", err, stack)
+					}
+				}
+			}()
+
+			err = next(c)
+			if err == nil || c.Written() {
+				return err
+			}
+			if status := ErrorStatus(err); status >= 500 {
+				_ = c.String(status, "%v", err)
+			}
+			return err
+		}
+	}
+}
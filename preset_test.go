@@ -0,0 +1,117 @@
+package rig
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func presetRouter(env Environment, logs *bytes.Buffer) *Router {
+	r := New()
+	r.Use(Preset(env, PresetConfig{
+		Slog:      slog.New(slog.NewJSONHandler(logs, nil)),
+		SkipPaths: []string{"/health"},
+	}))
+	r.GET("/ok", func(c *Context) error {
+		return c.String(http.StatusOK, "%s", EnvironmentOf(c))
+	})
+	r.GET("/fail", func(c *Context) error {
+		return errors.New("database unreachable")
+	})
+	r.GET("/missing", func(c *Context) error {
+		return NewHTTPError(http.StatusNotFound, "no such item")
+	})
+	r.GET("/panic", func(c *Context) error {
+		panic("boom")
+	})
+	r.GET("/health", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func TestPreset_Production(t *testing.T) {
+	var logs bytes.Buffer
+	r := presetRouter(Production, &logs)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.RemoteAddr = "10.0.0.2:1234"
+	req.Header.Set("X-Forwarded-For", "198.51.100.1")
+	r.ServeHTTP(w, req)
+
+	if w.Body.String() != string(Production) {
+		t.Errorf("EnvironmentOf = %q, want %q", w.Body.String(), Production)
+	}
+	if w.Header().Get("X-Content-Type-Options") != "nosniff" {
+		t.Error("Production should set security headers")
+	}
+	if !strings.Contains(logs.String(), `"client_ip":"198.51.100.1:1234"`) {
+		t.Errorf("log should contain the real client IP:\n%s", logs.String())
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/fail", nil))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "database") {
+		t.Errorf("Production should hide error details, got %d %q", w.Code, w.Body.String())
+	}
+
+	logs.Reset()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "boom") {
+		t.Errorf("Production should hide panics, got %d %q", w.Code, w.Body.String())
+	}
+	if !strings.Contains(logs.String(), "panic recovered") || !strings.Contains(logs.String(), `"status":500`) {
+		t.Errorf("panic should be logged:\n%s", logs.String())
+	}
+}
+
+func TestPreset_Development(t *testing.T) {
+	var logs bytes.Buffer
+	r := presetRouter(Development, &logs)
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+		level  string
+	}{
+		{"/ok", http.StatusOK, string(Development), "INFO"},
+		{"/fail", http.StatusInternalServerError, "database unreachable", "ERROR"},
+		{"/missing", http.StatusNotFound, "no such item", "WARN"},
+		{"/panic", http.StatusInternalServerError, "panic: boom", "ERROR"},
+	}
+	for _, tt := range tests {
+		logs.Reset()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+		if w.Code != tt.status || !strings.Contains(w.Body.String(), tt.body) {
+			t.Errorf("%s: got %d %q, want %d containing %q", tt.path, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+		if !strings.Contains(logs.String(), `"level":"`+tt.level+`","msg":"request"`) {
+			t.Errorf("%s: log should have level %s:\n%s", tt.path, tt.level, logs.String())
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if !strings.Contains(w.Body.String(), "goroutine") {
+		t.Error("Development should include the panic stack")
+	}
+}
+
+func TestPreset_SkipPaths(t *testing.T) {
+	var logs bytes.Buffer
+	r := presetRouter(Production, &logs)
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if logs.Len() != 0 {
+		t.Errorf("skipped path should not be logged:\n%s", logs.String())
+	}
+}
@@ -0,0 +1,151 @@
+package rig
+
+import (
+	"context"
+	"net/http"
+)
+
+// PropagateConfig defines the configuration for Propagate middleware.
+type PropagateConfig struct {
+	// Headers lists the inbound headers to propagate, such as a tenant ID,
+	// Accept-Language, or traceparent. Required unless RequestIDHeader is
+	// set.
+	Headers []string
+
+	// RequestIDHeader, if set, propagates the request ID stored under
+	// RequestIDKey (see the requestid package) in this header, replacing
+	// any inbound value. Register Propagate after requestid to use it.
+	// Default: "" (the request ID is only propagated if listed in Headers).
+	RequestIDHeader string
+
+	// Skipper selects requests whose headers are not captured.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// propagationKey is the request context key holding the headers captured
+// by Propagate.
+type propagationKey struct{}
+
+// Propagate creates middleware that captures the given inbound headers
+// into the request context, so they can be copied onto outbound requests
+// to other services with PropagateHeaders or PropagationTransport. This
+// keeps tenant, locale, and trace headers consistent across service
+// boundaries without threading them through every call.
+//
+// Only headers present on the inbound request are captured. Outbound
+// requests must carry the request context (c.Context()) or one derived
+// from it.
+//
+// Example:
+//
+//	r.Use(rig.Propagate("X-Tenant-ID", "Accept-Language", "traceparent", "tracestate"))
+//
+//	r.GET("/orders", func(c *rig.Context) error {
+//	    req, _ := http.NewRequestWithContext(c.Context(), http.MethodGet, inventoryURL, nil)
+//	    rig.PropagateHeaders(c.Context(), req)
+//	    resp, err := http.DefaultClient.Do(req)
+//	    // ...
+//	})
+func Propagate(headers ...string) MiddlewareFunc {
+	return PropagateWithConfig(PropagateConfig{Headers: headers})
+}
+
+// PropagateWithConfig creates header propagation middleware with custom
+// configuration. It panics if neither Headers nor RequestIDHeader is set.
+//
+// Example:
+//
+//	r.Use(requestid.New())
+//	r.Use(rig.PropagateWithConfig(rig.PropagateConfig{
+//	    Headers:         []string{"X-Tenant-ID", "traceparent"},
+//	    RequestIDHeader: "X-Request-ID",
+//	}))
+func PropagateWithConfig(config PropagateConfig) MiddlewareFunc {
+	if len(config.Headers) == 0 && config.RequestIDHeader == "" {
+		panic("rig: Propagate requires Headers or RequestIDHeader")
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			captured := make(http.Header, len(config.Headers)+1)
+			for _, name := range config.Headers {
+				if values := c.Request().Header.Values(name); len(values) > 0 {
+					captured[http.CanonicalHeaderKey(name)] = append([]string(nil), values...)
+				}
+			}
+			if config.RequestIDHeader != "" {
+				if id, err := GetType[string](c, RequestIDKey); err == nil && id != "" {
+					captured.Set(config.RequestIDHeader, id)
+				}
+			}
+
+			if len(captured) > 0 {
+				c.SetContext(context.WithValue(c.Context(), propagationKey{}, captured))
+			}
+			return next(c)
+		}
+	}
+}
+
+// PropagatedHeaders returns the headers captured by Propagate for the
+// request ctx belongs to, or nil if there are none. The result must not be
+// modified.
+func PropagatedHeaders(ctx context.Context) http.Header {
+	headers, _ := ctx.Value(propagationKey{}).(http.Header)
+	return headers
+}
+
+// PropagateHeaders copies the headers captured by Propagate for the
+// request ctx belongs to onto the outbound request req. Headers already set
+// on req are kept.
+//
+// Example:
+//
+//	req, _ := http.NewRequestWithContext(c.Context(), http.MethodPost, billingURL, body)
+//	rig.PropagateHeaders(c.Context(), req)
+func PropagateHeaders(ctx context.Context, req *http.Request) {
+	for name, values := range PropagatedHeaders(ctx) {
+		if _, ok := req.Header[name]; !ok {
+			req.Header[name] = append([]string(nil), values...)
+		}
+	}
+}
+
+// PropagationTransport returns an http.RoundTripper that applies
+// PropagateHeaders to every request, using the request's context, before
+// sending it with base. If base is nil, http.DefaultTransport is used.
+//
+// Example:
+//
+//	client := &http.Client{Transport: rig.PropagationTransport(nil)}
+//
+//	// In handlers, requests made with c.Context() carry the headers
+//	req, _ := http.NewRequestWithContext(c.Context(), http.MethodGet, usersURL, nil)
+//	resp, err := client.Do(req)
+func PropagationTransport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return propagationTransport{base: base}
+}
+
+// propagationTransport is the RoundTripper returned by
+// PropagationTransport.
+type propagationTransport struct {
+	base http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t propagationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if headers := PropagatedHeaders(req.Context()); len(headers) > 0 {
+		// A RoundTripper must not modify the request it is given
+		req = req.Clone(req.Context())
+		PropagateHeaders(req.Context(), req)
+	}
+	return t.base.RoundTrip(req)
+}
@@ -0,0 +1,83 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestPropagate(t *testing.T) {
+	var received http.Header
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		received = req.Header.Clone()
+	}))
+	defer upstream.Close()
+
+	client := &http.Client{Transport: PropagationTransport(nil)}
+
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(RequestIDKey, "01HREQUEST")
+			return next(c)
+		}
+	})
+	r.Use(PropagateWithConfig(PropagateConfig{
+		Headers:         []string{"x-tenant-id", "Accept-Language", "traceparent"},
+		RequestIDHeader: "X-Request-ID",
+	}))
+	r.GET("/orders", func(c *Context) error {
+		req, err := http.NewRequestWithContext(c.Context(), http.MethodGet, upstream.URL, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Accept-Language", "fr")
+		resp, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		_ = resp.Body.Close()
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/orders", nil)
+	req.Header.Set("X-Tenant-ID", "acme")
+	req.Header.Set("Accept-Language", "de")
+	req.Header.Set("X-Request-ID", "spoofed")
+	req.Header.Set("Authorization", "Bearer secret")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+
+	want := map[string]string{
+		"X-Tenant-Id":     "acme",
+		"Accept-Language": "fr", // set on the outbound request
+		"X-Request-Id":    "01HREQUEST",
+		"Traceparent":     "", // not sent inbound
+		"Authorization":   "", // not configured
+	}
+	for name, value := range want {
+		if got := received.Get(name); got != value {
+			t.Errorf("outbound %s = %q, want %q", name, got, value)
+		}
+	}
+}
+
+func TestPropagateHeaders_WithoutMiddleware(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	PropagateHeaders(req.Context(), req)
+	if len(req.Header) != 0 || PropagatedHeaders(req.Context()) != nil {
+		t.Errorf("headers = %v, want none", req.Header)
+	}
+}
+
+func TestPropagate_NoHeaders(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("Propagate() did not panic")
+		}
+	}()
+	Propagate()
+}
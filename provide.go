@@ -0,0 +1,90 @@
+package rig
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Provide stores value in the context's type-keyed store, so handlers can
+// retrieve it with Use without a string key or type assertion. Values are
+// keyed by the type parameter T: providing an interface type (e.g.,
+// Provide[UserStore]) stores the value under that interface, not under its
+// concrete type. Providing the same T again replaces the previous value.
+//
+// Example:
+//
+//	func InjectDB(db *sql.DB) rig.MiddlewareFunc {
+//	    return func(next rig.HandlerFunc) rig.HandlerFunc {
+//	        return func(c *rig.Context) error {
+//	            rig.Provide(c, db)
+//	            return next(c)
+//	        }
+//	    }
+//	}
+func Provide[T any](c *Context, value T) {
+	if c.deps == nil {
+		c.deps = make(map[reflect.Type]any)
+	}
+	c.deps[reflect.TypeFor[T]()] = value
+}
+
+// ProvideGlobal registers value as an application-wide dependency of r, so
+// every request's Context can retrieve it with Use, without a middleware
+// per dependency. Values are keyed by T, as with Provide; a value provided
+// for a single request with Provide takes precedence. Register dependencies
+// before serving requests.
+//
+// Example:
+//
+//	r := rig.New()
+//	rig.ProvideGlobal(r, db)
+//	rig.ProvideGlobal[UserStore](r, postgresUsers{db})
+//
+//	r.GET("/users", func(c *rig.Context) error {
+//	    users := rig.MustUse[UserStore](c)
+//	    // ...
+//	})
+func ProvideGlobal[T any](r *Router, value T) {
+	if r.deps == nil {
+		r.deps = make(map[reflect.Type]any)
+	}
+	r.deps[reflect.TypeFor[T]()] = value
+}
+
+// Use retrieves the value of type T stored with Provide, or else registered
+// on the router with ProvideGlobal. It returns an error if no value of that
+// type was provided.
+//
+// Example:
+//
+//	r.GET("/users", func(c *rig.Context) error {
+//	    db, err := rig.Use[*sql.DB](c)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    // Use db...
+//	})
+func Use[T any](c *Context) (T, error) {
+	typ := reflect.TypeFor[T]()
+	value, ok := c.deps[typ]
+	if !ok && c.router != nil {
+		value, ok = c.router.deps[typ]
+	}
+	if !ok {
+		var zero T
+		return zero, fmt.Errorf("rig: no value of type %s provided in context", typ)
+	}
+	// A nil interface value was provided, which a type assertion rejects
+	v, _ := value.(T)
+	return v, nil
+}
+
+// MustUse is like Use but panics if no value of type T was provided.
+// Use it for dependencies that are always injected by middleware.
+func MustUse[T any](c *Context) T {
+	value, err := Use[T](c)
+	if err != nil {
+		panic(err.Error())
+	}
+	return value
+}
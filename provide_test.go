@@ -0,0 +1,90 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type userStore interface {
+	Name() string
+}
+
+type memoryStore struct{ name string }
+
+func (s *memoryStore) Name() string { return s.name }
+
+func TestProvideAndUse(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if _, err := Use[*memoryStore](c); err == nil {
+		t.Error("Use before Provide should return an error")
+	}
+
+	store := &memoryStore{name: "primary"}
+	Provide(c, store)
+	Provide[userStore](c, &memoryStore{name: "interface"})
+
+	got, err := Use[*memoryStore](c)
+	if err != nil {
+		t.Fatalf("Use returned error: %v", err)
+	}
+	if got != store {
+		t.Errorf("Use[*memoryStore] = %v, want %v", got, store)
+	}
+
+	iface, err := Use[userStore](c)
+	if err != nil {
+		t.Fatalf("Use returned error: %v", err)
+	}
+	if iface.Name() != "interface" {
+		t.Errorf("Use[userStore].Name() = %q, want %q", iface.Name(), "interface")
+	}
+
+	Provide(c, &memoryStore{name: "replacement"})
+	if MustUse[*memoryStore](c).Name() != "replacement" {
+		t.Error("Provide should replace the previous value of the same type")
+	}
+	Provide[userStore](c, nil)
+	if iface, err := Use[userStore](c); err != nil || iface != nil {
+		t.Errorf("Use after providing nil = %v, %v; want nil, nil", iface, err)
+	}
+}
+
+func TestMustUse_Panics(t *testing.T) {
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	defer func() {
+		r := recover()
+		if r == nil {
+			t.Fatal("MustUse should panic when no value was provided")
+		}
+		if msg, _ := r.(string); !strings.Contains(msg, "int") {
+			t.Errorf("panic message = %v, want type name", r)
+		}
+	}()
+	MustUse[int](c)
+}
+
+func TestProvideGlobal(t *testing.T) {
+	r := New()
+	ProvideGlobal[userStore](r, &memoryStore{name: "global"})
+
+	api := r.Group("/api")
+	api.GET("/users", func(c *Context) error {
+		return c.String(http.StatusOK, "%s", MustUse[userStore](c).Name())
+	})
+	api.GET("/override", func(c *Context) error {
+		Provide[userStore](c, &memoryStore{name: "request"})
+		return c.String(http.StatusOK, "%s", MustUse[userStore](c).Name())
+	})
+
+	for path, want := range map[string]string{"/api/users": "global", "/api/override": "request"} {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		if w.Body.String() != want {
+			t.Errorf("%s: body = %q, want %q", path, w.Body.String(), want)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package rig
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/cloudresty/rig/ratelimit"
+)
+
+// RateLimitConfig defines the configuration for RateLimit middleware.
+type RateLimitConfig struct {
+	// Rate is the sustained number of requests allowed per second for each
+	// key. Use fractions for slower rates (e.g., 100.0/60 for 100 per
+	// minute). Required.
+	Rate float64
+
+	// Burst is the number of requests a key may make at once before being
+	// limited to Rate.
+	// Default: Rate rounded up, at least 1.
+	Burst int
+
+	// KeyFunc returns the key requests are counted against, such as the
+	// client IP, an API key, or a user ID. Requests for which it returns an
+	// empty string are not limited.
+	// Default: RateLimitByIP.
+	KeyFunc func(c *Context) string
+
+	// OnLimit is called when a request is rejected, after the RateLimit-*
+	// and Retry-After headers have been set.
+	// Default: a JSON response with 429 Too Many Requests.
+	OnLimit func(c *Context) error
+
+	// Store holds the buckets. Use a shared store (e.g., backed by Redis)
+	// to enforce the limit across replicas. RateLimit middlewares with
+	// different limits may share a store; each limit has its own buckets.
+	// Default: ratelimit.NewMemory().
+	Store ratelimit.Store
+
+	// OnError is called instead of the handler when Store fails. Call next
+	// to let the request through (fail open), or respond to reject it
+	// (fail closed).
+	// Default: fails open, so an outage of a shared store does not take the
+	// service down.
+	OnError func(c *Context, next HandlerFunc, err error) error
+
+	// Skipper selects requests that are neither counted nor limited. To
+	// exempt clients by key, return an empty string from KeyFunc instead.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// RateLimit creates middleware that limits how often each key (by default,
+// each client IP) may make requests, using a token bucket per key. Rejected
+// requests are answered with 429 Too Many Requests and a Retry-After
+// header. Every response carries RateLimit-Limit, RateLimit-Remaining, and
+// RateLimit-Reset headers describing the key's bucket. It panics if
+// config.Rate is not positive.
+//
+// By default, buckets live in the process's memory, so each replica of a
+// service limits independently; set config.Store to share them. Register
+// rig.RealIP first when running behind a proxy, so clients are told apart.
+//
+// Example:
+//
+//	// 10 requests per second per client, with bursts of up to 20
+//	r.Use(rig.RateLimit(rig.RateLimitConfig{Rate: 10, Burst: 20}))
+//
+//	// 1000 requests per hour per API key
+//	api.Use(rig.RateLimit(rig.RateLimitConfig{
+//	    Rate:    1000.0 / 3600,
+//	    Burst:   1000,
+//	    KeyFunc: rig.RateLimitByHeader("X-API-Key"),
+//	}))
+func RateLimit(config RateLimitConfig) MiddlewareFunc {
+	if config.Rate <= 0 || math.IsInf(config.Rate, 0) || math.IsNaN(config.Rate) {
+		panic("rig: RateLimit requires a positive Rate")
+	}
+	if config.Burst <= 0 {
+		config.Burst = max(1, int(math.Ceil(config.Rate)))
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByIP
+	}
+	if config.OnLimit == nil {
+		config.OnLimit = func(c *Context) error {
+			return c.JSON(http.StatusTooManyRequests, map[string]string{
+				"error": c.Translate(MsgTooManyRequests, "too many requests"),
+			})
+		}
+	}
+
+	if config.Store == nil {
+		config.Store = ratelimit.NewMemory()
+	}
+	if config.OnError == nil {
+		config.OnError = func(c *Context, next HandlerFunc, _ error) error {
+			return next(c)
+		}
+	}
+
+	limit := ratelimit.Limit{Rate: config.Rate, Burst: config.Burst}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			result, err := config.Store.Allow(c.Context(), key, limit)
+			if err != nil {
+				return config.OnError(c, next, err)
+			}
+			h := c.Header()
+			h.Set("RateLimit-Limit", strconv.Itoa(config.Burst))
+			h.Set("RateLimit-Remaining", strconv.Itoa(max(0, result.Remaining)))
+			h.Set("RateLimit-Reset", ceilSeconds(result.Reset))
+			if !result.Allowed {
+				h.Set("Retry-After", ceilSeconds(max(time.Second, result.RetryAfter)))
+				return config.OnLimit(c)
+			}
+			return next(c)
+		}
+	}
+}
+
+// RateLimitByIP is a RateLimitConfig.KeyFunc that limits by the client's IP
+// address (the host of the request's RemoteAddr).
+func RateLimitByIP(c *Context) string {
+	return clientIP(c)
+}
+
+// RateLimitByHeader returns a RateLimitConfig.KeyFunc that limits by the
+// value of a request header, such as an API key. Requests without the
+// header are limited by IP.
+func RateLimitByHeader(name string) func(*Context) string {
+	return func(c *Context) string {
+		if v := c.GetHeader(name); v != "" {
+			return name + ":" + v
+		}
+		return RateLimitByIP(c)
+	}
+}
+
+// RateLimitByIdentity is a RateLimitConfig.KeyFunc that limits by the
+// authenticated identity stored under IdentityKey (see the auth package).
+// Unauthenticated requests are limited by IP. Register it after the
+// authentication middleware.
+func RateLimitByIdentity(c *Context) string {
+	if identity, err := GetType[string](c, IdentityKey); err == nil && identity != "" {
+		return "identity:" + identity
+	}
+	return RateLimitByIP(c)
+}
+
+// ceilSeconds formats d as a whole number of seconds, rounded up.
+func ceilSeconds(d time.Duration) string {
+	return strconv.FormatInt(int64(math.Ceil(d.Seconds())), 10)
+}
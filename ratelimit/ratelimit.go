@@ -0,0 +1,152 @@
+// Package ratelimit defines the storage interface behind rig.RateLimit and
+// its default in-memory implementation.
+//
+// The in-memory store keeps buckets in the process, so each replica of a
+// service limits independently. To enforce a limit across replicas,
+// implement Store on top of a shared backend such as Redis and pass it to
+// rig.RateLimitConfig.Store.
+//
+// # Basic Usage
+//
+//	r.Use(rig.RateLimit(rig.RateLimitConfig{
+//	    Rate:  10,
+//	    Burst: 20,
+//	    Store: redisStore, // implements ratelimit.Store
+//	}))
+//
+// # Implementing a Store
+//
+// Allow must be atomic per key: concurrent calls from any replica must not
+// admit more requests than the limit allows. With Redis, run the token
+// bucket update in a Lua script (or use GCRA) keyed by the request key and
+// the limit, and expire keys once their bucket would be full again.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Limit describes a token bucket: Burst requests may be made at once, and
+// tokens refill at Rate per second.
+type Limit struct {
+	Rate  float64
+	Burst int
+}
+
+// Result is the outcome of a Store.Allow call.
+type Result struct {
+	// Allowed reports whether the request may proceed.
+	Allowed bool
+
+	// Remaining is the number of requests the key may still make right now.
+	Remaining int
+
+	// Reset is the time until the key's bucket is full again.
+	Reset time.Duration
+
+	// RetryAfter is the time until the next request is allowed. It is zero
+	// when Allowed is true.
+	RetryAfter time.Duration
+}
+
+// Store tracks rate limit state per key. Implementations must be safe for
+// concurrent use.
+type Store interface {
+	// Allow takes one request from key's budget under limit, if one is
+	// available, and reports the key's remaining budget. A key has a
+	// separate budget under each limit, so RateLimit middlewares with
+	// different limits can share a store without resetting each other.
+	Allow(ctx context.Context, key string, limit Limit) (Result, error)
+}
+
+// bucketKey identifies a token bucket: a key under a limit.
+type bucketKey struct {
+	key   string
+	limit Limit
+}
+
+// bucket is the state of a single key's token bucket.
+type bucket struct {
+	limit  Limit
+	tokens float64
+	last   time.Time
+}
+
+// refill returns b's token count at time now.
+func (b *bucket) refill(now time.Time) float64 {
+	elapsed := max(0, now.Sub(b.last).Seconds())
+	return min(float64(b.limit.Burst), b.tokens+elapsed*b.limit.Rate)
+}
+
+// duration returns the time needed to refill n tokens.
+func (b *bucket) duration(n float64) time.Duration {
+	return time.Duration(n / b.limit.Rate * float64(time.Second))
+}
+
+// sweepInterval is how often Memory removes buckets that have refilled.
+const sweepInterval = time.Minute
+
+// Memory is an in-process Store. Buckets that have refilled completely are
+// swept periodically, so idle keys don't accumulate. The zero value is not
+// usable; create one with NewMemory.
+type Memory struct {
+	mu        sync.Mutex
+	buckets   map[bucketKey]*bucket
+	lastSweep time.Time
+}
+
+// NewMemory creates an empty in-memory Store.
+func NewMemory() *Memory {
+	return &Memory{
+		buckets:   make(map[bucketKey]*bucket),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow implements Store. It never returns an error.
+func (m *Memory) Allow(_ context.Context, key string, limit Limit) (Result, error) {
+	return m.allow(key, limit, time.Now()), nil
+}
+
+// allow takes a token from key's bucket at time now, if one is available.
+func (m *Memory) allow(key string, limit Limit, now time.Time) Result {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if now.Sub(m.lastSweep) >= sweepInterval {
+		m.sweep(now)
+	}
+
+	k := bucketKey{key, limit}
+	b, ok := m.buckets[k]
+	if !ok {
+		b = &bucket{limit: limit, tokens: float64(limit.Burst), last: now}
+		m.buckets[k] = b
+	}
+	b.tokens = b.refill(now)
+	b.last = now
+
+	var result Result
+	if b.tokens >= 1 {
+		b.tokens--
+		result.Allowed = true
+	} else {
+		result.RetryAfter = b.duration(1 - b.tokens)
+	}
+	result.Remaining = int(b.tokens)
+	result.Reset = b.duration(float64(limit.Burst) - b.tokens)
+	return result
+}
+
+// sweep removes buckets that have refilled completely; they are
+// indistinguishable from new ones.
+func (m *Memory) sweep(now time.Time) {
+	for key, b := range m.buckets {
+		if b.refill(now) >= float64(b.limit.Burst) {
+			delete(m.buckets, key)
+		}
+	}
+	m.lastSweep = now
+}
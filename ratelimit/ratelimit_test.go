@@ -0,0 +1,71 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemory_Allow(t *testing.T) {
+	start := time.Now()
+	m := NewMemory()
+	limit := Limit{Rate: 2, Burst: 2}
+
+	for i, want := range []int{1, 0} {
+		result := m.allow("k", limit, start)
+		if !result.Allowed || result.Remaining != want {
+			t.Fatalf("request %d: got %+v, want allowed with %d remaining", i, result, want)
+		}
+	}
+	result := m.allow("k", limit, start)
+	if result.Allowed || result.RetryAfter != 500*time.Millisecond || result.Reset != time.Second {
+		t.Errorf("got %+v, want denied with 500ms RetryAfter and 1s Reset", result)
+	}
+
+	// Half a second refills one token
+	if !m.allow("k", limit, start.Add(500*time.Millisecond)).Allowed {
+		t.Error("expected request after refill to be allowed")
+	}
+
+	// Keys have separate buckets
+	if result, _ := m.Allow(context.Background(), "other", limit); !result.Allowed {
+		t.Error("expected first request for another key to be allowed")
+	}
+}
+
+func TestMemory_SeparateLimits(t *testing.T) {
+	now := time.Now()
+	m := NewMemory()
+	strict := Limit{Rate: 1, Burst: 1}
+	loose := Limit{Rate: 1, Burst: 5}
+
+	// Alternating limits must not refill either bucket
+	for i := range 4 {
+		if result := m.allow("k", strict, now); result.Allowed != (i == 0) {
+			t.Errorf("strict request %d: got %+v", i, result)
+		}
+		if result := m.allow("k", loose, now); !result.Allowed || result.Remaining != 4-i {
+			t.Errorf("loose request %d: got %+v, want %d remaining", i, result, 4-i)
+		}
+	}
+}
+
+func TestMemory_Sweep(t *testing.T) {
+	start := time.Now()
+	m := NewMemory()
+	limit := Limit{Rate: 1, Burst: 100}
+
+	m.allow("idle", limit, start)
+	m.allow("busy", limit, start)
+	for range 99 {
+		m.allow("busy", limit, start.Add(time.Minute))
+	}
+
+	m.allow("new", limit, start.Add(2*time.Minute))
+	if _, ok := m.buckets[bucketKey{"idle", limit}]; ok {
+		t.Error("full bucket was not swept")
+	}
+	if _, ok := m.buckets[bucketKey{"busy", limit}]; !ok {
+		t.Error("partially drained bucket was swept")
+	}
+}
@@ -0,0 +1,180 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/cloudresty/rig/ratelimit"
+)
+
+func rateLimitRouter(config RateLimitConfig) *Router {
+	r := New()
+	r.Use(RateLimit(config))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	return r
+}
+
+func serveFrom(r *Router, remoteAddr string, header http.Header) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.RemoteAddr = remoteAddr
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestRateLimit(t *testing.T) {
+	r := rateLimitRouter(RateLimitConfig{Rate: 1, Burst: 2})
+
+	for i, want := range []string{"1", "0"} {
+		w := serveFrom(r, "192.0.2.1:1234", nil)
+		if w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+		}
+		if got := w.Header().Get("RateLimit-Limit"); got != "2" {
+			t.Errorf("request %d: RateLimit-Limit = %q, want 2", i, got)
+		}
+		if got := w.Header().Get("RateLimit-Remaining"); got != want {
+			t.Errorf("request %d: RateLimit-Remaining = %q, want %s", i, got, want)
+		}
+	}
+
+	w := serveFrom(r, "192.0.2.1:5678", nil)
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if got := w.Header().Get("Retry-After"); got != "1" {
+		t.Errorf("Retry-After = %q, want 1", got)
+	}
+	if got := w.Header().Get("RateLimit-Reset"); got != "2" {
+		t.Errorf("RateLimit-Reset = %q, want 2", got)
+	}
+	if w.Body.String() != `{"error":"too many requests"}`+"\n" {
+		t.Errorf("body = %q", w.Body.String())
+	}
+
+	// Other clients have their own bucket
+	if w := serveFrom(r, "192.0.2.2:1234", nil); w.Code != http.StatusOK {
+		t.Errorf("other client: status = %d, want 200", w.Code)
+	}
+}
+
+func TestRateLimit_KeyFuncs(t *testing.T) {
+	r := rateLimitRouter(RateLimitConfig{Rate: 1, KeyFunc: RateLimitByHeader("X-API-Key")})
+
+	key := http.Header{"X-Api-Key": {"k1"}}
+	if w := serveFrom(r, "192.0.2.1:1", key); w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	// Same key from another address shares the bucket
+	if w := serveFrom(r, "192.0.2.2:1", key); w.Code != http.StatusTooManyRequests {
+		t.Errorf("same key: status = %d, want 429", w.Code)
+	}
+	// Requests without a key fall back to the IP
+	if w := serveFrom(r, "192.0.2.1:1", nil); w.Code != http.StatusOK {
+		t.Errorf("no key: status = %d, want 200", w.Code)
+	}
+
+	c := newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	c.Set(IdentityKey, "ada")
+	if got := RateLimitByIdentity(c); got != "identity:ada" {
+		t.Errorf("RateLimitByIdentity() = %q, want identity:ada", got)
+	}
+}
+
+func TestRateLimit_EmptyKeyAndOnLimit(t *testing.T) {
+	r := rateLimitRouter(RateLimitConfig{
+		Rate: 1,
+		KeyFunc: func(c *Context) string {
+			return c.GetHeader("X-Tenant")
+		},
+		OnLimit: func(c *Context) error {
+			return c.String(http.StatusServiceUnavailable, "slow down")
+		},
+	})
+
+	for range 3 {
+		if w := serveFrom(r, "192.0.2.1:1", nil); w.Code != http.StatusOK {
+			t.Fatalf("unkeyed request: status = %d, want 200", w.Code)
+		}
+	}
+
+	tenant := http.Header{"X-Tenant": {"acme"}}
+	serveFrom(r, "192.0.2.1:1", tenant)
+	w := serveFrom(r, "192.0.2.1:1", tenant)
+	if w.Code != http.StatusServiceUnavailable || w.Body.String() != "slow down" {
+		t.Errorf("got %d %q, want 503 slow down", w.Code, w.Body.String())
+	}
+}
+
+func TestRateLimit_InvalidRate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for zero Rate")
+		}
+	}()
+	RateLimit(RateLimitConfig{})
+}
+
+// storeFunc adapts a function to ratelimit.Store.
+type storeFunc func(key string, limit ratelimit.Limit) (ratelimit.Result, error)
+
+func (f storeFunc) Allow(_ context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return f(key, limit)
+}
+
+func TestRateLimit_Store(t *testing.T) {
+	var gotKey string
+	var gotLimit ratelimit.Limit
+	r := rateLimitRouter(RateLimitConfig{
+		Rate:  5,
+		Burst: 10,
+		Store: storeFunc(func(key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+			gotKey, gotLimit = key, limit
+			return ratelimit.Result{Remaining: 0, Reset: 90 * time.Second, RetryAfter: 1500 * time.Millisecond}, nil
+		}),
+	})
+
+	w := serveFrom(r, "192.0.2.1:1", nil)
+	if gotKey != "192.0.2.1" || gotLimit != (ratelimit.Limit{Rate: 5, Burst: 10}) {
+		t.Errorf("Allow(%q, %+v), want 192.0.2.1 with {5 10}", gotKey, gotLimit)
+	}
+	if w.Code != http.StatusTooManyRequests {
+		t.Fatalf("status = %d, want 429", w.Code)
+	}
+	if w.Header().Get("Retry-After") != "2" || w.Header().Get("RateLimit-Reset") != "90" {
+		t.Errorf("Retry-After = %q, RateLimit-Reset = %q, want 2 and 90",
+			w.Header().Get("Retry-After"), w.Header().Get("RateLimit-Reset"))
+	}
+}
+
+func TestRateLimit_StoreError(t *testing.T) {
+	failing := storeFunc(func(string, ratelimit.Limit) (ratelimit.Result, error) {
+		return ratelimit.Result{}, errors.New("connection refused")
+	})
+
+	// Fails open by default
+	r := rateLimitRouter(RateLimitConfig{Rate: 1, Store: failing})
+	if w := serveFrom(r, "192.0.2.1:1", nil); w.Code != http.StatusOK {
+		t.Errorf("default: status = %d, want 200", w.Code)
+	}
+
+	r = rateLimitRouter(RateLimitConfig{
+		Rate:  1,
+		Store: failing,
+		OnError: func(c *Context, next HandlerFunc, err error) error {
+			return c.String(http.StatusServiceUnavailable, "rate limiter unavailable")
+		},
+	})
+	if w := serveFrom(r, "192.0.2.1:1", nil); w.Code != http.StatusServiceUnavailable {
+		t.Errorf("fail closed: status = %d, want 503", w.Code)
+	}
+}
@@ -0,0 +1,206 @@
+package rig
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+)
+
+// RolesKey is the context key under which authentication middleware stores
+// the roles ([]string) of the authenticated identity (see the auth
+// package). RequireRole reads them.
+const RolesKey = "auth.roles"
+
+// Roles returns the roles of the authenticated identity stored under
+// RolesKey, or nil if there are none.
+func Roles(c *Context) []string {
+	roles, _ := GetType[[]string](c, RolesKey)
+	return roles
+}
+
+// HasRole reports whether the authenticated identity has the given role.
+func HasRole(c *Context, role string) bool {
+	return slices.Contains(Roles(c), role)
+}
+
+// PermissionResolver reports whether the authenticated identity of a
+// request holds a permission. Implementations must be safe for concurrent
+// use. Back it with your own store (a database, a policy engine) by
+// implementing it, or use RolePermissions.
+type PermissionResolver interface {
+	HasPermission(c *Context, identity, permission string) (bool, error)
+}
+
+// PermissionResolverFunc adapts a function to the PermissionResolver
+// interface.
+type PermissionResolverFunc func(c *Context, identity, permission string) (bool, error)
+
+// HasPermission calls f(c, identity, permission).
+func (f PermissionResolverFunc) HasPermission(c *Context, identity, permission string) (bool, error) {
+	return f(c, identity, permission)
+}
+
+// RolePermissions is a static PermissionResolver mapping roles to the
+// permissions they grant. A permission is held if any role of the request
+// (see Roles) grants it; the permission "*" grants every permission.
+//
+// Example:
+//
+//	r.SetPermissionResolver(rig.RolePermissions{
+//	    "admin":  {"*"},
+//	    "editor": {"posts:read", "posts:write"},
+//	    "viewer": {"posts:read"},
+//	})
+type RolePermissions map[string][]string
+
+// HasPermission implements PermissionResolver.
+func (m RolePermissions) HasPermission(c *Context, _, permission string) (bool, error) {
+	for _, role := range Roles(c) {
+		granted := m[role]
+		if slices.Contains(granted, permission) || slices.Contains(granted, "*") {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// SetPermissionResolver sets the PermissionResolver used by
+// RequirePermission and by RBAC middleware created without one.
+func (r *Router) SetPermissionResolver(resolver PermissionResolver) {
+	r.permissions = resolver
+}
+
+// RBACConfig defines the configuration for RBAC middleware.
+type RBACConfig struct {
+	// Roles lists the roles allowed to access the routes; having any one of
+	// them is enough. If empty, roles are not checked.
+	Roles []string
+
+	// Permissions lists the permissions required to access the routes; all
+	// of them must be held. If empty, permissions are not checked.
+	Permissions []string
+
+	// Resolver reports whether the identity holds a permission.
+	// Default: the router's resolver (see Router.SetPermissionResolver).
+	Resolver PermissionResolver
+
+	// OnUnauthenticated is called for requests without an authenticated
+	// identity (see IdentityKey).
+	// Default: a JSON response with 401 Unauthorized.
+	OnUnauthenticated func(c *Context) error
+
+	// OnForbidden is called for requests lacking a required role or
+	// permission.
+	// Default: a JSON response with 403 Forbidden.
+	OnForbidden func(c *Context) error
+
+	// Skipper selects requests that are not checked.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// RequireRole creates middleware that only lets through requests whose
+// authenticated identity has at least one of roles (see Roles). Requests
+// without an identity are answered with 401 Unauthorized, and the others
+// with 403 Forbidden. Register it after authentication middleware.
+//
+// Example:
+//
+//	admin := r.Group("/admin")
+//	admin.Use(auth.Bearer(auth.BearerConfig{
+//	    Validator: validateToken,
+//	    Roles:     rolesOf,
+//	}))
+//	admin.Use(rig.RequireRole("admin"))
+func RequireRole(roles ...string) MiddlewareFunc {
+	return RBAC(RBACConfig{Roles: roles})
+}
+
+// RequirePermission creates middleware that only lets through requests
+// whose authenticated identity holds all of permissions, as reported by the
+// router's PermissionResolver (see Router.SetPermissionResolver). Requests
+// without an identity are answered with 401 Unauthorized, and the others
+// with 403 Forbidden. If no resolver is set, every request is forbidden.
+//
+// Example:
+//
+//	r.SetPermissionResolver(rig.RolePermissions{
+//	    "editor": {"posts:write"},
+//	})
+//	r.POST("/posts", rig.RequirePermission("posts:write")(createPost))
+func RequirePermission(permissions ...string) MiddlewareFunc {
+	return RBAC(RBACConfig{Permissions: permissions})
+}
+
+// RBAC creates role and permission checking middleware with custom
+// configuration. Roles are checked before permissions. Errors from the
+// resolver are returned to the error handler. It panics if neither Roles
+// nor Permissions is set.
+//
+// Example:
+//
+//	api.Use(rig.RBAC(rig.RBACConfig{
+//	    Permissions: []string{"reports:export"},
+//	    Resolver: rig.PermissionResolverFunc(func(c *rig.Context, identity, permission string) (bool, error) {
+//	        return store.HasPermission(c.Context(), identity, permission)
+//	    }),
+//	}))
+func RBAC(config RBACConfig) MiddlewareFunc {
+	if len(config.Roles) == 0 && len(config.Permissions) == 0 {
+		panic("rig: RBAC requires Roles or Permissions")
+	}
+	if config.OnUnauthenticated == nil {
+		config.OnUnauthenticated = func(c *Context) error {
+			return c.JSON(http.StatusUnauthorized, map[string]string{
+				"error": c.Translate(MsgUnauthorized, "unauthorized"),
+			})
+		}
+	}
+	if config.OnForbidden == nil {
+		config.OnForbidden = func(c *Context) error {
+			return c.JSON(http.StatusForbidden, map[string]string{
+				"error": c.Translate(MsgForbidden, "forbidden"),
+			})
+		}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			identity, err := GetType[string](c, IdentityKey)
+			if err != nil || identity == "" {
+				return config.OnUnauthenticated(c)
+			}
+
+			if len(config.Roles) > 0 && !slices.ContainsFunc(config.Roles, func(role string) bool {
+				return HasRole(c, role)
+			}) {
+				return config.OnForbidden(c)
+			}
+
+			if len(config.Permissions) > 0 {
+				resolver := config.Resolver
+				if resolver == nil && c.router != nil {
+					resolver = c.router.permissions
+				}
+				if resolver == nil {
+					return config.OnForbidden(c)
+				}
+				for _, permission := range config.Permissions {
+					ok, err := resolver.HasPermission(c, identity, permission)
+					if err != nil {
+						return fmt.Errorf("rig: resolving permission %q: %w", permission, err)
+					}
+					if !ok {
+						return config.OnForbidden(c)
+					}
+				}
+			}
+
+			return next(c)
+		}
+	}
+}
@@ -0,0 +1,141 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// authenticate returns middleware storing identity and roles, as the auth
+// package does.
+func authenticate(identity string, roles ...string) MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if identity != "" {
+				c.Set(IdentityKey, identity)
+				c.Set(RolesKey, roles)
+			}
+			return next(c)
+		}
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	tests := []struct {
+		name     string
+		identity string
+		roles    []string
+		want     int
+	}{
+		{"unauthenticated", "", nil, http.StatusUnauthorized},
+		{"no roles", "alice", nil, http.StatusForbidden},
+		{"other role", "alice", []string{"viewer"}, http.StatusForbidden},
+		{"one of the roles", "alice", []string{"viewer", "editor"}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := New()
+			r.Use(authenticate(tt.identity, tt.roles...))
+			r.Use(RequireRole("admin", "editor"))
+			r.GET("/posts", func(c *Context) error {
+				return c.String(http.StatusOK, "ok")
+			})
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/posts", nil))
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %q)", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+func TestRequirePermission(t *testing.T) {
+	r := New()
+	r.SetPermissionResolver(RolePermissions{
+		"admin":  {"*"},
+		"editor": {"posts:read", "posts:write"},
+		"viewer": {"posts:read"},
+	})
+	handler := func(c *Context) error { return c.String(http.StatusOK, "ok") }
+
+	tests := []struct {
+		role string
+		want int
+	}{
+		{"admin", http.StatusOK},
+		{"editor", http.StatusOK},
+		{"viewer", http.StatusForbidden},
+		{"guest", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		g := r.Group("/" + tt.role)
+		g.Use(authenticate("bob", tt.role))
+		g.POST("/posts", RequirePermission("posts:read", "posts:write")(handler))
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/"+tt.role+"/posts", nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.role, w.Code, tt.want)
+		}
+	}
+}
+
+func TestRBAC_Resolver(t *testing.T) {
+	errStore := errors.New("store unavailable")
+	var checked []string
+	r := New()
+	r.Use(authenticate("carol"))
+	r.Use(RBAC(RBACConfig{
+		Permissions: []string{"reports:export"},
+		Resolver: PermissionResolverFunc(func(c *Context, identity, permission string) (bool, error) {
+			checked = append(checked, identity+" "+permission)
+			if c.Query("fail") != "" {
+				return false, errStore
+			}
+			return identity == "carol", nil
+		}),
+		OnForbidden: func(c *Context) error {
+			return c.String(http.StatusForbidden, "nope")
+		},
+	}))
+	r.GET("/reports", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports", nil))
+	if w.Code != http.StatusOK || len(checked) != 1 || checked[0] != "carol reports:export" {
+		t.Errorf("status = %d, checked = %v", w.Code, checked)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/reports?fail=1", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("resolver error: status = %d, want 500", w.Code)
+	}
+}
+
+func TestRequirePermission_NoResolver(t *testing.T) {
+	r := New()
+	r.Use(authenticate("dave", "admin"))
+	r.GET("/", RequirePermission("anything")(func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	}))
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusForbidden || w.Body.String() != `{"error":"forbidden"}`+"\n" {
+		t.Errorf("status = %d, body = %q", w.Code, w.Body.String())
+	}
+}
+
+func TestRBAC_Invalid(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RBAC without roles or permissions did not panic")
+		}
+	}()
+	RBAC(RBACConfig{})
+}
@@ -0,0 +1,218 @@
+package rig
+
+import (
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// DefaultTrustedProxies are the networks RealIP trusts when
+// RealIPConfig.TrustedProxies is empty: loopback and private addresses,
+// where load balancers and ingress controllers usually run.
+var DefaultTrustedProxies = []string{
+	"127.0.0.0/8",
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"::1/128",
+	"fc00::/7",
+}
+
+// RealIPConfig defines the configuration for RealIP middleware.
+type RealIPConfig struct {
+	// TrustedProxies lists the addresses or CIDR ranges of proxies allowed to
+	// report the client address in Header. Headers from other peers are
+	// ignored, so clients cannot spoof their address.
+	// Default: DefaultTrustedProxies.
+	TrustedProxies []string
+
+	// Header is the header the trusted proxies write the client address to:
+	// "X-Forwarded-For", "Forwarded" (RFC 7239), or "X-Real-IP". Only that
+	// header is read, since proxies pass the others on as the client sent
+	// them. Set it to "Forwarded" only if the proxies add to that header.
+	// Default: X-Forwarded-For, or X-Real-IP for requests without it.
+	Header string
+
+	// Skipper selects requests whose RemoteAddr is left unchanged.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// RealIP creates middleware that replaces the request's RemoteAddr with the
+// client address reported by a trusted proxy, so later middleware (rate
+// limiting, logging, IP filtering) and handlers see the real client. It uses
+// the rightmost address in config.Header (X-Forwarded-For or Forwarded) that
+// is not itself a trusted proxy, or the address in X-Real-IP. The header is
+// only honored when the immediate peer is a trusted proxy. The port of the
+// original RemoteAddr is kept. It panics if a trusted proxy is not a valid
+// address or CIDR range, or if config.Header is not a supported header.
+//
+// Example:
+//
+//	r.Use(rig.RealIP(rig.RealIPConfig{
+//	    TrustedProxies: []string{"10.0.0.0/8"},
+//	}))
+func RealIP(config RealIPConfig) MiddlewareFunc {
+	if len(config.TrustedProxies) == 0 {
+		config.TrustedProxies = DefaultTrustedProxies
+	}
+	header := proxyHeader(config.Header)
+	trusted := parsePrefixes(config.TrustedProxies, "trusted proxy")
+	isTrusted := func(ip string) bool {
+		return containsIP(trusted, ip)
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			req := c.Request()
+			host, port, err := net.SplitHostPort(req.RemoteAddr)
+			if err != nil || !isTrusted(host) {
+				return next(c)
+			}
+
+			if ip := proxiedClient(req, header, isTrusted); ip != "" {
+				req.RemoteAddr = net.JoinHostPort(ip, port)
+			}
+			return next(c)
+		}
+	}
+}
+
+// proxyHeader returns the canonical form of a RealIPConfig.Header value,
+// panicking if it is not supported.
+func proxyHeader(name string) string {
+	header := http.CanonicalHeaderKey(name)
+	switch header {
+	case "", "X-Forwarded-For", "Forwarded", "X-Real-Ip":
+		return header
+	}
+	panic("rig: unsupported client address header " + name)
+}
+
+// proxiedClient returns the client address reported by a trusted proxy in
+// header (see RealIPConfig.Header), or an empty string if there is none.
+func proxiedClient(req *http.Request, header string, isTrusted func(string) bool) string {
+	switch header {
+	case "Forwarded":
+		return forwardedClient(forwardedFor(req.Header.Values("Forwarded")), isTrusted)
+	case "X-Real-Ip":
+		return realIPClient(req)
+	}
+
+	var hops []string
+	for _, h := range req.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(h, ",")...)
+	}
+	if ip := forwardedClient(hops, isTrusted); ip != "" || header != "" {
+		return ip
+	}
+	return realIPClient(req)
+}
+
+// realIPClient returns the address in the X-Real-IP header, or an empty
+// string if it is not a valid address.
+func realIPClient(req *http.Request) string {
+	if ip := strings.TrimSpace(req.Header.Get("X-Real-IP")); validIP(ip) {
+		return ip
+	}
+	return ""
+}
+
+// forwardedFor returns the addresses of the for= parameters of Forwarded
+// headers (RFC 7239), one per hop, without quotes, brackets, and ports.
+// Hops without a usable address (e.g., for=unknown or an obfuscated
+// identifier) are returned as they are, so they are not mistaken for
+// clients.
+func forwardedFor(headers []string) []string {
+	var hops []string
+	for _, h := range headers {
+		for element := range strings.SplitSeq(h, ",") {
+			node := ""
+			for pair := range strings.SplitSeq(element, ";") {
+				name, value, _ := strings.Cut(strings.TrimSpace(pair), "=")
+				if strings.EqualFold(name, "for") {
+					node = strings.Trim(value, `"`)
+					break
+				}
+			}
+			if host, _, err := net.SplitHostPort(node); err == nil {
+				node = host
+			} else {
+				node = strings.TrimSuffix(strings.TrimPrefix(node, "["), "]")
+			}
+			hops = append(hops, node)
+		}
+	}
+	return hops
+}
+
+// forwardedClient returns the rightmost address of hops that is not a
+// trusted proxy, or the leftmost one if all are trusted (a client on the
+// internal network). Addresses further left may have been set by the client.
+func forwardedClient(hops []string, isTrusted func(string) bool) string {
+	client := ""
+	for i := len(hops) - 1; i >= 0; i-- {
+		ip := strings.TrimSpace(hops[i])
+		if !validIP(ip) {
+			break
+		}
+		client = ip
+		if !isTrusted(ip) {
+			break
+		}
+	}
+	return client
+}
+
+// validIP reports whether s is an IPv4 or IPv6 address.
+func validIP(s string) bool {
+	_, err := netip.ParseAddr(s)
+	return err == nil
+}
+
+// parseProxy parses an address or CIDR range into a prefix.
+func parseProxy(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		return prefix.Masked(), err
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// parsePrefixes parses addresses and CIDR ranges, panicking with a message
+// naming what they are if one is invalid.
+func parsePrefixes(list []string, what string) []netip.Prefix {
+	prefixes := make([]netip.Prefix, len(list))
+	for i, s := range list {
+		prefix, err := parseProxy(s)
+		if err != nil {
+			panic("rig: invalid " + what + " " + s + ": " + err.Error())
+		}
+		prefixes[i] = prefix
+	}
+	return prefixes
+}
+
+// containsIP reports whether the address ip is in one of prefixes.
+func containsIP(prefixes []netip.Prefix, ip string) bool {
+	addr, err := netip.ParseAddr(strings.TrimSpace(ip))
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
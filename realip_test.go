@@ -0,0 +1,107 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIP(t *testing.T) {
+	tests := []struct {
+		name       string
+		header     string
+		remoteAddr string
+		forwarded  string
+		xff        string
+		xRealIP    string
+		want       string
+	}{
+		{"untrusted peer ignored", "", "203.0.113.9:1234", "", "198.51.100.1", "", "203.0.113.9:1234"},
+		{"trusted peer", "", "10.0.0.2:1234", "", "198.51.100.1", "", "198.51.100.1:1234"},
+		{"rightmost untrusted hop", "", "10.0.0.2:1234", "", "1.1.1.1, 198.51.100.1, 10.0.0.3", "", "198.51.100.1:1234"},
+		{"all hops trusted", "", "10.0.0.2:1234", "", "192.168.1.5, 10.0.0.3", "", "192.168.1.5:1234"},
+		{"x-real-ip", "", "127.0.0.1:1234", "", "", "198.51.100.7", "198.51.100.7:1234"},
+		{"invalid header", "", "127.0.0.1:1234", "", "not-an-ip", "also-not", "127.0.0.1:1234"},
+		{"ipv6", "", "[::1]:1234", "", "2001:db8::1", "", "[2001:db8::1]:1234"},
+		{"client forwarded ignored", "", "10.0.0.2:1234", "for=6.6.6.6", "198.51.100.1", "", "198.51.100.1:1234"},
+		{"client forwarded without x-forwarded-for", "", "10.0.0.2:1234", "for=6.6.6.6", "", "", "10.0.0.2:1234"},
+		{"x-forwarded-for only", "X-Forwarded-For", "127.0.0.1:1234", "", "", "198.51.100.7", "127.0.0.1:1234"},
+		{"x-real-ip only", "x-real-ip", "10.0.0.2:1234", "", "6.6.6.6", "198.51.100.7", "198.51.100.7:1234"},
+		{"forwarded", "Forwarded", "10.0.0.2:1234", "for=198.51.100.1;proto=https", "", "", "198.51.100.1:1234"},
+		{"client x-forwarded-for ignored", "Forwarded", "10.0.0.2:1234", "for=198.51.100.1", "6.6.6.6", "6.6.6.7", "198.51.100.1:1234"},
+		{"forwarded hops", "Forwarded", "10.0.0.2:1234", `for=1.1.1.1, for="198.51.100.1:4711";by=10.0.0.3, For=10.0.0.3`, "", "", "198.51.100.1:1234"},
+		{"forwarded ipv6", "Forwarded", "10.0.0.2:1234", `for="[2001:db8:cafe::17]:4711"`, "", "", "[2001:db8:cafe::17]:1234"},
+		{"forwarded unknown hop", "Forwarded", "10.0.0.2:1234", "for=198.51.100.1, for=unknown", "", "", "10.0.0.2:1234"},
+		{"forwarded from untrusted peer", "Forwarded", "203.0.113.9:1234", "for=198.51.100.1", "", "", "203.0.113.9:1234"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			r := New()
+			r.Use(RealIP(RealIPConfig{Header: tt.header}))
+			r.GET("/", func(c *Context) error {
+				got = c.Request().RemoteAddr
+				return nil
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			if tt.forwarded != "" {
+				req.Header.Set("Forwarded", tt.forwarded)
+			}
+			if tt.xff != "" {
+				req.Header.Set("X-Forwarded-For", tt.xff)
+			}
+			if tt.xRealIP != "" {
+				req.Header.Set("X-Real-IP", tt.xRealIP)
+			}
+			r.ServeHTTP(httptest.NewRecorder(), req)
+
+			if got != tt.want {
+				t.Errorf("RemoteAddr = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRealIP_InvalidProxyPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("RealIP should panic on an invalid trusted proxy")
+		}
+	}()
+	RealIP(RealIPConfig{TrustedProxies: []string{"10.0.0.0/33"}})
+}
+
+func TestRealIP_InvalidHeaderPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for an unsupported header")
+		}
+	}()
+	RealIP(RealIPConfig{Header: "X-Client-IP"})
+}
+
+func TestRealIP_Downstream(t *testing.T) {
+	r := New()
+	r.Use(RealIP(RealIPConfig{TrustedProxies: []string{"10.0.0.1"}, Header: "Forwarded"}))
+	r.Use(IPFilter(IPFilterConfig{Allow: []string{"198.51.100.0/24"}}))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	for forwarded, code := range map[string]int{
+		"for=198.51.100.1": http.StatusOK,
+		"for=203.0.113.9":  http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.0.0.1:1234"
+		req.Header.Set("Forwarded", forwarded)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != code {
+			t.Errorf("Forwarded %q: status = %d, want %d", forwarded, w.Code, code)
+		}
+	}
+}
@@ -0,0 +1,256 @@
+package render
+
+import (
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io/fs"
+	"net/http"
+	"path"
+	"strings"
+
+	"github.com/cloudresty/rig"
+)
+
+// assetInfo is the manifest entry of a static asset.
+type assetInfo struct {
+	url       string // AssetsPrefix + fingerprinted name, or name with a ?v= fingerprint
+	integrity string // Subresource Integrity digest, e.g. "sha384-..."
+}
+
+// AssetManifest maps asset names, as used in templates (e.g.,
+// "css/app.css"), to fingerprinted file names whose content hash changes on
+// every deploy (e.g., "css/app.3f9a1c2b7d4e.css"). Set it in
+// Config.AssetManifest; build it at startup with GenerateAssetManifest, or
+// read a bundler's manifest with LoadAssetManifest.
+type AssetManifest map[string]string
+
+// GenerateAssetManifest hashes every file in fsys and returns the manifest
+// of fingerprinted names, which insert the first 12 hex digits of the
+// file's SHA-384 hash before its extension. The files are not renamed:
+// serve them with Engine.AssetHandler, which resolves fingerprinted names.
+//
+// Example:
+//
+//	assets := os.DirFS("./public")
+//	manifest, err := render.GenerateAssetManifest(assets)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	engine := render.New(render.Config{Assets: assets, AssetManifest: manifest})
+func GenerateAssetManifest(fsys fs.FS) (AssetManifest, error) {
+	manifest := make(AssetManifest)
+	err := fs.WalkDir(fsys, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		sum := sha512.Sum384(content)
+		ext := path.Ext(name)
+		manifest[name] = strings.TrimSuffix(name, ext) + "." + hex.EncodeToString(sum[:6]) + ext
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("render: generate asset manifest: %w", err)
+	}
+	return manifest, nil
+}
+
+// LoadAssetManifest reads a JSON manifest written by a build tool. Both flat
+// manifests ({"css/app.css": "css/app.3f9a1c.css"}) and Vite manifests
+// ({"src/main.js": {"file": "assets/main.4889e940.js"}}) are supported.
+//
+// Example:
+//
+//	manifest, err := render.LoadAssetManifest(os.DirFS("./dist"), ".vite/manifest.json")
+func LoadAssetManifest(fsys fs.FS, name string) (AssetManifest, error) {
+	data, err := fs.ReadFile(fsys, name)
+	if err != nil {
+		return nil, fmt.Errorf("render: load asset manifest: %w", err)
+	}
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("render: load asset manifest %s: %w", name, err)
+	}
+
+	manifest := make(AssetManifest, len(raw))
+	for key, value := range raw {
+		var file string
+		if err := json.Unmarshal(value, &file); err != nil {
+			var chunk struct {
+				File string `json:"file"`
+			}
+			if err := json.Unmarshal(value, &chunk); err != nil || chunk.File == "" {
+				return nil, fmt.Errorf("render: load asset manifest %s: invalid entry %q", name, key)
+			}
+			file = chunk.File
+		}
+		manifest[key] = file
+	}
+	return manifest, nil
+}
+
+// AssetHandler returns a handler serving Config.Assets under
+// Config.AssetsPrefix. Fingerprinted names from Config.AssetManifest are
+// resolved to their files and sent with "Cache-Control: public,
+// max-age=31536000, immutable", since their content never changes under
+// the same name; other files are served as they are.
+//
+// Example:
+//
+//	r.GET("/assets/{path...}", engine.AssetHandler())
+func (e *Engine) AssetHandler() rig.HandlerFunc {
+	prefix := strings.TrimSuffix(e.config.AssetsPrefix, "/") + "/"
+	originals := make(map[string]string, len(e.config.AssetManifest))
+	for name, fingerprinted := range e.config.AssetManifest {
+		originals[fingerprinted] = name
+	}
+
+	return func(c *rig.Context) error {
+		if e.config.Assets == nil {
+			return rig.NewHTTPError(http.StatusNotFound, "not found")
+		}
+		name, ok := strings.CutPrefix(c.Request().URL.Path, prefix)
+		if !ok {
+			return rig.NewHTTPError(http.StatusNotFound, "not found")
+		}
+		if original, ok := originals[name]; ok {
+			// Bundlers write fingerprinted files; generated manifests don't
+			if _, err := fs.Stat(e.config.Assets, name); err != nil {
+				name = original
+			}
+			c.SetHeader("Cache-Control", string(rig.Immutable))
+		}
+		http.ServeFileFS(c.Writer(), c.Request(), e.config.Assets, name)
+		return nil
+	}
+}
+
+// asset returns the manifest entry for name, hashing the file in
+// Config.Assets on first use. Entries are cached until the next Load, so
+// DevMode picks up changed files.
+func (e *Engine) asset(name string) (assetInfo, error) {
+	name = strings.TrimPrefix(name, "/")
+
+	e.assetsMu.Lock()
+	defer e.assetsMu.Unlock()
+
+	if info, ok := e.assets[name]; ok {
+		return info, nil
+	}
+
+	info, err := e.newAssetInfo(name)
+	if err != nil {
+		return assetInfo{}, fmt.Errorf("render: asset %q: %w", name, err)
+	}
+	if e.assets == nil {
+		e.assets = make(map[string]assetInfo)
+	}
+	e.assets[name] = info
+	return info, nil
+}
+
+// newAssetInfo builds the manifest entry for name. With an AssetManifest,
+// the URL is the fingerprinted name, and the integrity digest is omitted if
+// the file is not in Config.Assets (e.g., served by a CDN). Otherwise the
+// URL gets a ?v= fingerprint.
+func (e *Engine) newAssetInfo(name string) (assetInfo, error) {
+	if fingerprinted, ok := e.config.AssetManifest[name]; ok {
+		// Not path.Join, which would break a CDN prefix such as "https://..."
+		info := assetInfo{url: strings.TrimSuffix(e.config.AssetsPrefix, "/") + "/" + fingerprinted}
+		if e.config.Assets == nil {
+			return info, nil
+		}
+		content, err := fs.ReadFile(e.config.Assets, fingerprinted)
+		if errors.Is(err, fs.ErrNotExist) {
+			content, err = fs.ReadFile(e.config.Assets, name)
+		}
+		if err != nil {
+			return assetInfo{}, err
+		}
+		sum := sha512.Sum384(content)
+		info.integrity = "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+		return info, nil
+	}
+
+	if e.config.Assets == nil {
+		return assetInfo{}, errors.New("Config.Assets is not set")
+	}
+	content, err := fs.ReadFile(e.config.Assets, name)
+	if err != nil {
+		return assetInfo{}, err
+	}
+	sum := sha512.Sum384(content)
+	return assetInfo{
+		url:       path.Join(e.config.AssetsPrefix, name) + "?v=" + hex.EncodeToString(sum[:6]),
+		integrity: "sha384-" + base64.StdEncoding.EncodeToString(sum[:]),
+	}, nil
+}
+
+// integrityAttr returns the integrity and crossorigin attributes of a tag,
+// or nothing if the digest is unknown.
+func (info assetInfo) integrityAttr() string {
+	if info.integrity == "" {
+		return ""
+	}
+	return ` integrity="` + info.integrity + `" crossorigin="anonymous"`
+}
+
+// resetAssets clears the asset manifest, so files are hashed again.
+func (e *Engine) resetAssets() {
+	e.assetsMu.Lock()
+	e.assets = nil
+	e.assetsMu.Unlock()
+}
+
+// assetFuncs returns the template functions for fingerprinted assets.
+func (e *Engine) assetFuncs() template.FuncMap {
+	return template.FuncMap{
+		"asset": func(name string) (string, error) {
+			info, err := e.asset(name)
+			return info.url, err
+		},
+		"integrity": func(name string) (string, error) {
+			info, err := e.asset(name)
+			return info.integrity, err
+		},
+		"scriptTag": func(name string) (template.HTML, error) {
+			info, err := e.asset(name)
+			if err != nil {
+				return "", err
+			}
+			return template.HTML(fmt.Sprintf( //nolint:gosec // Attributes are escaped
+				`<script src="%s"%s></script>`,
+				template.HTMLEscapeString(info.url), info.integrityAttr())), nil
+		},
+		"stylesheetTag": func(name string) (template.HTML, error) {
+			info, err := e.asset(name)
+			if err != nil {
+				return "", err
+			}
+			return template.HTML(fmt.Sprintf( //nolint:gosec // Attributes are escaped
+				`<link rel="stylesheet" href="%s"%s>`,
+				template.HTMLEscapeString(info.url), info.integrityAttr())), nil
+		},
+		"preloadTag": func(name string) (template.HTML, error) {
+			info, err := e.asset(name)
+			if err != nil {
+				return "", err
+			}
+			as := rig.PreloadAs(name)
+			if as == "" {
+				as = "fetch"
+			}
+			return template.HTML(fmt.Sprintf( //nolint:gosec // Attributes are escaped
+				`<link rel="preload" href="%s" as="%s"%s>`,
+				template.HTMLEscapeString(info.url), as, info.integrityAttr())), nil
+		},
+	}
+}
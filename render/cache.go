@@ -0,0 +1,133 @@
+package render
+
+import (
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultCacheMaxEntries is the default of Config.CacheMaxEntries.
+const defaultCacheMaxEntries = 1000
+
+// cacheKey identifies a cached rendering.
+type cacheKey struct {
+	name     string // template name
+	fragment bool   // rendered without the layout (Config.DetectFragments)
+	key      string // key from WithCacheKey
+}
+
+// cacheEntry is a cached rendering.
+type cacheEntry struct {
+	content string // with the engine's nonce marker in place of the CSP nonce
+	expires time.Time
+}
+
+// renderCache holds renderings until they expire.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[cacheKey]cacheEntry
+}
+
+// get returns the unexpired rendering stored under key.
+func (rc *renderCache) get(key cacheKey, now time.Time) (string, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	entry, ok := rc.entries[key]
+	if !ok {
+		return "", false
+	}
+	if !now.Before(entry.expires) {
+		delete(rc.entries, key)
+		return "", false
+	}
+	return entry.content, true
+}
+
+// put stores a rendering under key. When the cache holds limit entries, the
+// expired ones are evicted; if none have expired, the rendering is dropped.
+func (rc *renderCache) put(key cacheKey, entry cacheEntry, now time.Time, limit int) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.entries == nil {
+		rc.entries = make(map[cacheKey]cacheEntry)
+	}
+	if _, ok := rc.entries[key]; !ok && len(rc.entries) >= limit {
+		for k, e := range rc.entries {
+			if !now.Before(e.expires) {
+				delete(rc.entries, k)
+			}
+		}
+		if len(rc.entries) >= limit {
+			return
+		}
+	}
+	rc.entries[key] = entry
+}
+
+// remove deletes the renderings for which match returns true.
+func (rc *renderCache) remove(match func(cacheKey) bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	for k := range rc.entries {
+		if match(k) {
+			delete(rc.entries, k)
+		}
+	}
+}
+
+// renderHTML renders a template, or its fragment, to w, through the cache
+// when Config.CacheTTL is set and a key is passed with WithCacheKey.
+func (e *Engine) renderHTML(w io.Writer, name string, data any, fragment bool, nonce string, o *options) error {
+	render, renderTo := e.render, e.renderTo
+	if fragment {
+		render, renderTo = e.renderFragment, e.renderFragmentTo
+	}
+	if e.config.CacheTTL <= 0 || o.cacheKey == "" || o.noCache {
+		return renderTo(w, name, data, nonce)
+	}
+
+	key := cacheKey{name: name, fragment: fragment, key: o.cacheKey}
+
+	now := time.Now()
+	content, ok := e.cache.get(key, now)
+	if !ok {
+		// Rendered with the marker, so the rendering can be shared by
+		// requests with different nonces
+		var err error
+		content, err = render(name, data, e.nonceMarker)
+		if err != nil {
+			return err
+		}
+		e.cache.put(key, cacheEntry{content: content, expires: now.Add(e.config.CacheTTL)}, now, e.config.CacheMaxEntries)
+	}
+	_, err := io.WriteString(w, strings.ReplaceAll(content, e.nonceMarker, nonce))
+	return err
+}
+
+// InvalidateCache removes the cached renderings of the template name, for
+// all keys, so the next request renders it again. Call it when the
+// data a cached page shows changes.
+//
+// Example:
+//
+//	func updateProduct(c *rig.Context) error {
+//	    // ...
+//	    engine.InvalidateCache("products/list")
+//	    return c.Redirect(http.StatusSeeOther, "/products")
+//	}
+func (e *Engine) InvalidateCache(name string) {
+	e.cache.remove(func(k cacheKey) bool { return k.name == name })
+}
+
+// InvalidateCacheKey removes the renderings cached under key with
+// WithCacheKey, for all templates.
+func (e *Engine) InvalidateCacheKey(key string) {
+	e.cache.remove(func(k cacheKey) bool { return k.key == key })
+}
+
+// ClearCache removes all cached renderings. Templates are reloaded with an
+// empty cache.
+func (e *Engine) ClearCache() {
+	e.cache.remove(func(cacheKey) bool { return true })
+}
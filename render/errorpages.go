@@ -0,0 +1,78 @@
+package render
+
+import (
+	"net/http"
+
+	"github.com/cloudresty/rig"
+)
+
+// ErrorPages returns an error handler for rig.Router.SetErrorHandler that
+// renders the template mapped to the response status, so handlers return
+// errors instead of rendering error pages themselves with HTMLSafe. The
+// status is the one rig.DefaultErrorHandler would send (see rig.ErrorStatus):
+// that of errors with a StatusCode() int method (such as *rig.HTTPError), and
+// 500 for other errors. Status 0 maps the template used for statuses without
+// their own. Call Router.HandleRouteErrors to render
+// the 404 and 405 pages for unmatched requests too.
+//
+// The template receives {"StatusCode": 404, "Status": "Not Found",
+// "Error": "user not found"}. Error is the error message for 4xx errors, and
+// the status text for server errors, so internal details are not shown.
+//
+// Clients preferring JSON (see Auto) get {"error": message} instead. When
+// there is no template for the status, or it fails to render, the error is
+// handled by rig.DefaultErrorHandler. Use the engine's Middleware so the
+// engine is found.
+//
+// Example:
+//
+//	r.Use(engine.Middleware())
+//	r.HandleRouteErrors()
+//	r.SetErrorHandler(render.ErrorPages(map[int]string{
+//	    http.StatusNotFound: "errors/404",
+//	    0:                   "errors/500",
+//	}))
+//
+//	r.GET("/users/{id}", func(c *rig.Context) error {
+//	    user, err := store.User(c.Param("id"))
+//	    if errors.Is(err, store.ErrNotFound) {
+//	        return rig.NewHTTPError(http.StatusNotFound, "user not found")
+//	    }
+//	    // ...
+//	})
+func ErrorPages(pages map[int]string) rig.ErrorHandler {
+	return func(c *rig.Context, err error) {
+		if err == nil || c.Written() {
+			return
+		}
+
+		status := rig.ErrorStatus(err)
+		message := http.StatusText(status)
+		switch {
+		case status < 500:
+			message = err.Error()
+		case status == http.StatusInternalServerError:
+			message = c.Translate(rig.MsgInternalServerError, message)
+		}
+
+		name, ok := pages[status]
+		if !ok {
+			name, ok = pages[0]
+		}
+		if negotiate(c, "error") != ContentTypeHTML {
+			_ = c.JSON(status, map[string]string{"error": message})
+			return
+		}
+		if engine := GetEngine(c); ok && engine != nil {
+			data := map[string]any{
+				"StatusCode": status,
+				"Status":     http.StatusText(status),
+				"Error":      message,
+			}
+			if HTMLDirect(c, engine, status, name, data) == nil {
+				return
+			}
+		}
+		rig.DefaultErrorHandler(c, err)
+	}
+}
@@ -2,6 +2,11 @@ module github.com/cloudresty/rig/render
 
 go 1.25.4
 
-require github.com/cloudresty/rig v0.0.0
+require (
+	github.com/cloudresty/rig v0.0.0
+	github.com/fsnotify/fsnotify v1.10.1
+)
+
+require golang.org/x/sys v0.13.0 // indirect
 
 replace github.com/cloudresty/rig => ..
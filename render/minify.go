@@ -2,10 +2,143 @@ package render
 
 import (
 	"fmt"
+	"io"
+	"mime"
 	"regexp"
 	"strings"
 )
 
+// Minifier minifies rendered output of a media type (e.g., "text/html"),
+// reading it from r and writing the result to w. *minify.M from
+// github.com/tdewolff/minify/v2 implements it as is; see Config.Minifier.
+type Minifier interface {
+	Minify(mediaType string, w io.Writer, r io.Reader) error
+}
+
+// HTMLMinifierConfig defines the configuration of the built-in Minifier.
+type HTMLMinifierConfig struct {
+	// CSS minifies inline <style> blocks and text/css output, removing
+	// comments and insignificant whitespace.
+	// Default: false.
+	CSS bool
+
+	// JS minifies inline JavaScript <script> blocks and text/javascript
+	// output, removing comments and collapsing whitespace. Line breaks are
+	// kept, so automatic semicolon insertion is unaffected.
+	// Default: false.
+	JS bool
+}
+
+// HTMLMinifier returns the built-in Minifier, the default of
+// Config.Minifier. It removes comments and insignificant whitespace from
+// text/html output, leaving <pre>, <script>, <style>, and <textarea> content
+// as is, and copies output of other media types unchanged.
+func HTMLMinifier() Minifier {
+	return htmlMinifier{}
+}
+
+// HTMLMinifierWithConfig returns the built-in Minifier with custom
+// configuration, which can also minify inline CSS and JavaScript. See
+// HTMLMinifier for the behavior. Blocks it cannot parse, such as an
+// unterminated string, are left as is.
+//
+// Example:
+//
+//	engine := render.New(render.Config{
+//	    Minify:   true,
+//	    Minifier: render.HTMLMinifierWithConfig(render.HTMLMinifierConfig{CSS: true, JS: true}),
+//	})
+func HTMLMinifierWithConfig(config HTMLMinifierConfig) Minifier {
+	return htmlMinifier{config: config}
+}
+
+// htmlMinifier minifies HTML with minifyHTML.
+type htmlMinifier struct {
+	config HTMLMinifierConfig
+}
+
+// Minify implements Minifier.
+func (m htmlMinifier) Minify(mediaType string, w io.Writer, r io.Reader) error {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	output := string(b)
+	switch {
+	case mediaType == "text/html":
+		output = minifyHTMLBlocks(output, m.block)
+	case mediaType == "text/css" && m.config.CSS:
+		output = minifyCSS(output)
+	case isJavaScript(mediaType) && m.config.JS:
+		output = minifyJS(output)
+	}
+	_, err = io.WriteString(w, output)
+	return err
+}
+
+// block minifies the content of a <script> or <style> element, if enabled.
+func (m htmlMinifier) block(element string) string {
+	parts := blockContentRegex.FindStringSubmatch(element)
+	if parts == nil {
+		return element
+	}
+	tag, content, end := parts[1], parts[2], parts[3]
+	switch {
+	case strings.EqualFold(tag[1:6], "style") && m.config.CSS:
+		content = minifyCSS(content)
+	case strings.EqualFold(tag[1:7], "script") && m.config.JS:
+		mediaType := "text/javascript"
+		if typ := scriptTypeRegex.FindStringSubmatch(tag); typ != nil {
+			mediaType = strings.ToLower(typ[1])
+		}
+		if isJavaScript(mediaType) || mediaType == "module" {
+			content = minifyJS(content)
+		}
+	}
+	return tag + content + end
+}
+
+// isJavaScript reports whether mediaType is a JavaScript media type.
+func isJavaScript(mediaType string) bool {
+	switch mediaType {
+	case "text/javascript", "application/javascript", "text/ecmascript", "application/ecmascript":
+		return true
+	}
+	return false
+}
+
+// templateMediaType returns the media type of templates with the file
+// extension ext, such as "image/svg+xml" for ".svg". Extensions without a
+// registered type, such as ".tmpl", are HTML, or plain text in text mode.
+func templateMediaType(ext string, text bool) string {
+	mediaType, _, err := mime.ParseMediaType(mime.TypeByExtension(ext))
+	if err != nil {
+		if text {
+			return "text/plain"
+		}
+		return "text/html"
+	}
+	return mediaType
+}
+
+// minify minifies the output of template name with Config.Minifier, unless
+// Config.MinifySkip excludes it. Output the minifier fails on is returned
+// unchanged. The caller must hold e.mu.
+func (e *Engine) minify(name, output string) string {
+	if e.config.MinifySkip != nil && e.config.MinifySkip(name) {
+		return output
+	}
+	mediaType, ok := e.mediaTypes[name]
+	if !ok {
+		mediaType = "text/html"
+	}
+	var buf strings.Builder
+	if err := e.config.Minifier.Minify(mediaType, &buf, strings.NewReader(output)); err != nil {
+		return output
+	}
+	return buf.String()
+}
+
 // Precompiled regex patterns for minification
 var (
 	// Regex patterns for protected blocks (Go regex doesn't support backreferences)
@@ -20,6 +153,12 @@ var (
 	// Regular HTML comments (will be removed after IE conditionals are masked)
 	htmlCommentRegex = regexp.MustCompile(`(?s)<!--.*?-->`)
 
+	// The opening tag, content, and closing tag of a <script> or <style> element
+	blockContentRegex = regexp.MustCompile(`(?is)^(<(?:script|style)[^>]*>)(.*)(</(?:script|style)>)$`)
+
+	// The type attribute of a <script> tag
+	scriptTypeRegex = regexp.MustCompile(`(?i)\stype\s*=\s*["']?([^"'\s>;]+)`)
+
 	// Regex to collapse multiple spaces/newlines into a single space
 	multiSpaceRegex = regexp.MustCompile(`\s+`)
 
@@ -34,6 +173,12 @@ var (
 // It preserves whitespace inside <pre>, <script>, <style>, and <textarea> blocks,
 // and maintains spaces between inline elements to prevent text from collapsing.
 func minifyHTML(html string) string {
+	return minifyHTMLBlocks(html, nil)
+}
+
+// minifyHTMLBlocks is minifyHTML, passing each <script> and <style> element
+// through block, if not nil, instead of preserving it.
+func minifyHTMLBlocks(html string, block func(element string) string) string {
 	// 1. Masking Phase: Hide content that must NOT be minified
 	var placeholders []string
 
@@ -42,11 +187,17 @@ func minifyHTML(html string) string {
 		placeholders = append(placeholders, match)
 		return token
 	}
+	blockFunc := maskFunc
+	if block != nil {
+		blockFunc = func(match string) string {
+			return maskFunc(block(match))
+		}
+	}
 
 	maskedHTML := html
 	maskedHTML = preTagRegex.ReplaceAllStringFunc(maskedHTML, maskFunc)
-	maskedHTML = scriptTagRegex.ReplaceAllStringFunc(maskedHTML, maskFunc)
-	maskedHTML = styleTagRegex.ReplaceAllStringFunc(maskedHTML, maskFunc)
+	maskedHTML = scriptTagRegex.ReplaceAllStringFunc(maskedHTML, blockFunc)
+	maskedHTML = styleTagRegex.ReplaceAllStringFunc(maskedHTML, blockFunc)
 	maskedHTML = textareaTagRegex.ReplaceAllStringFunc(maskedHTML, maskFunc)
 	// Preserve IE conditional comments
 	maskedHTML = ieConditionalRegex.ReplaceAllStringFunc(maskedHTML, maskFunc)
@@ -76,3 +227,255 @@ func minifyHTML(html string) string {
 
 	return minified
 }
+
+// minifyCSS removes comments and insignificant whitespace from a style
+// sheet. It returns css unchanged if a string or comment is unterminated.
+func minifyCSS(css string) string {
+	var b strings.Builder
+	space := false
+	for i := 0; i < len(css); i++ {
+		ch := css[i]
+		switch {
+		case ch == '"' || ch == '\'':
+			end := quotedEnd(css, i)
+			if end < 0 {
+				return css
+			}
+			writeCSSSpace(&b, space, ch)
+			space = false
+			b.WriteString(css[i:end])
+			i = end - 1
+		case ch == '/' && strings.HasPrefix(css[i:], "/*"):
+			end := strings.Index(css[i+2:], "*/")
+			if end < 0 {
+				return css
+			}
+			i += end + 3
+			space = true
+		case isSpace(ch):
+			space = true
+		default:
+			if ch == '}' {
+				// The last declaration needs no semicolon
+				trimmed := strings.TrimSuffix(b.String(), ";")
+				b.Reset()
+				b.WriteString(trimmed)
+			}
+			writeCSSSpace(&b, space, ch)
+			space = false
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
+}
+
+// writeCSSSpace writes a pending space before ch, unless it is at the start
+// or next to punctuation where whitespace is insignificant.
+func writeCSSSpace(b *strings.Builder, space bool, ch byte) {
+	if !space || b.Len() == 0 || strings.IndexByte("{};,", ch) >= 0 {
+		return
+	}
+	if s := b.String(); strings.IndexByte("{};,", s[len(s)-1]) >= 0 {
+		return
+	}
+	b.WriteByte(' ')
+}
+
+// minifyJS removes comments from a script and collapses whitespace, keeping
+// one line break where a run of whitespace had one. It returns js unchanged
+// if a string, template literal, comment, or regular expression is
+// unterminated.
+func minifyJS(js string) string {
+	var b strings.Builder
+	if _, ok := writeJS(&b, js, 0, false); !ok {
+		return js
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// writeJS writes the minified code of js from i to b, up to the end, or up
+// to the closing brace of a template literal substitution if inTemplate. It
+// returns the index after the last byte read.
+func writeJS(b *strings.Builder, js string, i int, inTemplate bool) (int, bool) {
+	depth := 0
+	space, newline := false, false
+	last := func() string {
+		// The last token written, to tell regular expressions from division
+		s := strings.TrimRight(b.String(), " \n")
+		j := len(s)
+		for j > 0 && isIdentByte(s[j-1]) {
+			j--
+		}
+		if j == len(s) && j > 0 {
+			j--
+		}
+		return s[j:]
+	}
+	flush := func() {
+		switch {
+		case newline && b.Len() > 0:
+			b.WriteByte('\n')
+		case space && b.Len() > 0:
+			b.WriteByte(' ')
+		}
+		space, newline = false, false
+	}
+
+	for i < len(js) {
+		ch := js[i]
+		switch {
+		case ch == '"' || ch == '\'':
+			end := quotedEnd(js, i)
+			if end < 0 {
+				return i, false
+			}
+			flush()
+			b.WriteString(js[i:end])
+			i = end
+		case ch == '`':
+			flush()
+			b.WriteByte('`')
+			i++
+			for {
+				if i >= len(js) {
+					return i, false
+				}
+				switch {
+				case js[i] == '\\' && i+1 < len(js):
+					b.WriteString(js[i : i+2])
+					i += 2
+					continue
+				case js[i] == '`':
+					b.WriteByte('`')
+					i++
+				case strings.HasPrefix(js[i:], "${"):
+					b.WriteString("${")
+					var ok bool
+					if i, ok = writeJS(b, js, i+2, true); !ok {
+						return i, false
+					}
+					b.WriteByte('}')
+					continue
+				default:
+					b.WriteByte(js[i])
+					i++
+					continue
+				}
+				break
+			}
+		case strings.HasPrefix(js[i:], "//"):
+			end := strings.IndexByte(js[i:], '\n')
+			if end < 0 {
+				i = len(js)
+			} else {
+				i += end
+			}
+		case strings.HasPrefix(js[i:], "/*"):
+			end := strings.Index(js[i+2:], "*/")
+			if end < 0 {
+				return i, false
+			}
+			if strings.Contains(js[i:i+end+2], "\n") {
+				newline = true
+			}
+			space = true
+			i += end + 4
+		case ch == '/' && regexAllowed(last()):
+			end := regexEnd(js, i)
+			if end < 0 {
+				return i, false
+			}
+			flush()
+			b.WriteString(js[i:end])
+			i = end
+		case isSpace(ch):
+			space = true
+			if ch == '\n' || ch == '\r' {
+				newline = true
+			}
+			i++
+		default:
+			if inTemplate {
+				if ch == '{' {
+					depth++
+				} else if ch == '}' {
+					if depth == 0 {
+						return i + 1, true
+					}
+					depth--
+				}
+			}
+			flush()
+			b.WriteByte(ch)
+			i++
+		}
+	}
+	return i, !inTemplate
+}
+
+// regexAllowed reports whether a slash after the token last starts a
+// regular expression rather than a division.
+func regexAllowed(last string) bool {
+	if last == "" {
+		return true
+	}
+	if isIdentByte(last[0]) {
+		switch last {
+		case "return", "typeof", "case", "do", "else", "in", "of", "new",
+			"delete", "void", "throw", "instanceof", "yield", "await":
+			return true
+		}
+		return false
+	}
+	return strings.IndexByte(")]}", last[0]) < 0
+}
+
+// regexEnd returns the index after the regular expression literal starting
+// at js[i], before its flags, or -1 if it is unterminated.
+func regexEnd(js string, i int) int {
+	class := false
+	for j := i + 1; j < len(js); j++ {
+		switch js[j] {
+		case '\\':
+			j++
+		case '[':
+			class = true
+		case ']':
+			class = false
+		case '/':
+			if !class {
+				return j + 1
+			}
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// quotedEnd returns the index after the string literal starting at s[i], or
+// -1 if it is unterminated.
+func quotedEnd(s string, i int) int {
+	quote := s[i]
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case quote:
+			return j + 1
+		case '\n':
+			return -1
+		}
+	}
+	return -1
+}
+
+// isSpace reports whether ch is CSS or JavaScript whitespace.
+func isSpace(ch byte) bool {
+	return ch == ' ' || ch == '\t' || ch == '\n' || ch == '\r' || ch == '\f' || ch == '\v'
+}
+
+// isIdentByte reports whether ch can be part of an identifier or number.
+func isIdentByte(ch byte) bool {
+	return ch == '_' || ch == '$' || ch >= '0' && ch <= '9' || ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z' || ch >= 0x80
+}
@@ -0,0 +1,75 @@
+package render
+
+import (
+	"bytes"
+	"sync"
+	"sync/atomic"
+)
+
+// maxPooledBufferSize is the capacity above which buffers are not returned
+// to the pool, so a rare huge page does not keep its memory alive.
+const maxPooledBufferSize = 1 << 20
+
+// bufferPool holds the buffers used to execute templates and compose
+// layouts, shared by all engines.
+var bufferPool = sync.Pool{
+	New: func() any {
+		poolStats.allocs.Add(1)
+		return new(bytes.Buffer)
+	},
+}
+
+// poolStats counts buffer pool use for BufferPoolStats.
+var poolStats struct {
+	gets     atomic.Uint64
+	allocs   atomic.Uint64
+	discards atomic.Uint64
+}
+
+// PoolStats reports the use of the buffer pool in which templates are
+// executed and layouts composed. See BufferPoolStats.
+type PoolStats struct {
+	// Gets is the number of buffers taken from the pool.
+	Gets uint64 `json:"gets"`
+
+	// Allocs is the number of buffers allocated because the pool had none
+	// free. Once traffic is steady, it should grow much slower than Gets.
+	Allocs uint64 `json:"allocs"`
+
+	// Discards is the number of buffers dropped instead of being returned
+	// to the pool, because they grew above 1 MB.
+	Discards uint64 `json:"discards"`
+}
+
+// BufferPoolStats returns the buffer pool statistics of the render
+// package, for verifying that pooling cuts allocations, such as in a
+// benchmark or a debug endpoint.
+//
+// Example:
+//
+//	r.GET("/debug/render", func(c *rig.Context) error {
+//	    return c.JSON(http.StatusOK, render.BufferPoolStats())
+//	})
+func BufferPoolStats() PoolStats {
+	return PoolStats{
+		Gets:     poolStats.gets.Load(),
+		Allocs:   poolStats.allocs.Load(),
+		Discards: poolStats.discards.Load(),
+	}
+}
+
+// getBuffer returns an empty buffer from the pool.
+func getBuffer() *bytes.Buffer {
+	poolStats.gets.Add(1)
+	return bufferPool.Get().(*bytes.Buffer)
+}
+
+// putBuffer returns buf to the pool. buf must not be used afterwards.
+func putBuffer(buf *bytes.Buffer) {
+	if buf.Cap() > maxPooledBufferSize {
+		poolStats.discards.Add(1)
+		return
+	}
+	buf.Reset()
+	bufferPool.Put(buf)
+}
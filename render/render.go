@@ -25,7 +25,9 @@
 //	    Layout:    "layouts/base",
 //	})
 //
-// Templates can use {{.Content}} to include the page content.
+// Templates can use {{.Content}} to include the page content. With
+// Config.BlockLayout, pages instead override the layout's {{block}} regions
+// with {{define}}.
 //
 // # Partials
 //
@@ -57,23 +59,43 @@
 //
 //	// Returns HTML or JSON based on Accept header
 //	render.Auto(c, http.StatusOK, "dashboard", data)
+//
+// # Static Assets
+//
+// With Config.Assets set, templates can reference fingerprinted assets with
+// Subresource Integrity:
+//
+//	{{preloadTag "fonts/inter.woff2"}}
+//	{{stylesheetTag "css/app.css"}}
+//	{{scriptTag "js/app.js"}}
 package render
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"encoding/xml"
 	"fmt"
 	"html/template"
+	"io"
 	"io/fs"
+	"iter"
 	"maps"
+	"net/http"
 	"os"
 	"path/filepath"
 	"slices"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/cloudresty/rig"
+	"github.com/fsnotify/fsnotify"
 )
 
 // ContextKey is the key used to store the Engine in the rig context.
@@ -84,6 +106,7 @@ const (
 	ContentTypeHTML = "text/html; charset=utf-8"
 	ContentTypeJSON = "application/json; charset=utf-8"
 	ContentTypeXML  = "application/xml; charset=utf-8"
+	ContentTypeYAML = rig.ContentTypeYAML
 )
 
 // Config defines the configuration for the template engine.
@@ -112,6 +135,24 @@ type Config struct {
 	// Default: []string{".html", ".tmpl"}.
 	Extensions []string
 
+	// Text parses templates with text/template instead of html/template,
+	// for plain-text output such as emails, CLI output, and configuration
+	// files, where HTML escaping would corrupt the output. Partials,
+	// layouts, functions, and the other options work the same way. Render
+	// with Render and RenderTo; the HTML helpers would send the output as
+	// text/html. Output is never escaped, so do not use it for HTML pages.
+	//
+	// Example:
+	//   emails := render.New(render.Config{
+	//       Directory:  "./templates/emails",
+	//       Extensions: []string{".txt"},
+	//       Layout:     "layout",
+	//       Text:       true,
+	//   })
+	//   body, err := emails.Render("welcome", user)
+	// Default: false.
+	Text bool
+
 	// SharedDirs is a list of directories (relative to Directory) containing
 	// templates that should be available globally to all other templates.
 	//
@@ -133,10 +174,43 @@ type Config struct {
 	// If set, all templates will be rendered within this layout.
 	// The layout should contain {{.Content}} to include page content.
 	// Data passed to the template is available via {{.Data}}.
+	// The layout can also declare named sections with {{yield "name"}},
+	// which pages fill with {{content "name"}}...{{end}} at the top level;
+	// sections a page does not fill render nothing.
 	// Default: "" (no layout).
 	Layout string
 
-	// DevMode enables hot reloading of templates on each request.
+	// BlockLayout renders pages through Layout with template inheritance
+	// instead of {{.Content}}. The layout declares overridable regions with
+	// {{block "name" .}}default{{end}}, and each page overrides any of them
+	// with {{define "name"}}...{{end}}; regions a page does not define keep
+	// their default. Both receive the page data directly (no .Data or
+	// .Content).
+	//
+	// Example layout:
+	//   <title>{{block "title" .}}My Site{{end}}</title>
+	//   <head>{{block "head" .}}{{end}}</head>
+	//   <body>{{block "body" .}}{{end}}</body>
+	//
+	// Example page:
+	//   {{define "title"}}{{.Product.Name}} | My Site{{end}}
+	//   {{define "body"}}<h1>{{.Product.Name}}</h1>{{end}}
+	// Default: false.
+	BlockLayout bool
+
+	// DetectFragments makes HTML render templates without the layout for
+	// requests that only need a fragment of the page: htmx requests
+	// (HX-Request, unless boosted or restoring history) and Turbo Frame
+	// requests (Turbo-Frame). Responses get "Vary: HX-Request, Turbo-Frame".
+	// See also Fragment.
+	// Default: false.
+	DetectFragments bool
+
+	// DevMode enables hot reloading of templates. The template directory is
+	// watched for changes (with fsnotify), and templates are reloaded on the
+	// next request after a file changes, so unchanged templates cost
+	// nothing. Templates in FileSystem, which cannot be watched, are reloaded
+	// on each request. Call Close to stop watching.
 	// This is useful during development but should be disabled in production.
 	// Default: false.
 	DevMode bool
@@ -157,16 +231,102 @@ type Config struct {
 	// This can reduce bandwidth and improve page load times in production.
 	// Default: false.
 	Minify bool
+
+	// Minifier minifies output when Minify is set. It receives the media
+	// type of each template, from its file extension (text/html for .html
+	// and .tmpl, image/svg+xml for .svg, ...). Use HTMLMinifierWithConfig
+	// to also minify inline CSS and JavaScript, or *minify.M from
+	// github.com/tdewolff/minify/v2 for full minification. Output the
+	// minifier returns an error for, including media types it has no
+	// minifier for, is sent unminified.
+	//
+	// Example:
+	//   m := minify.New()
+	//   m.AddFunc("text/html", html.Minify)
+	//   m.AddFunc("text/css", css.Minify)
+	//   m.AddFunc("application/javascript", js.Minify)
+	//
+	//   engine := render.New(render.Config{Minify: true, Minifier: m})
+	// Default: HTMLMinifier().
+	Minifier Minifier
+
+	// MinifySkip selects templates, by name, whose output is not minified,
+	// such as pages whose whitespace matters.
+	// Default: nil (all templates are minified).
+	MinifySkip func(name string) bool
+
+	// Assets is the filesystem of static assets (CSS, JavaScript, fonts)
+	// referenced by the asset, integrity, scriptTag, stylesheetTag, and
+	// preloadTag template functions. It is usually the directory served
+	// with r.Static. Files are hashed on first use to build the manifest of
+	// fingerprinted URLs and Subresource Integrity digests.
+	//
+	// Example:
+	//   //go:embed public
+	//   var public embed.FS
+	//   assets, _ := fs.Sub(public, "public")
+	//
+	//   engine := render.New(render.Config{Assets: assets})
+	//   r.Static("/assets", "./public")
+	Assets fs.FS
+
+	// AssetsPrefix is the URL path Assets are served under. With an
+	// AssetManifest, it may also be a CDN URL (e.g., "https://cdn.example.com").
+	// Default: "/assets".
+	AssetsPrefix string
+
+	// AssetManifest maps asset names to fingerprinted file names, which the
+	// asset functions use in URLs instead of a ?v= query string, so assets
+	// can be cached forever and are busted on deploy. Generate it from
+	// Assets at startup with GenerateAssetManifest (and serve Assets with
+	// AssetHandler), or load a bundler's with LoadAssetManifest.
+	// Default: nil (?v= fingerprints).
+	AssetManifest AssetManifest
+
+	// CacheTTL enables caching of HTML renderings for expensive pages, such
+	// as dashboards and listings, which are then served from memory for
+	// CacheTTL. Only calls passing WithCacheKey are cached, keyed by the
+	// template name and that key, which must identify everything the
+	// template shows. Pass WithoutCache to bypass the cache for a call,
+	// and use InvalidateCache when the data changes. The CSP nonce is
+	// filled in per request.
+	// Default: 0 (no caching).
+	CacheTTL time.Duration
+
+	// CacheMaxEntries is the maximum number of cached renderings. When the
+	// cache is full, expired renderings are evicted; if none has expired,
+	// new renderings are not cached.
+	// Default: 1000.
+	CacheMaxEntries int
 }
 
 // Engine is the template rendering engine.
 type Engine struct {
 	config     Config
-	templates  map[string]*template.Template
-	partials   *template.Template // Shared partials template
+	templates  map[string]templateSet
+	partials   templateSet       // Shared partials template
+	mediaTypes map[string]string // Media types of templates and partials
 	layoutName string
 	funcs      template.FuncMap
 	mu         sync.RWMutex
+
+	// assets caches the manifest entries of Config.Assets by name.
+	assets   map[string]assetInfo
+	assetsMu sync.Mutex
+
+	// watcher reports changes to the template directory in DevMode; stale
+	// records that templates changed since they were loaded.
+	watcher   *fsnotify.Watcher
+	watchOnce sync.Once
+	stale     atomic.Bool
+
+	// cache holds renderings when Config.CacheTTL is set.
+	cache renderCache
+
+	// nonceMarker is output by the cspNonce function and replaced with the
+	// request's CSP nonce after execution, since template functions are
+	// bound when templates are parsed, not per request.
+	nonceMarker string
 }
 
 // New creates a new template engine with the given configuration.
@@ -178,11 +338,21 @@ func New(config Config) *Engine {
 	if len(config.Extensions) == 0 {
 		config.Extensions = []string{".html", ".tmpl"}
 	}
+	if config.AssetsPrefix == "" {
+		config.AssetsPrefix = "/assets"
+	}
+	if config.Minifier == nil {
+		config.Minifier = HTMLMinifier()
+	}
+	if config.CacheMaxEntries <= 0 {
+		config.CacheMaxEntries = defaultCacheMaxEntries
+	}
 
 	e := &Engine{
-		config:    config,
-		templates: make(map[string]*template.Template),
-		funcs:     make(template.FuncMap),
+		config:      config,
+		templates:   make(map[string]templateSet),
+		funcs:       make(template.FuncMap),
+		nonceMarker: "rigcspnonce" + rand.Text(),
 	}
 
 	// Add default functions
@@ -200,6 +370,28 @@ func New(config Config) *Engine {
 		return template.HTML("<pre>" + string(b) + "</pre>") //nolint:gosec // Debug output
 	}
 
+	// Content-Security-Policy nonce of the request (see rig.SecureHeaders)
+	e.funcs["cspNonce"] = func() string {
+		return e.nonceMarker
+	}
+
+	// Locale-aware formatting; pass the request locale (e.g., c.Locale()) in the data
+	e.funcs["formatDate"] = func(locale string, t time.Time) string {
+		return rig.NewFormatter(locale).Date(t)
+	}
+	e.funcs["formatDateTime"] = func(locale string, t time.Time) string {
+		return rig.NewFormatter(locale).DateTime(t)
+	}
+	e.funcs["formatNumber"] = func(locale string, v float64, decimals int) string {
+		return rig.NewFormatter(locale).Number(v, decimals)
+	}
+	e.funcs["formatCurrency"] = func(locale string, amount float64, code string) string {
+		return rig.NewFormatter(locale).Currency(amount, code)
+	}
+
+	// Fingerprinted asset URLs with Subresource Integrity (see Config.Assets)
+	maps.Copy(e.funcs, e.assetFuncs())
+
 	// Merge custom functions
 	maps.Copy(e.funcs, config.Funcs)
 
@@ -227,9 +419,12 @@ func (e *Engine) Load() error {
 	e.mu.Lock()
 	defer e.mu.Unlock()
 
-	e.templates = make(map[string]*template.Template)
+	e.templates = make(map[string]templateSet)
 	e.partials = nil
+	e.mediaTypes = make(map[string]string)
 	e.layoutName = ""
+	e.resetAssets()
+	e.ClearCache()
 
 	// Setup the filesystem
 	// If FileSystem is provided, use it (e.g., embed.FS)
@@ -280,7 +475,8 @@ func (e *Engine) Load() error {
 			return fmt.Errorf("failed to read template %s: %w", name, err)
 		}
 
-		tf := templateFile{name: name, path: path, content: string(content)}
+		tf := templateFile{name: name, path: path, content: e.rewriteSections(name, string(content))}
+		e.mediaTypes[name] = templateMediaType(ext, e.config.Text)
 
 		// Check if this is a shared partial:
 		// - filename starts with "_" (legacy convention), OR
@@ -301,7 +497,7 @@ func (e *Engine) Load() error {
 	// First, create a base template with all partials
 	// This allows partials to be available to all templates
 	if len(partialFiles) > 0 {
-		e.partials = e.applyDelims(template.New("__partials__")).Funcs(e.funcs)
+		e.partials = e.newTemplateSet("__partials__")
 		for _, pf := range partialFiles {
 			_, err := e.partials.New(pf.name).Parse(pf.content)
 			if err != nil {
@@ -310,30 +506,30 @@ func (e *Engine) Load() error {
 		}
 	}
 
+	// With BlockLayout, pages are parsed on top of a copy of the layout, so
+	// their definitions override its blocks
+	var base templateSet
+	if e.config.BlockLayout && e.config.Layout != "" {
+		i := slices.IndexFunc(files, func(tf templateFile) bool { return tf.name == e.config.Layout })
+		if i < 0 {
+			return fmt.Errorf("layout template %q not found", e.config.Layout)
+		}
+		var err error
+		if base, err = e.parseTemplate(nil, files[i]); err != nil {
+			return err
+		}
+	}
+
 	// Now parse each template, cloning the partials so they're available
 	for _, tf := range files {
-		var tmpl *template.Template
-		if e.partials != nil {
-			// Clone partials so each template has access to them
-			var err error
-			tmpl, err = e.partials.Clone()
-			if err != nil {
-				return fmt.Errorf("failed to clone partials for %s: %w", tf.name, err)
-			}
-			// Parse the main template content into the cloned template
-			_, err = tmpl.New(tf.name).Parse(tf.content)
-			if err != nil {
-				return fmt.Errorf("failed to parse template %s: %w", tf.name, err)
-			}
-		} else {
-			// No partials, create a new template
-			var err error
-			tmpl, err = e.applyDelims(template.New(tf.name)).Funcs(e.funcs).Parse(tf.content)
-			if err != nil {
-				return fmt.Errorf("failed to parse template %s: %w", tf.name, err)
-			}
+		from := base
+		if tf.name == e.config.Layout {
+			from = nil
+		}
+		tmpl, err := e.parseTemplate(from, tf)
+		if err != nil {
+			return err
 		}
-
 		e.templates[tf.name] = tmpl
 	}
 
@@ -348,6 +544,33 @@ func (e *Engine) Load() error {
 	return nil
 }
 
+// parseTemplate parses tf into a copy of base, or of the partials if base is
+// nil, so the templates defined there are available to it.
+func (e *Engine) parseTemplate(base templateSet, tf templateFile) (templateSet, error) {
+	if base == nil {
+		base = e.partials
+	}
+	if base == nil {
+		// No partials, create a new template
+		tmpl, err := e.newTemplateSet(tf.name).Parse(tf.content)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse template %s: %w", tf.name, err)
+		}
+		return tmpl, nil
+	}
+
+	// Clone so each template has access to the shared definitions
+	tmpl, err := base.Clone()
+	if err != nil {
+		return nil, fmt.Errorf("failed to clone partials for %s: %w", tf.name, err)
+	}
+	// Parse the main template content into the cloned template
+	if _, err := tmpl.New(tf.name).Parse(tf.content); err != nil {
+		return nil, fmt.Errorf("failed to parse template %s: %w", tf.name, err)
+	}
+	return tmpl, nil
+}
+
 // isValidExtension checks if the given extension is in the allowed list.
 func (e *Engine) isValidExtension(ext string) bool {
 	return slices.Contains(e.config.Extensions, ext)
@@ -387,37 +610,58 @@ func (e *Engine) isShared(path string) bool {
 	return false
 }
 
-// applyDelims applies custom delimiters to a template if configured.
-func (e *Engine) applyDelims(t *template.Template) *template.Template {
-	if len(e.config.Delims) == 2 {
-		return t.Delims(e.config.Delims[0], e.config.Delims[1])
-	}
-	return t
-}
-
 // Middleware returns a rig middleware that injects the engine into the context.
-// It also loads templates on first request (and on each request in DevMode).
+// It also loads templates on first request (and after templates change in
+// DevMode, or when rig.Preset(rig.Development) is in use).
 func (e *Engine) Middleware() rig.MiddlewareFunc {
+	return e.middleware(ContextKey)
+}
+
+// NamedMiddleware is like Middleware, but registers the engine under name,
+// so an application serving distinct frontends can use several engines
+// (e.g., "admin" and "public" template sets with their own directories and
+// layouts) side by side. Render with HTMLFrom and PartialFrom.
+//
+// Example:
+//
+//	r.Use(admin.NamedMiddleware("admin"))
+//	r.Use(public.NamedMiddleware("public"))
+//
+//	r.GET("/admin/users", func(c *rig.Context) error {
+//	    return render.HTMLFrom(c, "admin", http.StatusOK, "users/index", data)
+//	})
+func (e *Engine) NamedMiddleware(name string) rig.MiddlewareFunc {
+	return e.middleware(namedKey(name))
+}
+
+// middleware loads the engine's templates and stores it under key.
+func (e *Engine) middleware(key string) rig.MiddlewareFunc {
 	var loaded bool
 	var loadMu sync.Mutex
 
 	return func(next rig.HandlerFunc) rig.HandlerFunc {
 		return func(c *rig.Context) error {
 			// Load or reload templates
-			if e.config.DevMode || !loaded {
+			devMode := e.config.DevMode || rig.EnvironmentOf(c) == rig.Development
+			changed := devMode && e.templatesChanged()
+			if changed || !loaded {
 				loadMu.Lock()
-				if e.config.DevMode || !loaded {
+				if changed || !loaded {
 					if err := e.Load(); err != nil {
+						e.stale.Store(true) // Retry on the next request
 						loadMu.Unlock()
 						return fmt.Errorf("failed to load templates: %w", err)
 					}
 					loaded = true
 				}
 				loadMu.Unlock()
+			} else if devMode {
+				// Assets are not watched; rehash them as they are used
+				e.resetAssets()
 			}
 
 			// Store engine in context
-			c.Set(ContextKey, e)
+			c.Set(key, e)
 
 			return next(c)
 		}
@@ -425,22 +669,77 @@ func (e *Engine) Middleware() rig.MiddlewareFunc {
 }
 
 // Render renders a template by name with the given data.
+// The cspNonce function renders an empty string; use HTML or HTMLDirect to
+// render with the request's nonce.
 func (e *Engine) Render(name string, data any) (string, error) {
+	return e.render(name, data, "")
+}
+
+// RenderTo renders a template by name with the given data to w, without
+// building the page as a string first (unless Config.Minify is set, which
+// needs the whole page). Output is written as the template executes, so w
+// may receive part of the page before an error. The cspNonce function
+// renders an empty string, as with Render.
+//
+// Example:
+//
+//	f, err := os.Create("public/index.html")
+//	if err != nil {
+//	    return err
+//	}
+//	defer f.Close()
+//	return engine.RenderTo(f, "home", data)
+func (e *Engine) RenderTo(w io.Writer, name string, data any) error {
+	return e.renderTo(w, name, data, "")
+}
+
+// render renders a template by name, substituting nonce for cspNonce.
+func (e *Engine) render(name string, data any, nonce string) (string, error) {
+	var sb strings.Builder
+	if err := e.renderTo(&sb, name, data, nonce); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// renderTo renders a template by name to w, substituting nonce for
+// cspNonce.
+func (e *Engine) renderTo(w io.Writer, name string, data any, nonce string) error {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	tmpl, ok := e.templates[name]
 	if !ok {
-		return "", fmt.Errorf("template %q not found", name)
+		return fmt.Errorf("template %q not found", name)
 	}
 
-	var buf bytes.Buffer
+	return e.output(w, name, nonce, func(w io.Writer) error {
+		// If we have a layout, render the content template first, then the layout
+		if e.config.BlockLayout && e.layoutName != "" && name != e.layoutName {
+			// The page's definitions were parsed over the layout's blocks
+			if err := tmpl.ExecuteTemplate(w, e.layoutName, data); err != nil {
+				return fmt.Errorf("failed to execute template %s: %w", name, err)
+			}
+			return nil
+		}
+		if e.layoutName == "" || name == e.layoutName {
+			// No layout, render template directly
+			if err := tmpl.ExecuteTemplate(w, name, data); err != nil {
+				return fmt.Errorf("failed to execute template %s: %w", name, err)
+			}
+			return nil
+		}
 
-	// If we have a layout, render the content template first, then the layout
-	if e.layoutName != "" && name != e.layoutName {
 		// Render content template - execute the named template within the set
-		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
-			return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err := tmpl.ExecuteTemplate(buf, name, data); err != nil {
+			return fmt.Errorf("failed to execute template %s: %w", name, err)
+		}
+
+		sections, err := renderSections(tmpl, data)
+		if err != nil {
+			return fmt.Errorf("failed to execute template %s: %w", name, err)
 		}
 
 		// Create layout data using the "View Bag" pattern.
@@ -449,8 +748,9 @@ func (e *Engine) Render(name string, data any) (string, error) {
 		//   - Use {{.Content}} for the rendered page content
 		//   - Use {{.Data.Title}} to access fields from the original data
 		layoutData := map[string]any{
-			"Content": template.HTML(buf.String()), //nolint:gosec // Content is from our own templates
-			"Data":    data,                        // Original data is always available via .Data
+			"Content":  template.HTML(buf.String()), //nolint:gosec // Content is from our own templates
+			"Data":     data,                        // Original data is always available via .Data
+			"CSPNonce": nonce,                       // Same value as the cspNonce function
 		}
 
 		// For backward compatibility, also merge map fields at the top level
@@ -458,29 +758,145 @@ func (e *Engine) Render(name string, data any) (string, error) {
 		if dataMap, ok := data.(map[string]any); ok {
 			maps.Copy(layoutData, dataMap)
 		}
+		// Read by {{yield "name"}}, so it is not overridden by the data
+		layoutData["Sections"] = sections
 
 		// Get the layout template and render it
 		layoutTmpl, ok := e.templates[e.layoutName]
 		if !ok {
-			return "", fmt.Errorf("layout template %q not found", e.layoutName)
+			return fmt.Errorf("layout template %q not found", e.layoutName)
 		}
 
-		buf.Reset()
-		if err := layoutTmpl.ExecuteTemplate(&buf, e.layoutName, layoutData); err != nil {
-			return "", fmt.Errorf("failed to execute layout: %w", err)
+		if err := layoutTmpl.ExecuteTemplate(w, e.layoutName, layoutData); err != nil {
+			return fmt.Errorf("failed to execute layout: %w", err)
 		}
-	} else {
-		// No layout, render template directly
-		if err := tmpl.ExecuteTemplate(&buf, name, data); err != nil {
-			return "", fmt.Errorf("failed to execute template %s: %w", name, err)
+		return nil
+	})
+}
+
+// RenderFragment renders a fragment of a template without the layout. The
+// name is a template name, optionally followed by "#" and the name of a
+// template defined in it (e.g., "pages/users#rows" renders the
+// {{define "rows"}} or {{block "rows" .}} of pages/users). Without "#", the
+// whole template is rendered.
+func (e *Engine) RenderFragment(name string, data any) (string, error) {
+	return e.renderFragment(name, data, "")
+}
+
+// renderFragment renders a fragment by name, substituting nonce for
+// cspNonce.
+func (e *Engine) renderFragment(name string, data any, nonce string) (string, error) {
+	var sb strings.Builder
+	if err := e.renderFragmentTo(&sb, name, data, nonce); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// renderFragmentTo renders a fragment by name to w, substituting nonce for
+// cspNonce.
+func (e *Engine) renderFragmentTo(w io.Writer, name string, data any, nonce string) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	page, block, found := strings.Cut(name, "#")
+	if !found {
+		block = page
+	}
+	tmpl, ok := e.templates[page]
+	if !ok {
+		return fmt.Errorf("template %q not found", page)
+	}
+	if tmpl.Lookup(block) == nil {
+		return fmt.Errorf("template %q not found in %s", block, page)
+	}
+
+	return e.output(w, page, nonce, func(w io.Writer) error {
+		if err := tmpl.ExecuteTemplate(w, block, data); err != nil {
+			return fmt.Errorf("failed to execute template %s: %w", name, err)
 		}
+		return nil
+	})
+}
+
+// output writes the output of template name, produced by execute, to w
+// with nonce substituted for cspNonce, minified if configured. The caller
+// must hold e.mu.
+func (e *Engine) output(w io.Writer, name, nonce string, execute func(w io.Writer) error) error {
+	if e.config.Minify {
+		// Minifiers need the whole output
+		buf := getBuffer()
+		defer putBuffer(buf)
+		if err := execute(buf); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, e.finish(name, buf.String(), nonce))
+		return err
+	}
+
+	nw := &nonceWriter{w: w, marker: []byte(e.nonceMarker), nonce: []byte(nonce)}
+	if err := execute(nw); err != nil {
+		return err
 	}
+	return nw.flush()
+}
 
-	result := buf.String()
+// finish substitutes nonce for cspNonce in the output of template name and
+// minifies it if configured. The caller must hold e.mu.
+func (e *Engine) finish(name, result, nonce string) string {
+	result = strings.ReplaceAll(result, e.nonceMarker, nonce)
 	if e.config.Minify {
-		result = minifyHTML(result)
+		result = e.minify(name, result)
+	}
+	return result
+}
+
+// nonceWriter substitutes nonce for marker in the output written through
+// it. Output that may be the start of a marker split across writes is held
+// back until the next write or flush.
+type nonceWriter struct {
+	w       io.Writer
+	marker  []byte
+	nonce   []byte
+	pending []byte
+}
+
+// Write substitutes the nonce and writes p, except for a possible partial
+// marker at its end.
+func (nw *nonceWriter) Write(p []byte) (int, error) {
+	data := p
+	if len(nw.pending) > 0 {
+		data = append(nw.pending, p...)
+		nw.pending = nil
+	}
+	if bytes.Contains(data, nw.marker) {
+		data = bytes.ReplaceAll(data, nw.marker, nw.nonce)
+	}
+
+	keep := 0
+	for n := min(len(nw.marker)-1, len(data)); n > 0; n-- {
+		if bytes.HasSuffix(data, nw.marker[:n]) {
+			keep = n
+			break
+		}
+	}
+	if keep > 0 {
+		nw.pending = append([]byte(nil), data[len(data)-keep:]...)
+	}
+	if _, err := nw.w.Write(data[:len(data)-keep]); err != nil {
+		return 0, err
 	}
-	return result, nil
+	return len(p), nil
+}
+
+// flush writes the output held back.
+func (nw *nonceWriter) flush() error {
+	if len(nw.pending) == 0 {
+		return nil
+	}
+	_, err := nw.w.Write(nw.pending)
+	nw.pending = nil
+	return err
 }
 
 // RenderPartial renders a partial template by name with the given data.
@@ -490,64 +906,136 @@ func (e *Engine) Render(name string, data any) (string, error) {
 // Partial names are the template names as loaded (e.g., "_header" or "partials/nav").
 // Use PartialNames() to see all available partials.
 func (e *Engine) RenderPartial(name string, data any) (string, error) {
+	return e.renderPartial(name, data, "")
+}
+
+// renderPartial renders a partial by name, substituting nonce for cspNonce.
+func (e *Engine) renderPartial(name string, data any, nonce string) (string, error) {
+	var sb strings.Builder
+	if err := e.renderPartialTo(&sb, name, data, nonce); err != nil {
+		return "", err
+	}
+	return sb.String(), nil
+}
+
+// renderPartialTo renders a partial by name to w, substituting nonce for
+// cspNonce.
+func (e *Engine) renderPartialTo(w io.Writer, name string, data any, nonce string) error {
 	e.mu.RLock()
 	defer e.mu.RUnlock()
 
 	if e.partials == nil {
-		return "", fmt.Errorf("no partials loaded; ensure SharedDirs is configured or use _prefix naming")
+		return fmt.Errorf("no partials loaded; ensure SharedDirs is configured or use _prefix naming")
 	}
 
 	// Look up the template in the partials set
 	tmpl := e.partials.Lookup(name)
 	if tmpl == nil {
-		return "", fmt.Errorf("partial %q not found", name)
+		return fmt.Errorf("partial %q not found", name)
 	}
 
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("failed to execute partial %s: %w", name, err)
-	}
-
-	result := buf.String()
-	if e.config.Minify {
-		result = minifyHTML(result)
-	}
-	return result, nil
+	return e.output(w, name, nonce, func(w io.Writer) error {
+		if err := tmpl.Execute(w, data); err != nil {
+			return fmt.Errorf("failed to execute partial %s: %w", name, err)
+		}
+		return nil
+	})
 }
 
 // HTML renders a template and writes it as an HTML response.
 // It retrieves the engine from the context (set by Middleware).
-func HTML(c *rig.Context, status int, name string, data any) error {
+// With Config.DetectFragments, htmx and Turbo Frame requests get the
+// template without the layout. With Config.CacheTTL, pass WithCacheKey or
+// WithoutCache to control caching.
+func HTML(c *rig.Context, status int, name string, data any, opts ...Option) error {
 	engine := GetEngine(c)
 	if engine == nil {
 		return fmt.Errorf("render engine not found in context; did you forget to use engine.Middleware()?")
 	}
+	return HTMLDirect(c, engine, status, name, data, opts...)
+}
 
-	content, err := engine.Render(name, data)
-	if err != nil {
+// HTMLDirect renders a template using the provided engine directly.
+// This is useful when you don't want to use middleware.
+func HTMLDirect(c *rig.Context, engine *Engine, status int, name string, data any, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	if engine.config.DetectFragments {
+		c.Header().Add("Vary", "HX-Request, Turbo-Frame")
+	}
+	fragment := engine.config.DetectFragments && IsFragmentRequest(c)
+	// Buffered, so a template error can still be answered with an error page
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := engine.renderHTML(buf, name, data, fragment, c.CSPNonce(), &o); err != nil {
 		return err
 	}
 
 	c.SetHeader("Content-Type", ContentTypeHTML)
 	c.Status(status)
-	_, err = c.WriteString(content)
+	_, err := c.Write(buf.Bytes())
 	return err
 }
 
-// HTMLDirect renders a template using the provided engine directly.
-// This is useful when you don't want to use middleware.
-func HTMLDirect(c *rig.Context, engine *Engine, status int, name string, data any) error {
-	content, err := engine.Render(name, data)
-	if err != nil {
+// HTMLFrom renders a template with the engine registered under engine by
+// NamedMiddleware and writes it as an HTML response.
+func HTMLFrom(c *rig.Context, engine string, status int, name string, data any, opts ...Option) error {
+	e := GetNamedEngine(c, engine)
+	if e == nil {
+		return fmt.Errorf("render engine %q not found in context; did you forget to use engine.NamedMiddleware(%q)?", engine, engine)
+	}
+	return HTMLDirect(c, e, status, name, data, opts...)
+}
+
+// Fragment renders a fragment of a template, without the layout, and writes
+// it as an HTML response. The name is a template name, optionally followed
+// by "#" and a template defined in it, so a page can keep the markup it
+// re-renders for htmx or Turbo requests in place instead of in a partial.
+// See Engine.RenderFragment.
+//
+// Example:
+//
+//	// pages/users.html: <table>{{block "rows" .}}{{range .Users}}<tr>...</tr>{{end}}{{end}}</table>
+//	r.GET("/users", func(c *rig.Context) error {
+//	    if render.IsFragmentRequest(c) {
+//	        return render.Fragment(c, http.StatusOK, "pages/users#rows", data)
+//	    }
+//	    return render.HTML(c, http.StatusOK, "pages/users", data)
+//	})
+func Fragment(c *rig.Context, status int, name string, data any) error {
+	engine := GetEngine(c)
+	if engine == nil {
+		return fmt.Errorf("render engine not found in context; did you forget to use engine.Middleware()?")
+	}
+
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := engine.renderFragmentTo(buf, name, data, c.CSPNonce()); err != nil {
 		return err
 	}
 
 	c.SetHeader("Content-Type", ContentTypeHTML)
 	c.Status(status)
-	_, err = c.WriteString(content)
+	_, err := c.Write(buf.Bytes())
 	return err
 }
 
+// IsFragmentRequest reports whether the request asks for a fragment of a
+// page rather than a full page: an htmx request (HX-Request: true) that is
+// neither boosted nor restoring history, or a Turbo Frame request (with a
+// Turbo-Frame header).
+func IsFragmentRequest(c *rig.Context) bool {
+	if c.GetHeader("Turbo-Frame") != "" {
+		return true
+	}
+	return c.GetHeader("HX-Request") == "true" &&
+		c.GetHeader("HX-Boosted") != "true" &&
+		c.GetHeader("HX-History-Restore-Request") != "true"
+}
+
 // Partial renders a partial template (without layout) and writes it as an HTML response.
 // This is commonly used for HTMX requests, dynamic widgets, or AJAX updates where
 // you only need a fragment of HTML, not the full page shell.
@@ -559,31 +1047,43 @@ func Partial(c *rig.Context, status int, name string, data any) error {
 		return fmt.Errorf("render engine not found in context; did you forget to use engine.Middleware()?")
 	}
 
-	content, err := engine.RenderPartial(name, data)
-	if err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := engine.renderPartialTo(buf, name, data, c.CSPNonce()); err != nil {
 		return err
 	}
 
 	c.SetHeader("Content-Type", ContentTypeHTML)
 	c.Status(status)
-	_, err = c.WriteString(content)
+	_, err := c.Write(buf.Bytes())
 	return err
 }
 
 // PartialDirect renders a partial template using the provided engine directly.
 // This is useful when you don't want to use middleware.
 func PartialDirect(c *rig.Context, engine *Engine, status int, name string, data any) error {
-	content, err := engine.RenderPartial(name, data)
-	if err != nil {
+	buf := getBuffer()
+	defer putBuffer(buf)
+	if err := engine.renderPartialTo(buf, name, data, c.CSPNonce()); err != nil {
 		return err
 	}
 
 	c.SetHeader("Content-Type", ContentTypeHTML)
 	c.Status(status)
-	_, err = c.WriteString(content)
+	_, err := c.Write(buf.Bytes())
 	return err
 }
 
+// PartialFrom renders a partial template with the engine registered under
+// engine by NamedMiddleware and writes it as an HTML response.
+func PartialFrom(c *rig.Context, engine string, status int, name string, data any) error {
+	e := GetNamedEngine(c, engine)
+	if e == nil {
+		return fmt.Errorf("render engine %q not found in context; did you forget to use engine.NamedMiddleware(%q)?", engine, engine)
+	}
+	return PartialDirect(c, e, status, name, data)
+}
+
 // HTMLSafe renders a template with automatic error page fallback.
 // If the primary template fails to render, it attempts to render an error
 // template (e.g., "errors/500" or "500") with the error details.
@@ -613,14 +1113,252 @@ func HTMLSafe(c *rig.Context, status int, name string, data any, errorTemplate s
 	return nil
 }
 
+// Option configures a single JSON, HTML, or Auto call.
+type Option func(*options)
+
+// options holds the settings applied by Option values.
+type options struct {
+	etag        bool
+	indent      bool
+	prettyQuery string
+	cacheKey    string
+	noCache     bool
+}
+
+// WithETag makes JSON responses carry a weak ETag computed over the
+// serialized payload. When the request is a GET or HEAD whose If-None-Match
+// header matches, a 304 Not Modified is sent without a body, saving bandwidth
+// for clients that poll unchanged data. It only applies to 200 responses.
+//
+// Example:
+//
+//	r.GET("/dashboard/stats", func(c *rig.Context) error {
+//	    return render.JSON(c, http.StatusOK, stats.Current(), render.WithETag())
+//	})
+func WithETag() Option {
+	return func(o *options) {
+		o.etag = true
+	}
+}
+
+// WithCacheKey caches the HTML rendering under key, when Config.CacheTTL is
+// set. Renderings are shared by every call with the same template and key,
+// so the key must identify all the data the template shows (e.g., a record
+// ID and version, plus the user for per-user pages). Invalidate it with
+// InvalidateCacheKey.
+//
+// Example:
+//
+//	return render.HTML(c, http.StatusOK, "reports/monthly", report,
+//	    render.WithCacheKey("report:"+month))
+func WithCacheKey(key string) Option {
+	return func(o *options) {
+		o.cacheKey = key
+	}
+}
+
+// WithoutCache renders HTML without the cache of Config.CacheTTL, neither
+// reading nor storing a rendering, even with WithCacheKey, such as for
+// pages showing per-user data.
+//
+// Example:
+//
+//	opts := []render.Option{render.WithCacheKey("home")}
+//	if user != nil {
+//	    opts = append(opts, render.WithoutCache())
+//	}
+//	return render.HTML(c, http.StatusOK, "home", data, opts...)
+func WithoutCache() Option {
+	return func(o *options) {
+		o.noCache = true
+	}
+}
+
+// WithIndent makes JSON responses indented with two spaces, like JSONIndent.
+// Use it with Auto; prefer compact output for production APIs.
+func WithIndent() Option {
+	return func(o *options) {
+		o.indent = true
+	}
+}
+
+// WithPrettyQuery makes JSON responses indented when the request's query
+// string asks for it with the "pretty" parameter (e.g., ?pretty, ?pretty=1,
+// or ?pretty=true), so people can read responses in a browser or with curl
+// while clients keep getting compact JSON.
+//
+// Example:
+//
+//	api.GET("/orders", func(c *rig.Context) error {
+//	    return render.JSON(c, http.StatusOK, orders, render.WithPrettyQuery())
+//	})
+func WithPrettyQuery() Option {
+	return func(o *options) {
+		o.prettyQuery = "pretty"
+	}
+}
+
+// indented reports whether JSON responses to c are indented.
+func (o *options) indented(c *rig.Context) bool {
+	if o.indent {
+		return true
+	}
+	if o.prettyQuery == "" {
+		return false
+	}
+	values := c.QueryArray(o.prettyQuery)
+	if len(values) == 0 {
+		return false
+	}
+	if values[0] == "" {
+		return true
+	}
+	pretty, _ := strconv.ParseBool(values[0])
+	return pretty
+}
+
 // JSON renders data as a JSON response.
-func JSON(c *rig.Context, status int, data any) error {
+// Pass WithETag to enable conditional responses, and WithIndent or
+// WithPrettyQuery for indented output.
+func JSON(c *rig.Context, status int, data any, opts ...Option) error {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	indent := o.indented(c)
+
+	if !o.etag || status != http.StatusOK {
+		c.SetHeader("Content-Type", ContentTypeJSON)
+		c.Status(status)
+		return newJSONEncoder(c.Writer(), indent).Encode(data)
+	}
+
+	var buf bytes.Buffer
+	if err := newJSONEncoder(&buf, indent).Encode(data); err != nil {
+		return err
+	}
+
+	sum := sha256.Sum256(buf.Bytes())
+	etag := `W/"` + hex.EncodeToString(sum[:16]) + `"`
+	c.SetHeader("ETag", etag)
+
+	method := c.Method()
+	if (method == http.MethodGet || method == http.MethodHead) && c.IfNoneMatch(etag) {
+		c.Status(http.StatusNotModified)
+		return nil
+	}
+
 	c.SetHeader("Content-Type", ContentTypeJSON)
 	c.Status(status)
+	_, err := c.Write(buf.Bytes())
+	return err
+}
+
+// JSONIndent renders data as a JSON response indented with two spaces, for
+// endpoints read by people, such as debug and status pages. It accepts the
+// same options as JSON.
+//
+// Example:
+//
+//	r.GET("/debug/config", func(c *rig.Context) error {
+//	    return render.JSONIndent(c, http.StatusOK, cfg)
+//	})
+func JSONIndent(c *rig.Context, status int, data any, opts ...Option) error {
+	return JSON(c, status, data, append(opts, WithIndent())...)
+}
 
-	encoder := json.NewEncoder(c.Writer())
+// newJSONEncoder returns an encoder writing to w, indented if indent is set.
+func newJSONEncoder(w io.Writer, indent bool) *json.Encoder {
+	encoder := json.NewEncoder(w)
 	encoder.SetEscapeHTML(true)
-	return encoder.Encode(data)
+	if indent {
+		encoder.SetIndent("", "  ")
+	}
+	return encoder
+}
+
+// jsonStreamFlushEvery is the number of items JSONStream writes between
+// flushes.
+const jsonStreamFlushEvery = 100
+
+// JSONStream renders the items produced by seq as a JSON array, writing
+// each item as it is produced and flushing every 100 items, so endpoints
+// returning tens of thousands of rows never hold them all in memory. It
+// stops early when the client goes away and returns the context's error.
+//
+// Since the status code is sent before the first item, an error while
+// producing or encoding items cannot change it; the array is left
+// unterminated, so clients see a malformed response rather than a truncated
+// but valid one.
+//
+// Example:
+//
+//	r.GET("/export/orders", func(c *rig.Context) error {
+//	    return render.JSONStream(c, http.StatusOK, func(yield func(any) bool) {
+//	        rows, _ := db.QueryContext(c.Context(), "SELECT id, total FROM orders")
+//	        defer rows.Close()
+//	        for rows.Next() {
+//	            var o Order
+//	            if rows.Scan(&o.ID, &o.Total) != nil || !yield(o) {
+//	                return
+//	            }
+//	        }
+//	    })
+//	})
+func JSONStream(c *rig.Context, status int, seq iter.Seq[any]) error {
+	c.SetHeader("Content-Type", ContentTypeJSON)
+	c.Status(status)
+
+	rc := http.NewResponseController(c.Writer())
+	w := bufio.NewWriter(c.Writer())
+	flush := func() error {
+		if err := w.Flush(); err != nil {
+			return err
+		}
+		// Writers that don't support flushing (http.ErrNotSupported) are
+		// written to normally
+		_ = rc.Flush()
+		return nil
+	}
+
+	if err := w.WriteByte('['); err != nil {
+		return err
+	}
+
+	var err error
+	n := 0
+	for item := range seq {
+		if err = c.Context().Err(); err != nil {
+			break
+		}
+		var b []byte
+		if b, err = json.Marshal(item); err != nil {
+			break
+		}
+		if n > 0 {
+			if err = w.WriteByte(','); err != nil {
+				break
+			}
+		}
+		if _, err = w.Write(b); err != nil {
+			break
+		}
+		n++
+		if n%jsonStreamFlushEvery == 0 {
+			if err = flush(); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = flush()
+		return err
+	}
+
+	if _, err := w.WriteString("]\n"); err != nil {
+		return err
+	}
+	return flush()
 }
 
 // XML renders data as an XML response.
@@ -637,64 +1375,125 @@ func XML(c *rig.Context, status int, data any) error {
 	return encoder.Encode(data)
 }
 
-// Auto performs content negotiation based on the Accept header.
+// YAML renders data as a YAML response.
+// Fields are named by their json tags; see rig.Context.YAML.
+func YAML(c *rig.Context, status int, data any) error {
+	return c.YAML(status, data)
+}
+
+// Auto performs content negotiation based on the Accept header, honoring
+// q-values and wildcards (see rig.Context.Negotiate).
 // It renders HTML (using the template) for browsers, or JSON for API clients.
 // If a template name is empty, only JSON/XML responses are supported.
-func Auto(c *rig.Context, status int, templateName string, data any) error {
-	accept := c.Request().Header.Get("Accept")
-
-	// Check for JSON preference
-	if strings.Contains(accept, "application/json") {
-		return JSON(c, status, data)
-	}
-
-	// Check for HTML preference (browsers send text/html first in Accept header)
-	// This must come before XML check because browsers also include application/xml
-	if templateName != "" && (strings.Contains(accept, "text/html") || strings.Contains(accept, "*/*")) {
-		return HTML(c, status, templateName, data)
+// Other media types registered with rig.RegisterEncoder or
+// Router.RegisterEncoder (CBOR, MessagePack, ...) are rendered with
+// rig.Context.RenderAs when the client prefers them, as is YAML for clients
+// sending application/yaml or a legacy YAML type such as application/x-yaml.
+// Options such as WithETag apply to the JSON responses, and WithCacheKey and
+// WithoutCache to the HTML ones.
+func Auto(c *rig.Context, status int, templateName string, data any, opts ...Option) error {
+	switch mediaType := negotiate(c, templateName); mediaType {
+	case ContentTypeHTML:
+		return HTML(c, status, templateName, data, opts...)
+	case ContentTypeXML:
+		return XML(c, status, data)
+	case ContentTypeJSON:
+		return JSON(c, status, data, opts...)
+	default:
+		return c.RenderAs(status, mediaType, data)
 	}
+}
 
-	// Check for XML preference
-	if strings.Contains(accept, "application/xml") || strings.Contains(accept, "text/xml") {
+// AutoDirect performs content negotiation using a specific engine.
+func AutoDirect(c *rig.Context, engine *Engine, status int, templateName string, data any, opts ...Option) error {
+	switch mediaType := negotiate(c, templateName); mediaType {
+	case ContentTypeHTML:
+		return HTMLDirect(c, engine, status, templateName, data, opts...)
+	case ContentTypeXML:
 		return XML(c, status, data)
+	case ContentTypeJSON:
+		return JSON(c, status, data, opts...)
+	default:
+		return c.RenderAs(status, mediaType, data)
 	}
+}
 
-	// Default to HTML if template is provided
+// yamlAliases are media types clients still send for YAML, predating the
+// registration of application/yaml (RFC 9512).
+var yamlAliases = []string{"application/x-yaml", "text/yaml", "text/x-yaml"}
+
+// negotiate picks the content type for Auto using rig.Context.Negotiate.
+// HTML is preferred on ties (e.g., "*/*" from browsers) when a template is
+// given, then JSON and XML, then the other media types rig.Context.Render
+// can produce. YAML is also offered under its legacy media types
+// (application/x-yaml, text/yaml), and JSON, XML, and YAML under the vendor
+// media types of the Accept header with their suffix (e.g.,
+// application/vnd.api+json, application/atom+xml) that have no registered
+// encoder. It returns ContentTypeHTML, ContentTypeXML, ContentTypeJSON, or
+// one of those other media types. If nothing is acceptable, it falls back
+// to HTML when a template is given and JSON otherwise.
+func negotiate(c *rig.Context, templateName string) string {
+	offers := []string{"application/json", "application/xml", "text/xml"}
 	if templateName != "" {
-		return HTML(c, status, templateName, data)
+		offers = append([]string{"text/html"}, offers...)
 	}
-
-	// No template, fall back to JSON
-	return JSON(c, status, data)
-}
-
-// AutoDirect performs content negotiation using a specific engine.
-func AutoDirect(c *rig.Context, engine *Engine, status int, templateName string, data any) error {
-	accept := c.Request().Header.Get("Accept")
-
-	// Check for JSON preference
-	if strings.Contains(accept, "application/json") {
-		return JSON(c, status, data)
+	registered := c.RenderTypes()
+	for _, mediaType := range registered {
+		if !slices.Contains(offers, mediaType) {
+			offers = append(offers, mediaType)
+		}
 	}
-
-	// Check for HTML preference (browsers send text/html first in Accept header)
-	// This must come before XML check because browsers also include application/xml
-	if templateName != "" && (strings.Contains(accept, "text/html") || strings.Contains(accept, "*/*")) {
-		return HTMLDirect(c, engine, status, templateName, data)
+	if slices.Contains(offers, ContentTypeYAML) {
+		offers = append(offers, yamlAliases...)
 	}
-
-	// Check for XML preference
-	if strings.Contains(accept, "application/xml") || strings.Contains(accept, "text/xml") {
-		return XML(c, status, data)
+	// Vendor media types with a structured syntax suffix (RFC 6839), such
+	// as application/vnd.api+json, are offered when the client names them
+	for part := range strings.SplitSeq(c.GetHeader("Accept"), ",") {
+		mediaType, _, _ := strings.Cut(part, ";")
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if suffixContentType(mediaType) != "" && !slices.Contains(offers, mediaType) {
+			offers = append(offers, mediaType)
+		}
 	}
 
-	// Default to HTML if template is provided
+	switch mediaType := c.Negotiate(offers...); mediaType {
+	case "text/html":
+		return ContentTypeHTML
+	case "application/xml", "text/xml":
+		return ContentTypeXML
+	case "application/json":
+		return ContentTypeJSON
+	case "application/x-yaml", "text/yaml", "text/x-yaml":
+		return ContentTypeYAML
+	case "":
+	default:
+		if contentType := suffixContentType(mediaType); contentType != "" && !slices.Contains(registered, mediaType) {
+			return contentType
+		}
+		return mediaType
+	}
 	if templateName != "" {
-		return HTMLDirect(c, engine, status, templateName, data)
+		return ContentTypeHTML
 	}
+	return ContentTypeJSON
+}
 
-	// No template, fall back to JSON
-	return JSON(c, status, data)
+// suffixContentType returns the content type Auto renders for a vendor media
+// type by its structured syntax suffix (e.g., ContentTypeJSON for
+// application/vnd.api+json), or "" if it has no known suffix.
+func suffixContentType(mediaType string) string {
+	if strings.HasSuffix(mediaType, "/*") {
+		return ""
+	}
+	switch {
+	case strings.HasSuffix(mediaType, "+json"):
+		return ContentTypeJSON
+	case strings.HasSuffix(mediaType, "+xml"):
+		return ContentTypeXML
+	case strings.HasSuffix(mediaType, "+yaml"):
+		return ContentTypeYAML
+	}
+	return ""
 }
 
 // GetEngine retrieves the render engine from the context.
@@ -708,6 +1507,22 @@ func GetEngine(c *rig.Context) *Engine {
 	return nil
 }
 
+// GetNamedEngine retrieves the engine registered under name with
+// NamedMiddleware. Returns nil if not found.
+func GetNamedEngine(c *rig.Context, name string) *Engine {
+	if val, ok := c.Get(namedKey(name)); ok {
+		if engine, ok := val.(*Engine); ok {
+			return engine
+		}
+	}
+	return nil
+}
+
+// namedKey returns the context key of the engine registered under name.
+func namedKey(name string) string {
+	return ContextKey + ":" + name
+}
+
 // AddFunc adds a custom template function.
 // This method is thread-safe and can be called concurrently, but should
 // typically be called before Load() or Middleware() for best results.
@@ -1,16 +1,26 @@
 package render
 
 import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"html/template"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"slices"
 	"strings"
 	"sync"
 	"testing"
 	"testing/fstest"
+	"time"
 
 	"github.com/cloudresty/rig"
 )
@@ -1001,6 +1011,40 @@ func TestXML(t *testing.T) {
 	}
 }
 
+func TestYAML(t *testing.T) {
+	type Response struct {
+		Message string   `json:"message"`
+		Tags    []string `json:"tags"`
+	}
+
+	r := rig.New()
+
+	r.GET("/api", func(c *rig.Context) error {
+		return YAML(c, http.StatusOK, Response{
+			Message: "hello",
+			Tags:    []string{"a", "b"},
+		})
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/api", nil)
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	contentType := w.Header().Get("Content-Type")
+	if contentType != ContentTypeYAML {
+		t.Errorf("Content-Type = %q, want %q", contentType, ContentTypeYAML)
+	}
+
+	want := "message: hello\ntags:\n  - a\n  - b\n"
+	if w.Body.String() != want {
+		t.Errorf("body = %q, want %q", w.Body.String(), want)
+	}
+}
+
 func TestAuto_JSON(t *testing.T) {
 	engine := New(Config{
 		Directory: "./testdata/templates",
@@ -1826,3 +1870,1225 @@ func TestEngine_Minify_Disabled(t *testing.T) {
 		t.Errorf("Result should contain newlines when minify is disabled, got: %s", result)
 	}
 }
+
+func TestEngine_FormatFunctions(t *testing.T) {
+	testFS := fstest.MapFS{
+		"invoice.html": {Data: []byte(
+			`{{formatDate .Locale .Issued}}|{{formatDateTime .Locale .Issued}}|` +
+				`{{formatNumber .Locale .Weight 1}}|{{formatCurrency .Locale .Total "EUR"}}`)},
+	}
+
+	engine := New(Config{
+		FileSystem: testFS,
+		Directory:  ".",
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	issued := time.Date(2025, time.March, 4, 17, 30, 0, 0, time.UTC)
+	tests := []struct {
+		locale string
+		want   string
+	}{
+		{"en", "03/04/2025|03/04/2025 5:30 PM|1,500.0|€99.90"},
+		{"de-DE", "04.03.2025|04.03.2025 17:30|1.500,0|99,90\u00a0€"},
+	}
+
+	for _, tt := range tests {
+		result, err := engine.Render("invoice", map[string]any{
+			"Locale": tt.locale,
+			"Issued": issued,
+			"Weight": 1500.0,
+			"Total":  99.9,
+		})
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if result != tt.want {
+			t.Errorf("locale %s: got %q, want %q", tt.locale, result, tt.want)
+		}
+	}
+}
+
+func TestEngine_CSPNonce(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<script nonce="{{.CSPNonce}}"></script>{{.Content}}`)},
+		"page.html":         {Data: []byte(`<script nonce="{{cspNonce}}">var x = {{cspNonce}};</script>`)},
+	}
+
+	engine := New(Config{
+		FileSystem: testFS,
+		Directory:  ".",
+		Layout:     "layouts/base",
+	})
+
+	r := rig.New()
+	r.Use(rig.SecureHeaders(rig.SecureHeadersConfig{
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	}))
+	r.Use(engine.Middleware())
+	r.GET("/", func(c *rig.Context) error {
+		return HTML(c, http.StatusOK, "page", nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	csp := w.Header().Get("Content-Security-Policy")
+	nonce := strings.TrimSuffix(strings.TrimPrefix(csp, "script-src 'nonce-"), "'")
+	if nonce == "" || nonce == csp {
+		t.Fatalf("Content-Security-Policy = %q", csp)
+	}
+
+	want := `<script nonce="` + nonce + `"></script><script nonce="` + nonce + `">var x = "` + nonce + `";</script>`
+	if w.Body.String() != want {
+		t.Errorf("body =\n%s\nwant\n%s", w.Body.String(), want)
+	}
+
+	// Without a nonce in the context, the function renders nothing
+	result, err := engine.Render("page", nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if result != `<script nonce=""></script><script nonce="">var x = "";</script>` {
+		t.Errorf("Render() without nonce = %q", result)
+	}
+}
+
+func TestEngine_Cache(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<script nonce="{{.CSPNonce}}"></script>{{.Content}}`)},
+		"page.html":         {Data: []byte(`<p>{{.name}} {{renders}}</p>`)},
+	}
+
+	renders := 0
+	engine := New(Config{
+		FileSystem: testFS,
+		Directory:  ".",
+		Layout:     "layouts/base",
+		CacheTTL:   time.Minute,
+		Funcs:      template.FuncMap{"renders": func() int { renders++; return renders }},
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	r := rig.New()
+	r.Use(rig.SecureHeaders(rig.SecureHeadersConfig{
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'",
+	}))
+	r.GET("/", func(c *rig.Context) error {
+		data := map[string]any{"name": c.Query("name")}
+		var opts []Option
+		if key := c.Query("key"); key != "" {
+			opts = append(opts, WithCacheKey(key))
+		}
+		if c.Query("fresh") != "" {
+			opts = append(opts, WithoutCache())
+		}
+		return HTMLDirect(c, engine, http.StatusOK, "page", data, opts...)
+	})
+
+	get := func(target, want string) {
+		t.Helper()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		nonce := strings.TrimSuffix(strings.TrimPrefix(w.Header().Get("Content-Security-Policy"), "script-src 'nonce-"), "'")
+		if body := `<script nonce="` + nonce + `"></script><p>` + want + `</p>`; w.Body.String() != body {
+			t.Errorf("%s: body = %q, want %q", target, w.Body.String(), body)
+		}
+	}
+
+	get("/?name=a", "a 1")
+	get("/?name=a", "a 2") // no key: not cached
+
+	get("/?name=a&key=k", "a 3")
+	get("/?name=a&key=k", "a 3") // cached, with this request's nonce
+	get("/?name=b&key=k", "a 3") // the key identifies the data
+	get("/?name=b&key=j", "b 4")
+	get("/?name=b&key=k&fresh=1", "b 5")
+
+	engine.InvalidateCacheKey("k")
+	get("/?name=b&key=k", "b 6")
+	get("/?name=b&key=j", "b 4")
+
+	engine.InvalidateCache("page")
+	get("/?name=a&key=j", "a 7")
+
+	engine.ClearCache()
+	get("/?name=b&key=j", "b 8")
+}
+
+func TestRenderCache_MaxEntries(t *testing.T) {
+	var rc renderCache
+	now := time.Now()
+	rc.put(cacheKey{name: "a"}, cacheEntry{content: "a", expires: now.Add(time.Second)}, now, 2)
+	rc.put(cacheKey{name: "b"}, cacheEntry{content: "b", expires: now.Add(time.Minute)}, now, 2)
+
+	// Full: dropped
+	rc.put(cacheKey{name: "c"}, cacheEntry{content: "c", expires: now.Add(time.Minute)}, now, 2)
+	if _, ok := rc.get(cacheKey{name: "c"}, now); ok {
+		t.Error("rendering stored in a full cache")
+	}
+
+	// The expired rendering is evicted to make room
+	later := now.Add(2 * time.Second)
+	rc.put(cacheKey{name: "c"}, cacheEntry{content: "c", expires: later.Add(time.Minute)}, later, 2)
+	if _, ok := rc.get(cacheKey{name: "c"}, later); !ok {
+		t.Error("rendering not stored after eviction")
+	}
+	if _, ok := rc.get(cacheKey{name: "a"}, now); ok {
+		t.Error("expired rendering not evicted")
+	}
+}
+
+func TestJSON_WithETag(t *testing.T) {
+	stats := map[string]int{"orders": 3}
+
+	r := rig.New()
+	r.GET("/stats", func(c *rig.Context) error {
+		return JSON(c, http.StatusOK, stats, WithETag())
+	})
+	r.POST("/stats", func(c *rig.Context) error {
+		return JSON(c, http.StatusOK, stats, WithETag())
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/stats", nil))
+	etag := w.Header().Get("ETag")
+	if w.Code != http.StatusOK || !strings.HasPrefix(etag, `W/"`) || w.Body.String() != "{\"orders\":3}\n" {
+		t.Fatalf("first request: %d ETag=%q body=%q", w.Code, etag, w.Body.String())
+	}
+
+	// Repeat poll with the cached ETag
+	req := httptest.NewRequest(http.MethodGet, "/stats", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified || w.Body.Len() != 0 || w.Header().Get("ETag") != etag {
+		t.Errorf("unchanged: %d ETag=%q body=%q", w.Code, w.Header().Get("ETag"), w.Body.String())
+	}
+
+	// Changed payload gets a new ETag and a full response
+	stats["orders"] = 4
+	req = httptest.NewRequest(http.MethodGet, "/stats", nil)
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") == etag {
+		t.Errorf("changed: %d ETag=%q", w.Code, w.Header().Get("ETag"))
+	}
+
+	// Unsafe methods are never answered with 304
+	req = httptest.NewRequest(http.MethodPost, "/stats", nil)
+	req.Header.Set("If-None-Match", "*")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("POST: status = %d, want 200", w.Code)
+	}
+}
+
+func TestJSON_Indent(t *testing.T) {
+	data := map[string]int{"orders": 3}
+	const compact, indented = "{\"orders\":3}\n", "{\n  \"orders\": 3\n}\n"
+
+	r := rig.New()
+	r.GET("/debug", func(c *rig.Context) error {
+		return JSONIndent(c, http.StatusOK, data)
+	})
+	r.GET("/stats", func(c *rig.Context) error {
+		return JSON(c, http.StatusOK, data, WithPrettyQuery())
+	})
+
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/debug", indented},
+		{"/stats", compact},
+		{"/stats?pretty", indented},
+		{"/stats?pretty=1", indented},
+		{"/stats?pretty=true", indented},
+		{"/stats?pretty=0", compact},
+		{"/stats?pretty=yes", compact},
+	}
+
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.target, w.Body.String(), tt.body)
+		}
+		if ct := w.Header().Get("Content-Type"); ct != ContentTypeJSON {
+			t.Errorf("%s: Content-Type = %q", tt.target, ct)
+		}
+	}
+}
+
+func TestJSONStream(t *testing.T) {
+	r := rig.New()
+	r.GET("/items", func(c *rig.Context) error {
+		return JSONStream(c, http.StatusOK, func(yield func(any) bool) {
+			for i := range 250 {
+				if !yield(map[string]int{"id": i}) {
+					return
+				}
+			}
+		})
+	})
+	r.GET("/empty", func(c *rig.Context) error {
+		return JSONStream(c, http.StatusOK, func(yield func(any) bool) {})
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	var items []map[string]int
+	if err := json.Unmarshal(w.Body.Bytes(), &items); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(items) != 250 || items[249]["id"] != 249 {
+		t.Errorf("got %d items", len(items))
+	}
+	if !w.Flushed {
+		t.Error("expected the response to be flushed")
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeJSON {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/empty", nil))
+	if w.Body.String() != "[]\n" {
+		t.Errorf("empty: body = %q, want %q", w.Body.String(), "[]\n")
+	}
+}
+
+func TestJSONStream_EncodeError(t *testing.T) {
+	r := rig.New()
+	var streamErr error
+	r.GET("/items", func(c *rig.Context) error {
+		streamErr = JSONStream(c, http.StatusOK, func(yield func(any) bool) {
+			if yield(1) {
+				yield(func() {})
+			}
+		})
+		return nil
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/items", nil))
+	if streamErr == nil {
+		t.Error("expected an encoding error")
+	}
+	if w.Body.String() != "[1" {
+		t.Errorf("body = %q, want the unterminated array", w.Body.String())
+	}
+}
+
+func TestAuto_WithETag(t *testing.T) {
+	engine := New(Config{
+		Directory: "./testdata/templates",
+	})
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.GET("/data", func(c *rig.Context) error {
+		return Auto(c, http.StatusOK, "simple", map[string]any{"Title": "Test"}, WithETag())
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("JSON branch should set an ETag")
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("If-None-Match", etag)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusNotModified {
+		t.Errorf("status = %d, want 304", w.Code)
+	}
+
+	// The HTML branch is unaffected
+	req = httptest.NewRequest(http.MethodGet, "/data", nil)
+	req.Header.Set("Accept", "text/html")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK || w.Header().Get("ETag") != "" {
+		t.Errorf("HTML: %d ETag=%q", w.Code, w.Header().Get("ETag"))
+	}
+}
+
+func TestAuto_QValues(t *testing.T) {
+	engine := New(Config{
+		Directory: "./testdata/templates",
+	})
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.GET("/data", func(c *rig.Context) error {
+		return Auto(c, http.StatusOK, "simple", map[string]any{"Title": "Test", "Message": "Hello"})
+	})
+
+	tests := []struct {
+		accept string
+		want   string
+	}{
+		// Substring matching used to pick JSON despite q=0.1
+		{"text/html, application/json;q=0.1", ContentTypeHTML},
+		{"application/json;q=0.5, application/xml", ContentTypeXML},
+		{"text/html;q=0, */*", ContentTypeJSON},
+		{"image/png", ContentTypeHTML},
+		// Vendor media types are rendered by their suffix
+		{"application/vnd.api+json", ContentTypeJSON},
+		{"application/vnd.github.v3+json;q=0.9, text/html;q=0.5", ContentTypeJSON},
+		{"application/atom+xml", ContentTypeXML},
+		{"application/vnd.api+json;q=0.2, text/html", ContentTypeHTML},
+		{"application/vnd.api+json;q=0", ContentTypeHTML},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/data", nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != tt.want {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, ct, tt.want)
+		}
+	}
+}
+
+func TestAuto_RegisteredEncoder(t *testing.T) {
+	r := rig.New()
+	r.RegisterEncoder("application/x-csv", func(w io.Writer, v any) error {
+		_, err := fmt.Fprintf(w, "title\n%s\n", v.(map[string]any)["Title"])
+		return err
+	})
+	r.GET("/data", func(c *rig.Context) error {
+		return Auto(c, http.StatusOK, "", map[string]any{"Title": "Test"})
+	})
+
+	tests := []struct {
+		accept      string
+		contentType string
+		body        string
+	}{
+		{"application/x-csv", "application/x-csv", "title\nTest\n"},
+		{"application/json;q=0.5, application/x-csv", "application/x-csv", "title\nTest\n"},
+		{"application/yaml", rig.ContentTypeYAML, "Title: Test\n"},
+		{"application/x-yaml", rig.ContentTypeYAML, "Title: Test\n"},
+		{"text/yaml, application/json;q=0.9", rig.ContentTypeYAML, "Title: Test\n"},
+		{"*/*", ContentTypeJSON, ""},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/data", nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if ct := w.Header().Get("Content-Type"); ct != tt.contentType {
+			t.Errorf("Accept %q: Content-Type = %q, want %q", tt.accept, ct, tt.contentType)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("Accept %q: body = %q, want %q", tt.accept, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestEngine_AssetFuncs(t *testing.T) {
+	css := []byte("body{margin:0}")
+	js := []byte("console.log(1)")
+	assets := fstest.MapFS{
+		"css/app.css":       {Data: css},
+		"js/app.js":         {Data: js},
+		"fonts/inter.woff2": {Data: []byte("font")},
+	}
+	templates := fstest.MapFS{
+		"page.html": {Data: []byte(`{{preloadTag "fonts/inter.woff2"}}
+{{stylesheetTag "/css/app.css"}}
+{{scriptTag "js/app.js"}}
+<img src="{{asset "css/app.css"}}" data-sri="{{integrity "js/app.js"}}">`)},
+		"missing.html": {Data: []byte(`{{asset "nope.js"}}`)},
+	}
+
+	engine := New(Config{
+		FileSystem:   templates,
+		Directory:    ".",
+		Assets:       assets,
+		AssetsPrefix: "/static",
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	digest := func(b []byte) (string, string) {
+		sum := sha512.Sum384(b)
+		return hex.EncodeToString(sum[:6]), "sha384-" + base64.StdEncoding.EncodeToString(sum[:])
+	}
+	cssVersion, cssSRI := digest(css)
+	jsVersion, jsSRI := digest(js)
+	fontVersion, fontSRI := digest([]byte("font"))
+
+	got, err := engine.Render("page", nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := `<link rel="preload" href="/static/fonts/inter.woff2?v=` + fontVersion + `" as="font" integrity="` + fontSRI + `" crossorigin="anonymous">
+<link rel="stylesheet" href="/static/css/app.css?v=` + cssVersion + `" integrity="` + cssSRI + `" crossorigin="anonymous">
+<script src="/static/js/app.js?v=` + jsVersion + `" integrity="` + jsSRI + `" crossorigin="anonymous"></script>
+<img src="/static/css/app.css?v=` + cssVersion + `" data-sri="` + strings.ReplaceAll(jsSRI, "+", "&#43;") + `">`
+	if got != want {
+		t.Errorf("Render() =\n%s\nwant\n%s", got, want)
+	}
+
+	if _, err := engine.Render("missing", nil); err == nil || !strings.Contains(err.Error(), `asset "nope.js"`) {
+		t.Errorf("Render() error = %v, want missing asset error", err)
+	}
+
+	// The manifest is rebuilt on Load, so DevMode sees changed files
+	assets["js/app.js"] = &fstest.MapFile{Data: []byte("console.log(2)")}
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got, _ = engine.Render("page", nil)
+	if strings.Contains(got, jsSRI) {
+		t.Error("integrity was not recomputed after Load")
+	}
+}
+
+func TestEngine_BlockLayout(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<title>{{block "title" .}}My Site{{end}}</title>` +
+			`<head>{{block "head" .}}{{end}}</head>` +
+			`<body>{{block "body" .}}{{end}}{{template "_footer" .}}</body>`)},
+		"_footer.html":  {Data: []byte(`<footer>{{.Year}}</footer>`)},
+		"product.html":  {Data: []byte(`{{define "title"}}{{.Name}} | My Site{{end}}{{define "body"}}<h1>{{.Name}}</h1>{{end}}`)},
+		"about.html":    {Data: []byte(`{{define "body"}}<p>About</p>{{end}}`)},
+		"scripted.html": {Data: []byte(`{{define "head"}}<script nonce="{{cspNonce}}"></script>{{end}}`)},
+	}
+
+	engine := New(Config{
+		FileSystem:  testFS,
+		Directory:   ".",
+		Layout:      "layouts/base",
+		BlockLayout: true,
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"product", `<title>Lamp | My Site</title><head></head><body><h1>Lamp</h1><footer>2026</footer></body>`},
+		{"about", `<title>My Site</title><head></head><body><p>About</p><footer>2026</footer></body>`},
+	}
+	for _, tt := range tests {
+		got, err := engine.Render(tt.name, map[string]any{"Name": "Lamp", "Year": 2026})
+		if err != nil {
+			t.Fatalf("Render(%q) error = %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("Render(%q) = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+
+	got, err := engine.render("scripted", map[string]any{"Year": 2026}, "abc")
+	if err != nil {
+		t.Fatalf("render() error = %v", err)
+	}
+	if !strings.Contains(got, `<script nonce="abc">`) {
+		t.Errorf("nonce not substituted: %s", got)
+	}
+}
+
+func TestEngine_Sections(t *testing.T) {
+	scripts := `{{content "scripts"}}<script nonce="{{cspNonce}}" src="/{{.Name}}.js"></script>{{end}}`
+	tests := []struct {
+		name        string
+		blockLayout bool
+		layout      string
+		dashboard   string
+		about       string
+	}{
+		{
+			name:      "content",
+			layout:    `<head>{{yield "head"}}</head><main>{{.Content}}</main>{{- yield "scripts" -}}`,
+			dashboard: scripts + `<h1>{{.Name}}</h1>`,
+			about:     `<p>About</p>`,
+		},
+		{
+			name:        "block",
+			blockLayout: true,
+			layout:      `<head>{{yield "head"}}</head><main>{{block "body" .}}{{end}}</main>{{- yield "scripts" -}}`,
+			dashboard:   scripts + `{{define "body"}}<h1>{{.Name}}</h1>{{end}}`,
+			about:       `{{define "body"}}<p>About</p>{{end}}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := New(Config{
+				FileSystem: fstest.MapFS{
+					"layouts/base.html": {Data: []byte(tt.layout)},
+					"dashboard.html":    {Data: []byte(tt.dashboard)},
+					"about.html":        {Data: []byte(tt.about)},
+				},
+				Directory:   ".",
+				Layout:      "layouts/base",
+				BlockLayout: tt.blockLayout,
+			})
+			if err := engine.Load(); err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+
+			got, err := engine.render("dashboard", map[string]any{"Name": "Stats"}, "abc")
+			if err != nil {
+				t.Fatalf("render() error = %v", err)
+			}
+			if want := `<head></head><main><h1>Stats</h1></main><script nonce="abc" src="/Stats.js"></script>`; got != want {
+				t.Errorf("render(dashboard) = %s, want %s", got, want)
+			}
+
+			got, err = engine.Render("about", nil)
+			if err != nil {
+				t.Fatalf("Render() error = %v", err)
+			}
+			if want := `<head></head><main><p>About</p></main>`; got != want {
+				t.Errorf("Render(about) = %s, want %s", got, want)
+			}
+		})
+	}
+
+	t.Run("delims", func(t *testing.T) {
+		engine := New(Config{
+			FileSystem: fstest.MapFS{
+				"layouts/base.html": {Data: []byte(`[[ yield "head" ]]<main>[[.Content]]</main>`)},
+				"page.html":         {Data: []byte(`[[ content "head" ]]<style></style>[[ end ]]{{ vue }}`)},
+			},
+			Directory: ".",
+			Layout:    "layouts/base",
+			Delims:    []string{"[[", "]]"},
+		})
+		if err := engine.Load(); err != nil {
+			t.Fatalf("Load() error = %v", err)
+		}
+		got, err := engine.Render("page", nil)
+		if err != nil {
+			t.Fatalf("Render() error = %v", err)
+		}
+		if want := `<style></style><main>{{ vue }}</main>`; got != want {
+			t.Errorf("Render(page) = %s, want %s", got, want)
+		}
+	})
+}
+
+func TestNamedMiddleware(t *testing.T) {
+	admin := New(Config{FileSystem: fstest.MapFS{
+		"dashboard.html": {Data: []byte(`<h1>Admin {{.}}</h1>`)},
+		"_row.html":      {Data: []byte(`<tr>{{.}}</tr>`)},
+	}, Directory: "."})
+	public := New(Config{FileSystem: fstest.MapFS{
+		"dashboard.html": {Data: []byte(`<h1>Public {{.}}</h1>`)},
+	}, Directory: "."})
+
+	r := rig.New()
+	r.Use(admin.NamedMiddleware("admin"))
+	r.Use(public.NamedMiddleware("public"))
+	r.GET("/admin", func(c *rig.Context) error {
+		return HTMLFrom(c, "admin", http.StatusOK, "dashboard", "panel")
+	})
+	r.GET("/admin/row", func(c *rig.Context) error {
+		return PartialFrom(c, "admin", http.StatusOK, "_row", "1")
+	})
+	r.GET("/public", func(c *rig.Context) error {
+		return HTMLFrom(c, "public", http.StatusOK, "dashboard", "home")
+	})
+	r.GET("/missing", func(c *rig.Context) error {
+		return HTMLFrom(c, "shop", http.StatusOK, "dashboard", nil)
+	})
+
+	tests := []struct {
+		path   string
+		status int
+		body   string
+	}{
+		{"/admin", http.StatusOK, "<h1>Admin panel</h1>"},
+		{"/admin/row", http.StatusOK, "<tr>1</tr>"},
+		{"/public", http.StatusOK, "<h1>Public home</h1>"},
+		{"/missing", http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+		if w.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", tt.path, w.Code, tt.status)
+		}
+		if tt.body != "" && w.Body.String() != tt.body {
+			t.Errorf("%s: body = %q, want %q", tt.path, w.Body.String(), tt.body)
+		}
+	}
+}
+
+func TestDevMode_WatchesTemplates(t *testing.T) {
+	dir := t.TempDir()
+	page := filepath.Join(dir, "page.html")
+	if err := os.WriteFile(page, []byte(`v1`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	engine := New(Config{Directory: dir, DevMode: true})
+	t.Cleanup(func() { _ = engine.Close() })
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.GET("/", func(c *rig.Context) error {
+		return HTML(c, http.StatusOK, "page", nil)
+	})
+	get := func() string {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		return w.Body.String()
+	}
+
+	if got := get(); got != "v1" {
+		t.Fatalf("body = %q, want v1", got)
+	}
+	loaded := engine.templates["page"]
+	get()
+	if engine.templates["page"] != loaded {
+		t.Error("templates were reloaded without changes")
+	}
+
+	if err := os.WriteFile(page, []byte(`v2`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for get() != "v2" {
+		if time.Now().After(deadline) {
+			t.Fatal("template change was not picked up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestFragment(t *testing.T) {
+	engine := New(Config{
+		FileSystem: fstest.MapFS{
+			"layout.html":      {Data: []byte(`<main>{{.Content}}</main>`)},
+			"pages/users.html": {Data: []byte(`<table>{{block "rows" .}}{{range .}}<tr>{{.}}</tr>{{end}}{{end}}</table>`)},
+		},
+		Directory:       ".",
+		Layout:          "layout",
+		DetectFragments: true,
+	})
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.GET("/users", func(c *rig.Context) error {
+		return HTML(c, http.StatusOK, "pages/users", []string{"ann"})
+	})
+	r.GET("/users/rows", func(c *rig.Context) error {
+		return Fragment(c, http.StatusOK, "pages/users#rows", []string{"ann"})
+	})
+	r.GET("/users/missing", func(c *rig.Context) error {
+		return Fragment(c, http.StatusOK, "pages/users#cells", nil)
+	})
+
+	tests := []struct {
+		path   string
+		header map[string]string
+		want   string
+	}{
+		{"/users", nil, "<main><table><tr>ann</tr></table></main>"},
+		{"/users", map[string]string{"HX-Request": "true"}, "<table><tr>ann</tr></table>"},
+		{"/users", map[string]string{"HX-Request": "true", "HX-Boosted": "true"}, "<main><table><tr>ann</tr></table></main>"},
+		{"/users", map[string]string{"Turbo-Frame": "users"}, "<table><tr>ann</tr></table>"},
+		{"/users/rows", nil, "<tr>ann</tr>"},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+		for k, v := range tt.header {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != tt.want {
+			t.Errorf("%s %v: body = %q, want %q", tt.path, tt.header, w.Body.String(), tt.want)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+	if got := w.Header().Get("Vary"); got != "HX-Request, Turbo-Frame" {
+		t.Errorf("Vary = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users/missing", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("missing fragment: status = %d", w.Code)
+	}
+}
+
+func TestAssetManifest_Generated(t *testing.T) {
+	assets := fstest.MapFS{"css/app.css": {Data: []byte("body{}")}}
+	manifest, err := GenerateAssetManifest(assets)
+	if err != nil {
+		t.Fatalf("GenerateAssetManifest() error = %v", err)
+	}
+	sum := sha512.Sum384([]byte("body{}"))
+	fingerprinted := "css/app." + hex.EncodeToString(sum[:6]) + ".css"
+	if manifest["css/app.css"] != fingerprinted {
+		t.Fatalf("manifest = %v, want css/app.css -> %s", manifest, fingerprinted)
+	}
+
+	engine := New(Config{
+		FileSystem:    fstest.MapFS{"page.html": {Data: []byte(`{{asset "css/app.css"}}`)}},
+		Directory:     ".",
+		Assets:        assets,
+		AssetManifest: manifest,
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if got, _ := engine.Render("page", nil); got != "/assets/"+fingerprinted {
+		t.Errorf("asset URL = %q, want /assets/%s", got, fingerprinted)
+	}
+
+	r := rig.New()
+	r.GET("/assets/{path...}", engine.AssetHandler())
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/"+fingerprinted, nil))
+	if w.Code != http.StatusOK || w.Body.String() != "body{}" {
+		t.Errorf("fingerprinted file: status = %d, body = %q", w.Code, w.Body.String())
+	}
+	if got := w.Header().Get("Cache-Control"); got != "public, max-age=31536000, immutable" {
+		t.Errorf("Cache-Control = %q", got)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/assets/css/app.css", nil))
+	if w.Code != http.StatusOK || w.Header().Get("Cache-Control") != "" {
+		t.Errorf("original name: status = %d, Cache-Control = %q", w.Code, w.Header().Get("Cache-Control"))
+	}
+}
+
+func TestLoadAssetManifest(t *testing.T) {
+	fsys := fstest.MapFS{
+		"manifest.json":       {Data: []byte(`{"css/app.css": "css/app.3f9a1c.css"}`)},
+		".vite/manifest.json": {Data: []byte(`{"src/main.js": {"file": "assets/main.4889e940.js", "isEntry": true}}`)},
+		"invalid.json":        {Data: []byte(`{"app.js": 42}`)},
+	}
+
+	manifest, err := LoadAssetManifest(fsys, "manifest.json")
+	if err != nil || manifest["css/app.css"] != "css/app.3f9a1c.css" {
+		t.Errorf("flat manifest = %v, %v", manifest, err)
+	}
+	manifest, err = LoadAssetManifest(fsys, ".vite/manifest.json")
+	if err != nil || manifest["src/main.js"] != "assets/main.4889e940.js" {
+		t.Errorf("Vite manifest = %v, %v", manifest, err)
+	}
+	if _, err := LoadAssetManifest(fsys, "invalid.json"); err == nil {
+		t.Error("invalid manifest should fail")
+	}
+
+	// Assets served elsewhere (e.g., a CDN): no integrity attribute
+	engine := New(Config{
+		FileSystem:    fstest.MapFS{"page.html": {Data: []byte(`{{scriptTag "src/main.js"}}`)}},
+		Directory:     ".",
+		AssetsPrefix:  "https://cdn.example.com",
+		AssetManifest: manifest,
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	got, err := engine.Render("page", nil)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	if want := `<script src="https://cdn.example.com/assets/main.4889e940.js"></script>`; got != want {
+		t.Errorf("Render() = %s, want %s", got, want)
+	}
+}
+
+func TestEngine_Validate(t *testing.T) {
+	valid := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<main>{{.Content}}</main>{{template "_footer"}}`)},
+		"_footer.html":      {Data: []byte(`<footer>{{template "_links"}}</footer>`)},
+		"_links.html":       {Data: []byte(`<a href="/">Home</a>`)},
+		"home.html":         {Data: []byte(`{{define "unused"}}{{template "missing"}}{{end}}<h1>{{.Title}}</h1>`)},
+	}
+	engine := New(Config{FileSystem: valid, Directory: ".", Layout: "layouts/base"})
+	if err := engine.ValidateWithConfig(ValidateConfig{Execute: true}); err != nil {
+		t.Errorf("ValidateWithConfig() error = %v", err)
+	}
+
+	invalid := fstest.MapFS{
+		"_footer.html": {Data: []byte(`<footer>{{template "_links"}}</footer>`)},
+		"home.html":    {Data: []byte(`{{template "_footer"}}{{template "_header" .}}`)},
+		"about.html":   {Data: []byte(`{{template "_footer"}}`)},
+	}
+	engine = New(Config{FileSystem: invalid, Directory: "."})
+	err := engine.Validate()
+	if err == nil {
+		t.Fatal("Validate() should fail")
+	}
+	want := "template _footer: undefined template \"_links\"\ntemplate home: undefined template \"_header\""
+	if err.Error() != want {
+		t.Errorf("Validate() error =\n%v\nwant\n%s", err, want)
+	}
+}
+
+func TestEngine_Validate_BlockLayout(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layout.html": {Data: []byte(`<title>{{block "title" .}}Site{{end}}</title>{{template "body" .}}`)},
+		"home.html":   {Data: []byte(`{{define "body"}}home{{end}}`)},
+		"about.html":  {Data: []byte(`{{define "title"}}About{{end}}`)},
+	}
+	engine := New(Config{FileSystem: testFS, Directory: ".", Layout: "layout", BlockLayout: true})
+
+	err := engine.Validate()
+	if err == nil || !strings.Contains(err.Error(), `template layout: undefined template "body"`) {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestEngine_Validate_Execute(t *testing.T) {
+	testFS := fstest.MapFS{
+		"home.html": {Data: []byte(`{{safe 42}}`)},
+	}
+	engine := New(Config{FileSystem: testFS, Directory: "."})
+
+	if err := engine.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+	if err := engine.ValidateWithConfig(ValidateConfig{Execute: true}); err == nil {
+		t.Error("ValidateWithConfig() should fail executing home")
+	}
+}
+
+func TestErrorPages(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<main>{{.Content}}</main>`)},
+		"errors/404.html":   {Data: []byte(`<h1>{{.StatusCode}} {{.Status}}</h1><p>{{.Error}}</p>`)},
+		"errors/error.html": {Data: []byte(`<h1>{{.StatusCode}}</h1><p>{{.Error}}</p>`)},
+	}
+	engine := New(Config{FileSystem: testFS, Directory: ".", Layout: "layouts/base"})
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.HandleRouteErrors()
+	r.SetErrorHandler(ErrorPages(map[int]string{
+		http.StatusNotFound: "errors/404",
+		0:                   "errors/error",
+	}))
+	r.GET("/users/{id}", func(c *rig.Context) error {
+		return rig.NewHTTPError(http.StatusNotFound, "user not found")
+	})
+	r.GET("/fail", func(c *rig.Context) error {
+		return fmt.Errorf("database password is hunter2")
+	})
+	r.GET("/joined", func(c *rig.Context) error {
+		return errors.Join(rig.NewHTTPError(http.StatusBadRequest, "bad id"), errors.New("db down"))
+	})
+
+	tests := []struct {
+		method, path, accept string
+		status               int
+		body                 string
+	}{
+		{http.MethodGet, "/users/1", "text/html", http.StatusNotFound, "<main><h1>404 Not Found</h1><p>user not found</p></main>"},
+		{http.MethodGet, "/missing", "text/html", http.StatusNotFound, "<main><h1>404 Not Found</h1><p>404 page not found</p></main>"},
+		{http.MethodPost, "/fail", "*/*", http.StatusMethodNotAllowed, "<main><h1>405</h1><p>Method Not Allowed</p></main>"},
+		{http.MethodGet, "/fail", "text/html", http.StatusInternalServerError, "<main><h1>500</h1><p>Internal Server Error</p></main>"},
+		{http.MethodGet, "/joined", "text/html", http.StatusInternalServerError, "<main><h1>500</h1><p>Internal Server Error</p></main>"},
+		{http.MethodGet, "/users/1", "application/json", http.StatusNotFound, `{"error":"user not found"}` + "\n"},
+		{http.MethodGet, "/fail", "application/json", http.StatusInternalServerError, `{"error":"Internal Server Error"}` + "\n"},
+	}
+
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("Accept", tt.accept)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		if w.Code != tt.status || w.Body.String() != tt.body {
+			t.Errorf("%s %s (%s): %d %q, want %d %q", tt.method, tt.path, tt.accept, w.Code, w.Body.String(), tt.status, tt.body)
+		}
+	}
+}
+
+func TestErrorPages_Fallback(t *testing.T) {
+	testFS := fstest.MapFS{
+		"errors/404.html": {Data: []byte(`{{template "missing"}}`)},
+	}
+	engine := New(Config{FileSystem: testFS, Directory: "."})
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.SetErrorHandler(ErrorPages(map[int]string{http.StatusNotFound: "errors/404"}))
+	r.GET("/gone", func(c *rig.Context) error {
+		return rig.NewHTTPError(http.StatusNotFound, "gone")
+	})
+	r.GET("/conflict", func(c *rig.Context) error {
+		return rig.NewHTTPError(http.StatusConflict, "taken")
+	})
+
+	for path, want := range map[string]string{"/gone": "gone", "/conflict": "taken"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header.Set("Accept", "text/html")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Body.String() != want {
+			t.Errorf("%s: body = %q, want the default error handler's %q", path, w.Body.String(), want)
+		}
+	}
+}
+
+// recordingMinifier uppercases output and records the media types it gets.
+type recordingMinifier struct {
+	mediaTypes []string
+}
+
+func (m *recordingMinifier) Minify(mediaType string, w io.Writer, r io.Reader) error {
+	m.mediaTypes = append(m.mediaTypes, mediaType)
+	if mediaType == "text/plain" {
+		return fmt.Errorf("no minifier for %s", mediaType)
+	}
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	_, err = io.WriteString(w, strings.ToUpper(string(b)))
+	return err
+}
+
+func TestEngine_Minifier(t *testing.T) {
+	testFS := fstest.MapFS{
+		"page.html":  {Data: []byte(`<p>page</p>`)},
+		"icon.svg":   {Data: []byte(`<svg></svg>`)},
+		"notes.txt":  {Data: []byte(`notes`)},
+		"raw.html":   {Data: []byte(`<pre>raw</pre>`)},
+		"_card.tmpl": {Data: []byte(`<div>card</div>`)},
+	}
+	minifier := &recordingMinifier{}
+	engine := New(Config{
+		FileSystem: testFS,
+		Directory:  ".",
+		Extensions: []string{".html", ".svg", ".txt", ".tmpl"},
+		Minify:     true,
+		Minifier:   minifier,
+		MinifySkip: func(name string) bool { return name == "raw" },
+	})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		name, want string
+	}{
+		{"page", "<P>PAGE</P>"},
+		{"icon", "<SVG></SVG>"},
+		{"notes", "notes"}, // The minifier failed
+		{"raw", "<pre>raw</pre>"},
+	}
+	for _, tt := range tests {
+		got, err := engine.Render(tt.name, nil)
+		if err != nil || got != tt.want {
+			t.Errorf("Render(%q) = %q, %v, want %q", tt.name, got, err, tt.want)
+		}
+	}
+	if got, err := engine.RenderPartial("_card", nil); err != nil || got != "<DIV>CARD</DIV>" {
+		t.Errorf("RenderPartial() = %q, %v", got, err)
+	}
+
+	want := []string{"text/html", "image/svg+xml", "text/plain", "text/html"}
+	if !slices.Equal(minifier.mediaTypes, want) {
+		t.Errorf("media types = %v, want %v", minifier.mediaTypes, want)
+	}
+}
+
+func TestHTMLMinifier(t *testing.T) {
+	var buf strings.Builder
+	if err := HTMLMinifier().Minify("text/html", &buf, strings.NewReader("<div>\n  <p>a</p>\n</div>")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "<div><p>a</p></div>" {
+		t.Errorf("text/html = %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := HTMLMinifier().Minify("image/svg+xml", &buf, strings.NewReader("<svg>\n  <g/>\n</svg>")); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "<svg>\n  <g/>\n</svg>" {
+		t.Errorf("image/svg+xml = %q, want it unchanged", buf.String())
+	}
+}
+
+func TestHTMLMinifierWithConfig(t *testing.T) {
+	minifier := HTMLMinifierWithConfig(HTMLMinifierConfig{CSS: true, JS: true})
+	tests := []struct {
+		mediaType, input, want string
+	}{
+		{
+			"text/html",
+			"<style>\n  /* theme */\n  a :hover,\n  .btn {\n    color: red;\n    content: \"a  b\";\n  }\n</style>",
+			`<style>a :hover,.btn{color: red;content: "a  b"}</style>`,
+		},
+		{
+			"text/html",
+			"<script>\n    // greet\n    const msg = \"hi  // there\";\n    let re = /\\/*x/g, half = total / 2;\n    alert(`${msg}   ${ {a: 1}.a }`) /* done */\n</script>",
+			"<script>const msg = \"hi  // there\";\nlet re = /\\/*x/g, half = total / 2;\nalert(`${msg}   ${ {a: 1}.a}`)</script>",
+		},
+		{
+			"text/html",
+			"<script type=\"text/template\">\n  <p>  {{name}}  </p>\n</script>",
+			"<script type=\"text/template\">\n  <p>  {{name}}  </p>\n</script>",
+		},
+		{
+			"text/html",
+			"<script>\n  let s = \"unterminated\n</script>",
+			"<script>\n  let s = \"unterminated\n</script>",
+		},
+		{"text/css", "body {\n  margin: 0;\n}\n", "body{margin: 0}"},
+		{"text/javascript", "// app\nrun( 1 )\n", "run( 1 )"},
+	}
+	for _, tt := range tests {
+		var buf strings.Builder
+		if err := minifier.Minify(tt.mediaType, &buf, strings.NewReader(tt.input)); err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != tt.want {
+			t.Errorf("Minify(%q, %q) = %q, want %q", tt.mediaType, tt.input, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestEngine_RenderTo(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<main>{{.Content}}</main>`)},
+		"page.html":         {Data: []byte(`<h1>{{.Title}}</h1><script nonce="{{cspNonce}}"></script>`)},
+	}
+	engine := New(Config{FileSystem: testFS, Directory: ".", Layout: "layouts/base"})
+	if err := engine.Load(); err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	var buf bytes.Buffer
+	if err := engine.RenderTo(&buf, "page", map[string]any{"Title": "Hi"}); err != nil {
+		t.Fatalf("RenderTo() error = %v", err)
+	}
+	want, _ := engine.Render("page", map[string]any{"Title": "Hi"})
+	if buf.String() != want || want != `<main><h1>Hi</h1><script nonce=""></script></main>` {
+		t.Errorf("RenderTo() = %q, Render() = %q", buf.String(), want)
+	}
+
+	if err := engine.RenderTo(&buf, "missing", nil); err == nil {
+		t.Error("RenderTo() should fail for a missing template")
+	}
+}
+
+func TestNonceWriter(t *testing.T) {
+	var buf bytes.Buffer
+	nw := &nonceWriter{w: &buf, marker: []byte("MARKER"), nonce: []byte("n0nce")}
+
+	// Markers whole, split across writes, and partial
+	for _, p := range []string{"a MARKER b MAR", "KER c MA", "RK", "ER d M", "X MAR"} {
+		if n, err := nw.Write([]byte(p)); n != len(p) || err != nil {
+			t.Fatalf("Write(%q) = %d, %v", p, n, err)
+		}
+	}
+	if err := nw.flush(); err != nil {
+		t.Fatal(err)
+	}
+	if want := "a n0nce b n0nce c n0nce d MX MAR"; buf.String() != want {
+		t.Errorf("output = %q, want %q", buf.String(), want)
+	}
+}
+
+func TestBufferPoolStats(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layouts/base.html": {Data: []byte(`<main>{{.Content}}</main>`)},
+		"page.html":         {Data: []byte(`<h1>{{.Title}}</h1>`)},
+		"big.html":          {Data: []byte(`{{.Body}}`)},
+	}
+	engine := New(Config{FileSystem: testFS, Directory: ".", Layout: "layouts/base"})
+
+	r := rig.New()
+	r.Use(engine.Middleware())
+	r.GET("/", func(c *rig.Context) error {
+		return HTML(c, http.StatusOK, "page", map[string]any{"Title": "Hi"})
+	})
+	r.GET("/big", func(c *rig.Context) error {
+		return HTML(c, http.StatusOK, "big", map[string]any{"Body": strings.Repeat("x", 2<<20)})
+	})
+
+	before := BufferPoolStats()
+	for range 100 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Body.String() != "<main><h1>Hi</h1></main>" {
+			t.Fatalf("body = %q", w.Body.String())
+		}
+	}
+	after := BufferPoolStats()
+
+	// The response and layout content buffers, per request
+	if gets := after.Gets - before.Gets; gets < 200 {
+		t.Errorf("Gets grew by %d, want at least 200", gets)
+	}
+	if allocs := after.Allocs - before.Allocs; allocs >= 100 {
+		t.Errorf("Allocs grew by %d over 100 requests; buffers are not reused", allocs)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/big", nil))
+	if BufferPoolStats().Discards == after.Discards {
+		t.Error("oversized buffer returned to the pool")
+	}
+}
+
+func TestEngine_Text(t *testing.T) {
+	testFS := fstest.MapFS{
+		"layout.txt":     {Data: []byte("{{.Content}}\n{{template \"_signature\" .Data}}")},
+		"_signature.txt": {Data: []byte("-- {{.Team}} <{{.Email}}>")},
+		"welcome.txt":    {Data: []byte(`Hi {{.Name}}, your plan is "{{.Plan}}" & {{upper .Name}}.`)},
+	}
+	engine := New(Config{
+		FileSystem: testFS,
+		Directory:  ".",
+		Extensions: []string{".txt"},
+		Layout:     "layout",
+		Text:       true,
+		Funcs:      template.FuncMap{"upper": strings.ToUpper},
+	})
+	if err := engine.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	data := map[string]any{"Name": "O'Brien", "Plan": "<Pro>", "Team": "Rig & Co", "Email": "team@example.com"}
+	got, err := engine.Render("welcome", data)
+	if err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+	want := "Hi O'Brien, your plan is \"<Pro>\" & O'BRIEN.\n-- Rig & Co <team@example.com>"
+	if got != want {
+		t.Errorf("Render() = %q, want %q (unescaped)", got, want)
+	}
+
+	if got, err := engine.RenderPartial("_signature", data); err != nil || got != "-- Rig & Co <team@example.com>" {
+		t.Errorf("RenderPartial() = %q, %v", got, err)
+	}
+}
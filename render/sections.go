@@ -0,0 +1,72 @@
+package render
+
+import (
+	"fmt"
+	"html/template"
+	"regexp"
+	"strings"
+)
+
+// sectionPrefix starts the names of the templates that {{content "name"}}
+// defines.
+const sectionPrefix = "content:"
+
+// sectionActions returns the patterns matching {{content "name"}} and
+// {{yield "name"}} actions with the engine's delimiters, trim markers
+// included.
+func (e *Engine) sectionActions() (content, yield *regexp.Regexp) {
+	left, right := e.delims()
+	action := func(keyword string) *regexp.Regexp {
+		return regexp.MustCompile(regexp.QuoteMeta(left) + `(-\s+|\s*)` + keyword +
+			`\s+"([^"\\]+)"(\s+-|\s*)` + regexp.QuoteMeta(right))
+	}
+	return action("content"), action("yield")
+}
+
+// delims returns the engine's action delimiters.
+func (e *Engine) delims() (left, right string) {
+	if len(e.config.Delims) == 2 {
+		return e.config.Delims[0], e.config.Delims[1]
+	}
+	return "{{", "}}"
+}
+
+// rewriteSections turns the section actions of a template's source into
+// standard actions. In pages, {{content "name"}} becomes a {{define}} of
+// the section, closed by the page's {{end}}. In the layout, {{yield "name"}}
+// becomes the section's content, or nothing if the page does not fill it:
+// with BlockLayout, an empty {{block}} the page's definition overrides, and
+// otherwise the section rendered in advance from .Sections.
+func (e *Engine) rewriteSections(name, src string) string {
+	content, yield := e.sectionActions()
+	left, right := e.delims()
+
+	if name != e.config.Layout {
+		return content.ReplaceAllString(src, left+`${1}define "`+sectionPrefix+`${2}"${3}`+right)
+	}
+	if e.config.BlockLayout {
+		return yield.ReplaceAllString(src, left+`${1}block "`+sectionPrefix+`${2}" $$${3}`+right+left+`end`+right)
+	}
+	return yield.ReplaceAllString(src, left+`${1}index $$.Sections "${2}"${3}`+right)
+}
+
+// renderSections renders the sections a page fills with
+// {{content "name"}}, for the {{yield "name"}} actions of a {{.Content}}
+// layout.
+func renderSections(tmpl templateSet, data any) (map[string]template.HTML, error) {
+	sections := make(map[string]template.HTML)
+	for _, t := range tmpl.Templates() {
+		section, ok := strings.CutPrefix(t.Name(), sectionPrefix)
+		if !ok {
+			continue
+		}
+		buf := getBuffer()
+		err := tmpl.ExecuteTemplate(buf, t.Name(), data)
+		sections[section] = template.HTML(buf.String()) //nolint:gosec // Content is from our own templates
+		putBuffer(buf)
+		if err != nil {
+			return nil, fmt.Errorf("failed to execute section %q: %w", section, err)
+		}
+	}
+	return sections, nil
+}
@@ -0,0 +1,156 @@
+package render
+
+import (
+	htmltemplate "html/template"
+	"io"
+	texttemplate "text/template"
+	"text/template/parse"
+)
+
+// templateSet is a template and the templates associated with it, parsed
+// with html/template, or with text/template in Config.Text mode.
+type templateSet interface {
+	// Name returns the name of the template.
+	Name() string
+
+	// New allocates a template associated with the set.
+	New(name string) templateSet
+
+	// Parse parses text as the template's body.
+	Parse(text string) (templateSet, error)
+
+	// Clone returns a copy of the set, to which templates can be added
+	// without affecting the original.
+	Clone() (templateSet, error)
+
+	// Lookup returns the associated template called name, or nil.
+	Lookup(name string) templateSet
+
+	// Templates returns the templates associated with the set.
+	Templates() []templateSet
+
+	// Tree returns the parse tree of the template, or nil if it has no
+	// body.
+	Tree() *parse.Tree
+
+	// Execute applies the template to data, writing the output to w.
+	Execute(w io.Writer, data any) error
+
+	// ExecuteTemplate applies the associated template called name.
+	ExecuteTemplate(w io.Writer, name string, data any) error
+}
+
+// newTemplateSet returns an empty set named name, with the engine's
+// functions and delimiters.
+func (e *Engine) newTemplateSet(name string) templateSet {
+	if e.config.Text {
+		t := texttemplate.New(name).Funcs(texttemplate.FuncMap(e.funcs))
+		if len(e.config.Delims) == 2 {
+			t = t.Delims(e.config.Delims[0], e.config.Delims[1])
+		}
+		return textSet{t}
+	}
+
+	t := htmltemplate.New(name).Funcs(e.funcs)
+	if len(e.config.Delims) == 2 {
+		t = t.Delims(e.config.Delims[0], e.config.Delims[1])
+	}
+	return htmlSet{t}
+}
+
+// htmlSet is a templateSet of html/template, which escapes output by
+// context.
+type htmlSet struct {
+	t *htmltemplate.Template
+}
+
+func (s htmlSet) Name() string { return s.t.Name() }
+
+func (s htmlSet) New(name string) templateSet { return htmlSet{s.t.New(name)} }
+
+func (s htmlSet) Parse(text string) (templateSet, error) {
+	t, err := s.t.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return htmlSet{t}, nil
+}
+
+func (s htmlSet) Clone() (templateSet, error) {
+	t, err := s.t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return htmlSet{t}, nil
+}
+
+func (s htmlSet) Lookup(name string) templateSet {
+	if t := s.t.Lookup(name); t != nil {
+		return htmlSet{t}
+	}
+	return nil
+}
+
+func (s htmlSet) Templates() []templateSet {
+	var sets []templateSet
+	for _, t := range s.t.Templates() {
+		sets = append(sets, htmlSet{t})
+	}
+	return sets
+}
+
+func (s htmlSet) Tree() *parse.Tree { return s.t.Tree }
+
+func (s htmlSet) Execute(w io.Writer, data any) error { return s.t.Execute(w, data) }
+
+func (s htmlSet) ExecuteTemplate(w io.Writer, name string, data any) error {
+	return s.t.ExecuteTemplate(w, name, data)
+}
+
+// textSet is a templateSet of text/template, which writes output as is.
+type textSet struct {
+	t *texttemplate.Template
+}
+
+func (s textSet) Name() string { return s.t.Name() }
+
+func (s textSet) New(name string) templateSet { return textSet{s.t.New(name)} }
+
+func (s textSet) Parse(text string) (templateSet, error) {
+	t, err := s.t.Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	return textSet{t}, nil
+}
+
+func (s textSet) Clone() (templateSet, error) {
+	t, err := s.t.Clone()
+	if err != nil {
+		return nil, err
+	}
+	return textSet{t}, nil
+}
+
+func (s textSet) Lookup(name string) templateSet {
+	if t := s.t.Lookup(name); t != nil {
+		return textSet{t}
+	}
+	return nil
+}
+
+func (s textSet) Templates() []templateSet {
+	var sets []templateSet
+	for _, t := range s.t.Templates() {
+		sets = append(sets, textSet{t})
+	}
+	return sets
+}
+
+func (s textSet) Tree() *parse.Tree { return s.t.Tree }
+
+func (s textSet) Execute(w io.Writer, data any) error { return s.t.Execute(w, data) }
+
+func (s textSet) ExecuteTemplate(w io.Writer, name string, data any) error {
+	return s.t.ExecuteTemplate(w, name, data)
+}
@@ -0,0 +1,143 @@
+package render
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"text/template/parse"
+)
+
+// ValidateConfig defines the configuration for ValidateWithConfig.
+type ValidateConfig struct {
+	// Execute also executes every template and partial with nil data,
+	// catching errors that only show at execution, such as a function
+	// called with arguments of the wrong type. Templates that require data
+	// (e.g., {{index .Items 0}}) fail with nil data, so enable it only if
+	// all templates handle missing data.
+	// Default: false.
+	Execute bool
+}
+
+// Validate loads all templates and checks that every template they
+// reference with {{template}} or {{block}} exists, including in the layout
+// and partials, so template errors fail at startup instead of at request
+// time. It returns all the problems found, joined.
+//
+// Example:
+//
+//	engine := render.New(render.Config{Directory: "./templates", Layout: "layouts/base"})
+//	if err := engine.Validate(); err != nil {
+//	    log.Fatalf("templates: %v", err)
+//	}
+func (e *Engine) Validate() error {
+	return e.ValidateWithConfig(ValidateConfig{})
+}
+
+// ValidateWithConfig validates templates like Validate, and executes them
+// with nil data if config.Execute is set.
+//
+// Example:
+//
+//	if err := engine.ValidateWithConfig(render.ValidateConfig{Execute: true}); err != nil {
+//	    log.Fatalf("templates: %v", err)
+//	}
+func (e *Engine) ValidateWithConfig(config ValidateConfig) error {
+	if err := e.Load(); err != nil {
+		return err
+	}
+
+	pages := e.TemplateNames()
+	slices.Sort(pages)
+	partials := e.PartialNames()
+	slices.Sort(partials)
+
+	var errs []error
+	seen := make(map[string]bool)
+	report := func(err error) {
+		if !seen[err.Error()] {
+			seen[err.Error()] = true
+			errs = append(errs, err)
+		}
+	}
+
+	e.mu.RLock()
+	for _, name := range pages {
+		entry := name
+		if e.config.BlockLayout && e.layoutName != "" && name != e.layoutName {
+			entry = e.layoutName
+		}
+		for _, err := range undefinedTemplates(e.templates[name], entry) {
+			report(err)
+		}
+	}
+	for _, name := range partials {
+		for _, err := range undefinedTemplates(e.partials, name) {
+			report(err)
+		}
+	}
+	e.mu.RUnlock()
+
+	if config.Execute {
+		for _, name := range pages {
+			if _, err := e.render(name, nil, ""); err != nil {
+				report(err)
+			}
+		}
+		for _, name := range partials {
+			if _, err := e.renderPartial(name, nil, ""); err != nil {
+				report(err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// undefinedTemplates returns an error for every template referenced from
+// entry, directly or through the templates it references, that is not
+// defined in set.
+func undefinedTemplates(set templateSet, entry string) []error {
+	var errs []error
+	visited := make(map[string]bool)
+
+	var visit func(name string)
+	var walk func(from string, node parse.Node)
+	visit = func(name string) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		if t := set.Lookup(name); t != nil && t.Tree() != nil {
+			walk(name, t.Tree().Root)
+		}
+	}
+	walk = func(from string, node parse.Node) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(from, child)
+			}
+		case *parse.IfNode:
+			walk(from, n.List)
+			walk(from, n.ElseList)
+		case *parse.RangeNode:
+			walk(from, n.List)
+			walk(from, n.ElseList)
+		case *parse.WithNode:
+			walk(from, n.List)
+			walk(from, n.ElseList)
+		case *parse.TemplateNode:
+			if t := set.Lookup(n.Name); t == nil || t.Tree() == nil {
+				errs = append(errs, fmt.Errorf("template %s: undefined template %q", from, n.Name))
+				return
+			}
+			visit(n.Name)
+		}
+	}
+
+	visit(entry)
+	return errs
+}
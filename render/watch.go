@@ -0,0 +1,78 @@
+package render
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// templatesChanged reports whether the template directory changed since it
+// last returned true, starting a file watcher on first use. Without a
+// watcher (templates in Config.FileSystem, or watching failed), it always
+// reports true, so templates are reloaded on every request.
+func (e *Engine) templatesChanged() bool {
+	e.watchOnce.Do(e.watch)
+	if e.watcher == nil {
+		return true
+	}
+	return e.stale.Swap(false)
+}
+
+// watch starts watching Config.Directory and its subdirectories, marking
+// the templates stale on every change.
+func (e *Engine) watch() {
+	if e.config.FileSystem != nil {
+		return
+	}
+	w, err := fsnotify.NewWatcher()
+	if err != nil {
+		return
+	}
+	err = filepath.WalkDir(e.config.Directory, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || !d.IsDir() {
+			return err
+		}
+		return w.Add(path)
+	})
+	if err != nil {
+		_ = w.Close()
+		return
+	}
+	e.watcher = w
+
+	go func() {
+		for {
+			select {
+			case event, ok := <-w.Events:
+				if !ok {
+					return
+				}
+				// fsnotify does not watch recursively, so add new directories
+				if event.Has(fsnotify.Create) {
+					if info, err := os.Stat(event.Name); err == nil && info.IsDir() {
+						_ = w.Add(event.Name)
+					}
+				}
+				e.stale.Store(true)
+			case _, ok := <-w.Errors:
+				if !ok {
+					return
+				}
+				// Events may have been lost
+				e.stale.Store(true)
+			}
+		}
+	}()
+}
+
+// Close stops watching the template directory for changes (see
+// Config.DevMode). The engine keeps rendering its loaded templates.
+func (e *Engine) Close() error {
+	e.watchOnce.Do(func() {}) // Never start watching after Close
+	if e.watcher == nil {
+		return nil
+	}
+	return e.watcher.Close()
+}
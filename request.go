@@ -0,0 +1,101 @@
+package rig
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// ContentType returns the media type of the request body without parameters,
+// in lower case (e.g., "application/json" for
+// "Application/JSON; charset=utf-8"). It returns "" if the request has no
+// Content-Type header.
+func (c *Context) ContentType() string {
+	contentType := c.GetHeader("Content-Type")
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		return mediaType
+	}
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	return strings.ToLower(strings.TrimSpace(mediaType))
+}
+
+// IsWebSocket reports whether the request is a WebSocket upgrade request
+// ("Connection: Upgrade" and "Upgrade: websocket").
+func (c *Context) IsWebSocket() bool {
+	return headerHasToken(c.request.Header, "Connection", "upgrade") &&
+		strings.EqualFold(strings.TrimSpace(c.GetHeader("Upgrade")), "websocket")
+}
+
+// IsAJAX reports whether the request was sent with XMLHttpRequest, as
+// indicated by the "X-Requested-With: XMLHttpRequest" header that jQuery and
+// similar libraries set.
+func (c *Context) IsAJAX() bool {
+	return strings.EqualFold(c.GetHeader("X-Requested-With"), "XMLHttpRequest")
+}
+
+// Scheme returns the scheme the client used, "http" or "https". Behind a
+// TLS-terminating proxy it is taken from the Forwarded (proto=),
+// X-Forwarded-Proto, X-Forwarded-Ssl, or X-Url-Scheme header, in that order.
+// Header values other than "http" and "https" are ignored.
+//
+// These headers are sent by the client unless a proxy overwrites them, so
+// do not rely on Scheme for security decisions when the server is reachable
+// without a proxy.
+func (c *Context) Scheme() string {
+	if c.request.TLS != nil {
+		return "https"
+	}
+	if proto, ok := httpScheme(forwardedProto(c.GetHeader("Forwarded"))); ok {
+		return proto
+	}
+	// A chain of proxies may append; the first entry is the client's
+	first, _, _ := strings.Cut(c.GetHeader("X-Forwarded-Proto"), ",")
+	if proto, ok := httpScheme(first); ok {
+		return proto
+	}
+	if strings.EqualFold(c.GetHeader("X-Forwarded-Ssl"), "on") {
+		return "https"
+	}
+	if scheme, ok := httpScheme(c.GetHeader("X-Url-Scheme")); ok {
+		return scheme
+	}
+	return "http"
+}
+
+// httpScheme returns s in lower case if it is "http" or "https".
+func httpScheme(s string) (string, bool) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	return s, s == "http" || s == "https"
+}
+
+// Secure reports whether the client used HTTPS, directly or through a
+// TLS-terminating proxy (see Scheme).
+func (c *Context) Secure() bool {
+	return c.Scheme() == "https"
+}
+
+// forwardedProto returns the proto parameter of the first element of an
+// RFC 7239 Forwarded header (e.g., "for=192.0.2.60;proto=https").
+func forwardedProto(header string) string {
+	first, _, _ := strings.Cut(header, ",")
+	for pair := range strings.SplitSeq(first, ";") {
+		key, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if ok && strings.EqualFold(key, "proto") {
+			return strings.Trim(value, `"`)
+		}
+	}
+	return ""
+}
+
+// headerHasToken reports whether the comma-separated values of header key
+// contain token, compared case-insensitively.
+func headerHasToken(header http.Header, key, token string) bool {
+	for _, value := range header.Values(key) {
+		for t := range strings.SplitSeq(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
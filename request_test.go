@@ -0,0 +1,83 @@
+package rig
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestContext_ContentType(t *testing.T) {
+	tests := map[string]string{
+		"":                                "",
+		"application/json":                "application/json",
+		"Application/JSON; charset=utf-8": "application/json",
+		"text/plain;;":                    "text/plain",
+	}
+	for header, want := range tests {
+		req := httptest.NewRequest(http.MethodPost, "/", nil)
+		req.Header.Set("Content-Type", header)
+		if got := newContext(httptest.NewRecorder(), req).ContentType(); got != want {
+			t.Errorf("ContentType() for %q = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestContext_IsWebSocketAndIsAJAX(t *testing.T) {
+	req := httptest.NewRequest(http.MethodGet, "/ws", nil)
+	req.Header.Set("Connection", "keep-alive, Upgrade")
+	req.Header.Set("Upgrade", "WebSocket")
+	req.Header.Set("X-Requested-With", "xmlhttprequest")
+	c := newContext(httptest.NewRecorder(), req)
+
+	if !c.IsWebSocket() {
+		t.Error("IsWebSocket() = false, want true")
+	}
+	if !c.IsAJAX() {
+		t.Error("IsAJAX() = false, want true")
+	}
+
+	c = newContext(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	if c.IsWebSocket() || c.IsAJAX() {
+		t.Error("plain request should be neither WebSocket nor AJAX")
+	}
+}
+
+func TestContext_Scheme(t *testing.T) {
+	tests := []struct {
+		name   string
+		header map[string]string
+		tls    bool
+		want   string
+	}{
+		{"plain", nil, false, "http"},
+		{"tls", nil, true, "https"},
+		{"forwarded", map[string]string{"Forwarded": `for=192.0.2.60;proto="HTTPS", for=10.0.0.1;proto=http`}, false, "https"},
+		{"x-forwarded-proto chain", map[string]string{"X-Forwarded-Proto": "https, http"}, false, "https"},
+		{"x-forwarded-ssl", map[string]string{"X-Forwarded-Ssl": "on"}, false, "https"},
+		{"x-url-scheme", map[string]string{"X-Url-Scheme": "https"}, false, "https"},
+		{"invalid x-url-scheme", map[string]string{"X-Url-Scheme": "javascript"}, false, "http"},
+		{"invalid forwarded", map[string]string{"Forwarded": "proto=javascript", "X-Forwarded-Proto": "https"}, false, "https"},
+		{"invalid header with tls", map[string]string{"X-Forwarded-Proto": "ftp"}, true, "https"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v)
+			}
+			if tt.tls {
+				req.TLS = &tls.ConnectionState{}
+			}
+			c := newContext(httptest.NewRecorder(), req)
+
+			if got := c.Scheme(); got != tt.want {
+				t.Errorf("Scheme() = %q, want %q", got, tt.want)
+			}
+			if c.Secure() != (tt.want == "https") {
+				t.Errorf("Secure() = %v", c.Secure())
+			}
+		})
+	}
+}
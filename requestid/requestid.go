@@ -44,7 +44,8 @@ const (
 	DefaultHeader = "X-Request-ID"
 
 	// ContextKey is the key used to store the request ID in the context.
-	ContextKey = "request_id"
+	// rig.Logger reads it to add the "request_id" log attribute.
+	ContextKey = rig.RequestIDKey
 )
 
 // Config defines the configuration for the request ID middleware.
@@ -66,6 +67,10 @@ type Config struct {
 	//
 	// Default: false (always generate new IDs).
 	TrustProxy bool
+
+	// Skipper selects requests that get no request ID.
+	// Default: nil (every request gets one).
+	Skipper rig.Skipper
 }
 
 // New creates request ID middleware that assigns a unique ID to each request.
@@ -96,6 +101,10 @@ func New(config ...Config) rig.MiddlewareFunc {
 
 	return func(next rig.HandlerFunc) rig.HandlerFunc {
 		return func(c *rig.Context) error {
+			if cfg.Skipper != nil && cfg.Skipper(c) {
+				return next(c)
+			}
+
 			var requestID string
 
 			// If TrustProxy is enabled, check for existing request ID
@@ -291,3 +291,29 @@ func TestNew_CustomHeaderWithTrustProxy(t *testing.T) {
 		t.Error("Response should have custom header with incoming ID")
 	}
 }
+
+func TestNew_Skipper(t *testing.T) {
+	r := rig.New()
+	r.Use(New(Config{Skipper: rig.PathPrefix("/health")}))
+
+	var captured string
+	handler := func(c *rig.Context) error {
+		captured = Get(c)
+		c.Status(http.StatusOK)
+		return nil
+	}
+	r.GET("/health", handler)
+	r.GET("/test", handler)
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if captured != "" || rec.Header().Get(DefaultHeader) != "" {
+		t.Errorf("Expected no request ID for skipped request, got %q", captured)
+	}
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/test", nil))
+	if captured == "" {
+		t.Error("Expected request ID for non-skipped request")
+	}
+}
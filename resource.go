@@ -0,0 +1,143 @@
+package rig
+
+// Resource actions identify the RESTful routes registered by Resource.
+// They are used as keys in ResourceConfig.Middleware and ResourceConfig.Only.
+const (
+	ActionIndex  = "index"  // GET    /path
+	ActionShow   = "show"   // GET    /path/{id}
+	ActionCreate = "create" // POST   /path
+	ActionUpdate = "update" // PUT    /path/{id} and PATCH /path/{id}
+	ActionDelete = "delete" // DELETE /path/{id}
+)
+
+// ResourceController is implemented by types that handle the standard
+// CRUD actions for a RESTful resource.
+type ResourceController interface {
+	// Index lists the resource collection (GET /path).
+	Index(c *Context) error
+
+	// Show returns a single resource (GET /path/{id}).
+	Show(c *Context) error
+
+	// Create creates a new resource (POST /path).
+	Create(c *Context) error
+
+	// Update modifies an existing resource (PUT and PATCH /path/{id}).
+	Update(c *Context) error
+
+	// Delete removes an existing resource (DELETE /path/{id}).
+	Delete(c *Context) error
+}
+
+// ResourceConfig holds configuration options for Resource.
+type ResourceConfig struct {
+	// Param is the name of the path parameter identifying a single resource.
+	// Handlers read it with c.Param(Param).
+	// Default: "id".
+	Param string
+
+	// Only restricts registration to the listed actions (e.g., ActionIndex, ActionShow).
+	// If empty, all actions are registered.
+	Only []string
+
+	// Middleware maps an action to middleware that only applies to that action.
+	// Action middleware runs after router and group middleware.
+	//
+	// Example:
+	//   Middleware: map[string][]rig.MiddlewareFunc{
+	//       rig.ActionCreate: {requireAdmin},
+	//       rig.ActionDelete: {requireAdmin},
+	//   }
+	Middleware map[string][]MiddlewareFunc
+}
+
+// resourceRoute describes a single route registered by Resource.
+type resourceRoute struct {
+	action  string
+	method  string
+	member  bool // true if the route targets a single resource (/path/{id})
+	handler func(ResourceController) HandlerFunc
+}
+
+// resourceRoutes is the fixed mapping of controller actions to routes.
+var resourceRoutes = []resourceRoute{
+	{ActionIndex, "GET", false, func(rc ResourceController) HandlerFunc { return rc.Index }},
+	{ActionCreate, "POST", false, func(rc ResourceController) HandlerFunc { return rc.Create }},
+	{ActionShow, "GET", true, func(rc ResourceController) HandlerFunc { return rc.Show }},
+	{ActionUpdate, "PUT", true, func(rc ResourceController) HandlerFunc { return rc.Update }},
+	{ActionUpdate, "PATCH", true, func(rc ResourceController) HandlerFunc { return rc.Update }},
+	{ActionDelete, "DELETE", true, func(rc ResourceController) HandlerFunc { return rc.Delete }},
+}
+
+// Resource registers the RESTful routes for a ResourceController at the given path.
+// The path must begin with '/'. Panics if the path is invalid.
+//
+// The following routes are registered:
+//
+//	GET    /users       -> Index
+//	POST   /users       -> Create
+//	GET    /users/{id}  -> Show
+//	PUT    /users/{id}  -> Update
+//	PATCH  /users/{id}  -> Update
+//	DELETE /users/{id}  -> Delete
+//
+// Example:
+//
+//	r.Resource("/users", &UserController{}, rig.ResourceConfig{
+//	    Middleware: map[string][]rig.MiddlewareFunc{
+//	        rig.ActionDelete: {requireAdmin},
+//	    },
+//	})
+func (r *Router) Resource(path string, controller ResourceController, config ...ResourceConfig) {
+	validatePath(path)
+	registerResource(r.Handle, path, controller, config)
+}
+
+// Resource registers the RESTful routes for a ResourceController at the given
+// path within the group. The path must be empty or begin with '/'.
+// Panics if the path is invalid. See Router.Resource for the registered routes.
+func (g *RouteGroup) Resource(path string, controller ResourceController, config ...ResourceConfig) {
+	validateGroupPath(path)
+	registerResource(g.handle, joinPaths(g.prefix, path), controller, config)
+}
+
+// registerResource wires each enabled controller action through handle,
+// wrapping it with its action-specific middleware.
+func registerResource(handle func(string, HandlerFunc), path string, controller ResourceController, config []ResourceConfig) {
+	var cfg ResourceConfig
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.Param == "" {
+		cfg.Param = "id"
+	}
+
+	enabled := make(map[string]bool, len(cfg.Only))
+	for _, action := range cfg.Only {
+		enabled[action] = true
+	}
+
+	// Strip a trailing slash so "/users/" and "/users" register the same routes
+	if len(path) > 1 && path[len(path)-1] == '/' {
+		path = path[:len(path)-1]
+	}
+	memberPath := joinPaths(path, "/{"+cfg.Param+"}")
+
+	for _, route := range resourceRoutes {
+		if len(enabled) > 0 && !enabled[route.action] {
+			continue
+		}
+
+		handler := route.handler(controller)
+		mws := cfg.Middleware[route.action]
+		for i := len(mws) - 1; i >= 0; i-- {
+			handler = mws[i](handler)
+		}
+
+		routePath := path
+		if route.member {
+			routePath = memberPath
+		}
+		handle(route.method+" "+routePath, handler)
+	}
+}
@@ -0,0 +1,179 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// testController records which action handled the request.
+type testController struct{}
+
+func (testController) Index(c *Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"action": "index"})
+}
+
+func (testController) Show(c *Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"action": "show", "id": c.Param("id")})
+}
+
+func (testController) Create(c *Context) error {
+	return c.JSON(http.StatusCreated, map[string]string{"action": "create"})
+}
+
+func (testController) Update(c *Context) error {
+	return c.JSON(http.StatusOK, map[string]string{"action": "update", "id": c.Param("id")})
+}
+
+func (testController) Delete(c *Context) error {
+	c.Status(http.StatusNoContent)
+	return nil
+}
+
+func TestRouter_Resource(t *testing.T) {
+	r := New()
+	r.Resource("/users", testController{})
+
+	tests := []struct {
+		method     string
+		path       string
+		wantStatus int
+		wantBody   string
+	}{
+		{http.MethodGet, "/users", http.StatusOK, `{"action":"index"}`},
+		{http.MethodPost, "/users", http.StatusCreated, `{"action":"create"}`},
+		{http.MethodGet, "/users/42", http.StatusOK, `{"action":"show","id":"42"}`},
+		{http.MethodPut, "/users/42", http.StatusOK, `{"action":"update","id":"42"}`},
+		{http.MethodPatch, "/users/42", http.StatusOK, `{"action":"update","id":"42"}`},
+		{http.MethodDelete, "/users/42", http.StatusNoContent, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody+"\n" {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestRouter_Resource_CustomParam(t *testing.T) {
+	r := New()
+	r.Resource("/users/", testController{}, ResourceConfig{Param: "userID"})
+
+	req := httptest.NewRequest(http.MethodGet, "/users/7", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	// Show reads "id", which is not set when Param is customized
+	if w.Body.String() != `{"action":"show","id":""}`+"\n" {
+		t.Errorf("body = %q", w.Body.String())
+	}
+}
+
+func TestRouter_Resource_Only(t *testing.T) {
+	r := New()
+	r.Resource("/users", testController{}, ResourceConfig{
+		Only: []string{ActionIndex, ActionShow},
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET status = %d, want %d", w.Code, http.StatusOK)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE status = %d, want %d", w.Code, http.StatusMethodNotAllowed)
+	}
+}
+
+func TestRouter_Resource_ActionMiddleware(t *testing.T) {
+	r := New()
+
+	var order []string
+	r.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			order = append(order, "global")
+			return next(c)
+		}
+	})
+
+	deny := func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			order = append(order, "deny")
+			return c.JSON(http.StatusForbidden, map[string]string{"error": "forbidden"})
+		}
+	}
+
+	r.Resource("/users", testController{}, ResourceConfig{
+		Middleware: map[string][]MiddlewareFunc{
+			ActionDelete: {deny},
+		},
+	})
+
+	req := httptest.NewRequest(http.MethodDelete, "/users/1", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusForbidden {
+		t.Errorf("DELETE status = %d, want %d", w.Code, http.StatusForbidden)
+	}
+	if len(order) != 2 || order[0] != "global" || order[1] != "deny" {
+		t.Errorf("order = %v, want [global deny]", order)
+	}
+
+	// Other actions are not affected
+	req = httptest.NewRequest(http.MethodGet, "/users/1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("GET status = %d, want %d", w.Code, http.StatusOK)
+	}
+}
+
+func TestRouteGroup_Resource(t *testing.T) {
+	r := New()
+	api := r.Group("/api")
+
+	groupCalled := false
+	api.Use(func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			groupCalled = true
+			return next(c)
+		}
+	})
+	api.Resource("/users", testController{})
+
+	req := httptest.NewRequest(http.MethodGet, "/api/users/5", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if !groupCalled {
+		t.Error("group middleware was not called")
+	}
+}
+
+func TestRouter_Resource_PathValidation(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid path")
+		}
+	}()
+
+	New().Resource("users", testController{})
+}
@@ -0,0 +1,112 @@
+package rig
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+)
+
+// trackingWriter wraps the ResponseWriter of every Context to record the
+// status and size of the response. For response hooks, it also carries the
+// handler context and error.
+type trackingWriter struct {
+	http.ResponseWriter
+	status int
+	size   int64
+	ctx    *Context
+	err    error
+}
+
+// WriteHeader records the status code. Informational (1xx) responses such
+// as 103 Early Hints are not final and are not recorded.
+func (w *trackingWriter) WriteHeader(code int) {
+	if w.status == 0 && code >= 200 {
+		w.status = code
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Write records the number of bytes written.
+func (w *trackingWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(b)
+	w.size += int64(n)
+	return n, err
+}
+
+// ReadFrom keeps sendfile support of the underlying writer (used by c.File
+// and Static).
+func (w *trackingWriter) ReadFrom(src io.Reader) (int64, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	var n int64
+	var err error
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		n, err = rf.ReadFrom(src)
+	} else {
+		n, err = io.Copy(writerOnly{w.ResponseWriter}, src)
+	}
+	w.size += n
+	return n, err
+}
+
+// Flush implements http.Flusher.
+func (w *trackingWriter) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *trackingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := w.ResponseWriter.(http.Hijacker); ok {
+		if w.status == 0 {
+			w.status = http.StatusSwitchingProtocols
+		}
+		return h.Hijack()
+	}
+	return nil, nil, errors.New("rig: response writer does not support hijacking")
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *trackingWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// writerOnly hides any ReadFrom method of the wrapped writer, so io.Copy
+// does not call back into it.
+type writerOnly struct {
+	io.Writer
+}
+
+// noRangesWriter replaces the "Accept-Ranges: bytes" header that
+// http.FileServer always sets, for file servers that ignore Range requests.
+type noRangesWriter struct {
+	http.ResponseWriter
+}
+
+// WriteHeader advertises that ranges are not supported and writes the
+// status.
+func (w noRangesWriter) WriteHeader(code int) {
+	w.Header().Set("Accept-Ranges", "none")
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// ReadFrom keeps sendfile support of the underlying writer.
+func (w noRangesWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rf, ok := w.ResponseWriter.(io.ReaderFrom); ok {
+		return rf.ReadFrom(src)
+	}
+	return io.Copy(writerOnly{w.ResponseWriter}, src)
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w noRangesWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
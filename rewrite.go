@@ -0,0 +1,185 @@
+package rig
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// RewriteRule maps request paths to new paths before routing. Exactly one of
+// Prefix and Pattern must be set.
+type RewriteRule struct {
+	// Prefix matches paths starting with it; the matched prefix is replaced
+	// by To, so Prefix "/blog/" and To "/articles/" map "/blog/hello" to
+	// "/articles/hello". Include a trailing slash to match whole segments.
+	Prefix string
+
+	// Pattern is a regular expression that must match the whole path. To may
+	// reference its captures as $1 or ${name}, so Pattern
+	// `/posts/(\d+)/(?P<slug>[^/]+)` and To "/articles/${slug}?id=$1" map
+	// "/posts/7/hello" to "/articles/hello?id=7".
+	Pattern string
+
+	// To is the replacement path. It may include a query string, which is
+	// merged in front of the request's own query. The rewritten path is
+	// cleaned (see cleanPath), so the part taken from the request cannot
+	// turn it into another host's URL, such as "//evil.example".
+	To string
+
+	// Redirect, if non-zero, answers matching requests with this redirect
+	// status (e.g., http.StatusMovedPermanently) and the rewritten URL as
+	// Location instead of routing them internally.
+	Redirect int
+}
+
+// rewriteRule is a RewriteRule with its pattern compiled.
+type rewriteRule struct {
+	RewriteRule
+	pattern *regexp.Regexp
+}
+
+// Redirects registers permanent redirects from exact request paths to new
+// locations, evaluated before routing, so moved pages don't need a handler
+// each. The query string is preserved. GET and HEAD requests are answered
+// with 301 Moved Permanently, other methods with 308 Permanent Redirect so
+// the method and body are kept. Targets may be paths or absolute URLs.
+// Redirects may be called more than once; later entries for the same path
+// replace earlier ones.
+//
+// Redirects are applied by Router.ServeHTTP after normalization (see
+// SetNormalization) and before Rewrite rules; the ServeMux returned by
+// Handler does not apply them.
+//
+// Example:
+//
+//	r.Redirects(map[string]string{
+//	    "/about-us": "/about",
+//	    "/docs":     "https://docs.example.com/",
+//	})
+func (r *Router) Redirects(redirects map[string]string) {
+	if r.redirects == nil {
+		r.redirects = make(map[string]string, len(redirects))
+	}
+	for from, to := range redirects {
+		validatePath(from)
+		r.redirects[from] = to
+	}
+}
+
+// Rewrite registers rules that map request paths to new paths before
+// routing. Rules are evaluated in registration order and the first match
+// wins; the rewritten path is not matched against the rules again. Unless
+// the rule sets Redirect, the request is routed internally with the new
+// path, and the client sees the original URL.
+//
+// Rewrite panics if a rule sets both or neither of Prefix and Pattern, or if
+// Pattern is not a valid regular expression.
+//
+// Example:
+//
+//	r.Rewrite(
+//	    rig.RewriteRule{Prefix: "/v1/", To: "/api/v1/"},
+//	    rig.RewriteRule{
+//	        Pattern:  `/blog/(\d{4})/(\d{2})/([^/]+)`,
+//	        To:       "/articles/$3?year=$1&month=$2",
+//	        Redirect: http.StatusMovedPermanently,
+//	    },
+//	)
+func (r *Router) Rewrite(rules ...RewriteRule) {
+	for _, rule := range rules {
+		compiled := rewriteRule{RewriteRule: rule}
+		switch {
+		case rule.Prefix != "" && rule.Pattern != "":
+			panic("rig: rewrite rule must set only one of Prefix and Pattern")
+		case rule.Prefix != "":
+			validatePath(rule.Prefix)
+		case rule.Pattern != "":
+			compiled.pattern = regexp.MustCompile("^(?:" + rule.Pattern + ")$")
+		default:
+			panic("rig: rewrite rule must set Prefix or Pattern")
+		}
+		r.rewrites = append(r.rewrites, compiled)
+	}
+}
+
+// match returns the rewritten target for path and whether the rule matched.
+func (rule *rewriteRule) match(path string) (string, bool) {
+	if rule.pattern == nil {
+		rest, ok := strings.CutPrefix(path, rule.Prefix)
+		if !ok {
+			return "", false
+		}
+		return cleanTarget(rule.To + rest), true
+	}
+
+	m := rule.pattern.FindStringSubmatchIndex(path)
+	if m == nil {
+		return "", false
+	}
+	return cleanTarget(string(rule.pattern.ExpandString(nil, rule.To, path, m))), true
+}
+
+// cleanTarget cleans the path of a rewritten target. Leading slashes and
+// backslashes are collapsed too, since browsers follow a Location of
+// "//host" or "/\host" to another host. Absolute URLs are returned as is.
+func cleanTarget(target string) string {
+	if !strings.HasPrefix(target, "/") {
+		return target
+	}
+	p, query, hasQuery := strings.Cut(target, "?")
+	p = cleanPath("/" + strings.TrimLeft(p, `/\`))
+	if hasQuery {
+		return p + "?" + query
+	}
+	return p
+}
+
+// applyRewrites answers req with a redirect, or returns the request to route,
+// which is a shallow copy with the rewritten URL if a rule matched. It
+// reports false if a redirect was written.
+func (r *Router) applyRewrites(w http.ResponseWriter, req *http.Request) (*http.Request, bool) {
+	path := req.URL.Path
+
+	if to, ok := r.redirects[path]; ok {
+		code := http.StatusPermanentRedirect
+		if req.Method == http.MethodGet || req.Method == http.MethodHead {
+			code = http.StatusMovedPermanently
+		}
+		http.Redirect(w, req, mergeQuery(to, req.URL.RawQuery), code)
+		return req, false
+	}
+
+	for i := range r.rewrites {
+		rule := &r.rewrites[i]
+		to, ok := rule.match(path)
+		if !ok {
+			continue
+		}
+		target := mergeQuery(to, req.URL.RawQuery)
+		if rule.Redirect != 0 {
+			http.Redirect(w, req, target, rule.Redirect)
+			return req, false
+		}
+
+		u := *req.URL
+		u.Path, u.RawQuery, _ = strings.Cut(target, "?")
+		u.RawPath = ""
+		rewritten := new(http.Request)
+		*rewritten = *req
+		rewritten.URL = &u
+		return rewritten, true
+	}
+	return req, true
+}
+
+// mergeQuery appends query to target, after any query target already has.
+func mergeQuery(target, query string) string {
+	switch {
+	case query == "":
+		return target
+	case strings.Contains(target, "?"):
+		return target + "&" + query
+	default:
+		return target + "?" + query
+	}
+}
@@ -0,0 +1,137 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func rewriteRouter() *Router {
+	r := New()
+	r.GET("/about", func(c *Context) error {
+		return c.String(http.StatusOK, "about")
+	})
+	r.GET("/api/v1/users", func(c *Context) error {
+		return c.String(http.StatusOK, "users:%s", c.Request().URL.RawQuery)
+	})
+	r.GET("/articles/{slug}", func(c *Context) error {
+		return c.String(http.StatusOK, "article:%s:%s", c.Param("slug"), c.Query("id"))
+	})
+	return r
+}
+
+func TestRouter_Redirects(t *testing.T) {
+	r := rewriteRouter()
+	r.Redirects(map[string]string{
+		"/about-us": "/about",
+		"/docs":     "https://docs.example.com/?ref=app",
+	})
+
+	tests := []struct {
+		method   string
+		target   string
+		code     int
+		location string
+	}{
+		{http.MethodGet, "/about-us", http.StatusMovedPermanently, "/about"},
+		{http.MethodGet, "/about-us?x=1", http.StatusMovedPermanently, "/about?x=1"},
+		{http.MethodPost, "/about-us", http.StatusPermanentRedirect, "/about"},
+		{http.MethodGet, "/docs?page=2", http.StatusMovedPermanently, "https://docs.example.com/?ref=app&page=2"},
+		{http.MethodGet, "/about-us/team", http.StatusNotFound, ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.target, nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location {
+			t.Errorf("%s %s: got %d %q, want %d %q", tt.method, tt.target,
+				w.Code, w.Header().Get("Location"), tt.code, tt.location)
+		}
+	}
+}
+
+func TestRouter_Rewrite(t *testing.T) {
+	r := rewriteRouter()
+	r.Rewrite(
+		RewriteRule{Prefix: "/v1/", To: "/api/v1/"},
+		RewriteRule{Pattern: `/posts/(\d+)/(?P<slug>[^/]+)`, To: "/articles/${slug}?id=$1"},
+		RewriteRule{Pattern: `/blog/([^/]+)`, To: "/articles/$1", Redirect: http.StatusFound},
+		RewriteRule{Prefix: "/v1/users", To: "/unreachable"},
+	)
+
+	tests := []struct {
+		target   string
+		code     int
+		body     string
+		location string
+	}{
+		{"/v1/users?page=2", http.StatusOK, "users:page=2", ""},
+		{"/posts/7/hello", http.StatusOK, "article:hello:7", ""},
+		{"/posts/x/hello", http.StatusNotFound, "", ""},
+		{"/posts/7/hello/extra", http.StatusNotFound, "", ""},
+		{"/blog/hello?ref=rss", http.StatusFound, "", "/articles/hello?ref=rss"},
+		{"/about", http.StatusOK, "about", ""},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if w.Code != tt.code || w.Header().Get("Location") != tt.location ||
+			(tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%s: got %d %q %q, want %d %q %q", tt.target, w.Code, w.Body.String(),
+				w.Header().Get("Location"), tt.code, tt.body, tt.location)
+		}
+	}
+}
+
+func TestRouter_Rewrite_OpenRedirect(t *testing.T) {
+	r := rewriteRouter()
+	r.Rewrite(
+		RewriteRule{Prefix: "/legacy/", To: "/", Redirect: http.StatusMovedPermanently},
+		RewriteRule{Pattern: `/go/(.*)`, To: "/$1?from=go", Redirect: http.StatusFound},
+	)
+
+	tests := []struct {
+		target   string
+		location string
+	}{
+		{"/legacy//evil.example", "/evil.example"},
+		{"/legacy/%5Cevil.example", "/evil.example"},
+		{"/legacy/a/../b", "/b"},
+		{"/go//evil.example", "/evil.example?from=go"},
+		{"/go/docs/", "/docs/?from=go"},
+	}
+	for _, tt := range tests {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if got := w.Header().Get("Location"); got != tt.location {
+			t.Errorf("%s: Location = %q, want %q", tt.target, got, tt.location)
+		}
+	}
+}
+
+func TestRouter_Rewrite_InvalidRule(t *testing.T) {
+	tests := []struct {
+		name string
+		rule RewriteRule
+		want string
+	}{
+		{"both", RewriteRule{Prefix: "/a", Pattern: "/b", To: "/c"}, "only one of"},
+		{"neither", RewriteRule{To: "/c"}, "Prefix or Pattern"},
+		{"relative prefix", RewriteRule{Prefix: "a", To: "/c"}, "must begin with '/'"},
+		{"invalid pattern", RewriteRule{Pattern: "/(", To: "/c"}, "missing closing )"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			defer func() {
+				p := recover()
+				if p == nil {
+					t.Fatal("expected panic")
+				}
+				if msg, _ := p.(string); !strings.Contains(msg, tt.want) {
+					t.Errorf("panic = %v, want to contain %q", p, tt.want)
+				}
+			}()
+			New().Rewrite(tt.rule)
+		})
+	}
+}
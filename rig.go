@@ -3,6 +3,11 @@
 // like Gin or Echo while relying purely on the Go standard library.
 package rig
 
+import (
+	"net/http"
+	"strings"
+)
+
 // HandlerFunc is the custom handler signature for rig handlers.
 // Unlike http.HandlerFunc, it accepts a *Context and returns an error,
 // allowing handlers to return errors for centralized error handling.
@@ -20,9 +25,36 @@ type ErrorHandler func(*Context, error)
 
 // DefaultErrorHandler is the default error handler that writes a 500 Internal
 // Server Error response when a handler returns an error.
+// The message is localized if a Translator is configured on the router.
+//
+// Errors that carry a 4xx status through a StatusCode() int method (such as
+// *HTTPError and *BindError) are answered with that status and the error
+// message instead, since they describe a problem with the request rather than
+// the server. Errors combined with errors.Join or MultiError are answered the
+// same way when all of them are client errors, writing every message on its
+// own line. Server errors with a 5xx status are answered with that status
+// and its status text, so their messages are never shown. See ErrorStatus.
 func DefaultErrorHandler(c *Context, err error) {
-	if err != nil {
-		c.writer.WriteHeader(500)
-		_, _ = c.writer.Write([]byte("Internal Server Error"))
+	if err == nil {
+		return
+	}
+
+	code := ErrorStatus(err)
+	if code < 500 {
+		errs := SplitErrors(err)
+		msgs := make([]string, len(errs))
+		for i, e := range errs {
+			msgs[i] = e.Error()
+		}
+		c.writer.WriteHeader(code)
+		_, _ = c.writer.Write([]byte(strings.Join(msgs, "\n")))
+		return
+	}
+
+	message := http.StatusText(code)
+	if code == http.StatusInternalServerError {
+		message = c.Translate(MsgInternalServerError, message)
 	}
+	c.writer.WriteHeader(code)
+	_, _ = c.writer.Write([]byte(message))
 }
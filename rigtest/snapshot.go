@@ -0,0 +1,283 @@
+// Package rigtest provides helpers for testing rig applications.
+//
+// # Snapshot Testing
+//
+// Snapshot compares an HTTP response against a golden file stored under
+// testdata/snapshots. JSON bodies are canonicalized (sorted keys, indented)
+// and HTML bodies are normalized line by line, so snapshots only change when
+// the response content does. Volatile values such as RFC 3339 timestamps,
+// UUIDs, and ULIDs are redacted automatically.
+//
+//	func TestGetUser(t *testing.T) {
+//	    r := newRouter()
+//	    rec := httptest.NewRecorder()
+//	    r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/1", nil))
+//
+//	    rigtest.Snapshot(t, rec.Result(), rigtest.Redact("session_token"))
+//	}
+//
+// Missing golden files are created on the first run. To accept intentional
+// changes, re-run the tests with the RIGTEST_UPDATE environment variable set:
+//
+//	RIGTEST_UPDATE=1 go test ./...
+package rigtest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// UpdateEnv is the environment variable that, when set to a non-empty value,
+// makes Snapshot overwrite golden files instead of comparing against them.
+const UpdateEnv = "RIGTEST_UPDATE"
+
+// DefaultSnapshotDir is the directory golden files are stored in, relative
+// to the package under test.
+const DefaultSnapshotDir = "testdata/snapshots"
+
+// redaction replaces every match of pattern with replacement.
+type redaction struct {
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// defaultRedactions mask values that change between test runs.
+var defaultRedactions = []redaction{
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:\d{2})`), "[TIMESTAMP]"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "[UUID]"},
+	{regexp.MustCompile(`\b[0-7][0-9A-HJKMNP-TV-Z]{25}\b`), "[ULID]"},
+}
+
+// snapshotConfig holds the options for a single Snapshot call.
+type snapshotConfig struct {
+	name       string
+	dir        string
+	keys       map[string]bool
+	redactions []redaction
+}
+
+// Option configures Snapshot.
+type Option func(*snapshotConfig)
+
+// Name sets the golden file name (without extension).
+// Default: the test name, with subtest separators replaced by underscores.
+// Use it to take more than one snapshot in a single test.
+func Name(name string) Option {
+	return func(c *snapshotConfig) {
+		c.name = name
+	}
+}
+
+// Dir sets the directory golden files are stored in.
+// Default: DefaultSnapshotDir.
+func Dir(dir string) Option {
+	return func(c *snapshotConfig) {
+		c.dir = dir
+	}
+}
+
+// Redact replaces the values of the given JSON object keys with
+// "[REDACTED]", at any depth. Use it for volatile fields that the default
+// pattern redactions do not catch (e.g., numeric IDs or tokens).
+func Redact(keys ...string) Option {
+	return func(c *snapshotConfig) {
+		for _, k := range keys {
+			c.keys[k] = true
+		}
+	}
+}
+
+// RedactPattern replaces every match of pattern in the canonicalized body
+// with replacement. It applies to JSON, HTML, and plain text bodies.
+func RedactPattern(pattern *regexp.Regexp, replacement string) Option {
+	return func(c *snapshotConfig) {
+		c.redactions = append(c.redactions, redaction{pattern, replacement})
+	}
+}
+
+// Snapshot compares resp against its golden file and fails the test with a
+// line diff if they differ. The snapshot records the status code, the
+// Content-Type header, and the canonicalized body. The response body is
+// restored after reading, so resp can still be inspected afterwards.
+func Snapshot(t testing.TB, resp *http.Response, opts ...Option) {
+	t.Helper()
+
+	config := snapshotConfig{
+		name:       snapshotName(t.Name()),
+		dir:        DefaultSnapshotDir,
+		keys:       make(map[string]bool),
+		redactions: append([]redaction(nil), defaultRedactions...),
+	}
+	for _, opt := range opts {
+		opt(&config)
+	}
+
+	got, err := render(resp, &config)
+	if err != nil {
+		t.Fatalf("rigtest: snapshot %q: %v", config.name, err)
+		return
+	}
+
+	path := filepath.Join(config.dir, config.name+".snap")
+	want, err := os.ReadFile(path)
+	if os.IsNotExist(err) || os.Getenv(UpdateEnv) != "" {
+		if err := os.MkdirAll(config.dir, 0o755); err != nil {
+			t.Fatalf("rigtest: snapshot %q: %v", config.name, err)
+			return
+		}
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatalf("rigtest: snapshot %q: %v", config.name, err)
+			return
+		}
+		t.Logf("rigtest: wrote snapshot %s", path)
+		return
+	}
+	if err != nil {
+		t.Fatalf("rigtest: snapshot %q: %v", config.name, err)
+		return
+	}
+
+	if got != string(want) {
+		t.Errorf("rigtest: response does not match snapshot %s (set %s=1 to update):\n%s",
+			path, UpdateEnv, diff(string(want), got))
+	}
+}
+
+// snapshotName converts a test name into a file name.
+func snapshotName(testName string) string {
+	return strings.NewReplacer("/", "_", " ", "_").Replace(testName)
+}
+
+// render formats resp as snapshot text.
+func render(resp *http.Response, config *snapshotConfig) (string, error) {
+	var body []byte
+	if resp.Body != nil {
+		var err error
+		body, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+		if err != nil {
+			return "", err
+		}
+		resp.Body = io.NopCloser(bytes.NewReader(body))
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	var canonical string
+	switch {
+	case strings.Contains(contentType, "json"):
+		var err error
+		canonical, err = canonicalJSON(body, config.keys)
+		if err != nil {
+			return "", fmt.Errorf("invalid JSON body: %w", err)
+		}
+	case strings.Contains(contentType, "html"):
+		canonical = canonicalHTML(body)
+	default:
+		canonical = strings.ReplaceAll(string(body), "\r\n", "\n")
+	}
+
+	for _, r := range config.redactions {
+		canonical = r.pattern.ReplaceAllString(canonical, r.replacement)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "HTTP %d\n", resp.StatusCode)
+	if contentType != "" {
+		fmt.Fprintf(&b, "Content-Type: %s\n", contentType)
+	}
+	b.WriteString("\n")
+	b.WriteString(canonical)
+	if !strings.HasSuffix(canonical, "\n") {
+		b.WriteString("\n")
+	}
+	return b.String(), nil
+}
+
+// canonicalJSON re-encodes body with sorted keys and two-space indentation,
+// replacing the values of redacted keys.
+func canonicalJSON(body []byte, keys map[string]bool) (string, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return "", nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return "", err
+	}
+
+	out, err := json.MarshalIndent(redactKeys(v, keys), "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// redactKeys replaces the values of keys in every object within v.
+func redactKeys(v any, keys map[string]bool) any {
+	switch val := v.(type) {
+	case map[string]any:
+		for k, item := range val {
+			if keys[k] {
+				val[k] = "[REDACTED]"
+			} else {
+				val[k] = redactKeys(item, keys)
+			}
+		}
+	case []any:
+		for i, item := range val {
+			val[i] = redactKeys(item, keys)
+		}
+	}
+	return v
+}
+
+// canonicalHTML trims surrounding whitespace from every line and drops blank
+// lines, so indentation changes in templates do not break snapshots.
+func canonicalHTML(body []byte) string {
+	var lines []string
+	for line := range strings.SplitSeq(strings.ReplaceAll(string(body), "\r\n", "\n"), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// diff returns a line-by-line comparison of want and got, marking lines
+// only in want with "-" and lines only in got with "+".
+func diff(want, got string) string {
+	wantLines := strings.Split(want, "\n")
+	gotLines := strings.Split(got, "\n")
+
+	var b strings.Builder
+	for i := range max(len(wantLines), len(gotLines)) {
+		var w, g string
+		if i < len(wantLines) {
+			w = wantLines[i]
+		}
+		if i < len(gotLines) {
+			g = gotLines[i]
+		}
+		if w == g {
+			fmt.Fprintf(&b, "  %s\n", w)
+			continue
+		}
+		if i < len(wantLines) {
+			fmt.Fprintf(&b, "- %s\n", w)
+		}
+		if i < len(gotLines) {
+			fmt.Fprintf(&b, "+ %s\n", g)
+		}
+	}
+	return b.String()
+}
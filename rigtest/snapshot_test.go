@@ -0,0 +1,164 @@
+package rigtest
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/cloudresty/rig"
+)
+
+// recordingT captures failures so Snapshot mismatches can be asserted.
+type recordingT struct {
+	testing.TB
+	failed bool
+	output string
+}
+
+func (r *recordingT) Helper() {}
+
+func (r *recordingT) Logf(format string, args ...any) {}
+
+func (r *recordingT) Errorf(format string, args ...any) {
+	r.failed = true
+	r.output = format
+	if len(args) > 0 {
+		r.output = args[len(args)-1].(string)
+	}
+}
+
+func (r *recordingT) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+}
+
+func serve(t *testing.T, h rig.HandlerFunc) *http.Response {
+	t.Helper()
+
+	r := rig.New()
+	r.GET("/", h)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec.Result()
+}
+
+func TestSnapshot_JSON(t *testing.T) {
+	dir := t.TempDir()
+
+	first := serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{
+			"name":       "Alice",
+			"id":         17,
+			"created_at": "2025-01-02T03:04:05Z",
+			"request":    "01ARZ3NDEKTSV4RRFFQ69G5FAV",
+			"tags":       []string{"admin"},
+		})
+	})
+	Snapshot(t, first, Dir(dir), Redact("id"))
+
+	data, err := os.ReadFile(filepath.Join(dir, "TestSnapshot_JSON.snap"))
+	if err != nil {
+		t.Fatalf("golden file not written: %v", err)
+	}
+	want := `HTTP 200
+Content-Type: application/json; charset=utf-8
+
+{
+  "created_at": "[TIMESTAMP]",
+  "id": "[REDACTED]",
+  "name": "Alice",
+  "request": "[ULID]",
+  "tags": [
+    "admin"
+  ]
+}
+`
+	if string(data) != want {
+		t.Errorf("golden file =\n%s\nwant\n%s", data, want)
+	}
+
+	// Volatile values differ, but the snapshot still matches
+	second := serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]any{
+			"tags":       []string{"admin"},
+			"request":    "01HZX4Y7K2M3N4P5Q6R7S8T9VW",
+			"created_at": "2026-07-08T09:10:11.123+02:00",
+			"id":         42,
+			"name":       "Alice",
+		})
+	})
+	rt := &recordingT{TB: t}
+	Snapshot(rt, second, Dir(dir), Name("TestSnapshot_JSON"), Redact("id"))
+	if rt.failed {
+		t.Errorf("matching response failed snapshot:\n%s", rt.output)
+	}
+}
+
+func TestSnapshot_Mismatch(t *testing.T) {
+	dir := t.TempDir()
+
+	Snapshot(t, serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"name": "Alice"})
+	}), Dir(dir), Name("user"))
+
+	rt := &recordingT{TB: t}
+	Snapshot(rt, serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"name": "Bob"})
+	}), Dir(dir), Name("user"))
+
+	if !rt.failed {
+		t.Fatal("expected mismatch to fail the test")
+	}
+	if !strings.Contains(rt.output, `-   "name": "Alice"`) || !strings.Contains(rt.output, `+   "name": "Bob"`) {
+		t.Errorf("diff does not show the change:\n%s", rt.output)
+	}
+}
+
+func TestSnapshot_Update(t *testing.T) {
+	dir := t.TempDir()
+
+	Snapshot(t, serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"v": "1"})
+	}), Dir(dir), Name("versioned"))
+
+	t.Setenv(UpdateEnv, "1")
+	Snapshot(t, serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"v": "2"})
+	}), Dir(dir), Name("versioned"))
+
+	data, _ := os.ReadFile(filepath.Join(dir, "versioned.snap"))
+	if !strings.Contains(string(data), `"v": "2"`) {
+		t.Errorf("snapshot was not updated:\n%s", data)
+	}
+}
+
+func TestSnapshot_HTML(t *testing.T) {
+	dir := t.TempDir()
+
+	Snapshot(t, serve(t, func(c *rig.Context) error {
+		c.Data(http.StatusOK, "text/html; charset=utf-8", []byte("<ul>\n    <li>One</li>\r\n\n    <li>Order 550e8400-e29b-41d4-a716-446655440000</li>\n</ul>\n"))
+		return nil
+	}), Dir(dir), Name("page"), RedactPattern(regexp.MustCompile(`One`), "[FIRST]"))
+
+	data, _ := os.ReadFile(filepath.Join(dir, "page.snap"))
+	want := "HTTP 200\nContent-Type: text/html; charset=utf-8\n\n<ul>\n<li>[FIRST]</li>\n<li>Order [UUID]</li>\n</ul>\n"
+	if string(data) != want {
+		t.Errorf("golden file = %q, want %q", data, want)
+	}
+}
+
+func TestSnapshot_RestoresBody(t *testing.T) {
+	resp := serve(t, func(c *rig.Context) error {
+		return c.JSON(http.StatusOK, map[string]string{"ok": "yes"})
+	})
+	Snapshot(t, resp, Dir(t.TempDir()))
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || !strings.Contains(string(body), `"ok":"yes"`) {
+		t.Errorf("body after Snapshot = %q, %v", body, err)
+	}
+}
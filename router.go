@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"reflect"
+	"slices"
+	"sync/atomic"
 	"syscall"
 	"time"
 )
@@ -63,6 +66,26 @@ type ServerConfig struct {
 	//   config.Logger = func(format string, args ...any) {}
 	// Default: log.Printf
 	Logger LogFunc
+
+	// WarmupRequests are requests (e.g., "GET /products", "/templates/home")
+	// the server sends to itself after the listener binds, so template
+	// parsing, connection pools, and caches are initialized before real
+	// traffic arrives. The method defaults to GET. Warm-up requests carry the
+	// WarmupHeader header. Failures and 5xx responses are logged but do not
+	// stop the server.
+	WarmupRequests []string
+
+	// WarmupFuncs are called after the listener binds and before the warm-up
+	// requests are sent. Errors are logged but do not stop the server.
+	WarmupFuncs []WarmupFunc
+
+	// WarmupTimeout bounds the whole warm-up phase.
+	// Default: 30 seconds.
+	WarmupTimeout time.Duration
+
+	// Health, if set, reports not ready (503) from its ReadyHandler until
+	// warm-up has finished, so load balancers hold traffic back.
+	Health *Health
 }
 
 // DefaultServerConfig returns production-safe default timeouts.
@@ -98,6 +121,13 @@ type StaticConfig struct {
 	//   - "no-cache" (always revalidate)
 	// If empty, no Cache-Control header is set.
 	CacheControl string
+
+	// DisableRanges makes the file server ignore Range requests and always
+	// send the full file with 200 OK, advertising "Accept-Ranges: none".
+	// By default, byte-range requests are
+	// answered with 206 Partial Content, which lets media players seek and
+	// download managers resume large files.
+	DisableRanges bool
 }
 
 // Router wraps http.ServeMux to provide a convenient API for routing
@@ -105,7 +135,27 @@ type StaticConfig struct {
 type Router struct {
 	mux          *http.ServeMux
 	errorHandler ErrorHandler
-	middlewares  []MiddlewareFunc
+	routeErrors  bool
+	middlewares  middlewareChain
+	translator   Translator
+	queryBind    QueryBindConfig
+	binders      map[string]BinderFunc
+	encoders     []encoder
+	validator    Validator
+	flags        FlagProvider
+	permissions  PermissionResolver
+	reporter     Reporter
+	deps         map[reflect.Type]any
+	keyRing      *KeyRing
+	normalize    *NormalizeConfig
+	routes       map[string]RouteInfo
+	redirects    map[string]string
+	rewrites     []rewriteRule
+	maxBodyBytes int64
+	openConns    atomic.Int64
+
+	responseHooks []ResponseHook
+	segmentHooks  []SegmentHook
 }
 
 // New creates a new Router with a fresh http.ServeMux.
@@ -113,7 +163,7 @@ func New() *Router {
 	return &Router{
 		mux:          http.NewServeMux(),
 		errorHandler: DefaultErrorHandler,
-		middlewares:  make([]MiddlewareFunc, 0),
+		queryBind:    DefaultQueryBindConfig(),
 	}
 }
 
@@ -124,20 +174,12 @@ func (r *Router) SetErrorHandler(handler ErrorHandler) {
 }
 
 // Use appends one or more middleware to the router's middleware stack.
-// Middleware are executed in the order they are added.
+// Middleware are executed in the order they are added, after middleware
+// registered with a higher Priority (see UseNamed).
 func (r *Router) Use(mw ...MiddlewareFunc) {
-	r.middlewares = append(r.middlewares, mw...)
-}
-
-// applyMiddleware wraps a handler with all registered middleware.
-// Middleware are applied in reverse order so that the first registered
-// middleware executes first (outermost wrapper).
-func (r *Router) applyMiddleware(handler HandlerFunc) HandlerFunc {
-	// Apply middleware in reverse order
-	for i := len(r.middlewares) - 1; i >= 0; i-- {
-		handler = r.middlewares[i](handler)
+	for _, m := range mw {
+		r.middlewares = r.middlewares.add(m, "")
 	}
-	return handler
 }
 
 // wrap converts a rig.HandlerFunc into a standard http.HandlerFunc.
@@ -145,12 +187,28 @@ func (r *Router) applyMiddleware(handler HandlerFunc) HandlerFunc {
 func (r *Router) wrap(handler HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, req *http.Request) {
 		ctx := newContext(w, req)
+		ctx.router = r
+		if r.maxBodyBytes > 0 {
+			ctx.MaxBodyBytes(r.maxBodyBytes)
+		}
+
+		// Expose the context and error to response hooks
+		tw, tracked := w.(*trackingWriter)
+		if tracked {
+			tw.ctx = ctx
+		}
 
 		if err := handler(ctx); err != nil {
+			if tracked {
+				tw.err = err
+			}
 			// Only call error handler if response hasn't been written
 			if !ctx.Written() {
 				r.errorHandler(ctx, err)
 			}
+			if r.reporter != nil && !ctx.panicReported && ctx.StatusCode() >= http.StatusInternalServerError {
+				r.reporter.ReportError(context.WithoutCancel(ctx.Context()), newErrorReport(ctx, err))
+			}
 		}
 	}
 }
@@ -159,8 +217,17 @@ func (r *Router) wrap(handler HandlerFunc) http.HandlerFunc {
 // The pattern follows Go 1.22+ ServeMux patterns (e.g., "GET /users/{id}").
 // The handler is wrapped with all registered middleware before being added.
 func (r *Router) Handle(pattern string, handler HandlerFunc) {
+	r.handle(pattern, preflightGuard(handler), nil)
+}
+
+// handle registers handler wrapped with the router middleware. groupMiddleware
+// lists the group middleware handler is already wrapped with, so the full
+// chain can be recorded for route introspection.
+func (r *Router) handle(pattern string, handler HandlerFunc, groupMiddleware middlewareChain) {
+	r.recordRoute(pattern, groupMiddleware)
+
 	// Apply middleware chain to the handler
-	wrapped := r.applyMiddleware(handler)
+	wrapped := r.middlewares.apply(handler)
 	r.mux.HandleFunc(pattern, r.wrap(wrapped))
 }
 
@@ -236,6 +303,11 @@ func (r *Router) HEAD(path string, handler HandlerFunc) {
 //	r.Static("/assets", "./public", rig.StaticConfig{
 //	    CacheControl: "public, max-age=31536000", // 1 year
 //	})
+//
+// Files are written directly to the server's http.ResponseWriter, so full
+// responses use sendfile (via io.ReaderFrom) on Linux and other supported
+// platforms. Middleware that wraps the writer should implement io.ReaderFrom
+// to preserve this zero-copy path for large files.
 func (r *Router) Static(path, root string, config ...StaticConfig) {
 	validatePath(path)
 
@@ -259,7 +331,17 @@ func (r *Router) Static(path, root string, config ...StaticConfig) {
 		if cfg.CacheControl != "" {
 			c.SetHeader("Cache-Control", cfg.CacheControl)
 		}
-		fs.ServeHTTP(c.Writer(), c.Request())
+
+		req := c.Request()
+		if !cfg.DisableRanges {
+			fs.ServeHTTP(c.Writer(), req)
+			return nil
+		}
+		if req.Header.Get("Range") != "" {
+			req = req.Clone(req.Context())
+			req.Header.Del("Range")
+		}
+		fs.ServeHTTP(noRangesWriter{c.Writer()}, req)
 		return nil
 	}
 
@@ -271,9 +353,104 @@ func (r *Router) Static(path, root string, config ...StaticConfig) {
 // ServeHTTP implements the http.Handler interface.
 // This allows the Router to be used directly with http.ListenAndServe.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if len(r.responseHooks) > 0 {
+		r.serveWithHooks(w, req)
+		return
+	}
+	r.serve(w, req)
+}
+
+// serve normalizes, rewrites, and routes req.
+func (r *Router) serve(w http.ResponseWriter, req *http.Request) {
+	if r.normalize != nil {
+		if r.normalize.CleanPaths == CleanPathRedirect && cleanRedirect(w, req) {
+			return
+		}
+		normalized, err := normalizeRequest(req, r.normalize)
+		if err != nil {
+			ctx := newContext(w, req)
+			ctx.router = r
+			r.errorHandler(ctx, err)
+			return
+		}
+		req = normalized
+	}
+
+	if r.redirects != nil || r.rewrites != nil {
+		var route bool
+		if req, route = r.applyRewrites(w, req); !route {
+			return
+		}
+	}
+
+	if r.routeErrors {
+		// Unmatched requests are answered by ServeMux itself (404/405);
+		// intercept them so they go through the error handler.
+		if _, pattern := r.mux.Handler(req); pattern == "" {
+			w = &routeErrorWriter{ResponseWriter: w, router: r, req: req}
+		}
+	} else if r.translator != nil {
+		// Unmatched requests are answered by ServeMux itself (404/405);
+		// intercept them so their bodies can be localized.
+		if _, pattern := r.mux.Handler(req); pattern == "" {
+			ctx := newContext(w, req)
+			ctx.router = r
+			w = &localizedErrorWriter{ResponseWriter: w, ctx: ctx}
+		}
+	}
+	if req.Method == http.MethodOptions && r.servePreflight(w, req) {
+		return
+	}
 	r.mux.ServeHTTP(w, req)
 }
 
+// preflightKey is the request context key marking CORS preflights that
+// servePreflight dispatched to a route registered for another method. Its
+// value is the handler ServeMux would have used (405 Method Not Allowed).
+type preflightKey struct{}
+
+// servePreflight answers CORS preflight requests for paths without an
+// OPTIONS route, so CORS middleware can handle them. The preflight runs
+// through the middleware of the route registered for the requested method;
+// the route's handler itself never runs. It reports whether req was served.
+func (r *Router) servePreflight(w http.ResponseWriter, req *http.Request) bool {
+	method := req.Header.Get("Access-Control-Request-Method")
+	if method == "" || req.Header.Get("Origin") == "" {
+		return false
+	}
+	fallback, pattern := r.mux.Handler(req)
+	if pattern != "" {
+		return false // An OPTIONS route exists
+	}
+
+	probe := *req
+	probe.Method = method
+	h, pattern := r.mux.Handler(&probe)
+	if pattern == "" {
+		return false
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), preflightKey{}, fallback))
+	req.Pattern = pattern
+	h.ServeHTTP(w, req)
+	return true
+}
+
+// preflightGuard wraps a route handler, innermost, so preflights dispatched
+// by servePreflight that no middleware answered get ServeMux's usual
+// response instead of running the handler.
+func preflightGuard(handler HandlerFunc) HandlerFunc {
+	return func(c *Context) error {
+		if c.request.Method == http.MethodOptions {
+			if fallback, ok := c.request.Context().Value(preflightKey{}).(http.Handler); ok {
+				fallback.ServeHTTP(c.writer, c.request)
+				return nil
+			}
+		}
+		return handler(c)
+	}
+}
+
 // Handler returns the underlying http.ServeMux as an http.Handler.
 func (r *Router) Handler() http.Handler {
 	return r.mux
@@ -315,8 +492,14 @@ func (r *Router) RunWithConfig(config ServerConfig) error {
 		IdleTimeout:       config.IdleTimeout,
 		ReadHeaderTimeout: config.ReadHeaderTimeout,
 		MaxHeaderBytes:    config.MaxHeaderBytes,
+		ConnState:         r.ConnState,
 	}
-	return server.ListenAndServe()
+
+	logf := config.Logger
+	if logf == nil {
+		logf = log.Printf
+	}
+	return listenAndServe(server, config, logf)
 }
 
 // RunUnsafe starts the HTTP server without any timeouts.
@@ -339,6 +522,8 @@ func (r *Router) RunUnsafe(addr string) error {
 //  2. Stop accepting new connections when a signal is received
 //  3. Wait up to 5 seconds for active requests to complete
 //  4. Forcefully close remaining connections after the timeout
+//  5. Wait for subscribers of events published on Events, within the
+//     same timeout
 //
 // Example:
 //
@@ -375,6 +560,7 @@ func (r *Router) RunWithGracefulShutdown(config ServerConfig) error {
 		IdleTimeout:       config.IdleTimeout,
 		ReadHeaderTimeout: config.ReadHeaderTimeout,
 		MaxHeaderBytes:    config.MaxHeaderBytes,
+		ConnState:         r.ConnState,
 	}
 
 	// Use configured logger, default to log.Printf if not set
@@ -389,7 +575,7 @@ func (r *Router) RunWithGracefulShutdown(config ServerConfig) error {
 	// Start the server in a goroutine so it doesn't block
 	go func() {
 		logf("Rig server listening on %s", config.Addr)
-		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		if err := listenAndServe(server, config, logf); err != nil && !errors.Is(err, http.ErrServerClosed) {
 			serverErrors <- err
 		}
 	}()
@@ -422,6 +608,11 @@ func (r *Router) RunWithGracefulShutdown(config ServerConfig) error {
 		return fmt.Errorf("server forced to shutdown: %w", err)
 	}
 
+	// Let event subscribers triggered by the last requests finish
+	if err := Events().Drain(ctx); err != nil {
+		return fmt.Errorf("event handlers did not finish: %w", err)
+	}
+
 	logf("Server exited gracefully")
 	return nil
 }
@@ -432,9 +623,8 @@ func (r *Router) RunWithGracefulShutdown(config ServerConfig) error {
 func (r *Router) Group(prefix string) *RouteGroup {
 	validatePath(prefix)
 	return &RouteGroup{
-		router:      r,
-		prefix:      prefix,
-		middlewares: make([]MiddlewareFunc, 0),
+		router: r,
+		prefix: prefix,
 	}
 }
 
@@ -443,28 +633,22 @@ func (r *Router) Group(prefix string) *RouteGroup {
 type RouteGroup struct {
 	router      *Router
 	prefix      string
-	middlewares []MiddlewareFunc
+	middlewares middlewareChain
 }
 
 // Use appends one or more middleware to the group's middleware stack.
 // These middleware only apply to routes registered on this group.
 func (g *RouteGroup) Use(mw ...MiddlewareFunc) {
-	g.middlewares = append(g.middlewares, mw...)
-}
-
-// applyMiddleware wraps a handler with all group-specific middleware.
-func (g *RouteGroup) applyMiddleware(handler HandlerFunc) HandlerFunc {
-	for i := len(g.middlewares) - 1; i >= 0; i-- {
-		handler = g.middlewares[i](handler)
+	for _, m := range mw {
+		g.middlewares = g.middlewares.add(m, "")
 	}
-	return handler
 }
 
 // handle is an internal method that applies group middleware before
 // delegating to the router's Handle method.
 func (g *RouteGroup) handle(pattern string, handler HandlerFunc) {
-	wrapped := g.applyMiddleware(handler)
-	g.router.Handle(pattern, wrapped)
+	wrapped := g.middlewares.apply(preflightGuard(handler))
+	g.router.handle(pattern, wrapped, g.middlewares)
 }
 
 // validateGroupPath ensures the path is valid for a route group.
@@ -516,14 +700,10 @@ func (g *RouteGroup) PATCH(path string, handler HandlerFunc) {
 func (g *RouteGroup) Group(prefix string) *RouteGroup {
 	validatePath(prefix)
 
-	// Copy parent middleware to new group
-	newMiddlewares := make([]MiddlewareFunc, len(g.middlewares))
-	copy(newMiddlewares, g.middlewares)
-
 	return &RouteGroup{
 		router:      g.router,
 		prefix:      joinPaths(g.prefix, prefix),
-		middlewares: newMiddlewares,
+		middlewares: slices.Clone(g.middlewares), // Copy parent middleware
 	}
 }
 
@@ -3,10 +3,12 @@ package rig
 import (
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strings"
 	"syscall"
 	"testing"
@@ -1111,6 +1113,109 @@ func TestRouter_Static_WithoutCacheControl(t *testing.T) {
 	}
 }
 
+// readerFromRecorder is a ResponseRecorder that implements io.ReaderFrom,
+// like the server's own response writer, and records whether it was used.
+type readerFromRecorder struct {
+	*httptest.ResponseRecorder
+	readFromCalled bool
+}
+
+func (w *readerFromRecorder) ReadFrom(src io.Reader) (int64, error) {
+	w.readFromCalled = true
+	return io.Copy(w.ResponseRecorder.Body, src)
+}
+
+// writeStaticFile creates a temp directory containing name with content.
+func writeStaticFile(t *testing.T, name, content string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+	return dir
+}
+
+func TestRouter_Static_RangeRequest(t *testing.T) {
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc { return next })
+	r.Static("/media", writeStaticFile(t, "clip.bin", "0123456789"))
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/media/clip.bin", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusPartialContent {
+		t.Fatalf("status = %d, want %d", w.Code, http.StatusPartialContent)
+	}
+	if w.Body.String() != "2345" {
+		t.Errorf("body = %q, want %q", w.Body.String(), "2345")
+	}
+	if cr := w.Header().Get("Content-Range"); cr != "bytes 2-5/10" {
+		t.Errorf("Content-Range = %q, want %q", cr, "bytes 2-5/10")
+	}
+	if ar := w.Header().Get("Accept-Ranges"); ar != "bytes" {
+		t.Errorf("Accept-Ranges = %q, want %q", ar, "bytes")
+	}
+
+	// Unsatisfiable ranges are rejected
+	w = httptest.NewRecorder()
+	req = httptest.NewRequest(http.MethodGet, "/media/clip.bin", nil)
+	req.Header.Set("Range", "bytes=50-60")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusRequestedRangeNotSatisfiable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusRequestedRangeNotSatisfiable)
+	}
+}
+
+func TestRouter_Static_DisableRanges(t *testing.T) {
+	r := New()
+	r.Static("/media", writeStaticFile(t, "clip.bin", "0123456789"), StaticConfig{DisableRanges: true})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/media/clip.bin", nil)
+	req.Header.Set("Range", "bytes=2-5")
+	r.ServeHTTP(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusOK)
+	}
+	if w.Body.String() != "0123456789" {
+		t.Errorf("body = %q, want full file", w.Body.String())
+	}
+	if ar := w.Header().Get("Accept-Ranges"); ar != "none" {
+		t.Errorf("Accept-Ranges = %q, want %q", ar, "none")
+	}
+	if req.Header.Get("Range") == "" {
+		t.Error("original request headers should not be modified")
+	}
+}
+
+func TestRouter_Static_UsesReaderFrom(t *testing.T) {
+	dir := writeStaticFile(t, "video.bin", strings.Repeat("x", 4096))
+
+	r := New()
+	r.Use(func(next HandlerFunc) HandlerFunc { return next })
+	r.Static("/media", dir)
+	r.GET("/download", func(c *Context) error {
+		c.File(filepath.Join(dir, "video.bin"))
+		return nil
+	})
+
+	for _, path := range []string{"/media/video.bin", "/download"} {
+		w := &readerFromRecorder{ResponseRecorder: httptest.NewRecorder()}
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		if w.Code != http.StatusOK || w.Body.Len() != 4096 {
+			t.Errorf("%s: status = %d, body length = %d", path, w.Code, w.Body.Len())
+		}
+		if !w.readFromCalled {
+			t.Errorf("%s: response was not written through io.ReaderFrom (sendfile path)", path)
+		}
+	}
+}
+
 // --- Server Config Tests ---
 
 func TestDefaultServerConfig(t *testing.T) {
@@ -0,0 +1,175 @@
+package rig
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"runtime"
+	"slices"
+	"strings"
+)
+
+// RouteInfo describes a registered route.
+type RouteInfo struct {
+	// Pattern is the ServeMux pattern (e.g., "GET /users/{id}").
+	Pattern string `json:"pattern"`
+
+	// Middleware lists the router and group middleware wrapping the route,
+	// in execution order (outermost first). Names are those given to
+	// UseNamed, or derived from the function that created each middleware
+	// (e.g., "rig.RecoverWithConfig").
+	Middleware []string `json:"middleware"`
+}
+
+// RouteMatch is the result of matching a method and path against the router.
+type RouteMatch struct {
+	Method string `json:"method"`
+	Path   string `json:"path"`
+
+	// Matched reports whether a route handles the request.
+	Matched bool `json:"matched"`
+
+	// Status is the status ServeMux answers unmatched requests with
+	// (404 or 405); it is 0 for matched requests.
+	Status int `json:"status,omitempty"`
+
+	// Allow lists the methods allowed for the path when Status is 405.
+	Allow string `json:"allow,omitempty"`
+
+	// Route is the matched route. Its Middleware is nil if the route was
+	// registered directly on the ServeMux returned by Handler.
+	Route *RouteInfo `json:"route,omitempty"`
+}
+
+// recordRoute stores the middleware chain of a newly registered route.
+func (r *Router) recordRoute(pattern string, groupMiddleware middlewareChain) {
+	if r.routes == nil {
+		r.routes = make(map[string]RouteInfo)
+	}
+
+	names := make([]string, 0, len(r.middlewares)+len(groupMiddleware))
+	for _, mw := range r.middlewares {
+		names = append(names, mw.name)
+	}
+	for _, mw := range groupMiddleware {
+		names = append(names, mw.name)
+	}
+	r.routes[pattern] = RouteInfo{Pattern: pattern, Middleware: names}
+}
+
+// closureSuffix matches the suffixes the compiler gives function literals.
+var closureSuffix = regexp.MustCompile(`(\.func\d+)+$|(\.gowrap\d+)+$`)
+
+// middlewareName returns a readable name for mw, such as
+// "rig.RecoverWithConfig" for the closure returned by RecoverWithConfig.
+func middlewareName(mw MiddlewareFunc) string {
+	fn := runtime.FuncForPC(reflect.ValueOf(mw).Pointer())
+	if fn == nil {
+		return "unknown"
+	}
+	name := closureSuffix.ReplaceAllString(fn.Name(), "")
+	if i := strings.LastIndexByte(name, '/'); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
+
+// Routes returns all routes registered through the router and its groups,
+// sorted by pattern.
+func (r *Router) Routes() []RouteInfo {
+	routes := make([]RouteInfo, 0, len(r.routes))
+	for _, route := range r.routes {
+		routes = append(routes, route)
+	}
+	slices.SortFunc(routes, func(a, b RouteInfo) int {
+		return strings.Compare(a.Pattern, b.Pattern)
+	})
+	return routes
+}
+
+// Match reports which route would handle a request with the given method
+// and path (which may include a query string), and which middleware would
+// run. Request normalization configured with SetNormalization is applied
+// first, as it would be for a real request.
+func (r *Router) Match(method, path string) RouteMatch {
+	result := RouteMatch{Method: method, Path: path}
+
+	req, err := http.NewRequest(method, path, nil)
+	if err != nil {
+		result.Status = http.StatusBadRequest
+		return result
+	}
+	if r.normalize != nil {
+		normalized, err := normalizeRequest(req, r.normalize)
+		if err != nil {
+			result.Status = http.StatusBadRequest
+			return result
+		}
+		req = normalized
+	}
+
+	h, pattern := r.mux.Handler(req)
+	if pattern == "" {
+		// Let ServeMux's own 404/405 handler report the status
+		rec := &statusRecorder{header: make(http.Header)}
+		h.ServeHTTP(rec, req)
+		result.Status = rec.status
+		result.Allow = rec.header.Get("Allow")
+		return result
+	}
+
+	result.Matched = true
+	route, ok := r.routes[pattern]
+	if !ok {
+		route = RouteInfo{Pattern: pattern}
+	}
+	result.Route = &route
+	return result
+}
+
+// statusRecorder is a minimal http.ResponseWriter that keeps the status
+// and headers and discards the body.
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (w *statusRecorder) Header() http.Header { return w.header }
+
+func (w *statusRecorder) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return len(b), nil
+}
+
+func (w *statusRecorder) WriteHeader(code int) {
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// RoutesHandler returns a handler for debugging routing. Without query
+// parameters it lists every route with its middleware chain. With a "path"
+// query parameter (and optional "method", default GET) it reports which
+// route matches and which middleware would run, in order.
+//
+// The output reveals the application's structure, so mount it behind
+// authentication and never expose it publicly.
+//
+// Example:
+//
+//	admin := r.Group("/admin")
+//	admin.Use(auth.APIKeySimple(os.Getenv("ADMIN_KEY")))
+//	admin.GET("/debug/routes", r.RoutesHandler())
+//
+//	// GET /admin/debug/routes?method=POST&path=/api/orders
+func (r *Router) RoutesHandler() HandlerFunc {
+	return func(c *Context) error {
+		path := c.Query("path")
+		if path == "" {
+			return c.JSON(http.StatusOK, map[string]any{"routes": r.Routes()})
+		}
+		return c.JSON(http.StatusOK, r.Match(c.QueryDefault("method", http.MethodGet), path))
+	}
+}
@@ -0,0 +1,101 @@
+package rig
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func tagMiddleware(next HandlerFunc) HandlerFunc {
+	return next
+}
+
+func routesRouter() *Router {
+	r := New()
+	r.Use(Recover())
+	r.GET("/health", func(c *Context) error { return nil })
+
+	api := r.Group("/api")
+	api.Use(tagMiddleware)
+	api.POST("/orders/{id}", func(c *Context) error { return nil })
+
+	// Middleware added later only applies to routes registered afterwards
+	r.Use(Timeout(time.Second))
+	r.GET("/slow", func(c *Context) error { return nil })
+	return r
+}
+
+func TestRouter_Routes(t *testing.T) {
+	got := routesRouter().Routes()
+	want := []RouteInfo{
+		{Pattern: "GET /health", Middleware: []string{"rig.RecoverWithConfig"}},
+		{Pattern: "GET /slow", Middleware: []string{"rig.RecoverWithConfig", "rig.TimeoutWithConfig"}},
+		{Pattern: "POST /api/orders/{id}", Middleware: []string{"rig.RecoverWithConfig", "rig.tagMiddleware"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Routes() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestRouter_Match(t *testing.T) {
+	r := routesRouter()
+
+	m := r.Match(http.MethodPost, "/api/orders/42?dry=1")
+	if !m.Matched || m.Route == nil || m.Route.Pattern != "POST /api/orders/{id}" {
+		t.Fatalf("Match() = %+v", m)
+	}
+	if want := []string{"rig.RecoverWithConfig", "rig.tagMiddleware"}; !reflect.DeepEqual(m.Route.Middleware, want) {
+		t.Errorf("Middleware = %v, want %v", m.Route.Middleware, want)
+	}
+
+	m = r.Match(http.MethodGet, "/api/orders/42")
+	if m.Matched || m.Status != http.StatusMethodNotAllowed || m.Allow != "POST" {
+		t.Errorf("wrong method: %+v", m)
+	}
+
+	m = r.Match(http.MethodGet, "/missing")
+	if m.Matched || m.Status != http.StatusNotFound {
+		t.Errorf("unknown path: %+v", m)
+	}
+}
+
+func TestRouter_Match_Normalization(t *testing.T) {
+	r := routesRouter()
+	r.SetNormalization(NormalizeConfig{LowercasePaths: true})
+
+	if m := r.Match(http.MethodGet, "/HEALTH"); !m.Matched {
+		t.Errorf("Match() should apply normalization: %+v", m)
+	}
+}
+
+func TestRouter_RoutesHandler(t *testing.T) {
+	r := routesRouter()
+	r.GET("/debug/routes", r.RoutesHandler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/routes?method=POST&path=/api/orders/1", nil))
+
+	var m RouteMatch
+	if err := json.Unmarshal(w.Body.Bytes(), &m); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if !m.Matched || m.Route.Pattern != "POST /api/orders/{id}" {
+		t.Errorf("match = %+v", m)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/debug/routes", nil))
+
+	var list struct {
+		Routes []RouteInfo `json:"routes"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &list); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(list.Routes) != 4 {
+		t.Errorf("listed %d routes, want 4", len(list.Routes))
+	}
+}
@@ -0,0 +1,133 @@
+package rig
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"strconv"
+	"strings"
+)
+
+// CSPNonceKey is the context key under which SecureHeaders stores the
+// Content-Security-Policy nonce of the current request. Use c.CSPNonce to
+// read it.
+const CSPNonceKey = "rig.csp_nonce"
+
+// NoncePlaceholder is replaced with a fresh nonce for every request when it
+// appears in SecureHeadersConfig.ContentSecurityPolicy.
+const NoncePlaceholder = "{nonce}"
+
+// SecureHeadersConfig defines the configuration for SecureHeaders middleware.
+// Empty fields use the defaults listed below.
+type SecureHeadersConfig struct {
+	// ContentSecurityPolicy sets the Content-Security-Policy header.
+	// Every occurrence of "{nonce}" is replaced with a random per-request
+	// nonce, which is also available through c.CSPNonce and the render
+	// package's cspNonce template function.
+	// Example: "default-src 'self'; script-src 'self' 'nonce-{nonce}'"
+	// Default: "" (no CSP header).
+	ContentSecurityPolicy string
+
+	// ContentTypeOptions sets the X-Content-Type-Options header.
+	// Default: "nosniff".
+	ContentTypeOptions string
+
+	// FrameOptions sets the X-Frame-Options header.
+	// Default: "DENY".
+	FrameOptions string
+
+	// ReferrerPolicy sets the Referrer-Policy header.
+	// Default: "strict-origin-when-cross-origin".
+	ReferrerPolicy string
+
+	// HSTSMaxAge sets the max-age (in seconds) of the Strict-Transport-Security
+	// header. Only enable this for sites served exclusively over HTTPS.
+	// Default: 0 (no HSTS header).
+	HSTSMaxAge int
+
+	// HSTSIncludeSubdomains adds includeSubDomains to the HSTS header.
+	HSTSIncludeSubdomains bool
+
+	// Skipper selects requests that get none of the headers, and no CSP
+	// nonce.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// SecureHeaders creates middleware that sets common security headers.
+//
+// Example:
+//
+//	r.Use(rig.SecureHeaders(rig.SecureHeadersConfig{
+//	    ContentSecurityPolicy: "default-src 'self'; script-src 'self' 'nonce-{nonce}'",
+//	    HSTSMaxAge:            31536000,
+//	}))
+//
+//	// In templates rendered with the render package:
+//	// <script nonce="{{ cspNonce }}">...</script>
+func SecureHeaders(config SecureHeadersConfig) MiddlewareFunc {
+	if config.ContentTypeOptions == "" {
+		config.ContentTypeOptions = "nosniff"
+	}
+	if config.FrameOptions == "" {
+		config.FrameOptions = "DENY"
+	}
+	if config.ReferrerPolicy == "" {
+		config.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+
+	hsts := ""
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(config.HSTSMaxAge)
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	useNonce := strings.Contains(config.ContentSecurityPolicy, NoncePlaceholder)
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			h := c.Header()
+			h.Set("X-Content-Type-Options", config.ContentTypeOptions)
+			h.Set("X-Frame-Options", config.FrameOptions)
+			h.Set("Referrer-Policy", config.ReferrerPolicy)
+			if hsts != "" {
+				h.Set("Strict-Transport-Security", hsts)
+			}
+
+			if config.ContentSecurityPolicy != "" {
+				csp := config.ContentSecurityPolicy
+				if useNonce {
+					nonce := newCSPNonce()
+					c.Set(CSPNonceKey, nonce)
+					csp = strings.ReplaceAll(csp, NoncePlaceholder, nonce)
+				}
+				h.Set("Content-Security-Policy", csp)
+			}
+
+			return next(c)
+		}
+	}
+}
+
+// CSPNonce returns the Content-Security-Policy nonce generated for the
+// request by SecureHeaders, or an empty string if there is none.
+func (c *Context) CSPNonce() string {
+	if v, ok := c.Get(CSPNonceKey); ok {
+		if nonce, ok := v.(string); ok {
+			return nonce
+		}
+	}
+	return ""
+}
+
+// newCSPNonce returns 128 random bits, base64url-encoded so the nonce is safe
+// in any HTML attribute or script context.
+func newCSPNonce() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return base64.RawURLEncoding.EncodeToString(b)
+}
@@ -0,0 +1,71 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestSecureHeaders_Defaults(t *testing.T) {
+	r := New()
+	r.Use(SecureHeaders(SecureHeadersConfig{}))
+	r.GET("/", func(c *Context) error {
+		if nonce := c.CSPNonce(); nonce != "" {
+			t.Errorf("CSPNonce() = %q, want empty without a CSP", nonce)
+		}
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	want := map[string]string{
+		"X-Content-Type-Options":    "nosniff",
+		"X-Frame-Options":           "DENY",
+		"Referrer-Policy":           "strict-origin-when-cross-origin",
+		"Strict-Transport-Security": "",
+		"Content-Security-Policy":   "",
+	}
+	for header, value := range want {
+		if got := w.Header().Get(header); got != value {
+			t.Errorf("%s = %q, want %q", header, got, value)
+		}
+	}
+}
+
+func TestSecureHeaders_CSPNonce(t *testing.T) {
+	r := New()
+	r.Use(SecureHeaders(SecureHeadersConfig{
+		ContentSecurityPolicy: "script-src 'nonce-{nonce}'; style-src 'nonce-{nonce}'",
+		HSTSMaxAge:            3600,
+		HSTSIncludeSubdomains: true,
+	}))
+
+	var nonces []string
+	r.GET("/", func(c *Context) error {
+		nonces = append(nonces, c.CSPNonce())
+		return nil
+	})
+
+	for range 2 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+		nonce := nonces[len(nonces)-1]
+		if len(nonce) < 20 || strings.ContainsAny(nonce, "+/=") {
+			t.Errorf("nonce %q should be 128-bit base64url", nonce)
+		}
+		wantCSP := "script-src 'nonce-" + nonce + "'; style-src 'nonce-" + nonce + "'"
+		if csp := w.Header().Get("Content-Security-Policy"); csp != wantCSP {
+			t.Errorf("Content-Security-Policy = %q, want %q", csp, wantCSP)
+		}
+		if hsts := w.Header().Get("Strict-Transport-Security"); hsts != "max-age=3600; includeSubDomains" {
+			t.Errorf("Strict-Transport-Security = %q", hsts)
+		}
+	}
+
+	if nonces[0] == nonces[1] {
+		t.Error("each request should get a fresh nonce")
+	}
+}
@@ -0,0 +1,128 @@
+package rig
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// segmentsKey is the context key under which OnSegment records segments.
+const segmentsKey = "rig.segments"
+
+// serverTimingKey is the context key under which ServerTiming enables the
+// Server-Timing header.
+const serverTimingKey = "rig.server_timing"
+
+// Segment is a named, timed part of a handler recorded by OnSegment.
+type Segment struct {
+	Name     string
+	Start    time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// SegmentHook is called when a segment starts. The returned function, if
+// not nil, is called with the finished segment. Register one with
+// Router.OnSegmentStart to create trace spans for segments.
+type SegmentHook func(c *Context, name string) func(Segment)
+
+// OnSegmentStart registers a hook called at the start of every segment
+// recorded with OnSegment, typically to start a trace span. The hook may
+// replace the request context with c.SetContext (e.g., with the span's
+// context) so calls made within the segment are attributed to it; the
+// previous context is restored when the segment ends.
+//
+// Example:
+//
+//	r.OnSegmentStart(func(c *rig.Context, name string) func(rig.Segment) {
+//	    ctx, span := tracer.Start(c.Context(), name)
+//	    c.SetContext(ctx)
+//	    return func(s rig.Segment) {
+//	        if s.Err != nil {
+//	            span.RecordError(s.Err)
+//	        }
+//	        span.End()
+//	    }
+//	})
+func (r *Router) OnSegmentStart(hook SegmentHook) {
+	r.segmentHooks = append(r.segmentHooks, hook)
+}
+
+// OnSegment runs fn as a named segment of the handler (e.g., "db" or
+// "render") and returns its error. The segment's duration is recorded for
+// the logger middleware (see Segments), added to the Server-Timing header
+// when ServerTiming is in use, and reported to hooks registered with
+// Router.OnSegmentStart, giving per-request breakdowns without a full APM.
+//
+// Example:
+//
+//	var user User
+//	err := rig.OnSegment(c, "db", func() error {
+//	    return db.QueryRowContext(c.Context(), query, id).Scan(&user.Name)
+//	})
+func OnSegment(c *Context, name string, fn func() error) error {
+	var ends []func(Segment)
+	if c.router != nil && len(c.router.segmentHooks) > 0 {
+		prev := c.Context()
+		defer func() { c.SetContext(prev) }()
+		for _, hook := range c.router.segmentHooks {
+			if end := hook(c, name); end != nil {
+				ends = append(ends, end)
+			}
+		}
+	}
+
+	seg := Segment{Name: name, Start: time.Now()}
+	seg.Err = fn()
+	seg.Duration = time.Since(seg.Start)
+
+	for i := len(ends) - 1; i >= 0; i-- {
+		ends[i](seg)
+	}
+
+	segments, _ := GetType[[]Segment](c, segmentsKey)
+	c.Set(segmentsKey, append(segments, seg))
+
+	if enabled, _ := GetType[bool](c, serverTimingKey); enabled && !c.Written() {
+		c.Header().Add("Server-Timing", serverTimingMetric(name, seg.Duration))
+	}
+	return seg.Err
+}
+
+// Segments returns the segments recorded with OnSegment for the request,
+// in the order they finished.
+func Segments(c *Context) []Segment {
+	segments, _ := GetType[[]Segment](c, segmentsKey)
+	return segments
+}
+
+// ServerTiming creates middleware that adds a Server-Timing header entry
+// (e.g., "db;dur=12.5") for each segment recorded with OnSegment, so browser
+// developer tools show the breakdown. Segments finishing after the response
+// is written are not included. Timings reveal internal details, so consider
+// enabling it only for internal clients (see When).
+//
+// Example:
+//
+//	r.Use(rig.When(isInternal, rig.ServerTiming()))
+func ServerTiming() MiddlewareFunc {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			c.Set(serverTimingKey, true)
+			return next(c)
+		}
+	}
+}
+
+// serverTimingMetric formats a Server-Timing metric. Characters not allowed
+// in a token are replaced with "_".
+func serverTimingMetric(name string, d time.Duration) string {
+	name = strings.Map(func(r rune) rune {
+		if r < 0x80 && (r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || strings.ContainsRune("!#$%&'*+-.^_`|~", r)) {
+			return r
+		}
+		return '_'
+	}, name)
+	ms := strconv.FormatFloat(float64(d.Microseconds())/1000, 'f', -1, 64)
+	return name + ";dur=" + ms
+}
@@ -0,0 +1,75 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOnSegment(t *testing.T) {
+	type spanKey struct{}
+	errNotFound := errors.New("not found")
+
+	var started []string
+	var ended []Segment
+	var inSegment any
+
+	r := New()
+	r.OnSegmentStart(func(c *Context, name string) func(Segment) {
+		started = append(started, name)
+		c.SetContext(context.WithValue(c.Context(), spanKey{}, name))
+		return func(s Segment) { ended = append(ended, s) }
+	})
+	r.Use(ServerTiming())
+
+	var segments []Segment
+	var afterSegment any
+	r.GET("/", func(c *Context) error {
+		_ = OnSegment(c, "db query", func() error {
+			inSegment = c.Context().Value(spanKey{})
+			return nil
+		})
+		afterSegment = c.Context().Value(spanKey{})
+		if err := OnSegment(c, "cache", func() error { return errNotFound }); !errors.Is(err, errNotFound) {
+			t.Errorf("OnSegment error = %v, want %v", err, errNotFound)
+		}
+		segments = Segments(c)
+		return c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if len(segments) != 2 || segments[0].Name != "db query" || !errors.Is(segments[1].Err, errNotFound) {
+		t.Fatalf("Segments = %+v", segments)
+	}
+	if strings.Join(started, ",") != "db query,cache" || len(ended) != 2 {
+		t.Errorf("hooks: started %v, ended %d", started, len(ended))
+	}
+	if inSegment != "db query" || afterSegment != nil {
+		t.Errorf("context in segment = %v, after = %v", inSegment, afterSegment)
+	}
+
+	timing := w.Header().Values("Server-Timing")
+	if len(timing) != 2 || !strings.HasPrefix(timing[0], "db_query;dur=") || !strings.HasPrefix(timing[1], "cache;dur=") {
+		t.Errorf("Server-Timing = %v", timing)
+	}
+}
+
+func TestOnSegment_ServerTimingOptIn(t *testing.T) {
+	r := New()
+	r.GET("/", func(c *Context) error {
+		_ = OnSegment(c, "db", func() error { return nil })
+		return c.String(http.StatusOK, "ok")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if w.Header().Get("Server-Timing") != "" {
+		t.Errorf("Server-Timing = %q without ServerTiming middleware", w.Header().Get("Server-Timing"))
+	}
+}
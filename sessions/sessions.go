@@ -0,0 +1,264 @@
+// Package sessions provides cookie-based sessions for the rig HTTP library.
+//
+// Session values are kept in a Store: MemoryStore keeps them in the
+// process, CookieStore keeps them encrypted in the cookie itself, and the
+// Store interface lets Redis or SQL backends share sessions across replicas.
+// Values are stored as JSON, so every store returns them with the same
+// types: numbers come back as float64, and structs as map[string]any.
+//
+// # Basic Usage
+//
+//	r := rig.New()
+//	r.Use(sessions.New(sessions.NewMemoryStore()))
+//
+//	r.POST("/login", func(c *rig.Context) error {
+//	    // ... authenticate ...
+//	    s := sessions.Get(c)
+//	    if err := s.Regenerate(); err != nil { // Prevent session fixation
+//	        return err
+//	    }
+//	    s.Set("user_id", user.ID)
+//	    if err := s.Save(); err != nil {
+//	        return err
+//	    }
+//	    return c.Redirect(http.StatusSeeOther, "/")
+//	})
+//
+//	r.GET("/", func(c *rig.Context) error {
+//	    userID, _ := sessions.Get(c).Get("user_id").(string)
+//	    // ...
+//	})
+//
+// # Saving
+//
+// Changes are not persisted until Save is called, which also sets the
+// session cookie. Call it before writing the response body, since cookies
+// are sent with the headers.
+package sessions
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"maps"
+	"net/http"
+	"time"
+
+	"github.com/cloudresty/rig"
+)
+
+// ContextKey is the context key under which the middleware stores the
+// request's *Session.
+const ContextKey = "sessions.session"
+
+// ErrNotFound is returned by Store.Load when no session exists for an ID,
+// or it has expired or cannot be verified. The middleware then starts a new
+// session.
+var ErrNotFound = errors.New("sessions: session not found")
+
+// Store persists session data. Implementations must be safe for concurrent
+// use.
+type Store interface {
+	// Load returns the data saved for id, or ErrNotFound.
+	Load(ctx context.Context, id string) ([]byte, error)
+
+	// Save stores data for id, or for a new session if id is empty, and
+	// returns the ID to send in the session cookie. The data must expire
+	// after ttl.
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) (string, error)
+
+	// Delete removes the session with the given ID, if it exists.
+	Delete(ctx context.Context, id string) error
+}
+
+// Config defines the configuration for the sessions middleware.
+type Config struct {
+	// CookieName is the name of the session cookie.
+	// Default: "session".
+	CookieName string
+
+	// MaxAge is how long a session lives after it was last saved. It sets
+	// the cookie's Max-Age and the Store's ttl.
+	// Default: 24 hours.
+	MaxAge time.Duration
+
+	// Path and Domain scope the session cookie.
+	// Default: Path "/", no Domain.
+	Path   string
+	Domain string
+
+	// Secure sends the cookie only over HTTPS. It is always set for
+	// requests served over HTTPS (see rig.Context.Secure); enable it to
+	// also keep it for plain HTTP requests behind a TLS-terminating proxy
+	// that does not report the scheme.
+	// Default: false.
+	Secure bool
+
+	// SameSite sets the cookie's SameSite attribute.
+	// Default: http.SameSiteLaxMode.
+	SameSite http.SameSite
+}
+
+// Session holds the values of a single client's session.
+type Session struct {
+	id     string
+	values map[string]any
+	isNew  bool
+	c      *rig.Context
+	store  Store
+	config *Config
+}
+
+// New creates middleware that loads the session identified by the request's
+// session cookie from store, or starts a new one, and makes it available
+// through Get. Requests with missing, expired, or invalid cookies get a new,
+// empty session. Errors from the store other than ErrNotFound are returned
+// to the error handler.
+//
+// Example:
+//
+//	r.Use(sessions.New(sessions.NewMemoryStore(), sessions.Config{
+//	    CookieName: "sid",
+//	    MaxAge:     7 * 24 * time.Hour,
+//	    Secure:     true,
+//	}))
+func New(store Store, config ...Config) rig.MiddlewareFunc {
+	cfg := Config{}
+	if len(config) > 0 {
+		cfg = config[0]
+	}
+	if cfg.CookieName == "" {
+		cfg.CookieName = "session"
+	}
+	if cfg.MaxAge <= 0 {
+		cfg.MaxAge = 24 * time.Hour
+	}
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	return func(next rig.HandlerFunc) rig.HandlerFunc {
+		return func(c *rig.Context) error {
+			s := &Session{values: make(map[string]any), isNew: true, c: c, store: store, config: &cfg}
+
+			if cookie, err := c.Cookie(cfg.CookieName); err == nil && cookie.Value != "" {
+				data, err := store.Load(c.Context(), cookie.Value)
+				switch {
+				case err == nil:
+					if err := json.Unmarshal(data, &s.values); err == nil {
+						s.id = cookie.Value
+						s.isNew = false
+					}
+				case !errors.Is(err, ErrNotFound):
+					return err
+				}
+			}
+
+			c.Set(ContextKey, s)
+			return next(c)
+		}
+	}
+}
+
+// Get returns the request's session. It panics if the sessions middleware
+// is not installed.
+func Get(c *rig.Context) *Session {
+	s, err := rig.GetType[*Session](c, ContextKey)
+	if err != nil {
+		panic("sessions: Get called without the sessions middleware")
+	}
+	return s
+}
+
+// ID returns the session's ID in the store, or an empty string for a
+// session that has not been saved yet.
+func (s *Session) ID() string {
+	return s.id
+}
+
+// IsNew reports whether the session was started by this request.
+func (s *Session) IsNew() bool {
+	return s.isNew
+}
+
+// Get returns the value stored under key, or nil.
+func (s *Session) Get(key string) any {
+	return s.values[key]
+}
+
+// Set stores value under key. The value must be encodable as JSON.
+func (s *Session) Set(key string, value any) {
+	s.values[key] = value
+}
+
+// Delete removes the value stored under key.
+func (s *Session) Delete(key string) {
+	delete(s.values, key)
+}
+
+// Values returns a copy of all values in the session.
+func (s *Session) Values() map[string]any {
+	return maps.Clone(s.values)
+}
+
+// Save persists the session's values and sets the session cookie. It
+// extends the session's lifetime by Config.MaxAge.
+func (s *Session) Save() error {
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		return err
+	}
+	id, err := s.store.Save(s.c.Context(), s.id, data, s.config.MaxAge)
+	if err != nil {
+		return err
+	}
+	s.id = id
+	s.setCookie(id, int(s.config.MaxAge/time.Second))
+	return nil
+}
+
+// Destroy deletes the session from the store, clears its values, and
+// expires the session cookie, e.g., on logout.
+func (s *Session) Destroy() error {
+	if s.id != "" {
+		if err := s.store.Delete(s.c.Context(), s.id); err != nil {
+			return err
+		}
+	}
+	s.id = ""
+	s.values = make(map[string]any)
+	s.setCookie("", -1)
+	return nil
+}
+
+// Regenerate deletes the session from the store under its current ID, so
+// the next Save stores its values under a new one. Call it when the
+// session's privilege level changes, such as on login, to prevent session
+// fixation.
+func (s *Session) Regenerate() error {
+	if s.id == "" {
+		return nil
+	}
+	if err := s.store.Delete(s.c.Context(), s.id); err != nil {
+		return err
+	}
+	s.id = ""
+	return nil
+}
+
+// setCookie sets the session cookie to value with the given Max-Age.
+func (s *Session) setCookie(value string, maxAge int) {
+	s.c.SetCookie(&http.Cookie{
+		Name:     s.config.CookieName,
+		Value:    value,
+		Path:     s.config.Path,
+		Domain:   s.config.Domain,
+		MaxAge:   maxAge,
+		Secure:   s.config.Secure || s.c.Secure(),
+		HttpOnly: true,
+		SameSite: s.config.SameSite,
+	})
+}
@@ -0,0 +1,229 @@
+package sessions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cloudresty/rig"
+)
+
+var testSecret = []byte("0123456789abcdef0123456789abcdef")
+
+func sessionRouter(store Store, config ...Config) *rig.Router {
+	r := rig.New()
+	r.Use(New(store, config...))
+
+	r.POST("/login", func(c *rig.Context) error {
+		s := Get(c)
+		if err := s.Regenerate(); err != nil {
+			return err
+		}
+		s.Set("user", c.Query("user"))
+		s.Set("visits", 1)
+		if err := s.Save(); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "logged in")
+	})
+	r.GET("/", func(c *rig.Context) error {
+		s := Get(c)
+		return c.String(http.StatusOK, "user=%v visits=%v new=%t", s.Get("user"), s.Get("visits"), s.IsNew())
+	})
+	r.POST("/forget", func(c *rig.Context) error {
+		s := Get(c)
+		s.Delete("user")
+		if err := s.Save(); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "%v", s.Values())
+	})
+	r.POST("/logout", func(c *rig.Context) error {
+		if err := Get(c).Destroy(); err != nil {
+			return err
+		}
+		return c.String(http.StatusOK, "logged out")
+	})
+	return r
+}
+
+// do serves a request with the given session cookie value and returns the
+// response and the session cookie it set, if any.
+func do(r *rig.Router, method, target, session string) (*httptest.ResponseRecorder, *http.Cookie) {
+	req := httptest.NewRequest(method, target, nil)
+	if session != "" {
+		req.AddCookie(&http.Cookie{Name: "session", Value: session})
+	}
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var cookie *http.Cookie
+	for _, c := range w.Result().Cookies() {
+		if c.Name == "session" {
+			cookie = c
+		}
+	}
+	return w, cookie
+}
+
+func TestSessions(t *testing.T) {
+	keys, _ := rig.NewKeyRing(testSecret)
+	stores := map[string]Store{
+		"memory": NewMemoryStore(),
+		"cookie": NewCookieStore(keys),
+	}
+
+	for name, store := range stores {
+		t.Run(name, func(t *testing.T) {
+			r := sessionRouter(store)
+
+			if w, cookie := do(r, http.MethodGet, "/", ""); w.Body.String() != "user=<nil> visits=<nil> new=true" || cookie != nil {
+				t.Errorf("no session: body = %q, cookie = %v", w.Body.String(), cookie)
+			}
+
+			_, cookie := do(r, http.MethodPost, "/login?user=ada", "")
+			if cookie == nil || cookie.Value == "" {
+				t.Fatal("login did not set a session cookie")
+			}
+			if !cookie.HttpOnly || cookie.SameSite != http.SameSiteLaxMode || cookie.Path != "/" || cookie.MaxAge != 86400 {
+				t.Errorf("cookie = %+v", cookie)
+			}
+
+			// Values round-trip through JSON
+			w, _ := do(r, http.MethodGet, "/", cookie.Value)
+			if w.Body.String() != "user=ada visits=1 new=false" {
+				t.Errorf("body = %q", w.Body.String())
+			}
+
+			w, updated := do(r, http.MethodPost, "/forget", cookie.Value)
+			if w.Body.String() != "map[visits:1]" || updated == nil {
+				t.Errorf("forget: body = %q, cookie = %v", w.Body.String(), updated)
+			}
+
+			w, expired := do(r, http.MethodPost, "/logout", updated.Value)
+			if w.Code != http.StatusOK || expired == nil || expired.MaxAge != -1 {
+				t.Errorf("logout: status = %d, cookie = %v", w.Code, expired)
+			}
+
+			if w, _ := do(r, http.MethodGet, "/", "tampered"+cookie.Value); !strings.HasSuffix(w.Body.String(), "new=true") {
+				t.Errorf("invalid cookie: body = %q, want a new session", w.Body.String())
+			}
+		})
+	}
+}
+
+func TestSessions_MemoryStore(t *testing.T) {
+	store := NewMemoryStore()
+	r := sessionRouter(store)
+
+	_, first := do(r, http.MethodPost, "/login?user=ada", "")
+	// Logging in again regenerates the ID and invalidates the old one
+	_, second := do(r, http.MethodPost, "/login?user=bob", first.Value)
+	if second.Value == first.Value {
+		t.Error("Regenerate did not change the session ID")
+	}
+	if w, _ := do(r, http.MethodGet, "/", first.Value); !strings.HasSuffix(w.Body.String(), "new=true") {
+		t.Errorf("old ID: body = %q, want a new session", w.Body.String())
+	}
+
+	// Logout removes the session from the store
+	do(r, http.MethodPost, "/logout", second.Value)
+	if _, err := store.Load(context.Background(), second.Value); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() after logout error = %v, want ErrNotFound", err)
+	}
+
+	// Expired sessions are not loaded, and are swept
+	ctx := context.Background()
+	id, _ := store.Save(ctx, "", []byte(`{}`), -time.Second)
+	if _, err := store.Load(ctx, id); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() expired error = %v, want ErrNotFound", err)
+	}
+	store.lastSweep = time.Time{}
+	_, _ = store.Save(ctx, "", []byte(`{}`), time.Hour)
+	if _, ok := store.sessions[id]; ok {
+		t.Error("expired session was not swept")
+	}
+}
+
+func TestSessions_CookieStore(t *testing.T) {
+	keys, _ := rig.NewKeyRing(testSecret)
+	store := NewCookieStore(keys)
+	ctx := context.Background()
+
+	value, err := store.Save(ctx, "", []byte(`{"a":1}`), -time.Second)
+	if err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	if _, err := store.Load(ctx, value); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Load() expired error = %v, want ErrNotFound", err)
+	}
+
+	large := fmt.Sprintf(`{"a":%q}`, strings.Repeat("x", 4000))
+	if _, err := store.Save(ctx, "", []byte(large), time.Hour); !errors.Is(err, ErrCookieTooLarge) {
+		t.Errorf("Save() large error = %v, want ErrCookieTooLarge", err)
+	}
+}
+
+func TestSessions_Config(t *testing.T) {
+	r := sessionRouter(NewMemoryStore(), Config{
+		CookieName: "sid",
+		MaxAge:     time.Hour,
+		Path:       "/app",
+		Secure:     true,
+		SameSite:   http.SameSiteStrictMode,
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login?user=ada", nil))
+	cookies := w.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("cookies = %v, want 1", cookies)
+	}
+	c := cookies[0]
+	if c.Name != "sid" || c.MaxAge != 3600 || c.Path != "/app" || !c.Secure || c.SameSite != http.SameSiteStrictMode {
+		t.Errorf("cookie = %+v", c)
+	}
+}
+
+func TestSessions_StoreError(t *testing.T) {
+	r := sessionRouter(failingStore{})
+
+	w, _ := do(r, http.MethodGet, "/", "some-id")
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want 500", w.Code)
+	}
+}
+
+func TestGet_WithoutMiddleware(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic")
+		}
+	}()
+	r := rig.New()
+	r.GET("/", func(c *rig.Context) error {
+		Get(c)
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+}
+
+// failingStore is a Store whose backend is unavailable.
+type failingStore struct{}
+
+func (failingStore) Load(context.Context, string) ([]byte, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingStore) Save(context.Context, string, []byte, time.Duration) (string, error) {
+	return "", errors.New("connection refused")
+}
+
+func (failingStore) Delete(context.Context, string) error {
+	return errors.New("connection refused")
+}
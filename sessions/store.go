@@ -0,0 +1,151 @@
+package sessions
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/cloudresty/rig"
+)
+
+// memorySweepInterval is how often MemoryStore removes expired sessions.
+const memorySweepInterval = time.Minute
+
+// memoryEntry is a session stored by MemoryStore.
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// MemoryStore keeps sessions in the process's memory. Sessions are lost on
+// restart and not shared between replicas, so it suits development and
+// single-instance services. Expired sessions are removed periodically.
+type MemoryStore struct {
+	mu        sync.Mutex
+	sessions  map[string]memoryEntry
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		sessions:  make(map[string]memoryEntry),
+		lastSweep: time.Now(),
+	}
+}
+
+// Load implements Store.
+func (m *MemoryStore) Load(_ context.Context, id string) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.sessions[id]
+	if !ok || time.Now().After(entry.expires) {
+		return nil, ErrNotFound
+	}
+	return entry.data, nil
+}
+
+// Save implements Store. New sessions get a random 128-bit ID.
+func (m *MemoryStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(m.lastSweep) >= memorySweepInterval {
+		for key, entry := range m.sessions {
+			if now.After(entry.expires) {
+				delete(m.sessions, key)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	if id == "" {
+		id = rand.Text()
+	}
+	m.sessions[id] = memoryEntry{data: data, expires: now.Add(ttl)}
+	return id, nil
+}
+
+// Delete implements Store.
+func (m *MemoryStore) Delete(_ context.Context, id string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, id)
+	return nil
+}
+
+// maxCookieSize is the largest session cookie value CookieStore produces;
+// browsers drop cookies larger than about 4 KB.
+const maxCookieSize = 4000
+
+// ErrCookieTooLarge is returned by CookieStore.Save when the encrypted
+// session does not fit in a cookie. Store less data, or use a server-side
+// store.
+var ErrCookieTooLarge = errors.New("sessions: session data too large for a cookie")
+
+// cookieStoreAD is the additional data authenticated with every session
+// encrypted by CookieStore.
+var cookieStoreAD = []byte("rig-session")
+
+// CookieStore keeps session data in the session cookie itself, encrypted
+// and authenticated with a rig.KeyRing, so no server-side storage is
+// needed. The session's ID is the encrypted data, and it changes on every
+// Save. Sessions cannot be revoked before they expire: Delete only clears
+// the cookie, and a copy of an earlier cookie stays valid until its MaxAge
+// has passed. Sessions are limited to about 4 KB.
+type CookieStore struct {
+	keys *rig.KeyRing
+}
+
+// NewCookieStore creates a CookieStore that encrypts sessions with keys.
+// Rotate keys as described for rig.KeyRing.
+//
+// Example:
+//
+//	keys, err := rig.NewKeyRing([]byte(os.Getenv("SESSION_KEY")))
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	r.Use(sessions.New(sessions.NewCookieStore(keys)))
+func NewCookieStore(keys *rig.KeyRing) *CookieStore {
+	return &CookieStore{keys: keys}
+}
+
+// Load implements Store.
+func (s *CookieStore) Load(_ context.Context, id string) ([]byte, error) {
+	plaintext, err := s.keys.Decrypt(id, cookieStoreAD)
+	if err != nil || len(plaintext) < 8 {
+		return nil, ErrNotFound
+	}
+	expires := time.Unix(int64(binary.BigEndian.Uint64(plaintext)), 0)
+	if time.Now().After(expires) {
+		return nil, ErrNotFound
+	}
+	return plaintext[8:], nil
+}
+
+// Save implements Store. It ignores id and returns the encrypted data.
+func (s *CookieStore) Save(_ context.Context, _ string, data []byte, ttl time.Duration) (string, error) {
+	plaintext := binary.BigEndian.AppendUint64(nil, uint64(time.Now().Add(ttl).Unix()))
+	plaintext = append(plaintext, data...)
+	value, err := s.keys.Encrypt(plaintext, cookieStoreAD)
+	if err != nil {
+		return "", err
+	}
+	if len(value) > maxCookieSize {
+		return "", ErrCookieTooLarge
+	}
+	return value, nil
+}
+
+// Delete implements Store. It is a no-op: the middleware expires the
+// cookie.
+func (s *CookieStore) Delete(context.Context, string) error {
+	return nil
+}
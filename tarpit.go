@@ -0,0 +1,187 @@
+package rig
+
+import (
+	"sync"
+	"time"
+)
+
+// TarpitConfig defines the configuration for Tarpit middleware.
+type TarpitConfig struct {
+	// Threshold is the number of requests per key and Window that are
+	// served without delay. Required.
+	Threshold int
+
+	// Window is the period over which requests are counted.
+	// Default: 1 minute.
+	Window time.Duration
+
+	// Delay is the delay added to the first request over Threshold. Each
+	// further request in the same window is delayed by Delay more.
+	// Default: 1 second.
+	Delay time.Duration
+
+	// MaxDelay caps the delay of a single request.
+	// Default: 30 seconds.
+	MaxDelay time.Duration
+
+	// KeyFunc returns the key requests are counted against, such as the
+	// client IP or the submitted username. Requests for which it returns an
+	// empty string are neither counted nor delayed.
+	// Default: RateLimitByIP.
+	KeyFunc func(c *Context) string
+
+	// Counted reports whether a handled request counts toward Threshold,
+	// given the handler's error. Use it to count only failed logins.
+	// Requests count from the moment they arrive, so parallel bursts are
+	// delayed too; those it rejects are uncounted once handled.
+	// Default: every request counts.
+	Counted func(c *Context, err error) bool
+
+	// Skipper selects requests that are neither counted nor delayed.
+	// Default: nil (no request is skipped).
+	Skipper Skipper
+}
+
+// Tarpit creates middleware that progressively slows down clients making
+// more than threshold requests per window, instead of rejecting them: the
+// first request over the threshold is delayed by one second, the next by
+// two, and so on, up to 30 seconds. Attackers guessing passwords or
+// stuffing credentials are slowed to a crawl, while legitimate users who
+// mistype a few times barely notice. Combine it with RateLimit, set to a
+// higher limit, for a hard cap.
+//
+// Requests are counted per client IP, in the process's memory. Register
+// rig.RealIP first when running behind a proxy.
+//
+// Example:
+//
+//	// Slow down clients making more than 5 login attempts per minute
+//	r.POST("/login", rig.Tarpit(5, time.Minute)(login))
+func Tarpit(threshold int, window time.Duration) MiddlewareFunc {
+	return TarpitWithConfig(TarpitConfig{Threshold: threshold, Window: window})
+}
+
+// TarpitWithConfig creates tarpit middleware with custom configuration.
+// See Tarpit for the behavior. It panics if config.Threshold is negative.
+//
+// Example:
+//
+//	// Count failed logins per username, whichever IP they come from
+//	r.POST("/login", rig.TarpitWithConfig(rig.TarpitConfig{
+//	    Threshold: 3,
+//	    Window:    15 * time.Minute,
+//	    Delay:     2 * time.Second,
+//	    KeyFunc: func(c *rig.Context) string {
+//	        return strings.ToLower(c.FormValue("username"))
+//	    },
+//	    Counted: func(c *rig.Context, err error) bool {
+//	        return err != nil || c.StatusCode() >= 400
+//	    },
+//	})(login))
+func TarpitWithConfig(config TarpitConfig) MiddlewareFunc {
+	if config.Threshold < 0 {
+		panic("rig: Tarpit requires a non-negative Threshold")
+	}
+	if config.Window <= 0 {
+		config.Window = time.Minute
+	}
+	if config.Delay <= 0 {
+		config.Delay = time.Second
+	}
+	if config.MaxDelay <= 0 {
+		config.MaxDelay = 30 * time.Second
+	}
+	if config.KeyFunc == nil {
+		config.KeyFunc = RateLimitByIP
+	}
+
+	counter := &tarpitCounter{
+		window:    config.Window,
+		counts:    make(map[string]*tarpitCount),
+		lastSweep: time.Now(),
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if config.Skipper != nil && config.Skipper(c) {
+				return next(c)
+			}
+
+			key := config.KeyFunc(c)
+			if key == "" {
+				return next(c)
+			}
+
+			// Count the request on entry, so a burst of parallel requests
+			// is delayed too, and release it if Counted says otherwise
+			n, start := counter.reserve(key, time.Now())
+			if over := n - config.Threshold; over >= 0 {
+				delay := min(config.MaxDelay, config.Delay*time.Duration(over+1))
+				timer := time.NewTimer(delay)
+				select {
+				case <-timer.C:
+				case <-c.Context().Done():
+					timer.Stop()
+					return c.Context().Err()
+				}
+			}
+
+			err := next(c)
+			if config.Counted != nil && !config.Counted(c, err) {
+				counter.release(key, start)
+			}
+			return err
+		}
+	}
+}
+
+// tarpitCounter counts requests per key in fixed windows.
+type tarpitCounter struct {
+	window time.Duration
+
+	mu        sync.Mutex
+	counts    map[string]*tarpitCount
+	lastSweep time.Time
+}
+
+// tarpitCount is the number of requests of one key in the window starting
+// at start.
+type tarpitCount struct {
+	n     int
+	start time.Time
+}
+
+// reserve counts a request for key at time now. It returns the number of
+// requests counted before it in the window, and the window's start.
+func (t *tarpitCounter) reserve(key string, now time.Time) (int, time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	// Remove expired windows, so idle keys don't accumulate
+	if now.Sub(t.lastSweep) >= t.window {
+		for k, count := range t.counts {
+			if now.Sub(count.start) >= t.window {
+				delete(t.counts, k)
+			}
+		}
+		t.lastSweep = now
+	}
+
+	count, ok := t.counts[key]
+	if !ok || now.Sub(count.start) >= t.window {
+		count = &tarpitCount{start: now}
+		t.counts[key] = count
+	}
+	count.n++
+	return count.n - 1, count.start
+}
+
+// release uncounts a request reserved for key in the window starting at
+// start. Requests from an earlier window are already gone.
+func (t *tarpitCounter) release(key string, start time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if count, ok := t.counts[key]; ok && count.start.Equal(start) && count.n > 0 {
+		count.n--
+	}
+}
@@ -0,0 +1,125 @@
+package rig
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestTarpit(t *testing.T) {
+	r := New()
+	r.Use(TarpitWithConfig(TarpitConfig{
+		Threshold: 2,
+		Delay:     50 * time.Millisecond,
+		MaxDelay:  80 * time.Millisecond,
+	}))
+	r.GET("/login", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+
+	// The first two requests are served at once; the next are delayed by
+	// 50ms, then 80ms (capped)
+	wants := []time.Duration{0, 0, 50 * time.Millisecond, 80 * time.Millisecond, 80 * time.Millisecond}
+	for i, want := range wants {
+		start := time.Now()
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil))
+		elapsed := time.Since(start)
+
+		if w.Code != http.StatusOK {
+			t.Errorf("request %d: status = %d", i, w.Code)
+		}
+		if elapsed < want || (want == 0 && elapsed >= 50*time.Millisecond) {
+			t.Errorf("request %d: took %v, want a delay of %v", i, elapsed, want)
+		}
+	}
+
+	// Another client is not slowed down
+	req := httptest.NewRequest(http.MethodGet, "/login", nil)
+	req.RemoteAddr = "203.0.113.9:1234"
+	start := time.Now()
+	r.ServeHTTP(httptest.NewRecorder(), req)
+	if elapsed := time.Since(start); elapsed >= 50*time.Millisecond {
+		t.Errorf("other client: took %v", elapsed)
+	}
+}
+
+func TestTarpitWithConfig_Counted(t *testing.T) {
+	r := New()
+	r.Use(TarpitWithConfig(TarpitConfig{
+		Threshold: 1,
+		Delay:     time.Hour,
+		Counted: func(c *Context, err error) bool {
+			return err != nil
+		},
+	}))
+	r.POST("/login", func(c *Context) error {
+		if c.Query("password") != "secret" {
+			return NewHTTPError(http.StatusUnauthorized, "invalid credentials")
+		}
+		return c.String(http.StatusOK, "welcome")
+	})
+
+	// Successful logins are not counted
+	for range 3 {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login?password=secret", nil))
+		if w.Code != http.StatusOK {
+			t.Fatalf("status = %d", w.Code)
+		}
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login?password=guess", nil))
+	if w.Code != http.StatusUnauthorized {
+		t.Fatalf("failed login: status = %d", w.Code)
+	}
+
+	// The next attempt is delayed until the client gives up
+	ctx, cancel := context.WithTimeout(t.Context(), 20*time.Millisecond)
+	defer cancel()
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/login?password=secret", nil).WithContext(ctx))
+	if w.Body.String() == "welcome" {
+		t.Error("request over the threshold was served without delay")
+	}
+}
+
+func TestTarpit_ParallelBurst(t *testing.T) {
+	r := New()
+	r.Use(TarpitWithConfig(TarpitConfig{
+		Threshold: 2,
+		Delay:     time.Hour,
+	}))
+	release := make(chan struct{})
+	r.GET("/login", func(c *Context) error {
+		<-release
+		return c.String(http.StatusOK, "ok")
+	})
+
+	// Requests in flight count toward Threshold before their handlers return
+	served := make(chan int, 5)
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	for range 5 {
+		go func() {
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/login", nil).WithContext(ctx))
+			served <- w.Code
+		}()
+	}
+	<-ctx.Done()
+	close(release)
+
+	ok := 0
+	for range 5 {
+		if <-served == http.StatusOK {
+			ok++
+		}
+	}
+	if ok != 2 {
+		t.Errorf("%d requests served without delay, want 2", ok)
+	}
+}
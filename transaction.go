@@ -0,0 +1,244 @@
+package rig
+
+import (
+	"bufio"
+	"fmt"
+	"maps"
+	"net"
+	"net/http"
+	"slices"
+)
+
+// DefaultTxKey is the context store key the Transaction middleware uses
+// for the open transaction.
+const DefaultTxKey = "tx"
+
+// Tx is a unit of work that can be committed or rolled back.
+// *sql.Tx satisfies it, as do the transaction types of most database drivers.
+type Tx interface {
+	Commit() error
+	Rollback() error
+}
+
+// TxConfig defines the configuration for the Transaction middleware.
+type TxConfig struct {
+	// Begin opens a transaction for the request. It is required.
+	// Use c.Context() so the transaction is cancelled with the request.
+	Begin func(c *Context) (Tx, error)
+
+	// Key is the context store key the transaction is stored under.
+	// Default: DefaultTxKey ("tx").
+	Key string
+
+	// Methods lists the HTTP methods that get a transaction; requests with
+	// other methods pass through untouched.
+	// Default: POST, PUT, PATCH, DELETE.
+	Methods []string
+
+	// OnRollbackError is called when rolling back fails. The handler's own
+	// error is still returned to the error handler.
+	// Default: ignore rollback errors.
+	OnRollbackError func(c *Context, err error)
+}
+
+// Transaction creates middleware that opens a transaction per request and
+// stores it in the context under config.Key. The transaction is committed if
+// the handler returns nil, and rolled back if the handler returns an error or
+// panics (the panic is re-raised so Recover still handles it).
+//
+// The response is buffered until the transaction is committed, so clients
+// never see a success response for changes that were not saved: if the
+// commit fails, the buffered response is discarded and the commit error is
+// returned to the error handler (500 by default). A handler that flushes
+// (e.g., c.Stream) commits the transaction at the first flush instead, and
+// the rest of the response is streamed.
+//
+// To enable transactions only for some routes, register the middleware on a
+// RouteGroup or combine it with When (e.g., rig.When(rig.PathPrefix("/api"), tx)).
+//
+// Example:
+//
+//	r.Use(rig.Transaction(rig.TxConfig{
+//	    Begin: func(c *rig.Context) (rig.Tx, error) {
+//	        return db.BeginTx(c.Context(), nil)
+//	    },
+//	}))
+//
+//	r.POST("/orders", func(c *rig.Context) error {
+//	    tx, err := rig.GetType[*sql.Tx](c, rig.DefaultTxKey)
+//	    if err != nil {
+//	        return err
+//	    }
+//	    _, err = tx.ExecContext(c.Context(), "INSERT INTO orders ...")
+//	    return err // a non-nil error rolls the transaction back
+//	})
+func Transaction(config TxConfig) MiddlewareFunc {
+	if config.Begin == nil {
+		panic("rig: Transaction requires a Begin function")
+	}
+	if config.Key == "" {
+		config.Key = DefaultTxKey
+	}
+	if config.Methods == nil {
+		config.Methods = []string{http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+	}
+
+	return func(next HandlerFunc) HandlerFunc {
+		return func(c *Context) error {
+			if !slices.Contains(config.Methods, c.Method()) {
+				return next(c)
+			}
+
+			tx, err := config.Begin(c)
+			if err != nil {
+				return fmt.Errorf("rig: begin transaction: %w", err)
+			}
+			c.Set(config.Key, tx)
+
+			committed := false
+			defer func() {
+				if committed {
+					return
+				}
+				if rbErr := tx.Rollback(); rbErr != nil && config.OnRollbackError != nil {
+					config.OnRollbackError(c, rbErr)
+				}
+			}()
+
+			tw := &txWriter{
+				ResponseWriter: c.writer.ResponseWriter,
+				header:         c.writer.Header().Clone(),
+				commit: func() error {
+					// A failed commit leaves nothing to roll back
+					committed = true
+					return tx.Commit()
+				},
+			}
+			c.writer.ResponseWriter = tw
+			sent := false
+			defer func() {
+				c.writer.ResponseWriter = tw.ResponseWriter
+				if !sent && !tw.streaming {
+					// Panicked: drop the response so Recover can answer
+					tw.discard(c)
+				}
+			}()
+
+			err = next(c)
+			sent = true
+			if err != nil && tw.err == nil {
+				// The handler chose what to send, if anything
+				_ = tw.send()
+				return err
+			}
+
+			// Unless a flush already tried, commit before anything is sent
+			if !tw.streaming && tw.err == nil {
+				tw.err = tw.commit()
+			}
+			if tw.err != nil {
+				tw.discard(c)
+				return fmt.Errorf("rig: commit transaction: %w", tw.err)
+			}
+			return tw.send()
+		}
+	}
+}
+
+// txWriter buffers a response until the transaction is committed. The first
+// flush commits the transaction and streams the rest of the response.
+type txWriter struct {
+	http.ResponseWriter
+	header http.Header // Headers before the handler ran
+	commit func() error
+
+	status    int
+	buf       []byte
+	streaming bool
+	err       error // Commit error from a flush
+}
+
+// WriteHeader records the status; informational (1xx) responses are sent
+// immediately.
+func (w *txWriter) WriteHeader(code int) {
+	if code < 200 || w.streaming {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	if w.status == 0 {
+		w.status = code
+	}
+}
+
+// Write buffers data until the response is flushed.
+func (w *txWriter) Write(b []byte) (int, error) {
+	if w.err != nil {
+		return 0, w.err
+	}
+	if w.streaming {
+		return w.ResponseWriter.Write(b)
+	}
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	w.buf = append(w.buf, b...)
+	return len(b), nil
+}
+
+// Flush implements http.Flusher: it commits the transaction, then sends the
+// buffered response. If the commit fails, nothing is sent and later writes
+// fail with the commit error.
+func (w *txWriter) Flush() {
+	if w.err != nil {
+		return
+	}
+	if !w.streaming {
+		if err := w.commit(); err != nil {
+			w.err = err
+			return
+		}
+		if err := w.send(); err != nil {
+			return
+		}
+		w.streaming = true
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+// Hijack implements http.Hijacker for WebSocket upgrades.
+func (w *txWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(w.ResponseWriter).Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter for http.ResponseController.
+func (w *txWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// send writes the buffered response, if any.
+func (w *txWriter) send() error {
+	if w.streaming || w.status == 0 {
+		return nil
+	}
+	w.ResponseWriter.WriteHeader(w.status)
+	w.status = 0
+	if len(w.buf) == 0 {
+		return nil
+	}
+	_, err := w.ResponseWriter.Write(w.buf)
+	w.buf = nil
+	return err
+}
+
+// discard drops the buffered response and the headers the handler set, so
+// the error handler can write its own response.
+func (w *txWriter) discard(c *Context) {
+	w.status = 0
+	w.buf = nil
+	h := w.ResponseWriter.Header()
+	clear(h)
+	maps.Copy(h, w.header)
+	c.written = false
+	c.writer.status = 0
+	c.writer.size = 0
+}
@@ -0,0 +1,230 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakeTx struct {
+	committed   bool
+	rolledBack  bool
+	commitErr   error
+	rollbackErr error
+}
+
+func (tx *fakeTx) Commit() error {
+	tx.committed = true
+	return tx.commitErr
+}
+
+func (tx *fakeTx) Rollback() error {
+	tx.rolledBack = true
+	return tx.rollbackErr
+}
+
+func txRouter(tx *fakeTx, handler HandlerFunc) *Router {
+	r := New()
+	r.Use(Recover())
+	r.Use(Transaction(TxConfig{
+		Begin: func(c *Context) (Tx, error) { return tx, nil },
+	}))
+	r.POST("/", handler)
+	r.GET("/", handler)
+	return r
+}
+
+func TestTransaction_Commit(t *testing.T) {
+	tx := &fakeTx{}
+	var stored Tx
+	r := txRouter(tx, func(c *Context) error {
+		stored, _ = GetType[Tx](c, DefaultTxKey)
+		return c.JSON(http.StatusCreated, nil)
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if stored != tx {
+		t.Error("transaction not stored in context")
+	}
+	if !tx.committed || tx.rolledBack {
+		t.Errorf("committed = %v, rolledBack = %v; want commit only", tx.committed, tx.rolledBack)
+	}
+}
+
+func TestTransaction_BuffersUntilCommit(t *testing.T) {
+	tx := &fakeTx{}
+	var beforeFlush, afterFlush bool
+	r := txRouter(tx, func(c *Context) error {
+		w := c.Writer()
+		_, _ = w.Write([]byte("a"))
+		beforeFlush = tx.committed
+		_ = http.NewResponseController(w).Flush()
+		afterFlush = tx.committed
+		_, err := w.Write([]byte("b"))
+		return err
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if beforeFlush || !afterFlush {
+		t.Error("transaction not committed at the first flush")
+	}
+	if w.Code != http.StatusOK || w.Body.String() != "ab" {
+		t.Errorf("got %d %q, want 200 %q", w.Code, w.Body.String(), "ab")
+	}
+	if tx.rolledBack {
+		t.Error("committed transaction rolled back")
+	}
+}
+
+func TestTransaction_RollbackOnError(t *testing.T) {
+	tx := &fakeTx{}
+	r := txRouter(tx, func(c *Context) error {
+		return errors.New("insert failed")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if tx.committed || !tx.rolledBack {
+		t.Errorf("committed = %v, rolledBack = %v; want rollback only", tx.committed, tx.rolledBack)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestTransaction_RollbackOnPanic(t *testing.T) {
+	tx := &fakeTx{}
+	r := txRouter(tx, func(c *Context) error {
+		panic("boom")
+	})
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+	if tx.committed || !tx.rolledBack {
+		t.Errorf("committed = %v, rolledBack = %v; want rollback only", tx.committed, tx.rolledBack)
+	}
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("status = %d, want %d (panic should reach Recover)", w.Code, http.StatusInternalServerError)
+	}
+}
+
+func TestTransaction_SkipsReadMethods(t *testing.T) {
+	begun := false
+	r := New()
+	r.Use(Transaction(TxConfig{
+		Begin: func(c *Context) (Tx, error) {
+			begun = true
+			return &fakeTx{}, nil
+		},
+	}))
+	r.GET("/", func(c *Context) error {
+		if _, ok := c.Get(DefaultTxKey); ok {
+			t.Error("transaction stored for GET request")
+		}
+		return nil
+	})
+
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if begun {
+		t.Error("Begin called for GET request")
+	}
+}
+
+func TestTransaction_Errors(t *testing.T) {
+	t.Run("begin", func(t *testing.T) {
+		r := New()
+		r.Use(Transaction(TxConfig{
+			Begin: func(c *Context) (Tx, error) { return nil, errors.New("pool exhausted") },
+		}))
+		called := false
+		r.POST("/", func(c *Context) error {
+			called = true
+			return nil
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if called {
+			t.Error("handler called after Begin failed")
+		}
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+		}
+	})
+
+	t.Run("commit", func(t *testing.T) {
+		tx := &fakeTx{commitErr: errors.New("serialization failure")}
+		r := txRouter(tx, func(c *Context) error {
+			c.SetHeader("Location", "/orders/1")
+			return c.JSON(http.StatusCreated, map[string]int{"id": 1})
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if w.Code != http.StatusInternalServerError {
+			t.Errorf("status = %d, want %d", w.Code, http.StatusInternalServerError)
+		}
+		if strings.Contains(w.Body.String(), `"id"`) || w.Header().Get("Location") != "" {
+			t.Errorf("success response sent after failed commit: %v %s", w.Header(), w.Body.String())
+		}
+	})
+
+	t.Run("commit on flush", func(t *testing.T) {
+		tx := &fakeTx{commitErr: errors.New("serialization failure")}
+		var writeErr error
+		r := txRouter(tx, func(c *Context) error {
+			_, _ = c.Writer().Write([]byte("first"))
+			_ = http.NewResponseController(c.Writer()).Flush()
+			_, writeErr = c.Writer().Write([]byte("second"))
+			return writeErr
+		})
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if writeErr == nil {
+			t.Error("write after failed commit succeeded")
+		}
+		if w.Code != http.StatusInternalServerError || strings.Contains(w.Body.String(), "first") {
+			t.Errorf("got %d %q, want 500 without the handler's output", w.Code, w.Body.String())
+		}
+	})
+
+	t.Run("rollback", func(t *testing.T) {
+		var reported error
+		r := New()
+		r.Use(Transaction(TxConfig{
+			Begin: func(c *Context) (Tx, error) {
+				return &fakeTx{rollbackErr: errors.New("connection lost")}, nil
+			},
+			OnRollbackError: func(c *Context, err error) { reported = err },
+		}))
+		r.POST("/", func(c *Context) error { return errors.New("failed") })
+
+		r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/", nil))
+
+		if reported == nil || reported.Error() != "connection lost" {
+			t.Errorf("OnRollbackError got %v", reported)
+		}
+	})
+}
+
+func TestTransaction_RequiresBegin(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic without Begin")
+		}
+	}()
+	Transaction(TxConfig{})
+}
@@ -0,0 +1,85 @@
+package rig
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestUserAgentFilter(t *testing.T) {
+	config := UserAgentConfig{
+		Deny:       []string{`bot|crawler`, `^curl/`},
+		Allow:      []string{`googlebot`},
+		BlockEmpty: true,
+	}
+	tests := []struct {
+		ua   string
+		code int
+	}{
+		{"Mozilla/5.0 (X11; Linux x86_64) Firefox/128.0", http.StatusOK},
+		{"Mozilla/5.0 (compatible; Googlebot/2.1)", http.StatusOK},
+		{"Mozilla/5.0 (compatible; AhrefsBot/7.0)", http.StatusForbidden},
+		{"curl/8.5.0", http.StatusForbidden},
+		{"", http.StatusForbidden},
+	}
+
+	r := New()
+	r.Use(UserAgentFilter(config))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", tt.ua)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != tt.code {
+			t.Errorf("User-Agent %q: status = %d, want %d", tt.ua, w.Code, tt.code)
+		}
+	}
+
+	config.BlockEmpty = false
+	r = New()
+	r.Use(UserAgentFilter(config))
+	r.GET("/", func(c *Context) error {
+		return c.String(http.StatusOK, "ok")
+	})
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("empty User-Agent allowed: status = %d", w.Code)
+	}
+}
+
+func TestUserAgentFilter_TagOnly(t *testing.T) {
+	r := New()
+	r.Use(UserAgentFilter(UserAgentConfig{Deny: []string{`bot`}, TagOnly: true}))
+	r.GET("/", func(c *Context) error {
+		var buf bytes.Buffer
+		c.Set(LoggerKey, slog.New(slog.NewTextHandler(&buf, nil)))
+		Logger(c).Info("served")
+		return c.String(http.StatusOK, "%t %t", UserAgentBlocked(c), strings.Contains(buf.String(), "blocked_user_agent=true"))
+	})
+
+	for ua, want := range map[string]string{"SemrushBot/7": "true true", "Mozilla/5.0": "false false"} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", ua)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		if w.Code != http.StatusOK || w.Body.String() != want {
+			t.Errorf("User-Agent %q: got %d %q, want 200 %q", ua, w.Code, w.Body.String(), want)
+		}
+	}
+}
+
+func TestUserAgentFilter_InvalidPattern(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("invalid pattern did not panic")
+		}
+	}()
+	UserAgentFilter(UserAgentConfig{Deny: []string{`(`}})
+}
@@ -0,0 +1,340 @@
+package rig
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validator validates a bound struct.
+// Implementations should return a *ValidationError describing each invalid
+// field, so the per-field messages reach the client.
+//
+// Use Router.SetValidator to plug in a third-party library
+// (e.g., go-playground/validator) in place of the built-in TagValidator.
+type Validator interface {
+	Validate(v any) error
+}
+
+// SetValidator sets the Validator used by Validate and BindAndValidate.
+// By default, a TagValidator is used.
+func (r *Router) SetValidator(v Validator) {
+	r.validator = v
+}
+
+// FieldError describes a single field that failed validation.
+type FieldError struct {
+	// Field is the field name as seen by the client (json tag if present),
+	// using dotted paths for nested structs (e.g., "address.zip" or "items[0].sku").
+	Field string `json:"field"`
+
+	// Rule is the validation rule that failed (e.g., "required", "min").
+	Rule string `json:"rule"`
+
+	// Param is the rule parameter, if any (e.g., "3" for "min=3").
+	Param string `json:"param,omitempty"`
+
+	// Message is a human-readable description of the failure.
+	Message string `json:"message"`
+}
+
+// ValidationError is returned when one or more fields fail validation.
+// It reports http.StatusUnprocessableEntity through StatusCode.
+type ValidationError struct {
+	Errors []FieldError `json:"errors"`
+}
+
+// Error implements the error interface.
+func (e *ValidationError) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Message
+	}
+	return "rig: validation failed: " + strings.Join(msgs, "; ")
+}
+
+// StatusCode returns http.StatusUnprocessableEntity.
+func (e *ValidationError) StatusCode() int {
+	return http.StatusUnprocessableEntity
+}
+
+// Validate validates v with the router's Validator (TagValidator by default).
+// Messages of a returned *ValidationError are translated to the request
+// locale using the keys "rig.validation.<rule>" (e.g., "rig.validation.required"),
+// where "{field}" and "{param}" are replaced with the field name and rule parameter.
+func (c *Context) Validate(v any) error {
+	var validator Validator = TagValidator{}
+	if c.router != nil && c.router.validator != nil {
+		validator = c.router.validator
+	}
+
+	err := validator.Validate(v)
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		for i := range ve.Errors {
+			fe := &ve.Errors[i]
+			if msg := c.Translate("rig.validation."+fe.Rule, ""); msg != "" {
+				fe.Message = strings.NewReplacer("{field}", fe.Field, "{param}", fe.Param).Replace(msg)
+			}
+		}
+	}
+	return err
+}
+
+// BindAndValidate decodes the request body into v using BindAny and then
+// validates it with Validate.
+//
+// Example:
+//
+//	type CreateUser struct {
+//	    Name  string `json:"name" validate:"required,min=3"`
+//	    Email string `json:"email" validate:"required,email"`
+//	    Role  string `json:"role" validate:"oneof=admin member"`
+//	}
+//
+//	r.POST("/users", func(c *rig.Context) error {
+//	    var req CreateUser
+//	    if err := c.BindAndValidate(&req); err != nil {
+//	        var ve *rig.ValidationError
+//	        if errors.As(err, &ve) {
+//	            return c.JSON(http.StatusUnprocessableEntity, ve)
+//	        }
+//	        return err
+//	    }
+//	    ...
+//	})
+func (c *Context) BindAndValidate(v any) error {
+	if err := c.BindAny(v); err != nil {
+		return err
+	}
+	return c.Validate(v)
+}
+
+// TagValidator is the built-in Validator. It checks the rules listed in
+// each field's `validate` tag, separated by commas:
+//
+//	required   the value must not be the zero value (or empty, for slices and maps)
+//	min=N      minimum length (strings, slices, maps) or value (numbers)
+//	max=N      maximum length (strings, slices, maps) or value (numbers)
+//	len=N      exact length (strings, slices, maps)
+//	email      the value must be a valid email address
+//	url        the value must be an absolute URL
+//	oneof=a b  the value must be one of the space-separated options
+//
+// Rules other than required are skipped for zero values, so optional fields
+// are only validated when present. Nested structs, pointers to structs, and
+// slices of structs are validated recursively.
+type TagValidator struct{}
+
+// Validate implements Validator.
+func (TagValidator) Validate(v any) error {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil
+	}
+
+	var fieldErrors []FieldError
+	if err := validateStruct(rv, "", &fieldErrors); err != nil {
+		return err
+	}
+	if len(fieldErrors) > 0 {
+		return &ValidationError{Errors: fieldErrors}
+	}
+	return nil
+}
+
+// validateStruct checks every field of sv, appending failures to errs.
+func validateStruct(sv reflect.Value, prefix string, errs *[]FieldError) error {
+	st := sv.Type()
+	for i := range st.NumField() {
+		sf := st.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		fv := sv.Field(i)
+
+		name := fieldName(sf)
+		if prefix != "" {
+			name = prefix + "." + name
+		}
+		if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+			name = prefix
+		}
+
+		if tag := sf.Tag.Get("validate"); tag != "" && tag != "-" {
+			fe, err := checkRules(fv, name, tag)
+			if err != nil {
+				return err
+			}
+			if fe != nil {
+				*errs = append(*errs, *fe)
+				continue
+			}
+		}
+
+		if err := validateNested(fv, name, errs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// validateNested recurses into struct, pointer-to-struct, and slice-of-struct fields.
+func validateNested(fv reflect.Value, name string, errs *[]FieldError) error {
+	switch fv.Kind() {
+	case reflect.Pointer:
+		if fv.IsNil() {
+			return nil
+		}
+		return validateNested(fv.Elem(), name, errs)
+	case reflect.Struct:
+		if isScalarType(fv.Type()) {
+			return nil
+		}
+		return validateStruct(fv, name, errs)
+	case reflect.Slice, reflect.Array:
+		for i := range fv.Len() {
+			if err := validateNested(fv.Index(i), fmt.Sprintf("%s[%d]", name, i), errs); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// fieldName returns the client-facing name of a struct field.
+func fieldName(sf reflect.StructField) string {
+	for _, tag := range []string{"json", "form", "query"} {
+		if name, _, _ := strings.Cut(sf.Tag.Get(tag), ","); name != "" && name != "-" {
+			return name
+		}
+	}
+	return sf.Name
+}
+
+// checkRules applies the comma-separated rules in tag to fv.
+// It returns the first failing rule, or an error for malformed rules.
+func checkRules(fv reflect.Value, name, tag string) (*FieldError, error) {
+	rules := strings.Split(tag, ",")
+
+	// Dereference pointers; a nil pointer is treated as a zero value
+	value := fv
+	for value.Kind() == reflect.Pointer && !value.IsNil() {
+		value = value.Elem()
+	}
+	zero := isEmptyValue(value)
+
+	for _, rule := range rules {
+		rule, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+		if rule == "" {
+			continue
+		}
+
+		if rule == "required" {
+			if zero {
+				return &FieldError{Field: name, Rule: rule, Message: name + " is required"}, nil
+			}
+			continue
+		}
+		if zero {
+			continue
+		}
+
+		ok, msg, err := checkRule(value, rule, param)
+		if err != nil {
+			return nil, fmt.Errorf("rig: invalid validation rule %q on field %s: %w", rule, name, err)
+		}
+		if !ok {
+			return &FieldError{Field: name, Rule: rule, Param: param, Message: name + " " + msg}, nil
+		}
+	}
+	return nil, nil
+}
+
+// isEmptyValue reports whether v is the zero value or an empty collection.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	}
+	return v.IsZero()
+}
+
+// checkRule evaluates a single rule. It returns whether the value passed and,
+// if not, a message fragment such as "must be at least 3 characters".
+func checkRule(v reflect.Value, rule, param string) (bool, string, error) {
+	switch rule {
+	case "min", "max", "len":
+		n, err := strconv.ParseFloat(param, 64)
+		if err != nil {
+			return false, "", err
+		}
+		return checkSize(v, rule, n)
+
+	case "email":
+		s := fmt.Sprint(v.Interface())
+		addr, err := mail.ParseAddress(s)
+		return err == nil && addr.Address == s, "must be a valid email address", nil
+
+	case "url":
+		u, err := url.ParseRequestURI(fmt.Sprint(v.Interface()))
+		return err == nil && u.Scheme != "" && u.Host != "", "must be a valid URL", nil
+
+	case "oneof":
+		s := fmt.Sprint(v.Interface())
+		options := strings.Fields(param)
+		for _, opt := range options {
+			if s == opt {
+				return true, "", nil
+			}
+		}
+		return false, "must be one of: " + strings.Join(options, ", "), nil
+	}
+
+	return false, "", errors.New("unknown rule")
+}
+
+// checkSize evaluates min, max, and len against a length or numeric value.
+func checkSize(v reflect.Value, rule string, n float64) (bool, string, error) {
+	var size float64
+	unit := ""
+
+	switch v.Kind() {
+	case reflect.String:
+		size = float64(utf8.RuneCountInString(v.String()))
+		unit = " characters"
+	case reflect.Slice, reflect.Map, reflect.Array:
+		size = float64(v.Len())
+		unit = " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		size = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		size = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		size = v.Float()
+	default:
+		return false, "", fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	param := strconv.FormatFloat(n, 'f', -1, 64)
+	switch rule {
+	case "min":
+		return size >= n, "must be at least " + param + unit, nil
+	case "max":
+		return size <= n, "must be at most " + param + unit, nil
+	default:
+		return size == n, "must be exactly " + param + unit, nil
+	}
+}
@@ -0,0 +1,179 @@
+package rig
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type validateAddress struct {
+	Zip string `json:"zip" validate:"required,len=5"`
+}
+
+type validateItem struct {
+	SKU string `json:"sku" validate:"required"`
+	Qty int    `json:"qty" validate:"min=1,max=99"`
+}
+
+type validateUser struct {
+	Name    string           `json:"name" validate:"required,min=3"`
+	Email   string           `json:"email" validate:"required,email"`
+	Website string           `json:"website" validate:"url"`
+	Role    string           `json:"role" validate:"oneof=admin member"`
+	Tags    []string         `json:"tags" validate:"max=2"`
+	Age     *int             `json:"age" validate:"min=18"`
+	Address *validateAddress `json:"address"`
+	Items   []validateItem   `json:"items"`
+}
+
+func TestTagValidator_Valid(t *testing.T) {
+	age := 30
+	u := validateUser{
+		Name:    "Ada",
+		Email:   "ada@example.com",
+		Website: "https://example.com",
+		Role:    "admin",
+		Tags:    []string{"a"},
+		Age:     &age,
+		Address: &validateAddress{Zip: "12345"},
+		Items:   []validateItem{{SKU: "X1", Qty: 2}},
+	}
+
+	if err := (TagValidator{}).Validate(&u); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestTagValidator_Invalid(t *testing.T) {
+	age := 12
+	u := validateUser{
+		Name:    "Al",
+		Email:   "not-an-email",
+		Website: "example",
+		Role:    "owner",
+		Tags:    []string{"a", "b", "c"},
+		Age:     &age,
+		Address: &validateAddress{Zip: "1"},
+		Items:   []validateItem{{SKU: "X1", Qty: 1}, {Qty: 100}},
+	}
+
+	err := (TagValidator{}).Validate(&u)
+
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		t.Fatalf("Validate() error = %v, want *ValidationError", err)
+	}
+
+	want := map[string]string{
+		"name":         "name must be at least 3 characters",
+		"email":        "email must be a valid email address",
+		"website":      "website must be a valid URL",
+		"role":         "role must be one of: admin, member",
+		"tags":         "tags must be at most 2 items",
+		"age":          "age must be at least 18",
+		"address.zip":  "address.zip must be exactly 5 characters",
+		"items[1].sku": "items[1].sku is required",
+		"items[1].qty": "items[1].qty must be at most 99",
+	}
+	if len(ve.Errors) != len(want) {
+		t.Errorf("len(Errors) = %d, want %d: %+v", len(ve.Errors), len(want), ve.Errors)
+	}
+	for _, fe := range ve.Errors {
+		if msg, ok := want[fe.Field]; !ok || msg != fe.Message {
+			t.Errorf("unexpected field error %+v", fe)
+		}
+	}
+}
+
+func TestTagValidator_OptionalFieldsSkipped(t *testing.T) {
+	u := struct {
+		Email string `validate:"email"`
+		Count int    `validate:"min=5"`
+	}{}
+
+	if err := (TagValidator{}).Validate(&u); err != nil {
+		t.Errorf("Validate() error = %v, want nil for empty optional fields", err)
+	}
+}
+
+func TestTagValidator_InvalidRule(t *testing.T) {
+	u := struct {
+		Name string `validate:"bogus"`
+	}{Name: "x"}
+
+	err := (TagValidator{}).Validate(&u)
+	if err == nil || !strings.Contains(err.Error(), `invalid validation rule "bogus"`) {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
+
+func TestContext_BindAndValidate(t *testing.T) {
+	r := New()
+	r.SetTranslator(Catalog{
+		"de": {"rig.validation.required": "{field} ist erforderlich"},
+	})
+	r.POST("/users", func(c *Context) error {
+		var u validateUser
+		if err := c.BindAndValidate(&u); err != nil {
+			return err
+		}
+		return c.JSON(http.StatusCreated, u)
+	})
+
+	tests := []struct {
+		name       string
+		body       string
+		lang       string
+		wantStatus int
+		wantBody   string
+	}{
+		{"valid", `{"name":"Ada","email":"ada@example.com"}`, "", http.StatusCreated, `"name":"Ada"`},
+		{"invalid", `{"name":"Al","email":"ada@example.com"}`, "", http.StatusUnprocessableEntity, "name must be at least 3 characters"},
+		{"localized", `{"email":"ada@example.com"}`, "de", http.StatusUnprocessableEntity, "name ist erforderlich"},
+		{"malformed", `{"name":`, "", http.StatusInternalServerError, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/users", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			if tt.lang != "" {
+				req.Header.Set("Accept-Language", tt.lang)
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d; body = %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+			if !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("body = %q, want to contain %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+}
+
+type rejectAllValidator struct{}
+
+func (rejectAllValidator) Validate(v any) error {
+	return &ValidationError{Errors: []FieldError{{Field: "all", Rule: "custom", Message: "rejected"}}}
+}
+
+func TestRouter_SetValidator(t *testing.T) {
+	r := New()
+	r.SetValidator(rejectAllValidator{})
+
+	var validateErr error
+	r.GET("/", func(c *Context) error {
+		validateErr = c.Validate(&struct{}{})
+		return nil
+	})
+	r.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+
+	var ve *ValidationError
+	if !errors.As(validateErr, &ve) || ve.Errors[0].Message != "rejected" {
+		t.Errorf("Validate() error = %v, want custom validator error", validateErr)
+	}
+}
@@ -0,0 +1,114 @@
+package rig
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// WarmupHeader is set on the requests the server sends to itself during
+// warm-up, so middleware (logging, metrics, rate limiting) can skip them.
+const WarmupHeader = "X-Rig-Warmup"
+
+// defaultWarmupTimeout bounds the warm-up phase when WarmupTimeout is unset.
+const defaultWarmupTimeout = 30 * time.Second
+
+// WarmupFunc prepares the application for traffic (e.g., loading templates,
+// opening database connections, filling caches). It is called once after
+// the listener binds.
+//
+// Example:
+//
+//	config := rig.DefaultServerConfig()
+//	config.WarmupFuncs = []rig.WarmupFunc{
+//	    func(ctx context.Context) error { return db.PingContext(ctx) },
+//	}
+//	config.WarmupRequests = []string{"GET /", "GET /products"}
+//	config.Health = health
+type WarmupFunc func(ctx context.Context) error
+
+// listenAndServe binds the listener, starts warm-up (if configured) in the
+// background, and serves until the server is closed.
+func listenAndServe(server *http.Server, config ServerConfig, logf LogFunc) error {
+	if len(config.WarmupRequests) == 0 && len(config.WarmupFuncs) == 0 {
+		return server.ListenAndServe()
+	}
+
+	addr := server.Addr
+	if addr == "" {
+		addr = ":http"
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	if config.Health != nil {
+		config.Health.SetWarmingUp(true)
+	}
+	go func() {
+		runWarmup(config, ln.Addr(), logf)
+		if config.Health != nil {
+			config.Health.SetWarmingUp(false)
+		}
+	}()
+	return server.Serve(ln)
+}
+
+// runWarmup calls the warm-up functions, then sends the warm-up requests to
+// the server listening on addr.
+func runWarmup(config ServerConfig, addr net.Addr, logf LogFunc) {
+	timeout := config.WarmupTimeout
+	if timeout == 0 {
+		timeout = defaultWarmupTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	start := time.Now()
+	for i, fn := range config.WarmupFuncs {
+		if err := fn(ctx); err != nil {
+			logf("Warm-up function %d failed: %v", i, err)
+		}
+	}
+
+	base := "http://" + warmupHost(addr)
+	client := &http.Client{}
+	for _, target := range config.WarmupRequests {
+		method, path := http.MethodGet, strings.TrimSpace(target)
+		if m, p, found := strings.Cut(path, " "); found {
+			method, path = m, strings.TrimSpace(p)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, base+path, nil)
+		if err != nil {
+			logf("Warm-up request %q failed: %v", target, err)
+			continue
+		}
+		req.Header.Set(WarmupHeader, "1")
+
+		resp, err := client.Do(req)
+		if err != nil {
+			logf("Warm-up request %q failed: %v", target, err)
+			continue
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode >= http.StatusInternalServerError {
+			logf("Warm-up request %q returned %d", target, resp.StatusCode)
+		}
+	}
+	logf("Warm-up finished in %v", time.Since(start).Round(time.Millisecond))
+}
+
+// warmupHost returns the host:port to reach a listener on addr, using the
+// loopback address when the listener is bound to all interfaces.
+func warmupHost(addr net.Addr) string {
+	tcp, ok := addr.(*net.TCPAddr)
+	if !ok || !tcp.IP.IsUnspecified() {
+		return addr.String()
+	}
+	return net.JoinHostPort("127.0.0.1", strconv.Itoa(tcp.Port))
+}
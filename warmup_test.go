@@ -0,0 +1,140 @@
+package rig
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListenAndServe_Warmup(t *testing.T) {
+	health := NewHealth()
+	release := make(chan struct{})
+
+	var mu sync.Mutex
+	var seen []string
+
+	r := New()
+	r.GET("/ready", health.ReadyHandler())
+	r.Handle("/warm/", func(c *Context) error {
+		mu.Lock()
+		seen = append(seen, c.Method()+" "+c.Path()+" "+c.GetHeader(WarmupHeader))
+		mu.Unlock()
+		return c.NoContent(http.StatusNoContent)
+	})
+
+	var logs strings.Builder
+	var logMu sync.Mutex
+	config := DefaultServerConfig()
+	config.Health = health
+	config.WarmupFuncs = []WarmupFunc{
+		func(ctx context.Context) error {
+			<-release
+			return nil
+		},
+		func(ctx context.Context) error { return errors.New("cache unavailable") },
+	}
+	config.WarmupRequests = []string{"/warm/a", "POST /warm/b"}
+	config.Logger = func(format string, args ...any) {
+		logMu.Lock()
+		defer logMu.Unlock()
+		logs.WriteString(format + "\n")
+	}
+
+	// Bind to a free port first so the test knows the address
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to get free port: %v", err)
+	}
+	config.Addr = ln.Addr().String()
+	_ = ln.Close()
+
+	server := &http.Server{Addr: config.Addr, Handler: r}
+	done := make(chan error, 1)
+	go func() { done <- listenAndServe(server, config, config.Logger) }()
+	defer func() {
+		_ = server.Close()
+		<-done
+	}()
+
+	ready := func() int {
+		for range 50 {
+			resp, err := http.Get("http://" + config.Addr + "/ready")
+			if err == nil {
+				_ = resp.Body.Close()
+				return resp.StatusCode
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("server did not start")
+		return 0
+	}
+
+	if code := ready(); code != http.StatusServiceUnavailable {
+		t.Errorf("ready during warm-up = %d, want 503", code)
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for ready() != http.StatusOK {
+		if time.Now().After(deadline) {
+			t.Fatal("readiness did not flip to OK after warm-up")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	want := []string{"GET /warm/a 1", "POST /warm/b 1"}
+	if strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("warm-up requests = %v, want %v", seen, want)
+	}
+
+	logMu.Lock()
+	defer logMu.Unlock()
+	if !strings.Contains(logs.String(), "Warm-up function %d failed") {
+		t.Errorf("failed warm-up function not logged:\n%s", logs.String())
+	}
+}
+
+func TestHealth_SetWarmingUp(t *testing.T) {
+	health := NewHealth()
+	health.SetWarmingUp(true)
+
+	r := New()
+	r.GET("/ready", health.ReadyHandler())
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusServiceUnavailable || !strings.Contains(w.Body.String(), "warming up") {
+		t.Errorf("warming up: %d %s", w.Code, w.Body.String())
+	}
+
+	health.SetWarmingUp(false)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("status = %d, want 200", w.Code)
+	}
+}
+
+func TestWarmupHost(t *testing.T) {
+	tests := []struct {
+		addr *net.TCPAddr
+		want string
+	}{
+		{&net.TCPAddr{IP: net.IPv4zero, Port: 8080}, "127.0.0.1:8080"},
+		{&net.TCPAddr{IP: net.IPv6unspecified, Port: 8080}, "127.0.0.1:8080"},
+		{&net.TCPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 80}, "10.0.0.1:80"},
+	}
+	for _, tt := range tests {
+		if got := warmupHost(tt.addr); got != tt.want {
+			t.Errorf("warmupHost(%v) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,246 @@
+package rig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// ContentTypeYAML is the media type for YAML responses (RFC 9512).
+const ContentTypeYAML = "application/yaml"
+
+// YAML writes v as a YAML response with the given status code and the
+// Content-Type "application/yaml".
+//
+// v is encoded following its JSON representation: struct fields are named
+// by their `json` tags, custom json.Marshaler implementations are honored,
+// and field order is preserved. This keeps YAML and JSON responses of the
+// same type consistent without an extra dependency.
+//
+// Example:
+//
+//	r.GET("/config", func(c *rig.Context) error {
+//	    return c.YAML(http.StatusOK, cfg)
+//	})
+func (c *Context) YAML(code int, v any) error {
+	data, err := MarshalYAML(v)
+	if err != nil {
+		return err
+	}
+
+	c.writer.Header().Set("Content-Type", ContentTypeYAML)
+	c.Status(code)
+	_, err = c.writer.Write(data)
+	return err
+}
+
+// MarshalYAML returns the YAML encoding of v, as written by Context.YAML.
+func MarshalYAML(v any) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	node, err := readYAMLNode(dec)
+	if err != nil {
+		return nil, err
+	}
+
+	var b strings.Builder
+	writeYAMLNode(&b, node, 0)
+	return []byte(b.String()), nil
+}
+
+// yamlPair is a key/value pair of a YAML mapping, kept in document order.
+type yamlPair struct {
+	key   string
+	value any
+}
+
+// readYAMLNode reads the next JSON value from dec as an ordered tree of
+// []yamlPair (objects), []any (arrays), and scalars.
+func readYAMLNode(dec *json.Decoder) (any, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		pairs := []yamlPair{}
+		for dec.More() {
+			keyTok, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			key, ok := keyTok.(string)
+			if !ok {
+				return nil, errors.New("rig: invalid object key in YAML encoding")
+			}
+			value, err := readYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			pairs = append(pairs, yamlPair{key, value})
+		}
+		_, err := dec.Token() // closing '}'
+		return pairs, err
+	case json.Delim('['):
+		items := []any{}
+		for dec.More() {
+			item, err := readYAMLNode(dec)
+			if err != nil {
+				return nil, err
+			}
+			items = append(items, item)
+		}
+		_, err := dec.Token() // closing ']'
+		return items, err
+	}
+	return tok, nil
+}
+
+// writeYAMLNode writes node in block style, indented by indent spaces.
+// Every line, including the last, ends with a newline.
+func writeYAMLNode(b *strings.Builder, node any, indent int) {
+	pad := strings.Repeat(" ", indent)
+
+	switch n := node.(type) {
+	case []yamlPair:
+		if len(n) == 0 {
+			b.WriteString(pad + "{}\n")
+			return
+		}
+		for _, p := range n {
+			b.WriteString(pad + yamlScalar(p.key) + ":")
+			writeYAMLChild(b, p.value, indent+2)
+		}
+	case []any:
+		if len(n) == 0 {
+			b.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range n {
+			if isYAMLCollection(item) {
+				// Render the item one level deeper, then put the dash in
+				// place of the first line's extra indentation
+				var child strings.Builder
+				writeYAMLNode(&child, item, indent+2)
+				b.WriteString(pad + "- " + child.String()[indent+2:])
+				continue
+			}
+			b.WriteString(pad + "- " + yamlValue(item) + "\n")
+		}
+	default:
+		b.WriteString(pad + yamlValue(n) + "\n")
+	}
+}
+
+// writeYAMLChild writes the value of a mapping entry after its "key:".
+func writeYAMLChild(b *strings.Builder, value any, indent int) {
+	if isYAMLCollection(value) {
+		b.WriteString("\n")
+		writeYAMLNode(b, value, indent)
+		return
+	}
+	switch v := value.(type) {
+	case []yamlPair:
+		b.WriteString(" {}\n")
+	case []any:
+		b.WriteString(" []\n")
+	default:
+		b.WriteString(" " + yamlValue(v) + "\n")
+	}
+}
+
+// isYAMLCollection reports whether node is a non-empty mapping or sequence.
+func isYAMLCollection(node any) bool {
+	switch n := node.(type) {
+	case []yamlPair:
+		return len(n) > 0
+	case []any:
+		return len(n) > 0
+	}
+	return false
+}
+
+// yamlValue formats a scalar (or an empty collection) as YAML.
+func yamlValue(v any) string {
+	switch s := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		if s {
+			return "true"
+		}
+		return "false"
+	case json.Number:
+		return s.String()
+	case string:
+		return yamlScalar(s)
+	case []yamlPair:
+		return "{}"
+	case []any:
+		return "[]"
+	}
+	return ""
+}
+
+// yamlScalar returns s as a plain YAML scalar, or double-quoted if a plain
+// scalar would be ambiguous (e.g., "true", "1.5", "null") or invalid.
+func yamlScalar(s string) string {
+	if !yamlNeedsQuotes(s) {
+		return s
+	}
+
+	// JSON string escapes are valid in YAML double-quoted scalars
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	_ = enc.Encode(s)
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// yamlNeedsQuotes reports whether s cannot be written as a plain scalar.
+func yamlNeedsQuotes(s string) bool {
+	if s == "" || s != strings.TrimSpace(s) {
+		return true
+	}
+
+	switch strings.ToLower(s) {
+	case "null", "~", "true", "false", "yes", "no", "on", "off", "y", "n", ".nan", ".inf", "-.inf", "+.inf":
+		return true
+	}
+	if json.Valid([]byte(s)) {
+		// Numbers (and anything else JSON would parse) must stay strings
+		return true
+	}
+	if strings.ContainsAny(s[:1], "-?:,[]{}#&*!|>'\"%@`") {
+		return true
+	}
+	if strings.Contains(s, ": ") || strings.Contains(s, " #") || strings.HasSuffix(s, ":") {
+		return true
+	}
+	for _, r := range s {
+		if r < 0x20 || r == 0x7f {
+			return true
+		}
+	}
+	return looksLikeYAMLNumber(s)
+}
+
+// looksLikeYAMLNumber reports whether a YAML 1.1 parser could read s as a
+// number (e.g., "0x1F", "1_000", "0o17", "1e3").
+func looksLikeYAMLNumber(s string) bool {
+	s = strings.TrimLeft(s, "+-")
+	if s == "" {
+		return false
+	}
+	if s[0] >= '0' && s[0] <= '9' {
+		return strings.Trim(s, "0123456789abcdefABCDEFxXoO_.:eE+-") == ""
+	}
+	return s[0] == '.' && len(s) > 1 && s[1] >= '0' && s[1] <= '9'
+}
@@ -0,0 +1,131 @@
+package rig
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMarshalYAML(t *testing.T) {
+	type Port struct {
+		Name string `json:"name"`
+		Port int    `json:"port"`
+	}
+	type Service struct {
+		APIVersion string            `json:"apiVersion"`
+		Kind       string            `json:"kind"`
+		Labels     map[string]string `json:"labels"`
+		Ports      []Port            `json:"ports"`
+		Hosts      []string          `json:"hosts"`
+		Matrix     [][]int           `json:"matrix"`
+		Empty      []string          `json:"empty"`
+		Meta       map[string]any    `json:"meta"`
+		Enabled    bool              `json:"enabled"`
+		Owner      *string           `json:"owner"`
+		Internal   string            `json:"-"`
+	}
+
+	svc := Service{
+		APIVersion: "v1",
+		Kind:       "Service",
+		Labels:     map[string]string{"app": "web", "tier": "frontend"},
+		Ports:      []Port{{"http", 80}, {"https", 443}},
+		Hosts:      []string{"example.com"},
+		Matrix:     [][]int{{1, 2}, {3}},
+		Empty:      []string{},
+		Meta:       map[string]any{},
+		Enabled:    true,
+	}
+
+	got, err := MarshalYAML(svc)
+	if err != nil {
+		t.Fatalf("MarshalYAML() error = %v", err)
+	}
+
+	want := `apiVersion: v1
+kind: Service
+labels:
+  app: web
+  tier: frontend
+ports:
+  - name: http
+    port: 80
+  - name: https
+    port: 443
+hosts:
+  - example.com
+matrix:
+  - - 1
+    - 2
+  - - 3
+empty: []
+meta: {}
+enabled: true
+owner: null
+`
+	if string(got) != want {
+		t.Errorf("MarshalYAML() =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestMarshalYAML_Quoting(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"hello world", "hello world"},
+		{"", `""`},
+		{"true", `"true"`},
+		{"No", `"No"`},
+		{"null", `"null"`},
+		{"42", `"42"`},
+		{"1.5", `"1.5"`},
+		{"0x1F", `"0x1F"`},
+		{"1_000", `"1_000"`},
+		{" padded", `" padded"`},
+		{"- item", `"- item"`},
+		{"key: value", `"key: value"`},
+		{"a #comment", `"a #comment"`},
+		{"line1\nline2", `"line1\nline2"`},
+		{"<b>&</b>", "<b>&</b>"},
+		{"v1.2.3", "v1.2.3"},
+		{"https://example.com/a", "https://example.com/a"},
+	}
+
+	for _, tt := range tests {
+		got, err := MarshalYAML(tt.in)
+		if err != nil {
+			t.Fatalf("MarshalYAML(%q) error = %v", tt.in, err)
+		}
+		if string(got) != tt.want+"\n" {
+			t.Errorf("MarshalYAML(%q) = %q, want %q", tt.in, got, tt.want+"\n")
+		}
+	}
+}
+
+func TestContext_YAML(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := c.YAML(http.StatusOK, map[string]any{"replicas": 3}); err != nil {
+		t.Fatalf("YAML() error = %v", err)
+	}
+	if ct := w.Header().Get("Content-Type"); ct != ContentTypeYAML {
+		t.Errorf("Content-Type = %q, want %q", ct, ContentTypeYAML)
+	}
+	if w.Body.String() != "replicas: 3\n" {
+		t.Errorf("body = %q", w.Body.String())
+	}
+}
+
+func TestContext_YAML_MarshalError(t *testing.T) {
+	w := httptest.NewRecorder()
+	c := newContext(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if err := c.YAML(http.StatusOK, map[string]any{"ch": make(chan int)}); err == nil {
+		t.Error("YAML() should fail for unsupported values")
+	}
+	if c.Written() {
+		t.Error("YAML() should not write a response on encoding failure")
+	}
+}